@@ -14,6 +14,7 @@ import (
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/logger"
 	"github.com/tokenized/smart-contract/pkg/spynode"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
 	"github.com/tokenized/smart-contract/pkg/storage"
@@ -48,12 +49,16 @@ type Config struct {
 	Contract    string  `envconfig:"CLIENT_CONTRACT_ADDRESS"`
 	ContractFee uint64  `default:"1000" envconfig:"CLIENT_CONTRACT_FEE"`
 	SpyNode     struct {
-		Address          string `default:"127.0.0.1:8333" envconfig:"CLIENT_NODE_ADDRESS"`
-		UserAgent        string `default:"/Tokenized:0.1.0/" envconfig:"CLIENT_NODE_USER_AGENT"`
-		StartHash        string `envconfig:"CLIENT_START_HASH"`
-		UntrustedClients int    `default:"16" envconfig:"CLIENT_UNTRUSTED_NODES"`
-		SafeTxDelay      int    `default:"10" envconfig:"CLIENT_SAFE_TX_DELAY"`
-		ShotgunCount     int    `default:"100" envconfig:"SHOTGUN_COUNT"`
+		Address          string   `default:"127.0.0.1:8333" envconfig:"CLIENT_NODE_ADDRESS"`
+		UserAgent        string   `default:"/Tokenized:0.1.0/" envconfig:"CLIENT_NODE_USER_AGENT"`
+		StartHash        string   `envconfig:"CLIENT_START_HASH"`
+		UntrustedClients int      `default:"16" envconfig:"CLIENT_UNTRUSTED_NODES"`
+		SafeTxDelay      int      `default:"10" envconfig:"CLIENT_SAFE_TX_DELAY"`
+		ShotgunCount     int      `default:"100" envconfig:"SHOTGUN_COUNT"`
+		StaticPeers      []string `envconfig:"CLIENT_NODE_STATIC_PEERS"`
+		ProxyAddress     string   `envconfig:"CLIENT_NODE_PROXY_ADDRESS"`
+		ProxyUsername    string   `envconfig:"CLIENT_NODE_PROXY_USERNAME"`
+		ProxyPassword    string   `envconfig:"CLIENT_NODE_PROXY_PASSWORD"`
 	}
 }
 
@@ -127,6 +132,9 @@ func (client *Client) setupSpyNode(ctx context.Context) error {
 		logger.Warn(ctx, "Failed to create spynode config : %s", err)
 		return err
 	}
+	spyConfig.StaticPeers = client.Config.SpyNode.StaticPeers
+	spyConfig.Dialer = dialer.New(client.Config.SpyNode.ProxyAddress,
+		client.Config.SpyNode.ProxyUsername, client.Config.SpyNode.ProxyPassword)
 
 	client.spyNode = spynode.NewNode(spyConfig, spyStorage)
 	client.spyNode.AddTxFilter(client)
@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/paymail"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScriptURLPrefix is the BIP-0276 prefix of a locking script.
+	ScriptURLPrefix = "bitcoin-script"
+
+	// ContractURLPrefix is the BIP-0276 prefix of a contract reference. The data is the contract's
+	//   raw address.
+	ContractURLPrefix = "tokenized-contract"
+
+	// AssetURLPrefix is the BIP-0276 prefix of an asset reference. The data is the 3 character
+	//   asset type followed by the 32 byte asset code.
+	AssetURLPrefix = "tokenized-asset"
+)
+
+// Resolver converts a payment destination entered by a user into an address.
+type Resolver interface {
+	Resolve(ctx context.Context, destination string) (bitcoin.RawAddress, error)
+}
+
+// DestinationResolver resolves base58 addresses, BIP-0276 locking scripts, and paymail handles.
+type DestinationResolver struct {
+	Net     bitcoin.Network
+	Paymail *paymail.Client // Paymail handles are not supported when nil.
+}
+
+// NewResolver returns the default destination resolver.
+func NewResolver(net bitcoin.Network) *DestinationResolver {
+	return &DestinationResolver{
+		Net:     net,
+		Paymail: paymail.NewClient(&paymail.Config{Timeout: 30 * time.Second}),
+	}
+}
+
+// Resolve returns the address for a destination.
+func (r *DestinationResolver) Resolve(ctx context.Context,
+	destination string) (bitcoin.RawAddress, error) {
+
+	destination = strings.TrimSpace(destination)
+
+	if paymail.IsHandle(destination) {
+		if r.Paymail == nil {
+			return bitcoin.RawAddress{}, fmt.Errorf("Paymail not supported : %s", destination)
+		}
+
+		script, err := r.Paymail.LockingScript(ctx, destination)
+		if err != nil {
+			return bitcoin.RawAddress{}, errors.Wrap(err, destination)
+		}
+
+		ra, err := bitcoin.RawAddressFromLockingScript(script)
+		if err != nil {
+			return bitcoin.RawAddress{}, errors.Wrap(err, fmt.Sprintf("%s locking script", destination))
+		}
+		return ra, nil
+	}
+
+	if strings.Contains(destination, ":") {
+		net, data, err := decodeBIP0276(destination, ScriptURLPrefix)
+		if err != nil {
+			return bitcoin.RawAddress{}, err
+		}
+		if !bitcoin.DecodeNetMatches(net, r.Net) {
+			return bitcoin.RawAddress{}, fmt.Errorf("Script encoded for wrong network : %s",
+				destination)
+		}
+
+		ra, err := bitcoin.RawAddressFromLockingScript(data)
+		if err != nil {
+			return bitcoin.RawAddress{}, errors.Wrap(err, "locking script")
+		}
+		return ra, nil
+	}
+
+	return decodeAddress(destination, r.Net)
+}
+
+// ParseContractReference returns the address of a contract from a BIP-0276 contract reference or
+//   a base58 address.
+func ParseContractReference(reference string, net bitcoin.Network) (bitcoin.RawAddress, error) {
+	reference = strings.TrimSpace(reference)
+	if !strings.Contains(reference, ":") {
+		return decodeAddress(reference, net)
+	}
+
+	decodedNet, data, err := decodeBIP0276(reference, ContractURLPrefix)
+	if err != nil {
+		return bitcoin.RawAddress{}, err
+	}
+	if !bitcoin.DecodeNetMatches(decodedNet, net) {
+		return bitcoin.RawAddress{}, fmt.Errorf("Contract encoded for wrong network : %s",
+			reference)
+	}
+
+	ra, err := bitcoin.DecodeRawAddress(data)
+	if err != nil {
+		return bitcoin.RawAddress{}, errors.Wrap(err, "contract address")
+	}
+	return ra, nil
+}
+
+// EncodeContractReference returns the BIP-0276 reference for a contract.
+func EncodeContractReference(ra bitcoin.RawAddress, net bitcoin.Network) string {
+	return bitcoin.BIP0276Encode(net, ContractURLPrefix, ra.Bytes())
+}
+
+// ParseAssetReference returns the asset type and code from a BIP-0276 asset reference or an asset
+//   ID.
+func ParseAssetReference(reference string, net bitcoin.Network) (string, *protocol.AssetCode,
+	error) {
+
+	reference = strings.TrimSpace(reference)
+	if !strings.Contains(reference, ":") {
+		assetType, assetCode, err := protocol.DecodeAssetID(reference)
+		if err != nil {
+			return "", nil, err
+		}
+		return assetType, &assetCode, nil
+	}
+
+	decodedNet, data, err := decodeBIP0276(reference, AssetURLPrefix)
+	if err != nil {
+		return "", nil, err
+	}
+	if !bitcoin.DecodeNetMatches(decodedNet, net) {
+		return "", nil, fmt.Errorf("Asset encoded for wrong network : %s", reference)
+	}
+	if len(data) != 35 {
+		return "", nil, fmt.Errorf("Wrong asset reference size : %d", len(data))
+	}
+
+	return string(data[:3]), protocol.AssetCodeFromBytes(data[3:]), nil
+}
+
+// EncodeAssetReference returns the BIP-0276 reference for an asset.
+func EncodeAssetReference(assetType string, assetCode *protocol.AssetCode,
+	net bitcoin.Network) string {
+	return bitcoin.BIP0276Encode(net, AssetURLPrefix, append([]byte(assetType),
+		assetCode.Bytes()...))
+}
+
+// decodeBIP0276 decodes a hex or base58 BIP-0276 value and checks its prefix.
+func decodeBIP0276(value, prefix string) (bitcoin.Network, []byte, error) {
+	net, decodedPrefix, data, err := bitcoin.BIP0276Decode(value)
+	if err != nil {
+		net, decodedPrefix, data, err = bitcoin.BIP0276Decode58(value)
+		if err != nil {
+			return bitcoin.InvalidNet, nil, errors.Wrap(err, "decode BIP-0276")
+		}
+	}
+
+	if decodedPrefix != prefix {
+		return bitcoin.InvalidNet, nil, fmt.Errorf("Wrong BIP-0276 prefix : got %s, want %s",
+			decodedPrefix, prefix)
+	}
+
+	return net, data, nil
+}
+
+func decodeAddress(value string, net bitcoin.Network) (bitcoin.RawAddress, error) {
+	address, err := bitcoin.DecodeAddress(value)
+	if err != nil {
+		return bitcoin.RawAddress{}, errors.Wrap(err, "decode address")
+	}
+	if !bitcoin.DecodeNetMatches(address.Network(), net) {
+		return bitcoin.RawAddress{}, fmt.Errorf("Address encoded for wrong network : %s", value)
+	}
+
+	return bitcoin.NewRawAddressFromAddress(address), nil
+}
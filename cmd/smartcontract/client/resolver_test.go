@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func TestReferences(t *testing.T) {
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+	ra, err := key.RawAddress()
+	if err != nil {
+		t.Fatalf("Failed to get address : %s", err)
+	}
+
+	reference := EncodeContractReference(ra, bitcoin.MainNet)
+	contract, err := ParseContractReference(reference, bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to parse contract reference : %s", err)
+	}
+	if !contract.Equal(ra) {
+		t.Fatalf("Wrong contract : %x", contract.Bytes())
+	}
+	if _, err := ParseContractReference(reference, bitcoin.TestNet); err == nil {
+		t.Fatalf("Parsed contract reference for wrong network")
+	}
+
+	assetCode := protocol.AssetCodeFromContract(ra, 1)
+	reference = EncodeAssetReference("SHC", assetCode, bitcoin.MainNet)
+	assetType, code, err := ParseAssetReference(reference, bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to parse asset reference : %s", err)
+	}
+	if assetType != "SHC" || !code.Equal(*assetCode) {
+		t.Fatalf("Wrong asset : %s %s", assetType, code.String())
+	}
+
+	// Asset IDs are also accepted.
+	assetType, code, err = ParseAssetReference(protocol.AssetID("SHC", *assetCode),
+		bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to parse asset id : %s", err)
+	}
+	if assetType != "SHC" || !code.Equal(*assetCode) {
+		t.Fatalf("Wrong asset from id : %s %s", assetType, code.String())
+	}
+
+	// Locking scripts and addresses resolve without paymail.
+	resolver := &DestinationResolver{Net: bitcoin.MainNet}
+	lockingScript, _ := ra.LockingScript()
+	for _, destination := range []string{
+		bitcoin.BIP0276Encode(bitcoin.MainNet, ScriptURLPrefix, lockingScript),
+		bitcoin.NewAddressFromRawAddress(ra, bitcoin.MainNet).String(),
+	} {
+		resolved, err := resolver.Resolve(context.Background(), destination)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s : %s", destination, err)
+		}
+		if !resolved.Equal(ra) {
+			t.Fatalf("Wrong address for %s", destination)
+		}
+	}
+
+	if _, err := resolver.Resolve(context.Background(), "alice@example.com"); err == nil {
+		t.Fatalf("Resolved paymail without a client")
+	}
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	"github.com/tokenized/smart-contract/internal/authority"
+	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var cmdAuthorities = &cobra.Command{
+	Use:   "authorities <contract address>",
+	Short: "Print the enforcement authorities registered by a contract.",
+	Long: "Print the enforcement authorities registered by a contract with their validity windows" +
+		" and whether they are currently active. The registry is changed by Message actions" +
+		" from the contract administration.",
+	RunE: func(c *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("Incorrect argument count")
+		}
+
+		ctx := bootstrap.NewContextWithDevelopmentLogger()
+
+		cfg := bootstrap.NewConfigFromEnv(ctx)
+
+		address, err := bitcoin.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+
+		masterDB := bootstrap.NewMasterDB(ctx, cfg)
+
+		contractAddress := bitcoin.NewRawAddressFromAddress(address)
+		ct, err := contract.Fetch(ctx, masterDB, contractAddress)
+		if err != nil {
+			return err
+		}
+
+		as, err := authority.List(ctx, masterDB, contractAddress)
+		if err != nil {
+			return err
+		}
+
+		now := protocol.CurrentTimestamp()
+		for _, a := range as {
+			status := "active"
+			if a.RevokedAt.Nano() != 0 {
+				status = "revoked at " + a.RevokedAt.String()
+			} else if !authority.IsActive(a, now) {
+				status = "inactive"
+			}
+
+			fmt.Printf("%s %s (%s)\n", a.PublicKey.String(), a.Name, status)
+			if a.ValidFrom.Nano() != 0 {
+				fmt.Printf("  valid from %s\n", a.ValidFrom.String())
+			}
+			if a.ValidUntil.Nano() != 0 {
+				fmt.Printf("  valid until %s\n", a.ValidUntil.String())
+			}
+		}
+
+		fmt.Printf("Confiscation authority signature required : %t\n",
+			ct.ConfiscationAuthorityRequired)
+		return nil
+	},
+}
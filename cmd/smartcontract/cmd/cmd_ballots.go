@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	"github.com/tokenized/smart-contract/internal/vote"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagHistory = "history"
+)
+
+var cmdBallots = &cobra.Command{
+	Use:   "ballots <contract address> <vote txid>",
+	Short: "Print the ballots counted in a vote.",
+	Long: "Print the ballots counted in a vote with the holders whose balances each ballot" +
+		" carried, including balances delegated to proxies. With --history every ballot counted is" +
+		" printed in order, including recast and revoked ballots.",
+	RunE: func(c *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("Incorrect argument count")
+		}
+
+		ctx := bootstrap.NewContextWithDevelopmentLogger()
+
+		cfg := bootstrap.NewConfigFromEnv(ctx)
+		net := bitcoin.NetworkFromString(cfg.Bitcoin.Network)
+
+		address, err := bitcoin.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+
+		b, err := hex.DecodeString(args[1])
+		if err != nil {
+			return errors.Wrap(err, "vote txid")
+		}
+		if len(b) != bitcoin.Hash32Size {
+			return fmt.Errorf("Wrong vote txid size : %d", len(b))
+		}
+
+		masterDB := bootstrap.NewMasterDB(ctx, cfg)
+
+		vt, err := vote.Fetch(ctx, masterDB, bitcoin.NewRawAddressFromAddress(address),
+			protocol.TxIdFromBytes(b))
+		if err != nil {
+			return err
+		}
+
+		ballots := vt.Ballots
+		if history, _ := c.Flags().GetBool(FlagHistory); history {
+			ballots = vt.BallotHistory
+		}
+
+		for _, ballot := range ballots {
+			if len(ballot.Vote) == 0 {
+				fmt.Printf("%s revoked at %s\n",
+					bitcoin.NewAddressFromRawAddress(ballot.Address, net).String(),
+					ballot.Timestamp.String())
+				continue
+			}
+
+			fmt.Printf("%s voted %s with %d at %s\n",
+				bitcoin.NewAddressFromRawAddress(ballot.Address, net).String(), ballot.Vote,
+				ballot.Quantity, ballot.Timestamp.String())
+
+			for _, share := range ballot.Shares {
+				fmt.Printf("  %d from %s\n", share.Quantity,
+					bitcoin.NewAddressFromRawAddress(share.Address, net).String())
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	cmdBallots.Flags().Bool(FlagHistory, false, "print all ballots counted")
+}
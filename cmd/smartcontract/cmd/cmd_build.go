@@ -3,6 +3,8 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -29,12 +31,13 @@ const (
 	FlagTx        = "tx"
 	FlagHexFormat = "hex"
 	FlagSend      = "send"
+	FlagContract  = "contract"
 )
 
 var cmdBuild = &cobra.Command{
 	Use:   "build <typeCode> <jsonFile>",
 	Short: "Build an action/asset/message payload from a json file.",
-	Long:  "Build and action/asset/message payload from a json file. Note: fixedbin (fixed size binary) in json is an array of 8 bit integers and bin (variable size binary) is hex encoded binary data.",
+	Long:  "Build and action/asset/message payload from a json file. Note: fixedbin (fixed size binary) in json is an array of 8 bit integers and bin (variable size binary) is hex encoded binary data. Transfer receiver addresses can be base58 addresses, BIP-0276 scripts, or paymail handles, and an asset can be given by an \"Asset\" BIP-0276 reference or asset ID.",
 	RunE: func(c *cobra.Command, args []string) error {
 		if len(args) != 2 {
 			return errors.New("Missing json file parameter")
@@ -76,6 +79,15 @@ func buildAction(c *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Resolve user friendly destinations and references
+	if _, ok := action.(*actions.Transfer); ok {
+		data, err = resolveTransfer(ctx, client.NewResolver(network(c)), network(c), data)
+		if err != nil {
+			fmt.Printf("Failed to resolve transfer : %s\n", err)
+			return nil
+		}
+	}
+
 	// Put json data into opReturn struct
 	if err := json.Unmarshal(data, action); err != nil {
 		fmt.Printf("Failed to unmarshal %s json file : %s\n", actionType, err)
@@ -137,6 +149,16 @@ func buildAction(c *cobra.Command, args []string) error {
 			return nil
 		}
 
+		contractReference, _ := c.Flags().GetString(FlagContract)
+		if len(contractReference) > 0 {
+			theClient.ContractAddress, err = client.ParseContractReference(contractReference,
+				network(c))
+			if err != nil {
+				fmt.Printf("Invalid contract reference : %s\n", err)
+				return nil
+			}
+		}
+
 		tx = txbuilder.NewTxBuilder(theClient.Config.DustLimit, theClient.Config.FeeRate)
 		tx.SetChangeAddress(theClient.Wallet.Address, "")
 
@@ -313,6 +335,64 @@ func buildAction(c *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolveTransfer replaces receiver destinations in transfer json with the hex raw addresses
+//   expected by the action, and "Asset" references with the asset type and code.
+func resolveTransfer(ctx context.Context, resolver client.Resolver, net bitcoin.Network,
+	data []byte) ([]byte, error) {
+
+	var transfer map[string]interface{}
+	if err := json.Unmarshal(data, &transfer); err != nil {
+		return nil, errors.Wrap(err, "unmarshal transfer")
+	}
+
+	assetList, _ := transfer["Assets"].([]interface{})
+	for i, assetValue := range assetList {
+		asset, ok := assetValue.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if reference, ok := asset["Asset"].(string); ok {
+			assetType, assetCode, err := client.ParseAssetReference(reference, net)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("asset %d reference", i))
+			}
+			asset["AssetType"] = assetType
+			asset["AssetCode"] = assetCode.String()
+			delete(asset, "Asset")
+		}
+
+		receivers, _ := asset["AssetReceivers"].([]interface{})
+		for j, receiverValue := range receivers {
+			receiver, ok := receiverValue.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			destination, ok := receiver["Address"].(string)
+			if !ok {
+				continue
+			}
+
+			// Already a hex raw address
+			if b, err := hex.DecodeString(destination); err == nil {
+				if _, err := bitcoin.DecodeRawAddress(b); err == nil {
+					continue
+				}
+			}
+
+			ra, err := resolver.Resolve(ctx, destination)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("asset %d receiver %d", i, j))
+			}
+			fmt.Printf("Resolved %s : %s\n", destination,
+				bitcoin.NewAddressFromRawAddress(ra, net).String())
+			receiver["Address"] = hex.EncodeToString(ra.Bytes())
+		}
+	}
+
+	return json.Marshal(transfer)
+}
+
 func buildAssetPayload(c *cobra.Command, args []string) error {
 	assetType := strings.ToUpper(args[0])
 
@@ -362,4 +442,5 @@ func init() {
 	cmdBuild.Flags().Bool(FlagTx, false, "build a tx, if false only op return is built")
 	cmdBuild.Flags().Bool(FlagHexFormat, false, "hex format")
 	cmdBuild.Flags().Bool(FlagSend, false, "send to network")
+	cmdBuild.Flags().String(FlagContract, "", "contract address or BIP-0276 reference, overrides the configured contract")
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	handlerstorage "github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
+
+	"github.com/spf13/cobra"
+)
+
+var cmdDoubleSpends = &cobra.Command{
+	Use:   "doublespends [txid]",
+	Short: "List double spend evidence recorded by the daemon's spynode.",
+	Long: "List double spend evidence recorded by the daemon's spynode. When a txid is " +
+		"specified only the double spend containing that tx is shown.",
+	RunE: func(c *cobra.Command, args []string) error {
+		ctx := bootstrap.NewContextWithDevelopmentLogger()
+
+		cfg := bootstrap.NewConfigFromEnv(ctx)
+
+		repo := handlerstorage.NewDoubleSpendRepository(bootstrap.NewSpyNodeStorage(ctx, cfg))
+		if err := repo.Load(ctx); err != nil {
+			return err
+		}
+
+		hexFormat, _ := c.Flags().GetBool(FlagHexFormat)
+		net := bitcoin.NetworkFromString(cfg.Bitcoin.Network)
+
+		if len(args) > 0 {
+			txid, err := bitcoin.NewHash32FromStr(args[0])
+			if err != nil {
+				return err
+			}
+
+			ds, err := repo.Get(ctx, txid)
+			if err != nil {
+				return err
+			}
+
+			return printDoubleSpend(ctx, ds, net, hexFormat)
+		}
+
+		list, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d double spends\n\n", len(list))
+		for _, ds := range list {
+			if err := printDoubleSpend(ctx, ds, net, hexFormat); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func printDoubleSpend(ctx context.Context, ds *handlerstorage.DoubleSpend, net bitcoin.Network,
+	hexFormat bool) error {
+	fmt.Printf("# Double Spend %s\n\n", ds.ID.String())
+	fmt.Printf("Detected : %s\n", time.Unix(int64(ds.Time), 0).String())
+	if ds.Confirmed != nil {
+		fmt.Printf("Confirmed : %s at height %d\n", ds.Confirmed.String(), ds.ConfirmedHeight)
+	} else {
+		fmt.Printf("Confirmed : none\n")
+	}
+
+	for _, dsTx := range ds.Txs {
+		fmt.Printf("\n## Tx %s\n", dsTx.TxID.String())
+		for _, announcement := range dsTx.Announcements {
+			fmt.Printf("  Sent by %s at %s\n", announcement.Peer,
+				time.Unix(int64(announcement.Time), 0).String())
+		}
+
+		if dsTx.Tx == nil {
+			fmt.Printf("  Raw tx not retained\n")
+			continue
+		}
+
+		if hexFormat {
+			var buf bytes.Buffer
+			if err := dsTx.Tx.Serialize(&buf); err != nil {
+				return err
+			}
+			fmt.Printf("  %s\n", hex.EncodeToString(buf.Bytes()))
+		} else {
+			fmt.Printf("%s\n", dsTx.Tx.StringWithAddresses(net))
+		}
+	}
+
+	fmt.Printf("\n")
+	return nil
+}
+
+func init() {
+	cmdDoubleSpends.Flags().Bool(FlagHexFormat, false, "print raw txs in hex")
+}
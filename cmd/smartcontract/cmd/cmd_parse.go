@@ -3,19 +3,19 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/tokenized/smart-contract/cmd/smartcontract/client"
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	"github.com/tokenized/smart-contract/internal/platform/config"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/txstore"
 	"github.com/tokenized/smart-contract/pkg/wire"
 
-	"github.com/tokenized/specification/dist/golang/actions"
-	"github.com/tokenized/specification/dist/golang/assets"
-	"github.com/tokenized/specification/dist/golang/messages"
-	"github.com/tokenized/specification/dist/golang/protocol"
-
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -55,7 +55,9 @@ var cmdParse = &cobra.Command{
 			return nil
 		}
 
-		parseScript(c, data)
+		if err := parseScript(c, data); err != nil {
+			fmt.Printf("Failed to parse as tx or script : %s\n", err)
+		}
 
 		return nil
 	},
@@ -90,84 +92,44 @@ func parseTx(c *cobra.Command, rawtx []byte) error {
 		}
 	}
 
-	fmt.Printf("\nTx (%d bytes) : %s\n", tx.SerializeSize(), tx.TxHash().String())
-	fmt.Printf(tx.StringWithAddresses(network(c)))
-
-	for _, txOut := range tx.TxOut {
-		if parseScript(c, txOut.PkScript) == nil {
-			return nil
-		}
-	}
+	ctx := context.Background()
+	node, closeNode := txNode(ctx)
+	defer closeNode()
 
-	return nil
+	return dumpJSON(inspector.DecodeTx(ctx, &tx, node, network(c)))
 }
 
-func parseScript(c *cobra.Command, script []byte) error {
-
-	isTest := false
-	message, err := protocol.Deserialize(script, isTest)
+// txNode returns the local tx store, falling back to the configured node for txs it doesn't
+//   have, so parsed txs include their inputs. Without a usable config it returns nil and inputs
+//   are left undecoded.
+func txNode(ctx context.Context) (inspector.NodeInterface, func()) {
+	cfg, err := config.Environment()
 	if err != nil {
-		if err == protocol.ErrNotTokenized {
-			// Check is test protocol signature
-			isTest = true
-			message, err = protocol.Deserialize(script, isTest)
-			if err != nil {
-				return errors.Wrap(err, "decode op return")
-			}
-		} else {
-			return errors.Wrap(err, "decode op return")
-		}
+		return nil, func() {}
 	}
 
-	fmt.Printf("\n")
+	node, closeNode := bootstrap.NewTxNode(ctx, cfg)
 
-	if isTest {
-		fmt.Printf("Uses Test Protocol Signature\n")
+	txStore := txstore.NewStore(bootstrap.NewSpyNodeStorage(ctx, cfg), cfg.TxStore.MaxSize,
+		cfg.TxStore.RecentCount)
+	if err := txStore.Load(ctx); err != nil {
+		fmt.Printf("Failed to load tx store : %s\n", err)
+		return node, closeNode
 	}
-
-	fmt.Printf("Action type : %s\n\n", message.Code())
-
-	if err := dumpJSON(message); err != nil {
-		return err
+	if node != nil {
+		txStore.Fallback = node
 	}
 
-	switch m := message.(type) {
-	case *actions.AssetDefinition:
-		if len(m.AssetPayload) == 0 {
-			fmt.Printf("Empty asset payload!\n")
-			return nil
-		}
-		asset, err := assets.Deserialize([]byte(m.AssetType), m.AssetPayload)
-		if err != nil {
-			fmt.Printf("Failed to deserialize payload : %s", err)
-		} else {
-			dumpJSON(asset)
-		}
-	case *actions.AssetCreation:
-		if len(m.AssetPayload) == 0 {
-			fmt.Printf("Empty asset payload!\n")
-			return nil
-		}
-		asset, err := assets.Deserialize([]byte(m.AssetType), m.AssetPayload)
-		if err != nil {
-			fmt.Printf("Failed to deserialize payload : %s", err)
-		} else {
-			dumpJSON(asset)
-		}
-	case *actions.Message:
-		if len(m.MessagePayload) == 0 {
-			fmt.Printf("Empty message payload!\n")
-			return nil
-		}
-		msg, err := messages.Deserialize(m.MessageCode, m.MessagePayload)
-		if err != nil {
-			fmt.Printf("Failed to deserialize payload : %s", err)
-		} else {
-			dumpJSON(msg)
-		}
+	return txStore, closeNode
+}
+
+func parseScript(c *cobra.Command, script []byte) error {
+	action := inspector.DecodeAction(script)
+	if action == nil {
+		return errors.New("Not a Tokenized script")
 	}
 
-	return nil
+	return dumpJSON(action)
 }
 
 func init() {
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	handlerstorage "github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagBanHours = "hours"
+	FlagReason   = "reason"
+)
+
+var cmdPeers = &cobra.Command{
+	Use:   "peers <list|add|remove|ban|unban> [address] [score]",
+	Short: "Inspect and edit the daemon's spynode peer database.",
+	Long: "Inspect and edit the daemon's spynode peer database. Changes are picked up when " +
+		"the daemon is restarted.\n\n" +
+		"  list                    List all peers with their bucket, score, and ban status.\n" +
+		"  add <address> [score]   Add a peer, optionally adjusting its score.\n" +
+		"  remove <address>        Remove a peer.\n" +
+		"  ban <address>           Temporarily ban a peer.\n" +
+		"  unban <address>         Remove a ban from a peer.",
+	RunE: func(c *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("Missing peers command")
+		}
+
+		ctx := bootstrap.NewContextWithDevelopmentLogger()
+
+		cfg := bootstrap.NewConfigFromEnv(ctx)
+
+		repo := handlerstorage.NewPeerRepository(bootstrap.NewSpyNodeStorage(ctx, cfg))
+		if err := repo.Load(ctx); err != nil {
+			return errors.Wrap(err, "load peers")
+		}
+
+		if args[0] == "list" {
+			listPeers(repo.List(ctx))
+			return nil
+		}
+
+		if len(args) < 2 {
+			return errors.New("Missing peer address")
+		}
+		address := args[1]
+
+		switch args[0] {
+		case "add":
+			added, err := repo.Add(ctx, address)
+			if err != nil {
+				return err
+			}
+			if !added {
+				fmt.Printf("Peer already exists : %s\n", address)
+			}
+
+			if len(args) > 2 {
+				score, err := strconv.Atoi(args[2])
+				if err != nil {
+					return errors.Wrap(err, "parse score")
+				}
+				repo.UpdateScore(ctx, address, int32(score))
+			}
+
+		case "remove":
+			if !repo.Remove(ctx, address) {
+				return fmt.Errorf("Peer not found : %s", address)
+			}
+
+		case "ban":
+			hours, _ := c.Flags().GetInt(FlagBanHours)
+			reason, _ := c.Flags().GetString(FlagReason)
+			repo.Ban(ctx, address, time.Duration(hours)*time.Hour, reason)
+
+		case "unban":
+			if !repo.Unban(ctx, address) {
+				return fmt.Errorf("Peer not banned : %s", address)
+			}
+
+		default:
+			return fmt.Errorf("Unknown peers command : %s", args[0])
+		}
+
+		return repo.Save(ctx)
+	},
+}
+
+// listPeers prints the peers grouped by bucket.
+func listPeers(peers []handlerstorage.Peer) {
+	now := time.Now()
+	var tried, untried, banned []string
+	for _, peer := range peers {
+		line := fmt.Sprintf("  %-48s score %4d  last %s", peer.Address, peer.Score,
+			formatPeerTime(peer.LastTime))
+		if peer.Static {
+			line += "  static"
+		}
+
+		switch {
+		case peer.IsBanned(now):
+			banned = append(banned, fmt.Sprintf("%s  until %s (%s)", line,
+				formatPeerTime(peer.BanUntil), peer.BanReason))
+		case peer.Tried:
+			tried = append(tried, line)
+		default:
+			untried = append(untried, line)
+		}
+	}
+
+	fmt.Printf("# Tried (%d)\n%s\n\n", len(tried), strings.Join(tried, "\n"))
+	fmt.Printf("# New (%d)\n%s\n\n", len(untried), strings.Join(untried, "\n"))
+	fmt.Printf("# Banned (%d)\n%s\n", len(banned), strings.Join(banned, "\n"))
+}
+
+func formatPeerTime(t uint32) string {
+	if t == 0 {
+		return "never"
+	}
+	return time.Unix(int64(t), 0).Format("2006-01-02 15:04:05")
+}
+
+func init() {
+	cmdPeers.Flags().Int(FlagBanHours, int(handlerstorage.BanDuration/time.Hour),
+		"number of hours to ban a peer for")
+	cmdPeers.Flags().String(FlagReason, "manual", "reason for banning a peer")
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"os"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
+	"github.com/tokenized/smart-contract/internal/enforcement"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/json"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var cmdReconciliations = &cobra.Command{
+	Use:   "reconciliations <contract address>",
+	Short: "Export the reconciliation records of a contract.",
+	Long: "Export the reconciliation records of a contract as NDJSON, oldest first. Each record" +
+		" links the order and its response to the holdings it changed, with their balances before" +
+		" and after, the bitcoin dispersed to them, and the authority signature of the order.",
+	RunE: func(c *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("Incorrect argument count")
+		}
+
+		ctx := bootstrap.NewContextWithDevelopmentLogger()
+
+		cfg := bootstrap.NewConfigFromEnv(ctx)
+		net := bitcoin.NetworkFromString(cfg.Bitcoin.Network)
+
+		address, err := bitcoin.DecodeAddress(args[0])
+		if err != nil {
+			return err
+		}
+
+		masterDB := bootstrap.NewMasterDB(ctx, cfg)
+
+		rs, err := enforcement.ListReconciliations(ctx, masterDB,
+			bitcoin.NewRawAddressFromAddress(address))
+		if err != nil {
+			return err
+		}
+
+		out := bufio.NewWriter(os.Stdout)
+		defer out.Flush()
+		writer := json.NewNDJSONWriter(out)
+
+		for _, r := range rs {
+			report := reconciliationReport{
+				OrderTxId:          r.OrderTxId.String(),
+				AssetID:            protocol.AssetID(r.AssetType, *r.AssetCode),
+				Message:            r.Message,
+				Operator:           bitcoin.NewAddressFromRawAddress(r.Operator, net).String(),
+				AuthorityName:      r.AuthorityName,
+				AuthorityPublicKey: hex.EncodeToString(r.AuthorityPublicKey),
+				SignatureAlgorithm: r.SignatureAlgorithm,
+				OrderSignature:     hex.EncodeToString(r.OrderSignature),
+				CreatedAt:          r.CreatedAt.String(),
+			}
+			if r.ResponseTxId != nil {
+				report.ResponseTxId = r.ResponseTxId.String()
+			}
+			if r.CompletedAt.Nano() != 0 {
+				report.CompletedAt = r.CompletedAt.String()
+			}
+
+			for _, h := range r.Holdings {
+				report.Holdings = append(report.Holdings, reconciledHoldingReport{
+					Address:       bitcoin.NewAddressFromRawAddress(h.Address, net).String(),
+					Quantity:      h.Quantity,
+					BalanceBefore: h.BalanceBefore,
+					BalanceAfter:  h.BalanceAfter,
+					Dispersion:    h.Dispersion,
+				})
+			}
+
+			if err := writer.Write(report); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+// reconciliationReport is a line of a reconciliation export.
+type reconciliationReport struct {
+	OrderTxId          string                    `json:"order_txid"`
+	ResponseTxId       string                    `json:"response_txid,omitempty"`
+	AssetID            string                    `json:"asset_id"`
+	Message            string                    `json:"message,omitempty"`
+	Operator           string                    `json:"operator"`
+	AuthorityName      string                    `json:"authority_name,omitempty"`
+	AuthorityPublicKey string                    `json:"authority_public_key,omitempty"`
+	SignatureAlgorithm uint32                    `json:"signature_algorithm,omitempty"`
+	OrderSignature     string                    `json:"order_signature,omitempty"`
+	Holdings           []reconciledHoldingReport `json:"holdings"`
+	CreatedAt          string                    `json:"created_at"`
+	CompletedAt        string                    `json:"completed_at,omitempty"`
+}
+
+type reconciledHoldingReport struct {
+	Address       string `json:"address"`
+	Quantity      uint64 `json:"quantity"`
+	BalanceBefore uint64 `json:"balance_before"`
+	BalanceAfter  uint64 `json:"balance_after"`
+	Dispersion    uint64 `json:"dispersion,omitempty"`
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tokenized/smart-contract/pkg/json"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/assets"
+	"github.com/tokenized/specification/dist/golang/messages"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var cmdSchema = &cobra.Command{
+	Use:   "schema <typeCode>",
+	Short: "Print the JSON schema of an action/asset/message payload.",
+	Long:  "Print the JSON schema of an action/asset/message payload as used by the build command. Binary fields are hex strings with a \"base16\" content encoding, code fields list their valid values in \"enum\", and fields the protocol requires are listed in \"required\".",
+	Example: "smartcontract schema T1 # schema of transfer json\n" +
+		"smartcontract schema SHC # schema of common share asset payload json\n" +
+		"smartcontract schema 1001 # schema of revert message json",
+	RunE: func(c *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("Missing type code parameter")
+		}
+
+		typeCode := strings.ToUpper(args[0])
+
+		var value interface{}
+		switch len(typeCode) {
+		case 2:
+			action := actions.NewActionFromCode(typeCode)
+			if action == nil {
+				return fmt.Errorf("Unsupported action type : %s", typeCode)
+			}
+			value = action
+		case 3:
+			asset := assets.NewAssetFromCode(typeCode)
+			if asset == nil {
+				return fmt.Errorf("Unsupported asset type : %s", typeCode)
+			}
+			value = asset
+		case 4:
+			code, err := strconv.ParseUint(typeCode, 10, 32)
+			if err != nil {
+				return errors.Wrap(err, "parse message code")
+			}
+			message := messages.NewMessageFromCode(uint32(code))
+			if message == nil {
+				return fmt.Errorf("Unsupported message type : %s", typeCode)
+			}
+			value = message
+		default:
+			return fmt.Errorf("Unknown type code length %d\n  Actions are 2 characters\n  Assets are 3 characters\n  Messages are 4 characters", len(typeCode))
+		}
+
+		schema, err := json.NewSchema(value, &json.SchemaOptions{
+			Title: typeCode,
+			Enums: protocolEnums(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "generate schema")
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		return enc.Encode(schema)
+	},
+}
+
+// protocolEnums returns the values allowed for protocol code fields. They match the option lists
+//   and resource lookups in the specification's validation functions.
+func protocolEnums() map[string][]interface{} {
+	result := map[string][]interface{}{
+		"ContractOffer.BodyOfAgreementType":           {1, 2},
+		"ContractFormation.BodyOfAgreementType":       {1, 2},
+		"StaticContractFormation.BodyOfAgreementType": {1, 2},
+		"AssetDefinition.AssetModificationGovernance": {0, 1},
+		"AssetCreation.AssetModificationGovernance":   {0, 1},
+		"AmendmentField.Operation":                    {0, 1, 2},
+		"AssetReceiverField.OracleSigAlgorithm":       {0, 1},
+		"Order.SignatureAlgorithm":                    {1},
+		"Proposal.Type":                               {0, 1, 2},
+		"AssetType": {
+			assets.CodeMembership,
+			assets.CodeCurrency,
+			assets.CodeShareCommon,
+			assets.CodeCoupon,
+			assets.CodeLoyaltyPoints,
+			assets.CodeTicketAdmission,
+			assets.CodeCasinoChip,
+		},
+	}
+
+	var roles []uint32
+	for code := range actions.RolesMap() {
+		roles = append(roles, code)
+	}
+	result["AdministratorField.Type"] = uintEnum(roles)
+	result["ManagerField.Type"] = uintEnum(roles)
+
+	var rejections []uint32
+	for code := range actions.RejectionsMap() {
+		rejections = append(rejections, code)
+	}
+	result["Rejection.RejectionCode"] = uintEnum(rejections)
+
+	var tags []uint32
+	for code := range messages.TagsMap() {
+		tags = append(tags, code)
+	}
+	result["OutputMetadata.Tags"] = uintEnum(tags)
+
+	var entities []string
+	for code := range actions.EntitiesMap() {
+		entities = append(entities, code)
+	}
+	result["EntityField.Type"] = stringEnum(entities)
+
+	var polities []string
+	for code := range actions.PolitiesMap() {
+		polities = append(polities, code)
+	}
+	result["AssetDefinition.TradeRestrictions"] = stringEnum(polities)
+	result["AssetCreation.TradeRestrictions"] = stringEnum(polities)
+
+	return result
+}
+
+func uintEnum(values []uint32) []interface{} {
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
+
+func stringEnum(values []string) []interface{} {
+	sort.Strings(values)
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
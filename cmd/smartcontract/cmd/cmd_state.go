@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/bootstrap"
 	"github.com/tokenized/smart-contract/internal/asset"
@@ -11,6 +13,7 @@ import (
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/state"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/json"
 	"github.com/tokenized/specification/dist/golang/assets"
 	"github.com/tokenized/specification/dist/golang/protocol"
 
@@ -18,10 +21,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const (
+	FlagNDJSON = "ndjson"
+)
+
 var cmdState = &cobra.Command{
 	Use:   "state <contract address>",
 	Short: "Load and print the contract state.",
-	Long:  "Load and print the contract state.",
+	Long:  "Load and print the contract state. Holdings are streamed so contracts with any number of holders can be exported.",
 	RunE: func(c *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return errors.New("Missing hash")
@@ -38,16 +45,49 @@ var cmdState = &cobra.Command{
 
 		masterDB := bootstrap.NewMasterDB(ctx, cfg)
 
-		return loadContract(ctx, c, masterDB, address,
-			bitcoin.NetworkFromString(cfg.Bitcoin.Network))
+		ndjson, _ := c.Flags().GetBool(FlagNDJSON)
+		if ndjson {
+			return exportContract(ctx, masterDB, address,
+				bitcoin.NetworkFromString(cfg.Bitcoin.Network))
+		}
+
+		return loadContract(ctx, c, masterDB, address)
 	},
 }
 
+// holdingJSON is a holding with statuses keyed by hex txid, because the json package requires map
+//   keys to be strings.
+type holdingJSON struct {
+	*state.Holding
+	HoldingStatuses map[string]*state.HoldingStatus `json:"HoldingStatuses,omitempty"`
+}
+
+// stateRecord is a line of an NDJSON state export.
+type stateRecord struct {
+	Type     string       `json:"type"`
+	AssetID  string       `json:"asset_id,omitempty"`
+	Address  string       `json:"address,omitempty"`
+	Contract interface{}  `json:"contract,omitempty"`
+	Asset    interface{}  `json:"asset,omitempty"`
+	Payload  interface{}  `json:"payload,omitempty"`
+	Holding  *holdingJSON `json:"holding,omitempty"`
+}
+
+func newHoldingJSON(h *state.Holding) *holdingJSON {
+	result := &holdingJSON{Holding: h}
+	if len(h.HoldingStatuses) > 0 {
+		result.HoldingStatuses = make(map[string]*state.HoldingStatus)
+		for _, s := range h.HoldingStatuses {
+			result.HoldingStatuses[fmt.Sprintf("%x", s.TxId.Bytes())] = s
+		}
+	}
+	return result
+}
+
 func loadContract(ctx context.Context,
 	cmd *cobra.Command,
 	db *db.DB,
-	address bitcoin.Address,
-	net bitcoin.Network) error {
+	address bitcoin.Address) error {
 
 	c, err := contract.Fetch(ctx, db, bitcoin.NewRawAddressFromAddress(address))
 	if err != nil {
@@ -83,53 +123,79 @@ func loadContract(ctx context.Context,
 			return err
 		}
 
-		fmt.Printf("### Holdings\n\n")
+		fmt.Printf("### Holdings\n\n```\n")
 
-		// get the PKH's inside the holders/asset_code directory
-		holdings, err := holdings.FetchAll(ctx, db, bitcoin.NewRawAddressFromAddress(address),
-			assetCode)
+		holdingsArray := json.NewArrayWriter(os.Stdout)
+		holdingsArray.SetIndent("", "    ")
+		err = holdings.ForEach(ctx, db, c.Address, assetCode, func(h *state.Holding) error {
+			return holdingsArray.Write(newHoldingJSON(h))
+		})
 		if err != nil {
-			return nil
+			return errors.Wrap(err, "holdings")
 		}
-
-		for _, holding := range holdings {
-			address = bitcoin.NewAddressFromRawAddress(holding.Address, net)
-			fmt.Printf("#### Holding for %s\n\n", address.String())
-
-			if err := dumpHoldingJSON(holding); err != nil {
-				return err
-			}
+		if err := holdingsArray.Close(); err != nil {
+			return err
 		}
+
+		fmt.Printf("```\n\n")
 	}
 
 	return nil
 }
 
-// dumpHoldingJSON dumps a Holding to JSON.
-//
-// As the json package requires map keys to be strings, this special function
-// handles key converstion.
-func dumpHoldingJSON(h *state.Holding) error {
-	holdingStatuses := h.HoldingStatuses
-	h.HoldingStatuses = nil
+// exportContract writes the contract, its assets, and their holdings as NDJSON records.
+func exportContract(ctx context.Context,
+	db *db.DB,
+	address bitcoin.Address,
+	net bitcoin.Network) error {
 
-	if err := dumpJSON(h); err != nil {
+	c, err := contract.Fetch(ctx, db, bitcoin.NewRawAddressFromAddress(address))
+	if err != nil {
 		return err
 	}
 
-	if len(holdingStatuses) == 0 {
-		return nil
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	writer := json.NewNDJSONWriter(out)
+
+	if err := writer.Write(stateRecord{Type: "contract", Address: address.String(),
+		Contract: c}); err != nil {
+		return err
 	}
 
-	// deal with key conversion.
-	statuses := map[string]state.HoldingStatus{}
+	for _, assetCode := range c.AssetCodes {
+		a, err := asset.Fetch(ctx, db, c.Address, assetCode)
+		if err != nil {
+			return err
+		}
+		assetID := protocol.AssetID(a.AssetType, *assetCode)
+
+		payload, err := assets.Deserialize([]byte(a.AssetType), a.AssetPayload)
+		if err != nil {
+			return err
+		}
+
+		if err := writer.Write(stateRecord{Type: "asset", AssetID: assetID, Asset: a,
+			Payload: payload}); err != nil {
+			return err
+		}
 
-	for _, s := range holdingStatuses {
-		k := fmt.Sprintf("%x", s.TxId.Bytes())
-		statuses[k] = *s
+		err = holdings.ForEach(ctx, db, c.Address, assetCode, func(h *state.Holding) error {
+			return writer.Write(stateRecord{
+				Type:    "holding",
+				AssetID: assetID,
+				Address: bitcoin.NewAddressFromRawAddress(h.Address, net).String(),
+				Holding: newHoldingJSON(h),
+			})
+		})
+		if err != nil {
+			return errors.Wrap(err, "holdings")
+		}
 	}
 
-	fmt.Printf("#### Holding Statuses\n\n")
+	return nil
+}
 
-	return dumpJSON(statuses)
+func init() {
+	cmdState.Flags().Bool(FlagNDJSON, false, "write newline delimited json records")
 }
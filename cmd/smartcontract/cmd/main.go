@@ -23,6 +23,8 @@ var scCmd = &cobra.Command{
 func Execute() {
 	scCmd.AddCommand(cmdSync)
 	scCmd.AddCommand(cmdScan)
+	scCmd.AddCommand(cmdPeers)
+	scCmd.AddCommand(cmdDoubleSpends)
 	scCmd.AddCommand(cmdBuild)
 	scCmd.AddCommand(cmdAuth)
 	scCmd.AddCommand(cmdConvert)
@@ -34,6 +36,10 @@ func Execute() {
 	scCmd.AddCommand(cmdParse)
 	scCmd.AddCommand(cmdState)
 	scCmd.AddCommand(cmdJSON)
+	scCmd.AddCommand(cmdSchema)
+	scCmd.AddCommand(cmdBallots)
+	scCmd.AddCommand(cmdAuthorities)
+	scCmd.AddCommand(cmdReconciliations)
 	scCmd.Execute()
 }
 
@@ -60,12 +66,15 @@ func network(c *cobra.Command) bitcoin.Network {
 
 // dumpJSON pretty prints a JSON representation of a struct.
 func dumpJSON(o interface{}) error {
-	js, err := json.MarshalIndent(o, "", "    ")
-	if err != nil {
+	fmt.Printf("```\n")
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(o); err != nil {
 		return err
 	}
 
-	fmt.Printf("```\n%s\n```\n\n", js)
+	fmt.Printf("```\n\n")
 
 	return nil
 }
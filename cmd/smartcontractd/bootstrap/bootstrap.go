@@ -3,9 +3,9 @@ package bootstrap
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"os"
-	"path"
-	"path/filepath"
+	"strings"
 
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/config"
@@ -13,18 +13,30 @@ import (
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/internal/utxos"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/electrum"
+	"github.com/tokenized/smart-contract/pkg/explorer"
+	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/rpcnode"
+	"github.com/tokenized/smart-contract/pkg/storage"
 	"github.com/tokenized/smart-contract/pkg/wallet"
 )
 
 func NewContextWithDevelopmentLogger() context.Context {
 	ctx := context.Background()
 
-	logPath := os.Getenv("LOG_FILE_PATH")
-	if len(logPath) > 0 {
-		os.MkdirAll(path.Dir(os.Getenv("LOG_FILE_PATH")), os.ModePerm)
-		logFileName := filepath.FromSlash(os.Getenv("LOG_FILE_PATH"))
-		logFile, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	logCfg, err := config.LogEnvironment()
+	if err != nil {
+		logger.Fatal(ctx, "Parsing Log Config : %s", err)
+	}
+
+	if len(logCfg.FilePath) > 0 {
+		logFile, err := logger.NewRotatingFile(logCfg.FilePath, logger.RotationConfig{
+			MaxSize:    logCfg.MaxSize,
+			MaxAge:     logCfg.MaxAge,
+			MaxBackups: logCfg.MaxBackups,
+		})
 		if err != nil {
 			logger.Fatal(ctx, "Failed to open log file : %v\n", err)
 		}
@@ -34,9 +46,48 @@ func NewContextWithDevelopmentLogger() context.Context {
 		ctx = node.ContextWithDevelopmentLogger(ctx, os.Stdout)
 	}
 
+	logConfig := logger.ConfigFromContext(ctx)
+
+	switch strings.ToLower(logCfg.Format) {
+	case "text", "":
+	case "json":
+		logConfig.Main.Format |= logger.FormatJSON
+		for _, subConfig := range logConfig.SubSystems {
+			subConfig.Format |= logger.FormatJSON
+		}
+	default:
+		logger.Fatal(ctx, "Unknown log format : %s", logCfg.Format)
+	}
+
+	if err := logConfig.SetLevels(logCfg.Levels); err != nil {
+		logger.Fatal(ctx, "Invalid log levels : %s", err)
+	}
+
 	return ctx
 }
 
+// StartLogAdmin serves the log levels admin endpoint at /log/levels if an address is configured.
+func StartLogAdmin(ctx context.Context, cfg *config.Config) {
+	if len(cfg.Log.AdminAddress) == 0 {
+		return
+	}
+
+	logConfig := logger.ConfigFromContext(ctx)
+	if logConfig == nil {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/log/levels", logger.LevelHandler(logConfig))
+
+	go func() {
+		logger.Info(ctx, "Log admin listening on %s", cfg.Log.AdminAddress)
+		if err := http.ListenAndServe(cfg.Log.AdminAddress, mux); err != nil {
+			logger.Error(ctx, "Log admin stopped : %s", err)
+		}
+	}()
+}
+
 func NewWallet() *wallet.Wallet {
 	return wallet.New()
 }
@@ -70,16 +121,99 @@ func NewMasterDB(ctx context.Context, cfg *config.Config) *db.DB {
 	return masterDB
 }
 
+// NewSpyNodeStorage returns the storage used by spynode.
+func NewSpyNodeStorage(ctx context.Context, cfg *config.Config) storage.Storage {
+	spyStorageConfig := storage.NewConfig(cfg.NodeStorage.Bucket, cfg.NodeStorage.Root)
+
+	if strings.ToLower(spyStorageConfig.Bucket) == "standalone" {
+		return storage.NewFilesystemStorage(spyStorageConfig)
+	}
+	return storage.NewS3Storage(spyStorageConfig)
+}
+
+// NewTxNode returns the RPC, Electrum, or explorer node that txs are requested from when they
+//   aren't in the tx store, or nil when none is configured. The returned function closes the
+//   node.
+func NewTxNode(ctx context.Context, cfg *config.Config) (inspector.NodeInterface, func()) {
+	if len(cfg.RpcNode.Host) > 0 {
+		rpcRetry := retry.DefaultPolicy()
+		rpcRetry.MaxAttempts = cfg.RpcNode.MaxAttempts
+		rpcRetry.Delay = cfg.RpcNode.RetryDelay
+
+		rpcConfig := &rpcnode.Config{
+			Host:             cfg.RpcNode.Host,
+			Username:         cfg.RpcNode.Username,
+			Password:         cfg.RpcNode.Password,
+			TLS:              cfg.RpcNode.TLS,
+			CAFile:           cfg.RpcNode.CAFile,
+			CertFile:         cfg.RpcNode.CertFile,
+			KeyFile:          cfg.RpcNode.KeyFile,
+			Timeout:          cfg.RpcNode.Timeout,
+			Retry:            rpcRetry,
+			BreakerThreshold: cfg.RpcNode.BreakerThreshold,
+			BreakerCooldown:  cfg.RpcNode.BreakerCooldown,
+		}
+
+		rpcNode, err := rpcnode.NewNode(rpcConfig)
+		if err != nil {
+			logger.Fatal(ctx, "Create RPC node : %s", err)
+		}
+
+		return rpcNode, func() {}
+	}
+
+	if len(cfg.Electrum.Address) > 0 {
+		electrumRetry := retry.DefaultPolicy()
+		electrumRetry.MaxAttempts = cfg.Electrum.MaxAttempts
+		electrumRetry.Delay = cfg.Electrum.RetryDelay
+
+		electrumNode := electrum.NewNode(&electrum.Config{
+			Address: cfg.Electrum.Address,
+			TLS:     cfg.Electrum.TLS,
+			Timeout: cfg.Electrum.Timeout,
+			Retry:   electrumRetry,
+		})
+
+		logger.Info(ctx, "Using Electrum server %s", cfg.Electrum.Address)
+		return electrumNode, func() { electrumNode.Close() }
+	}
+
+	if len(cfg.Explorer.URL) > 0 {
+		explorerRetry := retry.DefaultPolicy()
+		explorerRetry.MaxAttempts = cfg.Explorer.MaxAttempts
+		explorerRetry.Delay = cfg.Explorer.RetryDelay
+
+		explorerNode := explorer.NewNode(&explorer.Config{
+			URL:           cfg.Explorer.URL,
+			TxPath:        cfg.Explorer.TxPath,
+			BroadcastPath: cfg.Explorer.BroadcastPath,
+			APIKey:        cfg.Explorer.APIKey,
+			APIKeyHeader:  cfg.Explorer.APIKeyHeader,
+			Timeout:       cfg.Explorer.Timeout,
+			Retry:         explorerRetry,
+		})
+
+		logger.Info(ctx, "Using explorer %s", cfg.Explorer.URL)
+		return explorerNode, func() {}
+	}
+
+	logger.Info(ctx, "RPC disabled. Txs are only retrieved from the tx store")
+	return nil, func() {}
+}
+
 func NewNodeConfig(ctx context.Context, cfg *config.Config) *node.Config {
 	appConfig := &node.Config{
-		Net:                bitcoin.NetworkFromString(cfg.Bitcoin.Network),
-		ContractProviderID: cfg.Contract.OperatorName,
-		Version:            cfg.Contract.Version,
-		FeeRate:            cfg.Contract.FeeRate,
-		DustLimit:          cfg.Contract.DustLimit,
-		RequestTimeout:     cfg.Contract.RequestTimeout,
-		PreprocessThreads:  cfg.Contract.PreprocessThreads,
-		IsTest:             cfg.Contract.IsTest,
+		Net:                   bitcoin.NetworkFromString(cfg.Bitcoin.Network),
+		ContractProviderID:    cfg.Contract.OperatorName,
+		Version:               cfg.Contract.Version,
+		FeeRate:               cfg.Contract.FeeRate,
+		DustLimit:             cfg.Contract.DustLimit,
+		RequestTimeout:        cfg.Contract.RequestTimeout,
+		PreprocessThreads:     cfg.Contract.PreprocessThreads,
+		VerifyInputs:          cfg.Contract.VerifyInputs,
+		IsTest:                cfg.Contract.IsTest,
+		VoteBalanceLock:       cfg.Contract.VoteBalanceLock,
+		MaxEnforcementTargets: cfg.Contract.MaxEnforcementTargets,
 	}
 
 	feeAddress, err := bitcoin.DecodeAddress(cfg.Contract.FeeAddress)
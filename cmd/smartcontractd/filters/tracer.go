@@ -68,8 +68,9 @@ func (tracer *Tracer) Load(ctx context.Context, masterDB *db.DB) error {
 
 	// Read items
 	tracer.traces = make([]*traceNode, count)
-	for _, trace := range tracer.traces {
-		if err := trace.read(buf); err != nil {
+	for i := range tracer.traces {
+		tracer.traces[i] = &traceNode{}
+		if err := tracer.traces[i].read(buf); err != nil {
 			return err
 		}
 	}
@@ -235,8 +236,9 @@ func (node *traceNode) read(r io.Reader) error {
 
 	// Read items
 	node.children = make([]*traceNode, count)
-	for _, child := range node.children {
-		if err := child.read(r); err != nil {
+	for i := range node.children {
+		node.children[i] = &traceNode{}
+		if err := node.children[i].read(r); err != nil {
 			return err
 		}
 	}
@@ -275,6 +275,11 @@ func (a *Asset) ModificationRequest(ctx context.Context, w *node.ResponseWriter,
 			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 		}
 
+		if !vt.AppliedTxId.IsZero() {
+			node.LogWarn(ctx, "Vote already applied : %s", vt.AppliedTxId.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+		}
+
 		// Verify proposal amendments match these amendments.
 		if len(voteResult.ProposedAmendments) != len(msg.Amendments) {
 			node.LogWarn(ctx, "%s : Proposal has different count of amendments : %d != %d",
@@ -422,8 +427,21 @@ func (a *Asset) CreationResponse(ctx context.Context, w *node.ResponseWriter,
 		return errors.Wrap(err, "Failed to retrieve request tx")
 	}
 	var vt *state.Vote
+	var appliedTxId *protocol.TxId
 	var modification *actions.AssetModification
 	if request != nil {
+		appliedTxId = protocol.TxIdFromBytes(request.Hash[:])
+
+		if _, ok := request.MsgProto.(*actions.Proposal); ok && as != nil {
+			// Amendments applied automatically are funded by the proposal.
+			proposalTxId := protocol.TxIdFromBytes(request.Hash[:])
+			vt, err = vote.RetrieveByProposal(ctx, a.MasterDB, rk.Address, proposalTxId)
+			if err != nil {
+				return errors.Wrap(err, "Failed to retrieve vote for proposal")
+			}
+			appliedTxId = protocol.TxIdFromBytes(itx.Hash[:])
+		}
+
 		var ok bool
 		modification, ok = request.MsgProto.(*actions.AssetModification)
 
@@ -624,7 +642,7 @@ func (a *Asset) CreationResponse(ctx context.Context, w *node.ResponseWriter,
 		// Mark vote as "applied" if this amendment was a result of a vote.
 		if vt != nil {
 			node.Log(ctx, "Marking vote as applied : %s", vt.VoteTxId.String())
-			if err := vote.MarkApplied(ctx, a.MasterDB, rk.Address, vt.VoteTxId, protocol.TxIdFromBytes(request.Hash[:]), v.Now); err != nil {
+			if err := vote.MarkApplied(ctx, a.MasterDB, rk.Address, vt.VoteTxId, appliedTxId, v.Now); err != nil {
 				return errors.Wrap(err, "Failed to mark vote applied")
 			}
 		}
@@ -7,13 +7,18 @@ import (
 	"time"
 
 	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/enforcement"
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/internal/thaw"
 	"github.com/tokenized/smart-contract/internal/transactions"
+	"github.com/tokenized/smart-contract/internal/transfer"
+	"github.com/tokenized/smart-contract/internal/utxos"
 	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
 	"github.com/tokenized/smart-contract/pkg/wallet"
 
 	"github.com/tokenized/specification/dist/golang/actions"
@@ -27,6 +32,7 @@ type Contract struct {
 	MasterDB *db.DB
 	Config   *node.Config
 	Headers  node.BitcoinHeaders
+	UTXOs    *utxos.UTXOs
 }
 
 // OfferRequest handles an incoming Contract Offer and prepares a Formation response
@@ -82,6 +88,11 @@ func (c *Contract) OfferRequest(ctx context.Context, w *node.ResponseWriter, itx
 		}
 	}
 
+	if err = contract.ValidateSupportingDocs(msg.SupportingDocs, len(msg.VotingSystems)); err != nil {
+		node.LogWarn(ctx, "Invalid supporting documents : %s", err)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+	}
+
 	node.Log(ctx, "Accepting contract offer : %s", msg.ContractName)
 
 	// Contract Formation <- Contract Offer
@@ -211,6 +222,11 @@ func (c *Contract) AmendmentRequest(ctx context.Context, w *node.ResponseWriter,
 			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 		}
 
+		if !vt.AppliedTxId.IsZero() {
+			node.LogWarn(ctx, "Vote already applied : %s", vt.AppliedTxId.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+		}
+
 		// Verify proposal amendments match these amendments.
 		if len(voteResult.ProposedAmendments) != len(msg.Amendments) {
 			node.LogWarn(ctx, "%s : Proposal has different count of amendments : %d != %d",
@@ -359,6 +375,11 @@ func (c *Contract) AmendmentRequest(ctx context.Context, w *node.ResponseWriter,
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 	}
 
+	if err = contract.ValidateSupportingDocs(cf.SupportingDocs, len(cf.VotingSystems)); err != nil {
+		node.LogWarn(ctx, "Invalid supporting documents : %s", err)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+	}
+
 	// Build outputs
 	// 1 - Contract Address
 	// 2 - Contract Fee (change)
@@ -434,8 +455,21 @@ func (c *Contract) FormationResponse(ctx context.Context, w *node.ResponseWriter
 	// Get request tx
 	request, err := transactions.GetTx(ctx, c.MasterDB, &itx.Inputs[0].UTXO.Hash, c.Config.IsTest)
 	var vt *state.Vote
+	var appliedTxId *protocol.TxId
 	var amendment *actions.ContractAmendment
 	if err == nil && request != nil {
+		appliedTxId = protocol.TxIdFromBytes(request.Hash[:])
+
+		if _, ok := request.MsgProto.(*actions.Proposal); ok && ct != nil {
+			// Amendments applied automatically are funded by the proposal.
+			proposalTxId := protocol.TxIdFromBytes(request.Hash[:])
+			vt, err = vote.RetrieveByProposal(ctx, c.MasterDB, rk.Address, proposalTxId)
+			if err != nil {
+				return errors.Wrap(err, "Failed to retrieve vote for proposal")
+			}
+			appliedTxId = protocol.TxIdFromBytes(itx.Hash[:])
+		}
+
 		var ok bool
 		amendment, ok = request.MsgProto.(*actions.ContractAmendment)
 
@@ -692,8 +726,8 @@ func (c *Contract) FormationResponse(ctx context.Context, w *node.ResponseWriter
 		// Mark vote as "applied" if this amendment was a result of a vote.
 		if vt != nil {
 			node.Log(ctx, "Marking vote as applied : %s", vt.VoteTxId.String())
-			if err := vote.MarkApplied(ctx, c.MasterDB, rk.Address, vt.VoteTxId,
-				protocol.TxIdFromBytes(request.Hash[:]), v.Now); err != nil {
+			if err := vote.MarkApplied(ctx, c.MasterDB, rk.Address, vt.VoteTxId, appliedTxId,
+				v.Now); err != nil {
 				return errors.Wrap(err, "Failed to mark vote applied")
 			}
 		}
@@ -702,7 +736,8 @@ func (c *Contract) FormationResponse(ctx context.Context, w *node.ResponseWriter
 	return nil
 }
 
-// AddressChange handles an incoming Contract Address Change.
+// AddressChange handles an incoming Contract Address Change. The contract's state is moved to the
+//   new address and the bitcoin held by the current address is swept to it.
 func (c *Contract) AddressChange(ctx context.Context, w *node.ResponseWriter, itx *inspector.Transaction, rk *wallet.Key) error {
 	ctx, span := trace.StartSpan(ctx, "handlers.Contract.AddressChange")
 	defer span.End()
@@ -712,15 +747,29 @@ func (c *Contract) AddressChange(ctx context.Context, w *node.ResponseWriter, it
 		return errors.New("Could not assert as *actions.ContractAddressChange")
 	}
 
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
 	// Validate all fields have valid values.
 	if itx.RejectCode != 0 {
 		node.LogWarn(ctx, "Contract address change request invalid")
 		return node.RespondReject(ctx, w, itx, rk, itx.RejectCode)
 	}
 
+	newContractAddress, err := bitcoin.DecodeRawAddress(msg.NewContractAddress)
+	if err != nil {
+		node.LogWarn(ctx, "Invalid new contract address : %x", msg.NewContractAddress)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsTxMalformed)
+	}
+
+	if newContractAddress.Equal(rk.Address) {
+		// The move is performed while processing for the current contract address.
+		node.LogVerbose(ctx, "Contract address change to this address")
+		return nil
+	}
+
 	// Locate Contract
 	ct, err := contract.Retrieve(ctx, c.MasterDB, rk.Address)
-	if err != nil && err != contract.ErrNotFound {
+	if err != nil {
 		return errors.Wrap(err, "Failed to retrieve contract")
 	}
 
@@ -731,12 +780,6 @@ func (c *Contract) AddressChange(ctx context.Context, w *node.ResponseWriter, it
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsTxMalformed)
 	}
 
-	newContractAddress, err := bitcoin.DecodeRawAddress(msg.NewContractAddress)
-	if err != nil {
-		node.LogWarn(ctx, "Invalid new contract address : %x", msg.NewContractAddress)
-		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsTxMalformed)
-	}
-
 	// Check that it is to the current contract address and the new contract address
 	toCurrent := false
 	toNew := false
@@ -754,18 +797,180 @@ func (c *Contract) AddressChange(ctx context.Context, w *node.ResponseWriter, it
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsTxMalformed)
 	}
 
+	// A migration for this request means it is being processed again after an interruption.
+	requestTxId := protocol.TxIdFromBytes(itx.Hash[:])
+	migration, err := contract.FetchMigration(ctx, c.MasterDB, rk.Address)
+	if err != nil && err != contract.ErrMigrationNotFound {
+		return errors.Wrap(err, "Failed to fetch contract migration")
+	}
+	resuming := migration != nil && migration.RequestTxId.Equal(*requestTxId)
+
+	if resuming && migration.CompletedAt.Nano() != 0 {
+		node.Log(ctx, "Contract address change already complete")
+		return nil
+	}
+
+	if !resuming && !ct.MovedTo.IsEmpty() {
+		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo, w.Config.Net)
+		node.LogWarn(ctx, "Contract address changed : %s", address.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractMoved)
+	}
+
+	if !resuming {
+		_, err := contract.Retrieve(ctx, c.MasterDB, newContractAddress)
+		if err == nil {
+			address := bitcoin.NewAddressFromRawAddress(newContractAddress, w.Config.Net)
+			node.LogWarn(ctx, "Contract already exists at new address : %s", address.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractExists)
+		}
+		if err != contract.ErrNotFound {
+			return errors.Wrap(err, "Failed to retrieve new contract")
+		}
+
+		// The responses of an enforcement batch are chained from the current address, so the
+		//   batch can't be completed after the contract moves.
+		batches, err := enforcement.List(ctx, c.MasterDB, rk.Address)
+		if err != nil {
+			return errors.Wrap(err, "Failed to list enforcement batches")
+		}
+		for _, batch := range batches {
+			if batch.CompletedAt.Nano() == 0 {
+				node.LogWarn(ctx, "Enforcement batch in progress : %s", batch.OrderTxId.String())
+				return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractNotPermitted)
+			}
+		}
+
+		// Save Tx so the move can be resumed if it is interrupted.
+		if err := transactions.AddTx(ctx, c.MasterDB, itx); err != nil {
+			return errors.Wrap(err, "Failed to save tx")
+		}
+	}
+
 	// Perform move
-	err = contract.Move(ctx, c.MasterDB, rk.Address, newContractAddress,
+	migration, err = contract.Move(ctx, c.MasterDB, rk.Address, newContractAddress, requestTxId,
 		protocol.NewTimestamp(msg.Timestamp))
+	if err == contract.ErrMoveConflict {
+		node.LogWarn(ctx, "Contract already moving to a different address")
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractMoved)
+	}
 	if err != nil {
-		return err
+		return errors.Wrap(err, "Failed to move contract")
 	}
 
-	//TODO Transfer all UTXOs to fee address.
+	if migration.Step < contract.MoveStepSwept {
+		migration.SweepTxId, err = c.sweepContractUTXOs(ctx, w, itx, rk, newContractAddress)
+		if err != nil {
+			return errors.Wrap(err, "Failed to sweep contract UTXOs")
+		}
+
+		migration.Step = contract.MoveStepSwept
+		migration.UpdatedAt = v.Now
+		migration.CompletedAt = v.Now
+		if err := contract.SaveMigration(ctx, c.MasterDB, migration); err != nil {
+			return errors.Wrap(err, "Failed to save contract migration")
+		}
+	}
 
+	address := bitcoin.NewAddressFromRawAddress(newContractAddress, w.Config.Net)
+	node.Log(ctx, "Contract moved to %s", address.String())
 	return nil
 }
 
+// sweepContractUTXOs sends the bitcoin held by the old contract address to the new contract
+//   address. Outputs that fund responses to open votes, pending transfers, and pending thaws are
+//   left so those responses can still be made. Returns nil if there is not enough bitcoin to sweep.
+func (c *Contract) sweepContractUTXOs(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key,
+	newContractAddress bitcoin.RawAddress) (*protocol.TxId, error) {
+
+	// Votes, transfers, and thaws were already moved to the new address.
+	reserved := make(map[bitcoin.Hash32]bool)
+	vts, err := vote.List(ctx, c.MasterDB, newContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "list votes")
+	}
+	for _, vt := range vts {
+		if vt.CompletedAt.Nano() != 0 {
+			continue
+		}
+		hash, err := bitcoin.NewHash32(vt.ProposalTxId.Bytes())
+		if err != nil {
+			return nil, errors.Wrap(err, "proposal tx hash")
+		}
+		reserved[*hash] = true
+	}
+
+	pts, err := transfer.List(ctx, c.MasterDB, newContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "list transfers")
+	}
+	for _, pt := range pts {
+		hash, err := bitcoin.NewHash32(pt.TransferTxId.Bytes())
+		if err != nil {
+			return nil, errors.Wrap(err, "transfer tx hash")
+		}
+		reserved[*hash] = true
+	}
+
+	// Thaws are funded by the change the freeze tx kept at the contract.
+	pths, err := thaw.List(ctx, c.MasterDB, newContractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "list thaws")
+	}
+	for _, pth := range pths {
+		hash, err := bitcoin.NewHash32(pth.FreezeTxId.Bytes())
+		if err != nil {
+			return nil, errors.Wrap(err, "freeze tx hash")
+		}
+		reserved[*hash] = true
+	}
+
+	tx := txbuilder.NewTxBuilder(c.Config.DustLimit, c.Config.FeeRate)
+
+	requestUTXOs, err := itx.UTXOs().ForAddress(rk.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "request UTXOs")
+	}
+	for _, utxo := range requestUTXOs {
+		if err := tx.AddInputUTXO(utxo); err != nil {
+			return nil, errors.Wrap(err, "add request input")
+		}
+	}
+
+	if c.UTXOs != nil {
+		for _, utxo := range c.UTXOs.Unspent(rk.Address) {
+			if reserved[utxo.OutPoint.Hash] || utxo.OutPoint.Hash.Equal(itx.Hash) {
+				continue
+			}
+			if err := tx.AddInput(utxo.OutPoint, utxo.Output.PkScript,
+				uint64(utxo.Output.Value)); err != nil {
+				return nil, errors.Wrap(err, "add input")
+			}
+		}
+	}
+
+	if err := tx.AddMaxOutput(newContractAddress); err != nil {
+		return nil, errors.Wrap(err, "add output")
+	}
+
+	if err := tx.Sign([]bitcoin.Key{rk.Key}); err != nil {
+		if txbuilder.IsErrorCode(err, txbuilder.ErrorCodeInsufficientValue) {
+			node.LogWarn(ctx, "Not enough bitcoin to sweep contract UTXOs : %s", err)
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "sign")
+	}
+
+	sweepTxId := protocol.TxIdFromBytes(tx.MsgTx.TxHash()[:])
+	node.Log(ctx, "Sweeping %d to new contract address : %s", tx.OutputValue(true),
+		sweepTxId.String())
+	if err := node.Respond(ctx, w, tx.MsgTx); err != nil {
+		return nil, errors.Wrap(err, "respond")
+	}
+
+	return sweepTxId, nil
+}
+
 // applyContractAmendments applies the amendments to the contract formation.
 func applyContractAmendments(cf *actions.ContractFormation, amendments []*actions.AmendmentField,
 	proposed bool, proposalType, votingSystem uint32) error {
@@ -5,16 +5,24 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
 	"github.com/tokenized/smart-contract/internal/asset"
+	"github.com/tokenized/smart-contract/internal/authority"
 	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/enforcement"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
+	"github.com/tokenized/smart-contract/internal/platform/protomux"
 	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/internal/thaw"
 	"github.com/tokenized/smart-contract/internal/transactions"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/scheduler"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
 	"github.com/tokenized/smart-contract/pkg/wallet"
+	"github.com/tokenized/smart-contract/pkg/wire"
 
 	"github.com/tokenized/specification/dist/golang/actions"
 	"github.com/tokenized/specification/dist/golang/protocol"
@@ -24,8 +32,10 @@ import (
 )
 
 type Enforcement struct {
+	handler         protomux.Handler
 	MasterDB        *db.DB
 	Config          *node.Config
+	Scheduler       *scheduler.Scheduler
 	HoldingsChannel *holdings.CacheChannel
 }
 
@@ -71,8 +81,17 @@ func (e *Enforcement) OrderRequest(ctx context.Context, w *node.ResponseWriter,
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsNotOperator)
 	}
 
+	// Confiscations may be restricted to orders signed by a registered authority.
+	requireAuthority := ct.ConfiscationAuthorityRequired &&
+		msg.ComplianceAction == actions.ComplianceActionConfiscation
+
 	// Validate enforcement authority public key and signature
-	if len(msg.OrderSignature) > 0 || msg.SignatureAlgorithm != 0 {
+	if len(msg.OrderSignature) == 0 && msg.SignatureAlgorithm == 0 {
+		if requireAuthority {
+			node.LogWarn(ctx, "Confiscation order not signed by an authority")
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInvalidSignature)
+		}
+	} else {
 		if msg.SignatureAlgorithm != 1 {
 			node.LogWarn(ctx, "Invalid authority sig algo : %02x", msg.SignatureAlgorithm)
 			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
@@ -99,6 +118,28 @@ func (e *Enforcement) OrderRequest(ctx context.Context, w *node.ResponseWriter,
 			node.LogWarn(ctx, "Authority Sig Verify Failed")
 			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInvalidSignature)
 		}
+
+		// Contracts with an authority registry only accept orders signed by an active authority.
+		if !requireAuthority {
+			requireAuthority, err = authority.HasRegistry(ctx, e.MasterDB, rk.Address)
+			if err != nil {
+				return errors.Wrap(err, "Failed to check authority registry")
+			}
+		}
+
+		if requireAuthority {
+			a, err := authority.Check(ctx, e.MasterDB, rk.Address, authorityPubKey, v.Now)
+			if err == authority.ErrNotFound || err == authority.ErrNotActive {
+				node.LogWarn(ctx, "Order authority not recognized : %s : %s",
+					authorityPubKey.String(), err)
+				return node.RespondReject(ctx, w, itx, rk, actions.RejectionsUnauthorizedAddress)
+			}
+			if err != nil {
+				return errors.Wrap(err, "Failed to check authority")
+			}
+
+			node.Log(ctx, "Order signed by authority %s : %s", a.Name, authorityPubKey.String())
+		}
 	}
 
 	// Apply logic based on Compliance Action type
@@ -184,6 +225,7 @@ func (e *Enforcement) OrderFreezeRequest(ctx context.Context, w *node.ResponseWr
 		if !full {
 			outputIndex := uint32(0)
 			used := make(map[bitcoin.Hash20]bool)
+			targetAddresses := make([]bitcoin.RawAddress, 0, len(msg.TargetAddresses))
 
 			// Validate target addresses
 			for _, target := range msg.TargetAddresses {
@@ -214,19 +256,31 @@ func (e *Enforcement) OrderFreezeRequest(ctx context.Context, w *node.ResponseWr
 				}
 
 				used[*hash] = true
-
-				// Notify target address
-				w.AddOutput(ctx, targetAddress, 0)
+				targetAddresses = append(targetAddresses, targetAddress)
 
 				freeze.Quantities = append(freeze.Quantities,
 					&actions.QuantityIndexField{Index: outputIndex, Quantity: target.Quantity})
 				outputIndex++
 			}
+
+			if e.isBatch(len(targetAddresses)) {
+				return e.orderBatchFreeze(ctx, w, itx, rk, ct, targetAddresses)
+			}
+
+			// Notify target addresses
+			for _, targetAddress := range targetAddresses {
+				w.AddOutput(ctx, targetAddress, 0)
+			}
 		}
 	}
 
-	// Add contract output
-	w.AddOutput(ctx, rk.Address, 0)
+	// Add contract output. A time-limited freeze keeps the remaining funding in the contract
+	//   output so the contract can fund the thaw when the freeze period ends.
+	if msg.FreezePeriod != 0 {
+		w.AddChangeOutput(ctx, rk.Address)
+	} else {
+		w.AddOutput(ctx, rk.Address, 0)
+	}
 
 	// Add fee output
 	w.AddContractFee(ctx, ct.ContractFee)
@@ -418,6 +472,7 @@ func (e *Enforcement) OrderConfiscateRequest(ctx context.Context, w *node.Respon
 
 	// Validate target addresses
 	outputIndex := uint32(0)
+	targetAddresses := make([]bitcoin.RawAddress, 0, len(msg.TargetAddresses))
 	for _, target := range msg.TargetAddresses {
 		targetAddress, err := bitcoin.DecodeRawAddress(target.Address)
 		if err != nil {
@@ -466,8 +521,7 @@ func (e *Enforcement) OrderConfiscateRequest(ctx context.Context, w *node.Respon
 
 		node.Log(ctx, "Confiscation order request : %x %s", msg.AssetCode, address.String())
 
-		// Notify target address
-		w.AddOutput(ctx, targetAddress, 0)
+		targetAddresses = append(targetAddresses, targetAddress)
 		outputIndex++
 	}
 
@@ -500,6 +554,15 @@ func (e *Enforcement) OrderConfiscateRequest(ctx context.Context, w *node.Respon
 	hds[*hash] = depositHolding
 	confiscation.DepositQty = depositHolding.PendingBalance
 
+	if e.isBatch(len(targetAddresses)) {
+		return e.startBatch(ctx, w, itx, rk, ct, hds)
+	}
+
+	// Notify target addresses
+	for _, targetAddress := range targetAddresses {
+		w.AddOutput(ctx, targetAddress, 0)
+	}
+
 	// Notify deposit address
 	w.AddOutput(ctx, depositAddress, 0)
 
@@ -526,6 +589,421 @@ func (e *Enforcement) OrderConfiscateRequest(ctx context.Context, w *node.Respon
 	return nil
 }
 
+// isBatch returns true when an order has too many targets for one response.
+func (e *Enforcement) isBatch(targetCount int) bool {
+	return e.Config.MaxEnforcementTargets > 0 && targetCount > e.Config.MaxEnforcementTargets
+}
+
+// orderBatchFreeze holds the balances of all targets of a batched freeze order so the order takes
+//   effect at once. Each hold is replaced by a freeze when the response containing the target is
+//   processed.
+func (e *Enforcement) orderBatchFreeze(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key, ct *state.Contract,
+	targetAddresses []bitcoin.RawAddress) error {
+
+	msg, ok := itx.MsgProto.(*actions.Order)
+	if !ok {
+		return errors.New("Could not assert as *protocol.Order")
+	}
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	txid := protocol.TxIdFromBytes(itx.Hash[:])
+	assetCode := protocol.AssetCodeFromBytes(msg.AssetCode)
+	freezePeriod := protocol.NewTimestamp(msg.FreezePeriod)
+
+	hds := make(map[bitcoin.Hash20]*state.Holding)
+	for i, targetAddress := range targetAddresses {
+		hash, err := targetAddress.Hash()
+		if err != nil {
+			return errors.Wrap(err, "Invalid freeze address")
+		}
+
+		h, err := holdings.GetHolding(ctx, e.MasterDB, rk.Address, assetCode, targetAddress, v.Now)
+		if err != nil {
+			return errors.Wrap(err, "Failed to get holding")
+		}
+
+		err = holdings.AddFreeze(h, txid, msg.TargetAddresses[i].Quantity, freezePeriod, v.Now)
+		if err != nil {
+			address := bitcoin.NewAddressFromRawAddress(targetAddress, w.Config.Net)
+			node.LogWarn(ctx, "Failed to add order hold to holding : %x %s : %s", msg.AssetCode,
+				address.String(), err)
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+		}
+
+		hds[*hash] = h
+	}
+
+	return e.startBatch(ctx, w, itx, rk, ct, hds)
+}
+
+// startBatch responds to the first targets of an order with too many targets for one response,
+//   and saves the progress so the rest are responded to in chained txs. The holdings are the
+//   updated holdings of the order, which are saved when the response is sent.
+func (e *Enforcement) startBatch(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key, ct *state.Contract,
+	hds map[bitcoin.Hash20]*state.Holding) error {
+
+	msg, ok := itx.MsgProto.(*actions.Order)
+	if !ok {
+		return errors.New("Could not assert as *protocol.Order")
+	}
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	txid := protocol.TxIdFromBytes(itx.Hash[:])
+
+	// The order must fund every response so it is never partially applied.
+	required, err := e.batchFunding(ctx, rk, ct, msg, hds)
+	if err != nil {
+		return errors.Wrap(err, "Failed to calculate batch funding")
+	}
+
+	utxos, err := itx.UTXOs().ForAddress(rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get order utxos")
+	}
+
+	if funding := utxos.Value(); funding < required {
+		node.LogWarn(ctx, "Order funding too low for %d targets : %d < %d",
+			len(msg.TargetAddresses), funding, required)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInsufficientTxFeeFunding)
+	}
+
+	// Save Tx to build the remaining responses.
+	if err := transactions.AddTx(ctx, e.MasterDB, itx); err != nil {
+		return errors.Wrap(err, "Failed to save tx")
+	}
+
+	if err := e.respondBatch(ctx, w, itx, rk, ct, msg, 0, hds); err != nil {
+		return err
+	}
+
+	batch := state.EnforcementBatch{
+		OrderTxId: txid,
+		PrevTxId:  txid,
+		CreatedAt: v.Now,
+		UpdatedAt: v.Now,
+	}
+	if err := enforcement.Save(ctx, e.MasterDB, rk.Address, &batch); err != nil {
+		return errors.Wrap(err, "Failed to save enforcement batch")
+	}
+
+	assetCode := protocol.AssetCodeFromBytes(msg.AssetCode)
+	for _, h := range hds {
+		cacheItem, err := holdings.Save(ctx, e.MasterDB, rk.Address, assetCode, h)
+		if err != nil {
+			return errors.Wrap(err, "Failed to save holding")
+		}
+		e.HoldingsChannel.Add(cacheItem)
+	}
+
+	node.Log(ctx, "Started enforcement batch : %s %d targets", txid.String(),
+		len(msg.TargetAddresses))
+	return nil
+}
+
+// continueBatch records a processed response of an enforcement batch and responds to the next
+//   targets of the order, spending the contract output of the response.
+func (e *Enforcement) continueBatch(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key, batch *state.EnforcementBatch,
+	targetCount int) error {
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	txid := protocol.TxIdFromBytes(itx.Hash[:])
+
+	hash, err := bitcoin.NewHash32(batch.OrderTxId.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Invalid order txid")
+	}
+	orderTx, err := transactions.GetTx(ctx, e.MasterDB, hash, e.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve order tx")
+	}
+
+	order, ok := orderTx.MsgProto.(*actions.Order)
+	if !ok {
+		return errors.New("Could not assert as *protocol.Order")
+	}
+
+	batch.Next += uint32(targetCount)
+	batch.PrevTxId = txid
+	batch.ResponseTxIds = append(batch.ResponseTxIds, txid)
+	batch.UpdatedAt = v.Now
+
+	node.Log(ctx, "Processed enforcement batch response %d : %s %d/%d targets",
+		len(batch.ResponseTxIds), batch.OrderTxId.String(), batch.Next, len(order.TargetAddresses))
+
+	if int(batch.Next) >= len(order.TargetAddresses) {
+		batch.CompletedAt = v.Now
+		if err := enforcement.Save(ctx, e.MasterDB, rk.Address, batch); err != nil {
+			return errors.Wrap(err, "Failed to save enforcement batch")
+		}
+
+		node.Log(ctx, "Completed enforcement batch : %s", batch.OrderTxId.String())
+		return nil
+	}
+
+	if err := enforcement.Save(ctx, e.MasterDB, rk.Address, batch); err != nil {
+		return errors.Wrap(err, "Failed to save enforcement batch")
+	}
+
+	ct, err := contract.Retrieve(ctx, e.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
+	return e.respondBatch(ctx, w, itx, rk, ct, order, batch.Next, nil)
+}
+
+// ResumeBatch responds to the next targets of an enforcement batch that was interrupted, like by a
+//   restart.
+func (e *Enforcement) ResumeBatch(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key) error {
+
+	ctx, span := trace.StartSpan(ctx, "handlers.Enforcement.ResumeBatch")
+	defer span.End()
+
+	msg, ok := itx.MsgProto.(*actions.Order)
+	if !ok {
+		return errors.New("Could not assert as *protocol.Order")
+	}
+
+	txid := protocol.TxIdFromBytes(itx.Hash[:])
+	batch, err := enforcement.Fetch(ctx, e.MasterDB, rk.Address, txid)
+	if err != nil {
+		if err == enforcement.ErrNotFound {
+			return nil
+		}
+		return errors.Wrap(err, "Failed to fetch enforcement batch")
+	}
+
+	if batch.CompletedAt.Nano() != 0 {
+		return nil
+	}
+
+	ct, err := contract.Retrieve(ctx, e.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
+	if !ct.MovedTo.IsEmpty() {
+		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo, w.Config.Net)
+		node.LogWarn(ctx, "Contract address changed : %s", address.String())
+		return nil
+	}
+
+	hash, err := bitcoin.NewHash32(batch.PrevTxId.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Invalid previous txid")
+	}
+	prevTx, err := transactions.GetTx(ctx, e.MasterDB, hash, e.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve previous batch tx")
+	}
+
+	node.Log(ctx, "Resuming enforcement batch : %s %d/%d targets", txid.String(), batch.Next,
+		len(msg.TargetAddresses))
+	return e.respondBatch(ctx, w, prevTx, rk, ct, msg, batch.Next, nil)
+}
+
+// findBatch returns the incomplete enforcement batch that a response belongs to, or nil if it
+//   isn't part of one.
+func (e *Enforcement) findBatch(ctx context.Context, contractAddress bitcoin.RawAddress,
+	itx *inspector.Transaction) (*state.EnforcementBatch, error) {
+
+	batches, err := enforcement.List(ctx, e.MasterDB, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, batch := range batches {
+		if batch.CompletedAt.Nano() == 0 &&
+			bytes.Equal(batch.PrevTxId.Bytes(), itx.Inputs[0].UTXO.Hash[:]) {
+			return batch, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// respondBatch responds to the targets of an order starting at next. The contract output is the
+//   change so it funds the following response.
+func (e *Enforcement) respondBatch(ctx context.Context, w *node.ResponseWriter,
+	fundingTx *inspector.Transaction, rk *wallet.Key, ct *state.Contract, order *actions.Order,
+	next uint32, hds map[bitcoin.Hash20]*state.Holding) error {
+
+	action, addresses, err := e.buildBatchResponse(ctx, rk, order, next, hds)
+	if err != nil {
+		return errors.Wrap(err, "Failed to build batch response")
+	}
+
+	// Outputs
+	// 1..n - Target Addresses (and Deposit Address for confiscations)
+	// n+1  - Contract Address (change)
+	// n+2  - Contract Fee
+	for _, address := range addresses {
+		w.AddOutput(ctx, address, 0)
+	}
+	w.AddChangeOutput(ctx, rk.Address)
+	w.AddContractFee(ctx, ct.ContractFee)
+
+	return node.RespondSuccess(ctx, w, fundingTx, rk, action)
+}
+
+// buildBatchResponse returns the action responding to the targets of an order starting at next,
+//   and the addresses to notify. Holdings in hds are used before the stored holdings.
+func (e *Enforcement) buildBatchResponse(ctx context.Context, rk *wallet.Key,
+	order *actions.Order, next uint32,
+	hds map[bitcoin.Hash20]*state.Holding) (actions.Action, []bitcoin.RawAddress, error) {
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	assetCode := protocol.AssetCodeFromBytes(order.AssetCode)
+
+	end := len(order.TargetAddresses)
+	if e.Config.MaxEnforcementTargets > 0 && int(next)+e.Config.MaxEnforcementTargets < end {
+		end = int(next) + e.Config.MaxEnforcementTargets
+	}
+	targets := order.TargetAddresses[next:end]
+	addresses := make([]bitcoin.RawAddress, 0, len(targets)+1)
+
+	getHolding := func(address bitcoin.RawAddress) (*state.Holding, error) {
+		hash, err := address.Hash()
+		if err != nil {
+			return nil, err
+		}
+		if h, exists := hds[*hash]; exists {
+			return h, nil
+		}
+		return holdings.GetHolding(ctx, e.MasterDB, rk.Address, assetCode, address, v.Now)
+	}
+
+	switch order.ComplianceAction {
+	case actions.ComplianceActionFreeze:
+		freeze := actions.Freeze{}
+		if err := node.Convert(ctx, order, &freeze); err != nil {
+			return nil, nil, errors.Wrap(err, "convert order to freeze")
+		}
+		freeze.Timestamp = v.Now.Nano()
+
+		for i, target := range targets {
+			address, err := bitcoin.DecodeRawAddress(target.Address)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "target address")
+			}
+
+			addresses = append(addresses, address)
+			freeze.Quantities = append(freeze.Quantities,
+				&actions.QuantityIndexField{Index: uint32(i), Quantity: target.Quantity})
+		}
+
+		return &freeze, addresses, nil
+
+	case actions.ComplianceActionConfiscation:
+		confiscation := actions.Confiscation{}
+		if err := node.Convert(ctx, order, &confiscation); err != nil {
+			return nil, nil, errors.Wrap(err, "convert order to confiscation")
+		}
+		confiscation.Timestamp = v.Now.Nano()
+
+		share := uint64(0)
+		for i, target := range targets {
+			address, err := bitcoin.DecodeRawAddress(target.Address)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "target address")
+			}
+
+			h, err := getHolding(address)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "get holding")
+			}
+
+			addresses = append(addresses, address)
+			confiscation.Quantities = append(confiscation.Quantities,
+				&actions.QuantityIndexField{Index: uint32(i), Quantity: h.PendingBalance})
+			share += target.Quantity
+		}
+
+		depositAddress, err := bitcoin.DecodeRawAddress(order.DepositAddress)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "deposit address")
+		}
+
+		h, err := getHolding(depositAddress)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "get deposit holding")
+		}
+
+		// The deposit is finalized a response at a time, so each response only includes its
+		//   share.
+		addresses = append(addresses, depositAddress)
+		confiscation.DepositQty = h.FinalizedBalance + share
+
+		return &confiscation, addresses, nil
+	}
+
+	return nil, nil, fmt.Errorf("Unsupported batch compliance action : %s",
+		string(order.ComplianceAction))
+}
+
+// batchFunding returns an estimate of the funding needed for all responses of a batched order.
+//   Each response spends one contract output and pays dust to its addresses and the contract, the
+//   contract fee, and the tx fee.
+func (e *Enforcement) batchFunding(ctx context.Context, rk *wallet.Key, ct *state.Contract,
+	order *actions.Order, hds map[bitcoin.Hash20]*state.Holding) (uint64, error) {
+
+	result := uint64(0)
+	for next := 0; next < len(order.TargetAddresses); next += e.Config.MaxEnforcementTargets {
+		action, addresses, err := e.buildBatchResponse(ctx, rk, order, uint32(next), hds)
+		if err != nil {
+			return 0, err
+		}
+
+		script, err := protocol.Serialize(action, e.Config.IsTest)
+		if err != nil {
+			return 0, errors.Wrap(err, "serialize response")
+		}
+
+		outputCount := len(addresses) + 3 // Contract, fee, and payload
+		size := txbuilder.BaseTxSize + wire.VarIntSerializeSize(1) +
+			wire.VarIntSerializeSize(uint64(outputCount)) + txbuilder.MaximumP2PKHInputSize +
+			(outputCount-1)*txbuilder.P2PKHOutputSize + txbuilder.OutputBaseSize +
+			wire.VarIntSerializeSize(uint64(len(script))) + len(script)
+
+		result += uint64(len(addresses)+1)*e.Config.DustLimit + ct.ContractFee +
+			uint64(float32(size)*e.Config.FeeRate)
+	}
+
+	return result, nil
+}
+
+// reconciliationFunding returns an estimate of the funding needed for a reconciliation that
+//   spends one contract output and pays the values to its addresses, followed by the contract,
+//   the contract fee, and the tx fee. Values below the dust limit are paid as dust.
+func (e *Enforcement) reconciliationFunding(ct *state.Contract,
+	reconciliation *actions.Reconciliation, values []uint64) (uint64, error) {
+
+	script, err := protocol.Serialize(reconciliation, e.Config.IsTest)
+	if err != nil {
+		return 0, errors.Wrap(err, "serialize response")
+	}
+
+	outputCount := len(values) + 3 // Contract, fee, and payload
+	size := txbuilder.BaseTxSize + wire.VarIntSerializeSize(1) +
+		wire.VarIntSerializeSize(uint64(outputCount)) + txbuilder.MaximumP2PKHInputSize +
+		(outputCount-1)*txbuilder.P2PKHOutputSize + txbuilder.OutputBaseSize +
+		wire.VarIntSerializeSize(uint64(len(script))) + len(script)
+
+	result := e.Config.DustLimit + ct.ContractFee + uint64(float32(size)*e.Config.FeeRate)
+	for _, value := range values {
+		if value < e.Config.DustLimit {
+			value = e.Config.DustLimit
+		}
+		result += value
+	}
+
+	return result, nil
+}
+
 // OrderReconciliationRequest is a helper of Order
 func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.ResponseWriter,
 	itx *inspector.Transaction, rk *wallet.Key) error {
@@ -574,6 +1052,22 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 	reconciliation.Quantities = make([]*actions.QuantityIndexField, 0, len(msg.TargetAddresses))
 	txid := protocol.TxIdFromBytes(itx.Hash[:])
 	hds := make(map[bitcoin.Hash20]*state.Holding)
+	assetCode := protocol.AssetCodeFromBytes(msg.AssetCode)
+
+	record := state.ReconciliationRecord{
+		OrderTxId:          txid,
+		AssetType:          msg.AssetType,
+		AssetCode:          assetCode,
+		Message:            msg.Message,
+		Operator:           itx.Inputs[0].Address,
+		AuthorityName:      msg.AuthorityName,
+		AuthorityPublicKey: msg.AuthorityPublicKey,
+		SignatureAlgorithm: msg.SignatureAlgorithm,
+		OrderSignature:     msg.OrderSignature,
+		Holdings:           make([]*state.ReconciledHolding, 0, len(msg.TargetAddresses)),
+		CreatedAt:          v.Now,
+		UpdatedAt:          v.Now,
+	}
 
 	// Build outputs
 	// 1..n - Target Addresses
@@ -584,7 +1078,6 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 	outputIndex := uint32(0)
 	addressOutputIndex := make([]uint32, 0, len(msg.TargetAddresses))
 	outputs := make([]node.Output, 0, len(msg.TargetAddresses))
-	assetCode := protocol.AssetCodeFromBytes(msg.AssetCode)
 	for _, target := range msg.TargetAddresses {
 		targetAddress, err := bitcoin.DecodeRawAddress(target.Address)
 		if err != nil {
@@ -613,6 +1106,7 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 			return errors.Wrap(err, "Failed to get holding")
 		}
 
+		balanceBefore := h.PendingBalance
 		err = holdings.AddDebit(h, txid, target.Quantity, true, v.Now)
 		if err != nil {
 			node.LogWarn(ctx, "Failed reconciliation for holding : %x %s : %s", msg.AssetCode,
@@ -629,6 +1123,13 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 		reconciliation.Quantities = append(reconciliation.Quantities,
 			&actions.QuantityIndexField{Index: outputIndex, Quantity: h.PendingBalance})
 
+		record.Holdings = append(record.Holdings, &state.ReconciledHolding{
+			Address:       targetAddress,
+			Quantity:      target.Quantity,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  h.PendingBalance,
+		})
+
 		node.Log(ctx, "Reconciliation order request : %x %s", msg.AssetCode, address.String())
 
 		// Notify target address
@@ -644,7 +1145,35 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 		}
 
+		if quantity.Quantity == 0 {
+			node.LogWarn(ctx, "Zero bitcoin dispersion is invalid : %x %d", msg.AssetCode,
+				quantity.Index)
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+		}
+
 		outputs[addressOutputIndex[quantity.Index]].Value += quantity.Quantity
+		record.Holdings[quantity.Index].Dispersion += quantity.Quantity
+	}
+
+	// The order must fund the bitcoin dispersions as well as the response.
+	values := make([]uint64, 0, len(outputs))
+	for _, output := range outputs {
+		values = append(values, output.Value)
+	}
+	required, err := e.reconciliationFunding(ct, &reconciliation, values)
+	if err != nil {
+		return errors.Wrap(err, "Failed to calculate reconciliation funding")
+	}
+
+	utxos, err := itx.UTXOs().ForAddress(rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get order utxos")
+	}
+
+	if funding := utxos.Value(); funding < required {
+		node.LogWarn(ctx, "Order funding too low for bitcoin dispersions : %d < %d", funding,
+			required)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInsufficientValue)
 	}
 
 	// Add outputs to response writer
@@ -671,6 +1200,10 @@ func (e *Enforcement) OrderReconciliationRequest(ctx context.Context, w *node.Re
 		}
 		e.HoldingsChannel.Add(cacheItem)
 	}
+
+	if err := enforcement.SaveReconciliation(ctx, e.MasterDB, rk.Address, &record); err != nil {
+		return errors.Wrap(err, "Failed to save reconciliation record")
+	}
 	return nil
 }
 
@@ -707,6 +1240,11 @@ func (e *Enforcement) FreezeResponse(ctx context.Context, w *node.ResponseWriter
 		return fmt.Errorf("Contract address changed : %s", address.String())
 	}
 
+	batch, err := e.findBatch(ctx, rk.Address, itx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to find enforcement batch")
+	}
+
 	full := false
 	if len(msg.Quantities) == 0 {
 		return fmt.Errorf("No freeze addresses specified")
@@ -779,6 +1317,16 @@ func (e *Enforcement) FreezeResponse(ctx context.Context, w *node.ResponseWriter
 						msg.AssetCode, address.String(), err)
 				}
 
+				// The freeze replaces the hold placed by a batched order.
+				if batch != nil {
+					if err := holdings.RevertStatus(h, batch.OrderTxId); err != nil {
+						address := bitcoin.NewAddressFromRawAddress(itx.Outputs[quantity.Index].Address,
+							w.Config.Net)
+						node.LogWarn(ctx, "Missing order hold for holding : %x %s : %s",
+							msg.AssetCode, address.String(), err)
+					}
+				}
+
 				hds[*hash] = h
 			}
 
@@ -798,7 +1346,28 @@ func (e *Enforcement) FreezeResponse(ctx context.Context, w *node.ResponseWriter
 	}
 
 	txid := protocol.TxIdFromBytes(itx.Hash[:])
+
+	// Thaw time-limited freezes when the freeze period ends.
+	if msg.FreezePeriod != 0 {
+		pt := state.PendingThaw{
+			FreezeTxId: txid,
+			Expires:    protocol.NewTimestamp(msg.FreezePeriod),
+		}
+		if err := thaw.Save(ctx, e.MasterDB, rk.Address, &pt); err != nil {
+			return errors.Wrap(err, "Failed to save pending thaw")
+		}
+
+		if err := e.Scheduler.ScheduleJob(ctx, listeners.NewFreezeThawer(e.handler, itx,
+			pt.Expires)); err != nil {
+			return errors.Wrap(err, "Failed to schedule freeze thawer")
+		}
+	}
+
 	node.Log(ctx, "Processed Freeze : %s", txid.String())
+
+	if batch != nil {
+		return e.continueBatch(ctx, w, itx, rk, batch, len(msg.Quantities))
+	}
 	return nil
 }
 
@@ -819,9 +1388,13 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 	}
 
 	if !itx.Inputs[0].Address.Equal(rk.Address) {
-		address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
-			w.Config.Net)
-		return fmt.Errorf("Thaw not from contract : %s", address.String())
+		// Thaws of freezes that were pending when the contract moved are from the old address.
+		from, err := contract.RetrieveCurrent(ctx, e.MasterDB, itx.Inputs[0].Address)
+		if err != nil || !from.Address.Equal(rk.Address) {
+			address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
+				w.Config.Net)
+			return fmt.Errorf("Thaw not from contract : %s", address.String())
+		}
 	}
 
 	ct, err := contract.Retrieve(ctx, e.MasterDB, rk.Address)
@@ -830,6 +1403,13 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 	}
 
 	if !ct.MovedTo.IsEmpty() {
+		if itx.Inputs[0].Address.Equal(rk.Address) {
+			// The thaw is recorded by the contract at the new address.
+			address := bitcoin.NewAddressFromRawAddress(ct.MovedTo,
+				w.Config.Net)
+			node.LogVerbose(ctx, "Contract address changed : %s", address.String())
+			return nil
+		}
 		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo,
 			w.Config.Net)
 		return fmt.Errorf("Contract address changed : %s", address.String())
@@ -848,11 +1428,217 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 		return fmt.Errorf("Failed to assert freeze tx op return : %x", msg.FreezeTxId)
 	}
 
+	if err := e.releaseFreeze(ctx, rk.Address, freezeTx, freeze,
+		protocol.NewTimestamp(msg.Timestamp)); err != nil {
+		return err
+	}
+
+	// Remove pending thaw of a time-limited freeze. The job is already finished when this is the
+	//   automatic thaw.
+	freezeTxId := protocol.TxIdFromBytes(freezeTx.Hash[:])
+	if err := thaw.Remove(ctx, e.MasterDB, rk.Address, freezeTxId); err != nil {
+		if err != thaw.ErrNotFound {
+			return errors.Wrap(err, "Failed to remove pending thaw")
+		}
+	} else {
+		err := e.Scheduler.CancelJob(ctx, listeners.NewFreezeThawer(nil, freezeTx,
+			protocol.NewTimestamp(0)))
+		if err != nil && err != scheduler.NotFound {
+			return errors.Wrap(err, "Failed to cancel freeze thawer")
+		}
+	}
+
+	txid := protocol.TxIdFromBytes(itx.Hash[:])
+	node.Log(ctx, "Processed Thaw : %s", txid.String())
+	return nil
+}
+
+// FreezeExpired responds with a Thaw action when the freeze period of a time-limited freeze ends.
+//   The thaw is funded by the contract outputs of the freeze tx. When they are too low the freeze
+//   is released without a thaw.
+func (e *Enforcement) FreezeExpired(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, rk *wallet.Key) error {
+
+	ctx, span := trace.StartSpan(ctx, "handlers.Enforcement.FreezeExpired")
+	defer span.End()
+
+	msg, ok := itx.MsgProto.(*actions.Freeze)
+	if !ok {
+		return errors.New("Could not assert as *actions.Freeze")
+	}
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	freezeTxId := protocol.TxIdFromBytes(itx.Hash[:])
+
+	// Retrieve contract. A freeze that was pending a thaw when the contract moved is thawed with
+	//   the state at the new address, but is still funded by this address.
+	ct, err := contract.RetrieveCurrent(ctx, e.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
+	// The pending thaw is removed when the freeze is thawed by an order.
+	if _, err := thaw.Fetch(ctx, e.MasterDB, ct.Address, freezeTxId); err != nil {
+		if err == thaw.ErrNotFound {
+			node.LogVerbose(ctx, "Freeze already thawed : %s", freezeTxId.String())
+			return nil
+		}
+		return errors.Wrap(err, "Failed to fetch pending thaw")
+	}
+
+	full := false
+	if len(msg.Quantities) == 0 {
+		return fmt.Errorf("No freeze addresses specified")
+	} else if len(msg.Quantities) == 1 &&
+		itx.Outputs[msg.Quantities[0].Index].Address.Equal(rk.Address) {
+		full = true
+	}
+
+	// A later contract or asset wide freeze replaces the freeze period, so it isn't released when
+	//   this freeze expires.
+	if full {
+		var freezePeriod protocol.Timestamp
+		if len(msg.AssetCode) == 0 {
+			freezePeriod = ct.FreezePeriod
+		} else {
+			as, err := asset.Retrieve(ctx, e.MasterDB, ct.Address,
+				protocol.AssetCodeFromBytes(msg.AssetCode))
+			if err != nil {
+				return errors.Wrap(err, "Failed to retrieve asset")
+			}
+			freezePeriod = as.FreezePeriod
+		}
+
+		if freezePeriod.Nano() != msg.FreezePeriod {
+			node.LogVerbose(ctx, "Freeze replaced by later freeze : %s", freezeTxId.String())
+			if err := thaw.Remove(ctx, e.MasterDB, ct.Address, freezeTxId); err != nil {
+				return errors.Wrap(err, "Failed to remove pending thaw")
+			}
+			return nil
+		}
+	}
+
+	// The contract output of a batched freeze response funds the next response, so it can't fund
+	//   a thaw.
+	spent, err := e.spentByBatch(ctx, rk.Address, freezeTxId)
+	if err != nil {
+		return errors.Wrap(err, "Failed to check enforcement batches")
+	}
+	if spent {
+		node.Log(ctx, "Releasing expired batch freeze : %s", freezeTxId.String())
+		return e.releaseExpiredFreeze(ctx, ct.Address, itx, msg, v.Now)
+	}
+
+	thawMsg := actions.Thaw{
+		FreezeTxId: freezeTxId.Bytes(),
+		Timestamp:  v.Now.Nano(),
+	}
+
+	// Build outputs
+	// 1..n - Target Addresses
+	// n+1  - Contract Address
+	// n+2  - Contract Fee (change)
+	tx := txbuilder.NewTxBuilder(e.Config.DustLimit, e.Config.FeeRate)
+	tx.SetChangeAddress(e.Config.FeeAddress, "")
+
+	utxos, err := itx.UTXOs().ForAddress(rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "get freeze utxos")
+	}
+	for _, utxo := range utxos {
+		if err := tx.AddInputUTXO(utxo); err != nil {
+			return errors.Wrap(err, "add input")
+		}
+	}
+
+	if !full {
+		for _, quantity := range msg.Quantities {
+			if err := tx.AddDustOutput(itx.Outputs[quantity.Index].Address, false); err != nil {
+				return errors.Wrap(err, "add target output")
+			}
+		}
+	}
+
+	if err := tx.AddDustOutput(ct.Address, false); err != nil {
+		return errors.Wrap(err, "add contract output")
+	}
+
+	if ct.ContractFee > 0 {
+		if err := tx.AddPaymentOutput(e.Config.FeeAddress, ct.ContractFee, true); err != nil {
+			return errors.Wrap(err, "add fee output")
+		}
+	}
+
+	script, err := protocol.Serialize(&thawMsg, e.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "serialize thaw")
+	}
+	if err := tx.AddOutput(script, 0, false, false); err != nil {
+		return errors.Wrap(err, "add payload output")
+	}
+
+	if err := tx.Sign([]bitcoin.Key{rk.Key}); err != nil {
+		if !txbuilder.IsErrorCode(err, txbuilder.ErrorCodeInsufficientValue) {
+			return errors.Wrap(err, "sign")
+		}
+
+		node.LogWarn(ctx, "Freeze funding too low to thaw, releasing freeze : %s : %s",
+			freezeTxId.String(), err)
+		return e.releaseExpiredFreeze(ctx, ct.Address, itx, msg, v.Now)
+	}
+
+	node.Log(ctx, "Thawing expired freeze : %s", freezeTxId.String())
+	return node.Respond(ctx, w, tx.MsgTx)
+}
+
+// releaseExpiredFreeze releases an expired freeze without a thaw and removes its pending thaw.
+func (e *Enforcement) releaseExpiredFreeze(ctx context.Context, contractAddress bitcoin.RawAddress,
+	freezeTx *inspector.Transaction, freeze *actions.Freeze, timestamp protocol.Timestamp) error {
+
+	if err := e.releaseFreeze(ctx, contractAddress, freezeTx, freeze, timestamp); err != nil {
+		return err
+	}
+
+	freezeTxId := protocol.TxIdFromBytes(freezeTx.Hash[:])
+	if err := thaw.Remove(ctx, e.MasterDB, contractAddress, freezeTxId); err != nil {
+		return errors.Wrap(err, "Failed to remove pending thaw")
+	}
+	return nil
+}
+
+// spentByBatch returns true if a response's contract output was spent by the next response of an
+//   enforcement batch.
+func (e *Enforcement) spentByBatch(ctx context.Context, contractAddress bitcoin.RawAddress,
+	txid *protocol.TxId) (bool, error) {
+
+	batches, err := enforcement.List(ctx, e.MasterDB, contractAddress)
+	if err != nil {
+		return false, err
+	}
+
+	for _, batch := range batches {
+		for i, responseTxId := range batch.ResponseTxIds {
+			if !bytes.Equal(responseTxId.Bytes(), txid.Bytes()) {
+				continue
+			}
+			return i < len(batch.ResponseTxIds)-1 || batch.CompletedAt.Nano() == 0, nil
+		}
+	}
+
+	return false, nil
+}
+
+// releaseFreeze removes the effects of a freeze from the contract, asset, or holdings it froze.
+//   The contract address is the contract's current address, which is not the address that sent the
+//   freeze if the contract has moved since.
+func (e *Enforcement) releaseFreeze(ctx context.Context, contractAddress bitcoin.RawAddress,
+	freezeTx *inspector.Transaction, freeze *actions.Freeze, timestamp protocol.Timestamp) error {
+
 	full := false
 	if len(freeze.Quantities) == 0 {
 		return fmt.Errorf("No freeze addresses specified")
 	} else if len(freeze.Quantities) == 1 &&
-		freezeTx.Outputs[freeze.Quantities[0].Index].Address.Equal(rk.Address) {
+		freezeTx.Outputs[freeze.Quantities[0].Index].Address.Equal(freezeTx.Inputs[0].Address) {
 		full = true
 	}
 
@@ -863,8 +1649,8 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 			// Contract wide freeze
 			var zeroTimestamp protocol.Timestamp
 			uc := contract.UpdateContract{FreezePeriod: &zeroTimestamp}
-			if err := contract.Update(ctx, e.MasterDB, rk.Address, &uc,
-				protocol.NewTimestamp(msg.Timestamp)); err != nil {
+			if err := contract.Update(ctx, e.MasterDB, contractAddress, &uc,
+				timestamp); err != nil {
 				return errors.Wrap(err, "Failed to clear contract freeze period")
 			}
 		}
@@ -873,15 +1659,14 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 			// Asset wide freeze
 			var zeroTimestamp protocol.Timestamp
 			ua := asset.UpdateAsset{FreezePeriod: &zeroTimestamp}
-			if err := asset.Update(ctx, e.MasterDB, rk.Address, protocol.AssetCodeFromBytes(freeze.AssetCode),
-				&ua, protocol.NewTimestamp(msg.Timestamp)); err != nil {
+			if err := asset.Update(ctx, e.MasterDB, contractAddress, protocol.AssetCodeFromBytes(freeze.AssetCode),
+				&ua, timestamp); err != nil {
 				return errors.Wrap(err, "Failed to clear asset freeze period")
 			}
 		} else {
 			hds := make(map[bitcoin.Hash20]*state.Holding)
 			freezeTxId := protocol.TxIdFromBytes(freezeTx.Hash[:])
 			assetCode := protocol.AssetCodeFromBytes(freeze.AssetCode)
-			timestamp := protocol.NewTimestamp(msg.Timestamp)
 
 			// Validate target addresses
 			for _, quantity := range freeze.Quantities {
@@ -890,10 +1675,10 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 						len(freezeTx.Outputs))
 				}
 
-				hash, err := itx.Outputs[quantity.Index].Address.Hash()
+				hash, err := freezeTx.Outputs[quantity.Index].Address.Hash()
 				if err != nil {
-					address := bitcoin.NewAddressFromRawAddress(itx.Outputs[quantity.Index].Address,
-						w.Config.Net)
+					address := bitcoin.NewAddressFromRawAddress(freezeTx.Outputs[quantity.Index].Address,
+						e.Config.Net)
 					node.LogWarn(ctx, "Invalid freeze address : %x %s", freeze.AssetCode,
 						address.String())
 					return fmt.Errorf("Invalid freeze address : %x %s", freeze.AssetCode,
@@ -901,24 +1686,24 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 				}
 				_, exists := hds[*hash]
 				if exists {
-					address := bitcoin.NewAddressFromRawAddress(itx.Outputs[quantity.Index].Address,
-						w.Config.Net)
+					address := bitcoin.NewAddressFromRawAddress(freezeTx.Outputs[quantity.Index].Address,
+						e.Config.Net)
 					node.LogWarn(ctx, "Address used more than once : %x %s", freeze.AssetCode,
 						address.String())
 					return fmt.Errorf("Address used more than once : %x %s", freeze.AssetCode,
 						address.String())
 				}
 
-				h, err := holdings.GetHolding(ctx, e.MasterDB, rk.Address, assetCode,
-					itx.Outputs[quantity.Index].Address, timestamp)
+				h, err := holdings.GetHolding(ctx, e.MasterDB, contractAddress, assetCode,
+					freezeTx.Outputs[quantity.Index].Address, timestamp)
 				if err != nil {
 					return errors.Wrap(err, "Failed to get holding")
 				}
 
 				err = holdings.RevertStatus(h, freezeTxId)
 				if err != nil {
-					address := bitcoin.NewAddressFromRawAddress(itx.Outputs[quantity.Index].Address,
-						w.Config.Net)
+					address := bitcoin.NewAddressFromRawAddress(freezeTx.Outputs[quantity.Index].Address,
+						e.Config.Net)
 					node.LogWarn(ctx, "Failed thaw for holding : %x %s : %s", freeze.AssetCode,
 						address.String(), err)
 					return fmt.Errorf("Failed thaw for holding : %x %s : %s", freeze.AssetCode,
@@ -929,7 +1714,7 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 			}
 
 			for _, h := range hds {
-				cacheItem, err := holdings.Save(ctx, e.MasterDB, rk.Address, assetCode, h)
+				cacheItem, err := holdings.Save(ctx, e.MasterDB, contractAddress, assetCode, h)
 				if err != nil {
 					return errors.Wrap(err, "Failed to save holding")
 				}
@@ -938,8 +1723,6 @@ func (e *Enforcement) ThawResponse(ctx context.Context, w *node.ResponseWriter,
 		}
 	}
 
-	txid := protocol.TxIdFromBytes(itx.Hash[:])
-	node.Log(ctx, "Processed Thaw : %s", txid.String())
 	return nil
 }
 
@@ -979,12 +1762,23 @@ func (e *Enforcement) ConfiscationResponse(ctx context.Context, w *node.Response
 		return fmt.Errorf("Contract address changed : %s", address.String())
 	}
 
+	// Responses after the first of an enforcement batch spend the previous response instead of
+	//   the order, which the holding statuses are for.
+	batch, err := e.findBatch(ctx, rk.Address, itx)
+	if err != nil {
+		return errors.Wrap(err, "Failed to find enforcement batch")
+	}
+	if batch != nil {
+		txid = batch.OrderTxId
+	}
+
 	// Apply confiscations
 	hds := make(map[bitcoin.Hash20]*state.Holding)
 	assetCode := protocol.AssetCodeFromBytes(msg.AssetCode)
 	timestamp := protocol.NewTimestamp(msg.Timestamp)
 
 	highestIndex := uint32(0)
+	depositShare := uint64(0)
 	for _, quantity := range msg.Quantities {
 		hash, err := itx.Outputs[quantity.Index].Address.Hash()
 		if err != nil {
@@ -1007,6 +1801,10 @@ func (e *Enforcement) ConfiscationResponse(ctx context.Context, w *node.Response
 			return errors.Wrap(err, "Failed to get holding")
 		}
 
+		if hs, exists := h.HoldingStatuses[*txid]; exists {
+			depositShare += hs.Amount
+		}
+
 		err = holdings.FinalizeTx(h, txid, quantity.Quantity, timestamp)
 		if err != nil {
 			address := bitcoin.NewAddressFromRawAddress(itx.Outputs[quantity.Index].Address,
@@ -1025,13 +1823,19 @@ func (e *Enforcement) ConfiscationResponse(ctx context.Context, w *node.Response
 	}
 
 	// Update deposit balance
-	h, err := holdings.GetHolding(ctx, e.MasterDB, rk.Address, assetCode,
+	depositHolding, err := holdings.GetHolding(ctx, e.MasterDB, rk.Address, assetCode,
 		itx.Outputs[highestIndex+1].Address, timestamp)
 	if err != nil {
 		return errors.Wrap(err, "Failed to get deposit holding")
 	}
 
-	err = holdings.FinalizeTx(h, txid, msg.DepositQty, timestamp)
+	// Each response of a batched order deposits the share of its targets.
+	if batch == nil {
+		err = holdings.FinalizeTx(depositHolding, txid, msg.DepositQty, timestamp)
+	} else {
+		err = holdings.FinalizeDepositPart(depositHolding, txid, depositShare, msg.DepositQty,
+			timestamp)
+	}
 	if err != nil {
 		address := bitcoin.NewAddressFromRawAddress(itx.Outputs[highestIndex+1].Address,
 			w.Config.Net)
@@ -1041,14 +1845,14 @@ func (e *Enforcement) ConfiscationResponse(ctx context.Context, w *node.Response
 			msg.AssetCode, address.String(), err)
 	}
 
-	hash, err := itx.Outputs[highestIndex+1].Address.Hash()
+	depositHash, err := itx.Outputs[highestIndex+1].Address.Hash()
 	if err != nil {
 		address := bitcoin.NewAddressFromRawAddress(itx.Outputs[highestIndex+1].Address,
 			w.Config.Net)
 		node.LogWarn(ctx, "Invalid deposit address : %x %s", msg.AssetCode, address.String())
 		return fmt.Errorf("Invalid deposit address : %x %s", msg.AssetCode, address.String())
 	}
-	hds[*hash] = h
+	hds[*depositHash] = depositHolding
 
 	for _, h := range hds {
 		cacheItem, err := holdings.Save(ctx, e.MasterDB, rk.Address, assetCode, h)
@@ -1059,6 +1863,15 @@ func (e *Enforcement) ConfiscationResponse(ctx context.Context, w *node.Response
 	}
 
 	node.Log(ctx, "Processed Confiscation : %x", msg.AssetCode)
+
+	if batch != nil {
+		// Save Tx to fund the next response if the batch is resumed.
+		if err := transactions.AddTx(ctx, e.MasterDB, itx); err != nil {
+			return errors.Wrap(err, "Failed to save tx")
+		}
+
+		return e.continueBatch(ctx, w, itx, rk, batch, len(msg.Quantities))
+	}
 	return nil
 }
 
@@ -1149,6 +1962,30 @@ func (e *Enforcement) ReconciliationResponse(ctx context.Context, w *node.Respon
 		e.HoldingsChannel.Add(cacheItem)
 	}
 
-	node.Log(ctx, "Processed Confiscation : %x", msg.AssetCode)
+	// Complete the audit record with the finalized balances.
+	record, err := enforcement.FetchReconciliation(ctx, e.MasterDB, rk.Address, txid)
+	if err != nil && err != enforcement.ErrReconciliationNotFound {
+		return errors.Wrap(err, "Failed to fetch reconciliation record")
+	}
+	if err == nil {
+		for _, rh := range record.Holdings {
+			hash, err := rh.Address.Hash()
+			if err != nil {
+				return errors.Wrap(err, "Invalid reconciliation record address")
+			}
+			if h, exists := hds[*hash]; exists {
+				rh.BalanceAfter = h.FinalizedBalance
+			}
+		}
+
+		record.ResponseTxId = protocol.TxIdFromBytes(itx.Hash[:])
+		record.UpdatedAt = timestamp
+		record.CompletedAt = timestamp
+		if err := enforcement.SaveReconciliation(ctx, e.MasterDB, rk.Address, record); err != nil {
+			return errors.Wrap(err, "Failed to save reconciliation record")
+		}
+	}
+
+	node.Log(ctx, "Processed Reconciliation : %x", msg.AssetCode)
 	return nil
 }
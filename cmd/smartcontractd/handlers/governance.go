@@ -8,6 +8,7 @@ import (
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
 	"github.com/tokenized/smart-contract/internal/asset"
 	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/delegation"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
@@ -18,6 +19,7 @@ import (
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/scheduler"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
 	"github.com/tokenized/smart-contract/pkg/wallet"
 
 	"github.com/tokenized/specification/dist/golang/actions"
@@ -28,10 +30,11 @@ import (
 )
 
 type Governance struct {
-	handler   protomux.Handler
-	MasterDB  *db.DB
-	Config    *node.Config
-	Scheduler *scheduler.Scheduler
+	handler         protomux.Handler
+	MasterDB        *db.DB
+	Config          *node.Config
+	Scheduler       *scheduler.Scheduler
+	HoldingsChannel *holdings.CacheChannel
 }
 
 // ProposalRequest handles an incoming proposal request and prepares a Vote response
@@ -75,7 +78,8 @@ func (g *Governance) ProposalRequest(ctx context.Context, w *node.ResponseWriter
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractExpired)
 	}
 
-	// Verify first two outputs are to contract
+	// Verify first two outputs are to contract. A third output to the contract funds applying
+	//   the proposed amendments when the contract applies them automatically.
 	if len(itx.Outputs) < 2 || !itx.Outputs[0].Address.Equal(rk.Address) ||
 		!itx.Outputs[1].Address.Equal(rk.Address) {
 		node.LogWarn(ctx, "Proposal failed to fund vote and result txs")
@@ -357,6 +361,12 @@ func (g *Governance) VoteResponse(ctx context.Context, w *node.ResponseWriter, i
 	nv.Expires = protocol.NewTimestamp(proposal.VoteCutOffTimestamp)
 	nv.Timestamp = protocol.NewTimestamp(msg.Timestamp)
 
+	// Keep the requirement so changes to it don't affect open votes.
+	nv.Requirement, err = contract.VotingRequirement(ct, proposal.VoteSystem)
+	if err != nil {
+		return errors.Wrap(err, "Failed to get voting requirement")
+	}
+
 	if len(proposal.AssetCode) > 0 {
 		as, err := asset.Retrieve(ctx, g.MasterDB, rk.Address,
 			protocol.AssetCodeFromBytes(proposal.AssetCode))
@@ -457,11 +467,6 @@ func (g *Governance) BallotCastRequest(ctx context.Context, w *node.ResponseWrit
 		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 	}
 
-	if len(msg.Vote) == 0 {
-		node.LogWarn(ctx, "Ballot did not vote any options")
-		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
-	}
-
 	// Validate all chosen options are valid.
 	for _, choice := range msg.Vote {
 		found := false
@@ -477,64 +482,47 @@ func (g *Governance) BallotCastRequest(ctx context.Context, w *node.ResponseWrit
 		}
 	}
 
-	// TODO Handle transfers during vote time to ensure they don't vote the same tokens more than once.
+	address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
+		w.Config.Net)
+	previous := vote.FindBallot(vt, itx.Inputs[0].Address)
 
 	quantity := uint64(0)
-
-	// Add applicable holdings
-	if proposal.Type == 2 { // Administrative Token holders only
-		if ct.AdminMemberAsset.IsZero() {
-			node.LogWarn(ctx, "Admin Member Asset not defined : %s", proposal.String())
-			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsAssetNotFound)
-		}
-
-		as, err := asset.Retrieve(ctx, g.MasterDB, rk.Address,
-			protocol.AssetCodeFromBytes(proposal.AssetCode))
-		if err != nil {
-			node.LogWarn(ctx, "Admin Member Asset not found : %s", proposal.String())
-			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsAssetNotFound)
+	var shares []*state.BallotShare
+	if len(msg.Vote) == 0 {
+		// A ballot without options revokes the sender's current ballot.
+		if previous == nil {
+			node.LogWarn(ctx, "No ballot to revoke : %s", address.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
 		}
-
-		h, err := holdings.GetHolding(ctx, g.MasterDB, rk.Address, &ct.AdminMemberAsset,
-			itx.Inputs[0].Address, v.Now)
+	} else {
+		// A recast replaces the sender's current ballot, so the balances it carries are available
+		//   again.
+		// TODO Check issue where two ballots are sent simultaneously and the second received before the first response is processed.
+		shares, err = g.ballotShares(ctx, ct, vote.WithoutBallot(vt, itx.Inputs[0].Address),
+			proposal, itx.Inputs[0].Address, v.Now)
 		if err != nil {
-			return errors.Wrap(err, "Failed to get requestor admin member holding")
+			node.LogWarn(ctx, "Failed to get ballot balances : %s", err)
+			code, ok := node.ErrorCode(err)
+			if ok {
+				return node.RespondReject(ctx, w, itx, rk, code)
+			}
+			return errors.Wrap(err, "Failed to get ballot balances")
 		}
 
-		quantity = holdings.VotingBalance(as, h,
-			ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, v.Now)
-	} else if len(proposal.AssetCode) > 0 && !vt.ContractWideVote {
-		as, err := asset.Retrieve(ctx, g.MasterDB, rk.Address,
-			protocol.AssetCodeFromBytes(proposal.AssetCode))
-		if err != nil {
-			node.LogWarn(ctx, "Asset not found : %s", proposal.String())
-			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsAssetNotFound)
+		for _, share := range shares {
+			quantity += share.Quantity
 		}
 
-		h, err := holdings.GetHolding(ctx, g.MasterDB, rk.Address,
-			protocol.AssetCodeFromBytes(proposal.AssetCode), itx.Inputs[0].Address, v.Now)
-		if err != nil {
-			return errors.Wrap(err, "Failed to get requestor holding")
+		if quantity == 0 {
+			if vote.BalanceCounted(vt, itx.Inputs[0].Address) {
+				node.LogWarn(ctx, "Ballot sender's balance already counted by proxy : %s",
+					address.String())
+				return node.RespondReject(ctx, w, itx, rk, actions.RejectionsBallotAlreadyCounted)
+			}
+			node.LogWarn(ctx, "Ballot sender doesn't hold any voting tokens : %s",
+				address.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInsufficientQuantity)
 		}
-
-		quantity = holdings.VotingBalance(as, h,
-			ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, v.Now)
-	} else {
-		quantity = contract.GetVotingBalance(ctx, g.MasterDB, ct, itx.Inputs[0].Address,
-			ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, v.Now)
-	}
-
-	if quantity == 0 {
-		address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
-			w.Config.Net)
-		node.LogWarn(ctx, "Ballot sender doesn't hold any voting tokens : %s", address.String())
-		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInsufficientQuantity)
-	}
-
-	// TODO Check issue where two ballots are sent simultaneously and the second received before the first response is processed.
-	if err := vote.CheckBallot(ctx, vt, itx.Inputs[0].Address); err != nil {
-		node.LogWarn(ctx, "Failed to check ballot : %s", err)
-		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsBallotAlreadyCounted)
 	}
 
 	// Build Response
@@ -557,10 +545,31 @@ func (g *Governance) BallotCastRequest(ctx context.Context, w *node.ResponseWrit
 		return errors.Wrap(err, "Failed to add tx")
 	}
 
+	// Save the balances found now for the response. They can change before it is processed.
+	if len(msg.Vote) > 0 {
+		uv := vote.UpdateVote{
+			PendingBallot: &state.Ballot{
+				Address:  itx.Inputs[0].Address,
+				Vote:     msg.Vote,
+				Quantity: quantity,
+				TxId:     protocol.TxIdFromBytes(itx.Hash[:]),
+				Shares:   shares,
+			},
+		}
+		if err := vote.Update(ctx, g.MasterDB, rk.Address, voteTxId, &uv, v.Now); err != nil {
+			return errors.Wrap(err, "Failed to save pending ballot")
+		}
+	}
+
 	// Respond with a vote
-	address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
-		w.Config.Net)
-	node.LogWarn(ctx, "Accepting ballot for %d from %s", quantity, address.String())
+	switch {
+	case len(msg.Vote) == 0:
+		node.LogWarn(ctx, "Accepting ballot revocation from %s", address.String())
+	case previous != nil:
+		node.LogWarn(ctx, "Accepting recast ballot for %d from %s", quantity, address.String())
+	default:
+		node.LogWarn(ctx, "Accepting ballot for %d from %s", quantity, address.String())
+	}
 	return node.RespondSuccess(ctx, w, itx, rk, &ballotCounted)
 }
 
@@ -612,21 +621,260 @@ func (g *Governance) BallotCountedResponse(ctx context.Context, w *node.Response
 		return errors.Wrap(err, "Failed to retrieve vote for ballot cast")
 	}
 
+	timestamp := protocol.NewTimestamp(msg.Timestamp)
 	ballot := state.Ballot{
 		Address:   castTx.Inputs[0].Address,
 		Vote:      cast.Vote,
-		Timestamp: protocol.NewTimestamp(msg.Timestamp),
+		Timestamp: timestamp,
 		Quantity:  msg.Quantity,
+		TxId:      protocol.TxIdFromBytes(castTx.Hash[:]),
+	}
+
+	hash, err := bitcoin.NewHash32(vt.ProposalTxId.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Failed to convert proposal tx id")
+	}
+	proposalTx, err := transactions.GetTx(ctx, g.MasterDB, hash, g.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve proposal for ballot counted")
+	}
+
+	proposal, ok := proposalTx.MsgProto.(*actions.Proposal)
+	if !ok {
+		return fmt.Errorf("Proposal invalid for ballot counted")
+	}
+
+	// A ballot without options is a revocation and doesn't carry any balances.
+	if len(cast.Vote) > 0 {
+		// Record whose balances the ballot carries. They were found when the ballot was accepted,
+		//   since balances and delegations can change before the response is processed.
+		if pending := vote.FindPendingBallot(vt, ballot.TxId); pending != nil {
+			ballot.Shares = pending.Shares
+		} else {
+			// Ballots accepted before pending ballots were saved. A recast releases the balances
+			//   of the ballot it replaces.
+			ballot.Shares, err = g.ballotShares(ctx, ct, vote.WithoutBallot(vt, ballot.Address),
+				proposal, ballot.Address, timestamp)
+			if err != nil {
+				return errors.Wrap(err, "Failed to get ballot balances")
+			}
+		}
+
+		sharesQuantity := uint64(0)
+		for _, share := range ballot.Shares {
+			sharesQuantity += share.Quantity
+		}
+		if sharesQuantity != msg.Quantity {
+			return fmt.Errorf("Ballot balances don't match counted quantity : %d != %d",
+				sharesQuantity, msg.Quantity)
+		}
 	}
 
+	previous := vote.FindBallot(vt, ballot.Address)
+
 	// Add to vote results
 	if err := vote.AddBallot(ctx, g.MasterDB, rk.Address, vt, &ballot, v.Now); err != nil {
 		return errors.Wrap(err, "Failed to add ballot")
 	}
 
+	// Release the balances of the replaced ballot before locking the new ballot's, since they
+	//   can overlap.
+	if previous != nil {
+		if err := g.unlockBallot(ctx, ct, vt, previous, v.Now); err != nil {
+			return errors.Wrap(err, "Failed to unlock replaced ballot")
+		}
+	}
+
+	if g.Config.VoteBalanceLock && len(ballot.Vote) > 0 {
+		if err := g.lockBallot(ctx, ct, vt, proposal, &ballot, v.Now); err != nil {
+			return errors.Wrap(err, "Failed to lock ballot")
+		}
+	}
+
+	return nil
+}
+
+// lockBallot locks the balances counted in the ballot until the vote closes, so they can't be
+//   transferred and voted again from another address.
+func (g *Governance) lockBallot(ctx context.Context, ct *state.Contract, vt *state.Vote,
+	proposal *actions.Proposal, ballot *state.Ballot, now protocol.Timestamp) error {
+
+	var assetCodes []*protocol.AssetCode
+	if proposal.Type == 2 { // Administrative Token holders only
+		assetCodes = append(assetCodes, &ct.AdminMemberAsset)
+	} else if len(proposal.AssetCode) > 0 && !vt.ContractWideVote {
+		assetCodes = append(assetCodes, protocol.AssetCodeFromBytes(proposal.AssetCode))
+	} else {
+		for _, assetCode := range ct.AssetCodes {
+			if !assetCode.Equal(ct.AdminMemberAsset) {
+				assetCodes = append(assetCodes, assetCode)
+			}
+		}
+	}
+
+	for _, assetCode := range assetCodes {
+		as, err := asset.Retrieve(ctx, g.MasterDB, ct.Address, assetCode)
+		if err != nil {
+			return errors.Wrap(err, "retrieve asset")
+		}
+		if !as.VotingRights {
+			continue
+		}
+
+		for _, holder := range ballotHolders(ballot) {
+			h, err := holdings.Fetch(ctx, g.MasterDB, ct.Address, assetCode, holder)
+			if err == holdings.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return errors.Wrap(err, "fetch holding")
+			}
+			if h.FinalizedBalance == 0 {
+				continue
+			}
+
+			if err := holdings.AddVoteLock(h, vt.VoteTxId, h.FinalizedBalance, vt.Expires,
+				now); err != nil {
+				return errors.Wrap(err, "add vote lock")
+			}
+
+			cacheItem, err := holdings.Save(ctx, g.MasterDB, ct.Address, assetCode, h)
+			if err != nil {
+				return errors.Wrap(err, "save holding")
+			}
+			g.HoldingsChannel.Add(cacheItem)
+		}
+	}
+
+	return nil
+}
+
+// unlockBallot releases the balances the ballot locked in the vote.
+func (g *Governance) unlockBallot(ctx context.Context, ct *state.Contract, vt *state.Vote,
+	ballot *state.Ballot, now protocol.Timestamp) error {
+
+	for _, assetCode := range ct.AssetCodes {
+		for _, holder := range ballotHolders(ballot) {
+			h, err := holdings.Fetch(ctx, g.MasterDB, ct.Address, assetCode, holder)
+			if err == holdings.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return errors.Wrap(err, "fetch holding")
+			}
+
+			if !holdings.RemoveVoteLock(h, vt.VoteTxId, now) {
+				continue
+			}
+
+			cacheItem, err := holdings.Save(ctx, g.MasterDB, ct.Address, assetCode, h)
+			if err != nil {
+				return errors.Wrap(err, "save holding")
+			}
+			g.HoldingsChannel.Add(cacheItem)
+		}
+	}
+
 	return nil
 }
 
+// ballotHolders returns the addresses whose balances the ballot carries.
+func ballotHolders(ballot *state.Ballot) []bitcoin.RawAddress {
+	if len(ballot.Shares) == 0 {
+		return []bitcoin.RawAddress{ballot.Address}
+	}
+
+	result := make([]bitcoin.RawAddress, 0, len(ballot.Shares))
+	for _, share := range ballot.Shares {
+		result = append(result, share.Address)
+	}
+	return result
+}
+
+// ballotShares returns the balances a ballot from the address carries in the vote. That is the
+//   address's own balance and the balances of holders that delegated to it. Balances already
+//   counted in another ballot are skipped, so whichever of a holder and its proxy votes first
+//   carries the holder's balance. Delegation is not transitive.
+func (g *Governance) ballotShares(ctx context.Context, ct *state.Contract, vt *state.Vote,
+	proposal *actions.Proposal, address bitcoin.RawAddress,
+	now protocol.Timestamp) ([]*state.BallotShare, error) {
+
+	delegators, err := delegation.Delegators(ctx, g.MasterDB, ct.Address, address, vt.VoteTxId)
+	if err != nil {
+		return nil, errors.Wrap(err, "delegators")
+	}
+
+	var result []*state.BallotShare
+	for _, holder := range append([]bitcoin.RawAddress{address}, delegators...) {
+		if vote.BalanceCounted(vt, holder) {
+			continue
+		}
+
+		quantity, err := g.votingBalance(ctx, ct, vt, proposal, holder, now)
+		if err != nil {
+			return nil, err
+		}
+		if quantity == 0 {
+			continue
+		}
+
+		result = append(result, &state.BallotShare{
+			Address:  holder,
+			Quantity: quantity,
+		})
+	}
+
+	return result, nil
+}
+
+// votingBalance returns the balance the holder can vote in the proposal.
+func (g *Governance) votingBalance(ctx context.Context, ct *state.Contract, vt *state.Vote,
+	proposal *actions.Proposal, holder bitcoin.RawAddress, now protocol.Timestamp) (uint64, error) {
+
+	if proposal.Type == 2 { // Administrative Token holders only
+		if ct.AdminMemberAsset.IsZero() {
+			return 0, node.NewError(actions.RejectionsAssetNotFound,
+				"Admin Member Asset not defined")
+		}
+
+		as, err := asset.Retrieve(ctx, g.MasterDB, ct.Address,
+			protocol.AssetCodeFromBytes(proposal.AssetCode))
+		if err != nil {
+			return 0, node.NewError(actions.RejectionsAssetNotFound,
+				"Admin Member Asset not found")
+		}
+
+		h, err := holdings.GetHolding(ctx, g.MasterDB, ct.Address, &ct.AdminMemberAsset, holder,
+			now)
+		if err != nil {
+			return 0, errors.Wrap(err, "Failed to get admin member holding")
+		}
+
+		return holdings.VotingBalance(as, h,
+			ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, now), nil
+	}
+
+	if len(proposal.AssetCode) > 0 && !vt.ContractWideVote {
+		as, err := asset.Retrieve(ctx, g.MasterDB, ct.Address,
+			protocol.AssetCodeFromBytes(proposal.AssetCode))
+		if err != nil {
+			return 0, node.NewError(actions.RejectionsAssetNotFound, "Asset not found")
+		}
+
+		h, err := holdings.GetHolding(ctx, g.MasterDB, ct.Address,
+			protocol.AssetCodeFromBytes(proposal.AssetCode), holder, now)
+		if err != nil {
+			return 0, errors.Wrap(err, "Failed to get holding")
+		}
+
+		return holdings.VotingBalance(as, h,
+			ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, now), nil
+	}
+
+	return contract.GetVotingBalance(ctx, g.MasterDB, ct, holder,
+		ct.VotingSystems[proposal.VoteSystem].VoteMultiplierPermitted, now), nil
+}
+
 // FinalizeVote is called when a vote expires and sends the result response.
 func (g *Governance) FinalizeVote(ctx context.Context, w *node.ResponseWriter, itx *inspector.Transaction, rk *wallet.Key) error {
 	ctx, span := trace.StartSpan(ctx, "handlers.Governance.FinalizeVote")
@@ -641,25 +889,25 @@ func (g *Governance) FinalizeVote(ctx context.Context, w *node.ResponseWriter, i
 
 	node.LogVerbose(ctx, "Finalizing vote : %s", itx.Hash.String())
 
-	// Retrieve contract
-	ct, err := contract.Retrieve(ctx, g.MasterDB, rk.Address)
+	// Retrieve contract. A vote that was open when the contract moved is finalized with the state
+	//   at the new address, but is still funded by this address.
+	ct, err := contract.RetrieveCurrent(ctx, g.MasterDB, rk.Address)
 	if err != nil {
 		return err
 	}
 
-	if !ct.MovedTo.IsEmpty() {
-		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo,
-			w.Config.Net)
-		return fmt.Errorf("Contract address changed : %s", address.String())
-	}
-
 	// Retrieve vote
 	voteTxId := protocol.TxIdFromBytes(itx.Hash[:])
-	vt, err := vote.Retrieve(ctx, g.MasterDB, rk.Address, voteTxId)
+	vt, err := vote.Retrieve(ctx, g.MasterDB, ct.Address, voteTxId)
 	if err != nil {
 		return errors.Wrap(err, "Failed to retrieve vote for ballot cast")
 	}
 
+	if vt.CompletedAt.Nano() != 0 {
+		node.LogVerbose(ctx, "Vote already finalized : %s", itx.Hash.String())
+		return nil
+	}
+
 	// Get Proposal
 	hash, err := bitcoin.NewHash32(vt.ProposalTxId.Bytes())
 	proposalTx, err := transactions.GetTx(ctx, g.MasterDB, hash, g.Config.IsTest)
@@ -695,7 +943,7 @@ func (g *Governance) FinalizeVote(ctx context.Context, w *node.ResponseWriter, i
 	// Build outputs
 	// 1 - Contract Address
 	// 2 - Contract Fee (change)
-	w.AddOutput(ctx, rk.Address, 0)
+	w.AddOutput(ctx, ct.Address, 0)
 	w.AddContractFee(ctx, ct.ContractFee)
 
 	// Save Tx for response.
@@ -724,9 +972,13 @@ func (g *Governance) ResultResponse(ctx context.Context, w *node.ResponseWriter,
 	}
 
 	if !itx.Inputs[0].Address.Equal(rk.Address) {
-		address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
-			w.Config.Net)
-		return fmt.Errorf("Vote result not from contract : %x", address.String())
+		// Results of votes that were open when the contract moved are from the old address.
+		from, err := contract.RetrieveCurrent(ctx, g.MasterDB, itx.Inputs[0].Address)
+		if err != nil || !from.Address.Equal(rk.Address) {
+			address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address,
+				w.Config.Net)
+			return fmt.Errorf("Vote result not from contract : %s", address.String())
+		}
 	}
 
 	ct, err := contract.Retrieve(ctx, g.MasterDB, rk.Address)
@@ -735,9 +987,11 @@ func (g *Governance) ResultResponse(ctx context.Context, w *node.ResponseWriter,
 	}
 
 	if !ct.MovedTo.IsEmpty() {
+		// The result is recorded by the contract at the new address.
 		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo,
 			w.Config.Net)
-		return fmt.Errorf("Contract address changed : %s", address.String())
+		node.LogVerbose(ctx, "Contract address changed : %s", address.String())
+		return nil
 	}
 
 	uv := vote.UpdateVote{}
@@ -754,6 +1008,17 @@ func (g *Governance) ResultResponse(ctx context.Context, w *node.ResponseWriter,
 		return errors.Wrap(err, "Failed to update vote")
 	}
 
+	// Release balances locked by the vote's ballots.
+	vt, err := vote.Retrieve(ctx, g.MasterDB, rk.Address, voteTxId)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve vote")
+	}
+	for _, ballot := range vt.Ballots {
+		if err := g.unlockBallot(ctx, ct, vt, ballot, v.Now); err != nil {
+			return errors.Wrap(err, "Failed to unlock ballot")
+		}
+	}
+
 	if len(msg.AssetCode) > 0 {
 		// Save result for amendment action
 		if err := transactions.AddTx(ctx, g.MasterDB, itx); err != nil {
@@ -761,5 +1026,152 @@ func (g *Governance) ResultResponse(ctx context.Context, w *node.ResponseWriter,
 		}
 	}
 
+	if msg.Result == "A" && len(msg.ProposedAmendments) > 0 {
+		autoApply, err := contract.AutoApplyAmendments(ct.SupportingDocs)
+		if err != nil {
+			return errors.Wrap(err, "Failed to check auto apply amendments")
+		}
+
+		if autoApply {
+			if err := g.applyVoteAmendments(ctx, w, ct, voteTxId, rk); err != nil {
+				return errors.Wrap(err, "Failed to apply vote amendments")
+			}
+		}
+	}
+
 	return nil
 }
+
+// applyVoteAmendments posts the contract formation or asset creation containing the amendments of
+//   a passed proposal. It is funded by the third output of the proposal. The vote is marked as
+//   applied when the response is processed.
+func (g *Governance) applyVoteAmendments(ctx context.Context, w *node.ResponseWriter,
+	ct *state.Contract, voteTxId *protocol.TxId, rk *wallet.Key) error {
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
+	vt, err := vote.Retrieve(ctx, g.MasterDB, rk.Address, voteTxId)
+	if err != nil {
+		return errors.Wrap(err, "retrieve vote")
+	}
+
+	if !vt.AppliedTxId.IsZero() {
+		node.LogVerbose(ctx, "Vote already applied : %s", vt.AppliedTxId.String())
+		return nil
+	}
+
+	hash, err := bitcoin.NewHash32(vt.ProposalTxId.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "proposal tx hash")
+	}
+	proposalTx, err := transactions.GetTx(ctx, g.MasterDB, hash, g.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "get proposal tx")
+	}
+
+	if len(proposalTx.Outputs) < 3 || !proposalTx.Outputs[2].Address.Equal(rk.Address) {
+		node.LogWarn(ctx, "Proposal did not fund amendments : %s", vt.ProposalTxId.String())
+		return nil
+	}
+
+	var payload actions.Action
+	if vt.AssetCode.IsZero() {
+		if ct.AdminOracle != nil {
+			// Contract amendments need a new admin oracle signature, which only the administration
+			//   can provide.
+			node.LogWarn(ctx, "Admin oracle signature required to apply contract amendments")
+			return nil
+		}
+
+		cf := actions.ContractFormation{}
+		if err := node.Convert(ctx, ct, &cf); err != nil {
+			return errors.Wrap(err, "convert contract to formation")
+		}
+
+		cf.ContractRevision = ct.Revision + 1
+		cf.Timestamp = v.Now.Nano()
+
+		if err := applyContractAmendments(&cf, vt.ProposedAmendments, true, vt.Type,
+			vt.VoteSystem); err != nil {
+			node.LogWarn(ctx, "Failed to apply contract amendments : %s", err)
+			return nil
+		}
+
+		payload = &cf
+	} else {
+		as, err := asset.Retrieve(ctx, g.MasterDB, rk.Address, vt.AssetCode)
+		if err != nil {
+			return errors.Wrap(err, "retrieve asset")
+		}
+
+		ac := actions.AssetCreation{}
+		if err := node.Convert(ctx, as, &ac); err != nil {
+			return errors.Wrap(err, "convert asset to creation")
+		}
+
+		ac.AssetRevision = as.Revision + 1
+		ac.Timestamp = v.Now.Nano()
+		ac.AssetCode = vt.AssetCode.Bytes() // Asset code not in state data
+
+		if err := applyAssetAmendments(&ac, ct.VotingSystems, vt.ProposedAmendments, true,
+			vt.Type, vt.VoteSystem); err != nil {
+			node.LogWarn(ctx, "Failed to apply asset amendments : %s", err)
+			return nil
+		}
+
+		if ac.TokenQty < as.TokenQty {
+			// Administration has to hold any tokens being "burned".
+			h, err := holdings.GetHolding(ctx, g.MasterDB, rk.Address, vt.AssetCode,
+				ct.AdministrationAddress, v.Now)
+			if err != nil {
+				return errors.Wrap(err, "get admin holding")
+			}
+
+			if h.PendingBalance < as.TokenQty-ac.TokenQty {
+				node.LogWarn(ctx, "Administration holds too few tokens to apply asset amendments")
+				return nil
+			}
+		}
+
+		payload = &ac
+	}
+
+	// Build outputs
+	// 1 - Contract Address
+	// 2 - Contract Fee (change)
+	tx := txbuilder.NewTxBuilder(g.Config.DustLimit, g.Config.FeeRate)
+	tx.SetChangeAddress(g.Config.FeeAddress, "")
+
+	if err := tx.AddInputUTXO(proposalTx.Outputs[2].UTXO); err != nil {
+		return errors.Wrap(err, "add input")
+	}
+
+	if err := tx.AddDustOutput(rk.Address, false); err != nil {
+		return errors.Wrap(err, "add contract output")
+	}
+
+	if ct.ContractFee > 0 {
+		if err := tx.AddPaymentOutput(g.Config.FeeAddress, ct.ContractFee, true); err != nil {
+			return errors.Wrap(err, "add fee output")
+		}
+	}
+
+	script, err := protocol.Serialize(payload, g.Config.IsTest)
+	if err != nil {
+		return errors.Wrap(err, "serialize amendments")
+	}
+	if err := tx.AddOutput(script, 0, false, false); err != nil {
+		return errors.Wrap(err, "add payload output")
+	}
+
+	if err := tx.Sign([]bitcoin.Key{rk.Key}); err != nil {
+		if txbuilder.IsErrorCode(err, txbuilder.ErrorCodeInsufficientValue) {
+			node.LogWarn(ctx, "Proposal funding too low to apply amendments : %s", err)
+			return nil
+		}
+		return errors.Wrap(err, "sign")
+	}
+
+	node.Log(ctx, "Applying amendments of vote : %s", vt.VoteTxId.String())
+	return node.Respond(ctx, w, tx.MsgTx)
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/filters"
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
 	"github.com/tokenized/smart-contract/internal/asset"
+	"github.com/tokenized/smart-contract/internal/authority"
 	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/delegation"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
@@ -16,6 +18,7 @@ import (
 	"github.com/tokenized/smart-contract/internal/transactions"
 	"github.com/tokenized/smart-contract/internal/transfer"
 	"github.com/tokenized/smart-contract/internal/utxos"
+	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/scheduler"
@@ -80,6 +83,16 @@ func (m *Message) ProcessMessage(ctx context.Context, w *node.ResponseWriter,
 		return nil // Message not addressed to contract.
 	}
 
+	if msg.MessageCode == delegation.MessageCode {
+		node.LogVerbose(ctx, "Processing Delegation")
+		return m.processDelegation(ctx, w, itx, msg, rk)
+	}
+
+	if msg.MessageCode == authority.MessageCode {
+		node.LogVerbose(ctx, "Processing Authority Registry")
+		return m.processAuthority(ctx, w, itx, msg, rk)
+	}
+
 	messagePayload, err := messages.Deserialize(msg.MessageCode, msg.MessagePayload)
 	if err != nil {
 		return errors.Wrap(err, "Failed to deserialize message payload")
@@ -102,6 +115,184 @@ func (m *Message) ProcessMessage(ctx context.Context, w *node.ResponseWriter,
 	}
 }
 
+// processDelegation sets or revokes the delegation of the sender's voting power to a proxy.
+func (m *Message) processDelegation(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, msg *actions.Message, rk *wallet.Key) error {
+	ctx, span := trace.StartSpan(ctx, "handlers.Message.processDelegation")
+	defer span.End()
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
+	ct, err := contract.Retrieve(ctx, m.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
+	if !ct.MovedTo.IsEmpty() {
+		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo, w.Config.Net)
+		node.LogWarn(ctx, "Contract address changed : %s", address.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractMoved)
+	}
+
+	payload, err := delegation.Deserialize(msg.MessagePayload)
+	if err != nil {
+		node.LogWarn(ctx, "Delegation payload is invalid : %s", err)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+	}
+
+	delegator := itx.Inputs[0].Address
+	delegatorAddress := bitcoin.NewAddressFromRawAddress(delegator, w.Config.Net)
+
+	if payload.Proxy.Equal(delegator) {
+		node.LogWarn(ctx, "Delegation to self : %s", delegatorAddress.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+	}
+
+	if payload.VoteTxId != nil {
+		vt, err := vote.Retrieve(ctx, m.MasterDB, rk.Address, payload.VoteTxId)
+		if err == vote.ErrNotFound {
+			node.LogWarn(ctx, "Vote not found : %s", payload.VoteTxId.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsVoteNotFound)
+		} else if err != nil {
+			return errors.Wrap(err, "Failed to retrieve vote")
+		}
+
+		if vt.CompletedAt.Nano() != 0 || vt.Expires.Nano() <= v.Now.Nano() {
+			node.LogWarn(ctx, "Vote closed : %s", payload.VoteTxId.String())
+			return node.RespondReject(ctx, w, itx, rk, actions.RejectionsVoteClosed)
+		}
+	}
+
+	if payload.Proxy.IsEmpty() {
+		err := delegation.Remove(ctx, m.MasterDB, rk.Address, delegator, payload.VoteTxId)
+		if err == delegation.ErrNotFound {
+			node.LogWarn(ctx, "No delegation to revoke : %s", delegatorAddress.String())
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "Failed to remove delegation")
+		}
+
+		node.Log(ctx, "Revoked delegation of %s", delegatorAddress.String())
+		return nil
+	}
+
+	if !contract.HasAnyBalance(ctx, m.MasterDB, ct, delegator) {
+		node.LogWarn(ctx, "Delegator holds no assets : %s", delegatorAddress.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsInsufficientQuantity)
+	}
+
+	d := state.Delegation{
+		Delegator: delegator,
+		Proxy:     payload.Proxy,
+		VoteTxId:  payload.VoteTxId,
+		TxId:      protocol.TxIdFromBytes(itx.Hash[:]),
+		Timestamp: protocol.NewTimestamp(payload.Timestamp),
+		CreatedAt: v.Now,
+		UpdatedAt: v.Now,
+	}
+
+	existing, err := delegation.Fetch(ctx, m.MasterDB, rk.Address, delegator, payload.VoteTxId)
+	if err == nil {
+		d.CreatedAt = existing.CreatedAt
+	} else if err != delegation.ErrNotFound {
+		return errors.Wrap(err, "Failed to fetch delegation")
+	}
+
+	if err := delegation.Save(ctx, m.MasterDB, rk.Address, &d); err != nil {
+		return errors.Wrap(err, "Failed to save delegation")
+	}
+
+	proxyAddress := bitcoin.NewAddressFromRawAddress(payload.Proxy, w.Config.Net)
+	node.Log(ctx, "Delegated voting of %s to %s", delegatorAddress.String(),
+		proxyAddress.String())
+	return nil
+}
+
+// processAuthority applies a change to the contract's enforcement authority registry. Only the
+//   contract administration can change the registry.
+func (m *Message) processAuthority(ctx context.Context, w *node.ResponseWriter,
+	itx *inspector.Transaction, msg *actions.Message, rk *wallet.Key) error {
+	ctx, span := trace.StartSpan(ctx, "handlers.Message.processAuthority")
+	defer span.End()
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
+	ct, err := contract.Retrieve(ctx, m.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
+	if !ct.MovedTo.IsEmpty() {
+		address := bitcoin.NewAddressFromRawAddress(ct.MovedTo, w.Config.Net)
+		node.LogWarn(ctx, "Contract address changed : %s", address.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsContractMoved)
+	}
+
+	if !ct.AdministrationAddress.Equal(itx.Inputs[0].Address) {
+		address := bitcoin.NewAddressFromRawAddress(itx.Inputs[0].Address, w.Config.Net)
+		node.LogWarn(ctx, "Authority registry change not from administration : %s",
+			address.String())
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsNotAdministration)
+	}
+
+	payload, err := authority.Deserialize(msg.MessagePayload)
+	if err != nil {
+		node.LogWarn(ctx, "Authority payload is invalid : %s", err)
+		return node.RespondReject(ctx, w, itx, rk, actions.RejectionsMsgMalformed)
+	}
+
+	switch payload.Action {
+	case authority.ActionRequireConfiscationSignature, authority.ActionAllowUnsignedConfiscation:
+		required := payload.Action == authority.ActionRequireConfiscationSignature
+		uc := contract.UpdateContract{ConfiscationAuthorityRequired: &required}
+		if err := contract.Update(ctx, m.MasterDB, rk.Address, &uc, v.Now); err != nil {
+			return errors.Wrap(err, "Failed to update contract")
+		}
+
+		node.Log(ctx, "Set confiscation authority signature required : %t", required)
+		return nil
+	}
+
+	a, err := authority.Fetch(ctx, m.MasterDB, rk.Address, payload.PublicKey)
+	if err == authority.ErrNotFound {
+		if payload.Action == authority.ActionRevoke {
+			node.LogWarn(ctx, "No authority to revoke : %s", payload.PublicKey.String())
+			return nil
+		}
+
+		a = &state.EnforcementAuthority{
+			PublicKey: payload.PublicKey,
+			CreatedAt: v.Now,
+		}
+	} else if err != nil {
+		return errors.Wrap(err, "Failed to fetch authority")
+	}
+
+	a.TxId = protocol.TxIdFromBytes(itx.Hash[:])
+	a.UpdatedAt = v.Now
+
+	if payload.Action == authority.ActionRevoke {
+		a.RevokedAt = v.Now
+	} else {
+		a.Name = payload.Name
+		a.ValidFrom = protocol.NewTimestamp(payload.ValidFrom)
+		a.ValidUntil = protocol.NewTimestamp(payload.ValidUntil)
+		a.RevokedAt = protocol.Timestamp{}
+	}
+
+	if err := authority.Save(ctx, m.MasterDB, rk.Address, a); err != nil {
+		return errors.Wrap(err, "Failed to save authority")
+	}
+
+	if payload.Action == authority.ActionRevoke {
+		node.Log(ctx, "Revoked enforcement authority %s : %s", a.Name, a.PublicKey.String())
+	} else {
+		node.Log(ctx, "Registered enforcement authority %s : %s", a.Name, a.PublicKey.String())
+	}
+	return nil
+}
+
 // ProcessRejection handles an incoming Rejection OP_RETURN.
 func (m *Message) ProcessRejection(ctx context.Context, w *node.ResponseWriter,
 	itx *inspector.Transaction, rk *wallet.Key) error {
@@ -34,6 +34,7 @@ func API(
 		MasterDB: masterDB,
 		Config:   config,
 		Headers:  headers,
+		UTXOs:    utxos,
 	}
 
 	app.Handle("SEE", actions.CodeContractOffer, c.OfferRequest)
@@ -69,8 +70,10 @@ func API(
 
 	// Register enforcement based events.
 	e := Enforcement{
+		handler:         app,
 		MasterDB:        masterDB,
 		Config:          config,
+		Scheduler:       sch,
 		HoldingsChannel: holdingsChannel,
 	}
 
@@ -79,13 +82,16 @@ func API(
 	app.Handle("SEE", actions.CodeThaw, e.ThawResponse)
 	app.Handle("SEE", actions.CodeConfiscation, e.ConfiscationResponse)
 	app.Handle("SEE", actions.CodeReconciliation, e.ReconciliationResponse)
+	app.Handle("END", actions.CodeFreeze, e.FreezeExpired)
+	app.Handle("END", actions.CodeOrder, e.ResumeBatch)
 
 	// Register enforcement based events.
 	g := Governance{
-		handler:   app,
-		MasterDB:  masterDB,
-		Config:    config,
-		Scheduler: sch,
+		handler:         app,
+		MasterDB:        masterDB,
+		Config:          config,
+		Scheduler:       sch,
+		HoldingsChannel: holdingsChannel,
 	}
 
 	app.Handle("SEE", actions.CodeProposal, g.ProposalRequest)
@@ -290,16 +290,23 @@ func (t *Transfer) TransferTimeout(ctx context.Context, w *node.ResponseWriter,
 		return errors.New("Could not assert as *actions.Transfer")
 	}
 
+	// A transfer that was pending when the contract moved is rejected with the state at the new
+	//   address, but is still funded by this address.
+	ct, err := contract.RetrieveCurrent(ctx, t.MasterDB, rk.Address)
+	if err != nil {
+		return errors.Wrap(err, "Failed to retrieve contract")
+	}
+
 	// Remove pending transfer
-	if err := transfer.Remove(ctx, t.MasterDB, rk.Address, protocol.TxIdFromBytes(itx.Hash[:])); err != nil {
+	if err := transfer.Remove(ctx, t.MasterDB, ct.Address, protocol.TxIdFromBytes(itx.Hash[:])); err != nil {
 		if err != transfer.ErrNotFound {
 			return errors.Wrap(err, "Failed to remove pending transfer")
 		}
 	}
 
 	node.LogWarn(ctx, "Transfer timed out")
-	return respondTransferReject(ctx, t.MasterDB, t.HoldingsChannel, t.Config, w, itx, msg, rk,
-		actions.RejectionsTimeout, true, "")
+	return respondTransferRejectAt(ctx, t.MasterDB, t.HoldingsChannel, t.Config, w, itx, msg,
+		ct.Address, rk, actions.RejectionsTimeout, true, "")
 }
 
 // firstContractOutputIndex finds the "first" contract. The "first" contract of a transfer is the one
@@ -727,7 +734,8 @@ func addSettlementData(ctx context.Context, masterDB *db.DB, config *node.Config
 
 			address := bitcoin.NewAddressFromRawAddress(transferTx.Inputs[sender.Index].Address,
 				config.Net)
-			if err := holdings.AddDebit(h, txid, sender.Quantity, isSingleContract, v.Now); err != nil {
+			if err := holdings.AddTransferDebit(h, txid, sender.Quantity, isSingleContract,
+				v.Now); err != nil {
 				if err == holdings.ErrInsufficientHoldings {
 					node.LogWarn(ctx, "Insufficient funds: asset=%x party=%s : %d/%d",
 						assetTransfer.AssetCode, address.String(), sender.Quantity,
@@ -744,6 +752,12 @@ func addSettlementData(ctx context.Context, masterDB *db.DB, config *node.Config
 						assetTransfer.AssetCode, address.String())
 					return node.NewError(actions.RejectionsHoldingsLocked, "")
 				}
+				if err == holdings.ErrHoldingsVoteLocked {
+					node.LogWarn(ctx, "Vote locked funds: asset=%x party=%s : %d/%d",
+						assetTransfer.AssetCode, address.String(), sender.Quantity,
+						holdings.SafeBalance(h)-holdings.VoteLockedBalance(h, v.Now))
+					return node.NewError(actions.RejectionsHoldingsLocked, "")
+				}
 				node.LogWarn(ctx, "Send failed : %s : asset=%x party=%s",
 					err, assetTransfer.AssetCode, address.String())
 				return node.NewError(actions.RejectionsMsgMalformed, "")
@@ -1175,6 +1189,18 @@ func respondTransferReject(ctx context.Context, masterDB *db.DB,
 	holdingsChannel *holdings.CacheChannel, config *node.Config,
 	w *node.ResponseWriter, transferTx *inspector.Transaction, transfer *actions.Transfer,
 	rk *wallet.Key, code uint32, started bool, text string) error {
+	return respondTransferRejectAt(ctx, masterDB, holdingsChannel, config, w, transferTx,
+		transfer, rk.Address, rk, code, started, text)
+}
+
+// respondTransferRejectAt is respondTransferReject for a contract whose state is at
+//   contractAddress. It differs from rk's address when the contract moved while the transfer was
+//   pending.
+func respondTransferRejectAt(ctx context.Context, masterDB *db.DB,
+	holdingsChannel *holdings.CacheChannel, config *node.Config,
+	w *node.ResponseWriter, transferTx *inspector.Transaction, transfer *actions.Transfer,
+	contractAddress bitcoin.RawAddress, rk *wallet.Key, code uint32, started bool,
+	text string) error {
 
 	v := ctx.Value(node.KeyValues).(*node.Values)
 	transferTxId := protocol.TxIdFromBytes(transferTx.Hash[:])
@@ -1265,7 +1291,7 @@ func respondTransferReject(ctx context.Context, masterDB *db.DB,
 				// Revert sender pending statuses
 				for _, sender := range assetTransfer.AssetSenders {
 					// Revert holding status
-					h, err := holdings.GetHolding(ctx, masterDB, rk.Address, assetCode,
+					h, err := holdings.GetHolding(ctx, masterDB, contractAddress, assetCode,
 						transferTx.Inputs[sender.Index].Address, v.Now)
 					if err != nil {
 						return errors.Wrap(err, "get holding")
@@ -1291,7 +1317,7 @@ func respondTransferReject(ctx context.Context, masterDB *db.DB,
 						return err
 					}
 
-					h, err := holdings.GetHolding(ctx, masterDB, rk.Address, assetCode,
+					h, err := holdings.GetHolding(ctx, masterDB, contractAddress, assetCode,
 						receiverAddress, v.Now)
 					if err != nil {
 						return errors.Wrap(err, "get holding")
@@ -1314,14 +1340,14 @@ func respondTransferReject(ctx context.Context, masterDB *db.DB,
 	}
 
 	if started {
-		err = saveHoldings(ctx, masterDB, holdingsChannel, updates, rk.Address)
+		err = saveHoldings(ctx, masterDB, holdingsChannel, updates, contractAddress)
 		if err != nil {
 			return errors.Wrap(err, "save holdings")
 		}
 	}
 
 	if refundBalance > balance {
-		ct, err := contract.Retrieve(ctx, masterDB, rk.Address)
+		ct, err := contract.Retrieve(ctx, masterDB, contractAddress)
 		if err != nil {
 			return errors.Wrap(err, "Failed to retrieve contract")
 		}
@@ -0,0 +1,175 @@
+package listeners
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/internal/platform/node"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	handlerstorage "github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AlertDoubleSpend is published when conflicting txs involving a contract are seen.
+	AlertDoubleSpend = "DoubleSpend"
+
+	// AlertDoubleSpendConfirmed is published when one of the txs in a double spend confirms.
+	AlertDoubleSpendConfirmed = "DoubleSpendConfirmed"
+)
+
+// Alert is an event that operators should be notified of.
+type Alert struct {
+	Type      string           `json:"type"`
+	Message   string           `json:"message"`
+	TxIDs     []bitcoin.Hash32 `json:"txids"`
+	Confirmed *bitcoin.Hash32  `json:"confirmed,omitempty"`
+	Peers     []string         `json:"peers"`
+}
+
+// AlertFunc publishes alerts.
+type AlertFunc func(ctx context.Context, alert *Alert) error
+
+// NewWebhookAlertHandler returns an alert handler that posts alerts as JSON to a URL.
+func NewWebhookAlertHandler(url string, timeout time.Duration) AlertFunc {
+	client := &http.Client{Timeout: timeout}
+
+	return func(ctx context.Context, alert *Alert) error {
+		body, err := json.Marshal(alert)
+		if err != nil {
+			return errors.Wrap(err, "marshal alert")
+		}
+
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return errors.Wrap(err, "create request")
+		}
+		request = request.WithContext(ctx)
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := client.Do(request)
+		if err != nil {
+			return errors.Wrap(err, "post alert")
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("Alert webhook returned status %d", response.StatusCode)
+		}
+		return nil
+	}
+}
+
+// HandleDoubleSpend implements the spynode DoubleSpendListener interface.
+func (server *Server) HandleDoubleSpend(ctx context.Context,
+	ds *handlerstorage.DoubleSpend) error {
+	ctx = node.ContextWithOutLogSubSystem(ctx)
+
+	alert := &Alert{
+		Type:      AlertDoubleSpend,
+		Confirmed: ds.Confirmed,
+	}
+	if ds.Confirmed != nil {
+		alert.Type = AlertDoubleSpendConfirmed
+	}
+
+	peers := make(map[string]bool)
+	for _, dsTx := range ds.Txs {
+		alert.TxIDs = append(alert.TxIDs, dsTx.TxID)
+		for _, announcement := range dsTx.Announcements {
+			if !peers[announcement.Peer] {
+				peers[announcement.Peer] = true
+				alert.Peers = append(alert.Peers, announcement.Peer)
+			}
+		}
+	}
+
+	txids := make([]string, 0, len(alert.TxIDs))
+	for _, txid := range alert.TxIDs {
+		txids = append(txids, txid.String())
+	}
+	alert.Message = fmt.Sprintf("Double spend %s : %s", ds.ID.String(), strings.Join(txids, ", "))
+	if ds.Confirmed != nil {
+		alert.Message += fmt.Sprintf(" (confirmed %s at height %d)", ds.Confirmed.String(),
+			ds.ConfirmedHeight)
+	}
+
+	return server.publishAlert(ctx, alert)
+}
+
+// publishAlert queues an alert for the alert handler, or logs it if there isn't one. It doesn't
+//   wait for the handler so a slow webhook can't hold up spynode. Alerts are dropped when the
+//   queue is full.
+func (server *Server) publishAlert(ctx context.Context, alert *Alert) error {
+	node.LogWarn(ctx, "Alert %s : %s", alert.Type, alert.Message)
+
+	if server.AlertHandler == nil {
+		return nil
+	}
+
+	if err := server.alerts.Add(alert); err != nil {
+		node.LogWarn(ctx, "Dropped alert %s : %s", alert.Type, err)
+	}
+	return nil
+}
+
+// ProcessAlerts sends queued alerts to the alert handler until the alert channel is closed.
+func (server *Server) ProcessAlerts(ctx context.Context) {
+	for alert := range server.alerts.Channel {
+		if err := server.AlertHandler(ctx, alert); err != nil {
+			node.LogWarn(ctx, "Failed to publish alert %s : %s", alert.Type, err)
+		}
+	}
+}
+
+type AlertChannel struct {
+	Channel chan *Alert
+	lock    sync.Mutex
+	open    bool
+}
+
+// Add queues an alert without blocking. It returns an error if the channel is closed or full.
+func (c *AlertChannel) Add(alert *Alert) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.open {
+		return errors.New("Channel closed")
+	}
+
+	select {
+	case c.Channel <- alert:
+		return nil
+	default:
+		return errors.New("Channel full")
+	}
+}
+
+func (c *AlertChannel) Open(count int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.Channel = make(chan *Alert, count)
+	c.open = true
+	return nil
+}
+
+func (c *AlertChannel) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if !c.open {
+		return errors.New("Channel closed")
+	}
+
+	close(c.Channel)
+	c.open = false
+	return nil
+}
@@ -0,0 +1,57 @@
+package listeners
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/tokenized/smart-contract/internal/platform/node"
+	"github.com/tokenized/smart-contract/internal/platform/protomux"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/scheduler"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+// FreezeThawer is a Scheduler job that thaws a time-limited freeze when its freeze period ends.
+type FreezeThawer struct {
+	handler    protomux.Handler
+	freezeTx   *inspector.Transaction
+	expiration protocol.Timestamp
+	finished   bool
+}
+
+func NewFreezeThawer(handler protomux.Handler, freezeTx *inspector.Transaction, expiration protocol.Timestamp) *FreezeThawer {
+	result := FreezeThawer{
+		handler:    handler,
+		freezeTx:   freezeTx,
+		expiration: expiration,
+	}
+	return &result
+}
+
+// IsReady returns true when a job should be executed.
+func (ft *FreezeThawer) IsReady(ctx context.Context) bool {
+	return uint64(time.Now().UnixNano()) > ft.expiration.Nano()
+}
+
+// Run executes the job.
+func (ft *FreezeThawer) Run(ctx context.Context) {
+	node.Log(ctx, "Thawing expired freeze : %s", ft.freezeTx.Hash.String())
+	ft.handler.Reprocess(ctx, ft.freezeTx)
+	ft.finished = true
+}
+
+// IsComplete returns true when a job should be removed from the scheduler.
+func (ft *FreezeThawer) IsComplete(ctx context.Context) bool {
+	return ft.finished
+}
+
+// Equal returns true if another job matches it. Used to cancel jobs.
+func (ft *FreezeThawer) Equal(other scheduler.Task) bool {
+	otherFT, ok := other.(*FreezeThawer)
+	if !ok {
+		return false
+	}
+	return bytes.Equal(ft.freezeTx.Hash[:], otherFT.freezeTx.Hash[:])
+}
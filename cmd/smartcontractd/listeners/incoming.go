@@ -36,7 +36,13 @@ func (server *Server) AddTx(ctx context.Context, tx *wire.MsgTx) error {
 		server.pendingLock.Unlock()
 		return fmt.Errorf("Tx already added : %s", intx.Itx.Hash.String())
 	}
+	if server.wasProcessed(ctx, intx.Itx.Hash) {
+		server.pendingLock.Unlock()
+		node.LogVerbose(ctx, "Tx already processed : %s", intx.Itx.Hash.String())
+		return nil
+	}
 	server.pendingTxs[*intx.Itx.Hash] = intx
+	server.savePendingTx(ctx, intx)
 	server.pendingLock.Unlock()
 
 	server.incomingTxs.Add(intx)
@@ -55,8 +61,20 @@ func (server *Server) ProcessIncomingTxs(ctx context.Context, masterDB *db.DB,
 			return err
 		}
 		if err := intx.Itx.Promote(ctx, server.RpcNode); err != nil {
+			if errors.Cause(err) == inspector.ErrMissingInputs {
+				// The node is reachable but doesn't know an input tx, so this tx can't be processed.
+				node.LogWarn(ctx, "Dropping tx with unknown inputs %s : %s", intx.Itx.Hash.String(),
+					err)
+				server.CancelPendingTx(ctx, intx.Itx.Hash)
+				continue
+			}
 			return err
 		}
+		if server.Config.VerifyInputs && intx.Itx.RejectCode == 0 {
+			if err := intx.Itx.VerifyInputs(ctx); err != nil {
+				return err
+			}
+		}
 
 		switch msg := intx.Itx.MsgProto.(type) {
 		case *actions.Transfer:
@@ -109,10 +127,19 @@ func (server *Server) processReadyTxs(ctx context.Context) {
 
 	// Remove processed txids
 	if toRemove > 0 {
-		server.readyTxs = append(server.readyTxs[:toRemove-1], server.readyTxs[toRemove:]...)
+		server.readyTxs = server.readyTxs[toRemove:]
 	}
 }
 
+// addReady appends a tx to the list of txs approved for processing.
+// pendingLock must be held by the caller.
+func (server *Server) addReady(intx *IncomingTxData) {
+	intx.InReady = true
+	intx.ReadyIndex = server.readyIndex
+	server.readyIndex++
+	server.readyTxs = append(server.readyTxs, intx.Itx.Hash)
+}
+
 func (server *Server) MarkSafe(ctx context.Context, txid *bitcoin.Hash32) {
 	server.pendingLock.Lock()
 	defer server.pendingLock.Unlock()
@@ -131,9 +158,9 @@ func (server *Server) MarkSafe(ctx context.Context, txid *bitcoin.Hash32) {
 
 	intx.IsReady = true
 	if !intx.InReady {
-		intx.InReady = true
-		server.readyTxs = append(server.readyTxs, intx.Itx.Hash)
+		server.addReady(intx)
 	}
+	server.savePendingTx(ctx, intx)
 	server.processReadyTxs(ctx)
 }
 
@@ -147,13 +174,18 @@ func (server *Server) MarkUnsafe(ctx context.Context, txid *bitcoin.Hash32) {
 	}
 
 	intx.IsReady = true
+	intx.InReady = false
 	intx.Itx.RejectCode = actions.RejectionsDoubleSpend
+	if intx.UnsafeAt == 0 {
+		intx.UnsafeAt = uint64(time.Now().UnixNano())
+	}
 	for i, readyID := range server.readyTxs {
 		if *readyID == *txid {
 			server.readyTxs = append(server.readyTxs[:i], server.readyTxs[i+1:]...)
 			break
 		}
 	}
+	server.savePendingTx(ctx, intx)
 }
 
 func (server *Server) CancelPendingTx(ctx context.Context, txid *bitcoin.Hash32) bool {
@@ -166,6 +198,7 @@ func (server *Server) CancelPendingTx(ctx context.Context, txid *bitcoin.Hash32)
 	}
 
 	delete(server.pendingTxs, *txid)
+	server.removePendingTx(ctx, txid, false)
 	if intx.InReady {
 		for i, readyID := range server.readyTxs {
 			if *readyID == *txid {
@@ -183,22 +216,26 @@ func (server *Server) MarkConfirmed(ctx context.Context, txid *bitcoin.Hash32) {
 
 	intx, exists := server.pendingTxs[*txid]
 	if !exists {
+		// Confirmed txs won't be announced again, so they no longer need to be tracked.
+		server.removeProcessed(ctx, txid)
 		return
 	}
 
 	intx.IsReady = true
 	if !intx.InReady {
-		intx.InReady = true
-		server.readyTxs = append(server.readyTxs, intx.Itx.Hash)
+		server.addReady(intx)
 	}
+	server.savePendingTx(ctx, intx)
 	server.processReadyTxs(ctx)
 }
 
 type IncomingTxData struct {
 	Itx            *inspector.Transaction
-	IsPreprocessed bool // Preprocessing has completed
-	IsReady        bool // Is ready to be processed
-	InReady        bool // In ready list
+	IsPreprocessed bool   // Preprocessing has completed
+	IsReady        bool   // Is ready to be processed
+	InReady        bool   // In ready list
+	ReadyIndex     uint64 // Order in which the tx was added to the ready list
+	UnsafeAt       uint64 // Time the tx was marked unsafe. Expires if not confirmed or cancelled.
 }
 
 func NewIncomingTxData(ctx context.Context, tx *wire.MsgTx) (*IncomingTxData, error) {
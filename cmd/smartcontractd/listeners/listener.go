@@ -5,12 +5,16 @@ import (
 	"context"
 	"sort"
 
+	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/enforcement"
 	"github.com/tokenized/smart-contract/internal/platform/node"
+	"github.com/tokenized/smart-contract/internal/thaw"
 	"github.com/tokenized/smart-contract/internal/transactions"
 	"github.com/tokenized/smart-contract/internal/transfer"
 	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
+	"github.com/tokenized/smart-contract/pkg/wallet"
 	"github.com/tokenized/smart-contract/pkg/wire"
 )
 
@@ -81,6 +85,7 @@ func (server *Server) HandleTxState(ctx context.Context, msgType int, txid bitco
 		if server.CancelPendingTx(ctx, &txid) {
 			return nil
 		}
+		server.removeProcessed(ctx, &txid)
 
 		itx, err := transactions.GetTx(ctx, server.MasterDB, &txid, server.Config.IsTest)
 		if err != nil {
@@ -148,12 +153,32 @@ func (server *Server) HandleInSync(ctx context.Context) error {
 		if err := server.Handler.Trigger(ctx, "SEE", tx.Itx); err != nil {
 			node.LogError(ctx, "Failed to handle pending request tx : %s", err)
 		}
+		server.removePendingRequest(ctx, tx.Itx.Hash)
 	}
 
+	// -------------------------------------------------------------------------
+	// Resume contract moves
+	// Finish moves that were interrupted and monitor the new addresses.
+	keys := server.wallet.ListAll()
+	for _, key := range keys {
+		server.resumeContractMove(ctx, key.Address)
+	}
+
+	// Contracts that moved have their votes and transfers listed at the new address.
+	keys = server.wallet.ListAll()
+	var currentKeys []*wallet.Key
+	for _, key := range keys {
+		ct, err := contract.Retrieve(ctx, server.MasterDB, key.Address)
+		if err == nil && !ct.MovedTo.IsEmpty() {
+			continue
+		}
+		currentKeys = append(currentKeys, key)
+	}
+	keys = currentKeys
+
 	// -------------------------------------------------------------------------
 	// Schedule vote finalizers
 	// Iterate through votes for each contract and if they aren't complete schedule a finalizer.
-	keys := server.wallet.ListAll()
 	for _, key := range keys {
 		votes, err := vote.List(ctx, server.MasterDB, key.Address)
 		if err != nil {
@@ -217,5 +242,106 @@ func (server *Server) HandleInSync(ctx context.Context) error {
 		}
 	}
 
+	// -------------------------------------------------------------------------
+	// Schedule freeze thawers
+	// Iterate through pending thaws for each contract and schedule a thaw of the freeze.
+	for _, key := range keys {
+		thaws, err := thaw.List(ctx, server.MasterDB, key.Address)
+		if err != nil {
+			node.LogWarn(ctx, "Failed to list pending thaws : %s", err)
+			return nil
+		}
+		for _, pt := range thaws {
+			// Retrieve freezeTx
+			var hash *bitcoin.Hash32
+			hash, err = bitcoin.NewHash32(pt.FreezeTxId.Bytes())
+			if err != nil {
+				node.LogWarn(ctx, "Failed to create tx hash : %s", err)
+				return nil
+			}
+			freezeTx, err := transactions.GetTx(ctx, server.MasterDB, hash, server.Config.IsTest)
+			if err != nil {
+				node.LogWarn(ctx, "Failed to retrieve freeze tx : %s", err)
+				return nil
+			}
+
+			// Schedule freeze thawer
+			if err = server.Scheduler.ScheduleJob(ctx, NewFreezeThawer(server.Handler, freezeTx, pt.Expires)); err != nil {
+				node.LogWarn(ctx, "Failed to schedule freeze thawer : %s", err)
+				return nil
+			}
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Resume enforcement batches
+	// Iterate through enforcement batches for each contract and respond to remaining targets.
+	for _, key := range keys {
+		batches, err := enforcement.List(ctx, server.MasterDB, key.Address)
+		if err != nil {
+			node.LogWarn(ctx, "Failed to list enforcement batches : %s", err)
+			return nil
+		}
+		for _, batch := range batches {
+			if batch.CompletedAt.Nano() != 0 {
+				continue
+			}
+
+			// Retrieve orderTx
+			var hash *bitcoin.Hash32
+			hash, err = bitcoin.NewHash32(batch.OrderTxId.Bytes())
+			if err != nil {
+				node.LogWarn(ctx, "Failed to create tx hash : %s", err)
+				return nil
+			}
+			orderTx, err := transactions.GetTx(ctx, server.MasterDB, hash, server.Config.IsTest)
+			if err != nil {
+				node.LogWarn(ctx, "Failed to retrieve order tx : %s", err)
+				return nil
+			}
+
+			// Respond to remaining targets
+			if err = server.Handler.Reprocess(ctx, orderTx); err != nil {
+				node.LogWarn(ctx, "Failed to resume enforcement batch : %s", err)
+				return nil
+			}
+		}
+	}
+
 	return nil
 }
+
+// resumeContractMove processes the address change request again for a contract move that didn't
+//   finish, then monitors the new address.
+func (server *Server) resumeContractMove(ctx context.Context, contractAddress bitcoin.RawAddress) {
+	migration, err := contract.FetchMigration(ctx, server.MasterDB, contractAddress)
+	if err != nil {
+		if err != contract.ErrMigrationNotFound {
+			node.LogWarn(ctx, "Failed to fetch contract migration : %s", err)
+		}
+		return
+	}
+
+	if migration.CompletedAt.Nano() == 0 {
+		hash, err := bitcoin.NewHash32(migration.RequestTxId.Bytes())
+		if err != nil {
+			node.LogWarn(ctx, "Failed to create tx hash : %s", err)
+			return
+		}
+		requestTx, err := transactions.GetTx(ctx, server.MasterDB, hash, server.Config.IsTest)
+		if err != nil {
+			node.LogWarn(ctx, "Failed to retrieve contract address change tx : %s", err)
+			return
+		}
+
+		node.Log(ctx, "Resuming contract move : %s", requestTx.Hash.String())
+		if err := server.Handler.Trigger(ctx, "SEE", requestTx); err != nil {
+			node.LogError(ctx, "Failed to resume contract move : %s", err)
+			return
+		}
+	}
+
+	if err := server.monitorMovedContract(ctx, migration.To); err != nil {
+		node.LogError(ctx, "Failed to monitor moved contract : %s", err)
+	}
+}
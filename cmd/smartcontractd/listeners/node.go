@@ -37,6 +37,7 @@ type Server struct {
 	lock              sync.Mutex
 	Handler           protomux.Handler
 	contractAddresses []bitcoin.RawAddress // Used to determine which txs will be needed again
+	moveKeys          []*wallet.Key        // Keys for addresses contracts may be moved to
 	walletLock        sync.RWMutex
 	txFilter          *filters.TxFilter
 	pendingRequests   []pendingRequest
@@ -47,15 +48,27 @@ type Server struct {
 
 	pendingTxs  map[bitcoin.Hash32]*IncomingTxData
 	readyTxs    []*bitcoin.Hash32 // Saves order of tx approval in case preprocessing doesn't finish before approval.
+	readyIndex  uint64            // Next ready order index. Persisted so order survives a restart.
 	pendingLock sync.Mutex
 
+	processedTxs  map[bitcoin.Hash32]uint64 // Time processed markers were written, by txid
+	processedLock sync.Mutex
+
 	incomingTxs   IncomingTxChannel
 	processingTxs ProcessingTxChannel
+	alerts        AlertChannel
 
 	holdingsChannel *holdings.CacheChannel
 
 	TxSentCount        int
 	AlternateResponder protomux.ResponderFunc
+	AlertHandler       AlertFunc   // Receives alerts such as double spends. Alerts are logged if nil.
+	Broadcaster        Broadcaster // Broadcasts responses in addition to spynode. Optional.
+}
+
+// Broadcaster sends txs to the network through a service other than spynode.
+type Broadcaster interface {
+	SendTX(context.Context, *wire.MsgTx) (*bitcoin.Hash32, error)
 }
 
 type pendingRequest struct {
@@ -90,6 +103,7 @@ func NewServer(
 		utxos:            utxos,
 		txFilter:         txFilter,
 		pendingTxs:       make(map[bitcoin.Hash32]*IncomingTxData),
+		processedTxs:     make(map[bitcoin.Hash32]uint64),
 		pendingRequests:  make([]pendingRequest, 0),
 		pendingResponses: make(inspector.TransactionList, 0),
 		blockHeight:      0,
@@ -108,6 +122,7 @@ func (server *Server) Run(ctx context.Context) error {
 	server.incomingTxs.Open(100)
 	server.processingTxs.Open(100)
 	server.holdingsChannel.Open(5000)
+	server.alerts.Open(100)
 
 	// Register listeners
 	if server.SpyNode != nil {
@@ -118,6 +133,12 @@ func (server *Server) Run(ctx context.Context) error {
 		return err
 	}
 
+	// Restore txs that were pending when the daemon last stopped.
+	restored, err := server.loadPending(ctx)
+	if err != nil {
+		return err
+	}
+
 	wg := sync.WaitGroup{}
 
 	if server.SpyNode != nil {
@@ -173,6 +194,13 @@ func (server *Server) Run(ctx context.Context) error {
 		}()
 	}
 
+	// Queue restored txs for preprocessing.
+	for _, intx := range restored {
+		if err := server.incomingTxs.Add(intx); err != nil {
+			node.LogWarn(ctx, "Failed to queue restored tx : %s", err)
+		}
+	}
+
 	// Start process thread
 	wg.Add(1)
 	go func() {
@@ -208,8 +236,14 @@ func (server *Server) Run(ctx context.Context) error {
 		node.LogVerbose(ctx, "Process holdings cache thread finished")
 	}()
 
+	// Start alert thread. It isn't waited for so a hanging alert handler doesn't delay shutdown.
+	if server.AlertHandler != nil {
+		go server.ProcessAlerts(ctx)
+	}
+
 	// Block until goroutines finish as a result of Stop()
 	wg.Wait()
+	server.alerts.Close()
 
 	return server.Tracer.Save(ctx, server.MasterDB)
 }
@@ -244,6 +278,14 @@ func (server *Server) SetAlternateResponder(responder protomux.ResponderFunc) {
 	server.AlternateResponder = responder
 }
 
+func (server *Server) SetAlertHandler(handler AlertFunc) {
+	server.AlertHandler = handler
+}
+
+func (server *Server) SetBroadcaster(broadcaster Broadcaster) {
+	server.Broadcaster = broadcaster
+}
+
 func (server *Server) sendTx(ctx context.Context, tx *wire.MsgTx) error {
 	server.TxSentCount++
 
@@ -253,6 +295,16 @@ func (server *Server) sendTx(ctx context.Context, tx *wire.MsgTx) error {
 		}
 	}
 
+	if server.Broadcaster != nil {
+		if _, err := server.Broadcaster.SendTX(ctx, tx); err != nil {
+			if server.SpyNode == nil {
+				return errors.Wrap(err, "broadcast")
+			}
+			// Spynode already sent it so the tx still reaches the network.
+			node.LogWarn(ctx, "Failed to broadcast tx %s : %s", tx.TxHash().String(), err)
+		}
+	}
+
 	if server.AlternateResponder != nil {
 		server.AlternateResponder(ctx, tx)
 	}
@@ -262,6 +314,9 @@ func (server *Server) sendTx(ctx context.Context, tx *wire.MsgTx) error {
 
 // respondTx is an internal method used as the responder
 func (server *Server) respondTx(ctx context.Context, tx *wire.MsgTx) error {
+	// The request must not be processed again after a restart once its response is sent.
+	server.markResponded(ctx, tx)
+
 	// Add to spynode and mark as safe so it will be processed now
 	if server.SpyNode != nil {
 		if err := server.SpyNode.HandleTx(ctx, tx); err != nil {
@@ -294,6 +349,7 @@ func (server *Server) removeConflictingPending(ctx context.Context, itx *inspect
 			itx.MsgTx.TxIn[pendingTx.ContractIndex].PreviousOutPoint.Hash.Equal(pendingTx.Itx.Hash) {
 			node.Log(ctx, "Canceling pending request tx : %s", pendingTx.Itx.Hash.String())
 			server.pendingRequests = append(server.pendingRequests[:i], server.pendingRequests[i+1:]...)
+			server.removePendingRequest(ctx, pendingTx.Itx.Hash)
 			return nil
 		}
 	}
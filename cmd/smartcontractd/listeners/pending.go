@@ -0,0 +1,400 @@
+package listeners
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/node"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+
+	"github.com/pkg/errors"
+)
+
+// Pending txs are written through to storage as their state changes so that a restart doesn't
+//   drop requests that were received but not yet processed.
+//
+//   pending/txs/<txid>       Txs received from spynode and not yet processed.
+//   pending/requests/<txid>  Requests seen before the node was in sync.
+//   pending/processed/<txid> Txs processed but not yet confirmed. Prevents double processing if
+//                            spynode re-announces them after a restart. Written before responses
+//                            are broadcast and removed when the tx confirms, is cancelled, or
+//                            expires. They are also kept in memory so announced txs can be checked
+//                            without reading storage.
+
+const (
+	pendingStorageKey  = "pending"
+	pendingTxsKey      = "txs"
+	pendingRequestsKey = "requests"
+	pendingDoneKey     = "processed"
+
+	pendingVersion   = uint8(0)
+	pendingTxVersion = uint8(1) // Version 1 added the time a tx was marked unsafe
+
+	pendingFlagReady   = 0x01
+	pendingFlagInReady = 0x02
+	pendingFlagUnsafe  = 0x04
+
+	// Processed markers of txs that never confirm are removed after this long. By then the tx has
+	//   been dropped from mempools, so spynode won't re-announce it.
+	processedExpiration = 14 * 24 * time.Hour
+
+	// Double spent txs are only processed if they confirm, and are removed when they are cancelled.
+	//   Ones that are neither confirmed nor cancelled are removed after this long.
+	unsafeExpiration = 14 * 24 * time.Hour
+)
+
+// loadPending restores pending txs and pending requests saved before the last shutdown.
+// Restored txs are returned so they can be queued for preprocessing once the preprocess threads
+//   are running.
+func (server *Server) loadPending(ctx context.Context) ([]*IncomingTxData, error) {
+	server.pendingLock.Lock()
+	defer server.pendingLock.Unlock()
+
+	if err := server.loadProcessed(ctx); err != nil {
+		return nil, errors.Wrap(err, "load processed")
+	}
+
+	data, err := server.MasterDB.Search(ctx, buildPendingPrefix(pendingTxsKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "search pending txs")
+	}
+
+	unsafeExpired := uint64(time.Now().Add(-unsafeExpiration).UnixNano())
+	result := make([]*IncomingTxData, 0, len(data))
+	ready := make([]*IncomingTxData, 0, len(data))
+	for _, b := range data {
+		intx, err := readIncomingTxData(ctx, b)
+		if err != nil {
+			return nil, errors.Wrap(err, "read pending tx")
+		}
+
+		// Responses were sent before the last shutdown, but the tx wasn't removed yet.
+		if server.wasProcessed(ctx, intx.Itx.Hash) {
+			server.removePendingTx(ctx, intx.Itx.Hash, false)
+			continue
+		}
+
+		if intx.Itx.RejectCode == actions.RejectionsDoubleSpend &&
+			intx.UnsafeAt < unsafeExpired {
+			node.LogVerbose(ctx, "Removing expired unsafe tx : %s", intx.Itx.Hash.String())
+			server.removePendingTx(ctx, intx.Itx.Hash, false)
+			continue
+		}
+
+		server.pendingTxs[*intx.Itx.Hash] = intx
+		if intx.ReadyIndex >= server.readyIndex {
+			server.readyIndex = intx.ReadyIndex + 1
+		}
+		if intx.InReady {
+			ready = append(ready, intx)
+		}
+		result = append(result, intx)
+	}
+
+	// Restore the order that txs were approved in.
+	sort.Slice(ready, func(i, j int) bool { return ready[i].ReadyIndex < ready[j].ReadyIndex })
+	for _, intx := range ready {
+		server.readyTxs = append(server.readyTxs, intx.Itx.Hash)
+	}
+
+	data, err = server.MasterDB.Search(ctx, buildPendingPrefix(pendingRequestsKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "search pending requests")
+	}
+
+	for _, b := range data {
+		request, err := readPendingRequest(b, server.Config.IsTest)
+		if err != nil {
+			return nil, errors.Wrap(err, "read pending request")
+		}
+		server.pendingRequests = append(server.pendingRequests, request)
+	}
+
+	node.Log(ctx, "Loaded pending : %d txs (%d ready), %d requests", len(result), len(ready),
+		len(server.pendingRequests))
+	return result, nil
+}
+
+// savePendingTx writes the current state of a pending tx.
+// pendingLock must be held by the caller.
+func (server *Server) savePendingTx(ctx context.Context, intx *IncomingTxData) {
+	var buf bytes.Buffer
+	if err := intx.write(&buf); err != nil {
+		node.LogWarn(ctx, "Failed to serialize pending tx : %s", err)
+		return
+	}
+
+	if err := server.MasterDB.Put(ctx, buildPendingPath(pendingTxsKey, intx.Itx.Hash),
+		buf.Bytes()); err != nil {
+		node.LogWarn(ctx, "Failed to save pending tx : %s", err)
+	}
+}
+
+// removePendingTx removes a pending tx from storage. When processed is true a marker is left so
+//   the tx is not processed again if it is re-announced before it confirms.
+func (server *Server) removePendingTx(ctx context.Context, txid *bitcoin.Hash32, processed bool) {
+	if processed {
+		server.markProcessed(ctx, txid)
+	}
+
+	if err := server.MasterDB.Remove(ctx, buildPendingPath(pendingTxsKey, txid)); err != nil &&
+		err != db.ErrNotFound {
+		node.LogWarn(ctx, "Failed to remove pending tx : %s", err)
+	}
+}
+
+// markProcessed writes the processed marker for a tx.
+func (server *Server) markProcessed(ctx context.Context, txid *bitcoin.Hash32) {
+	processedAt := uint64(time.Now().UnixNano())
+
+	server.processedLock.Lock()
+	server.processedTxs[*txid] = processedAt
+	server.processedLock.Unlock()
+
+	var buf bytes.Buffer
+	buf.WriteByte(pendingVersion)
+	if err := binary.Write(&buf, binary.LittleEndian, processedAt); err != nil {
+		node.LogWarn(ctx, "Failed to serialize processed marker : %s", err)
+		return
+	}
+
+	if err := server.MasterDB.Put(ctx, buildPendingPath(pendingDoneKey, txid),
+		buf.Bytes()); err != nil {
+		node.LogWarn(ctx, "Failed to save processed marker : %s", err)
+	}
+}
+
+// markResponded writes the processed markers for the pending requests that a response spends, so
+//   they aren't processed again if the daemon stops before their processing completes.
+func (server *Server) markResponded(ctx context.Context, tx *wire.MsgTx) {
+	for _, input := range tx.TxIn {
+		txid := &input.PreviousOutPoint.Hash
+		if _, err := server.MasterDB.Fetch(ctx, buildPendingPath(pendingTxsKey,
+			txid)); err != nil {
+			continue // Not a pending tx
+		}
+		server.markProcessed(ctx, txid)
+	}
+}
+
+// loadProcessed reads the processed markers into memory and removes those older than
+//   processedExpiration.
+func (server *Server) loadProcessed(ctx context.Context) error {
+	keys, err := server.MasterDB.List(ctx, buildPendingPrefix(pendingDoneKey))
+	if err != nil {
+		return errors.Wrap(err, "list processed")
+	}
+
+	server.processedLock.Lock()
+	defer server.processedLock.Unlock()
+
+	expiration := uint64(time.Now().Add(-processedExpiration).UnixNano())
+	for _, key := range keys {
+		b, err := server.MasterDB.Fetch(ctx, key)
+		if err != nil {
+			return errors.Wrap(err, "fetch processed")
+		}
+
+		var processedAt uint64
+		if len(b) == 9 && b[0] == pendingVersion {
+			processedAt = binary.LittleEndian.Uint64(b[1:])
+		}
+		if processedAt >= expiration {
+			txid, err := bitcoin.NewHash32FromStr(path.Base(key))
+			if err == nil {
+				server.processedTxs[*txid] = processedAt
+				continue
+			}
+			node.LogWarn(ctx, "Invalid processed marker %s : %s", key, err)
+		}
+
+		if err := server.MasterDB.Remove(ctx, key); err != nil && err != db.ErrNotFound {
+			return errors.Wrap(err, "remove processed")
+		}
+	}
+
+	return nil
+}
+
+// wasProcessed returns true if the tx has already been processed and hasn't confirmed yet.
+func (server *Server) wasProcessed(ctx context.Context, txid *bitcoin.Hash32) bool {
+	server.processedLock.Lock()
+	defer server.processedLock.Unlock()
+
+	_, exists := server.processedTxs[*txid]
+	return exists
+}
+
+// removeProcessed removes the processed marker for a tx once it is confirmed or cancelled.
+func (server *Server) removeProcessed(ctx context.Context, txid *bitcoin.Hash32) {
+	server.processedLock.Lock()
+	_, exists := server.processedTxs[*txid]
+	delete(server.processedTxs, *txid)
+	server.processedLock.Unlock()
+
+	if !exists {
+		return
+	}
+
+	if err := server.MasterDB.Remove(ctx, buildPendingPath(pendingDoneKey, txid)); err != nil &&
+		err != db.ErrNotFound {
+		node.LogWarn(ctx, "Failed to remove processed marker : %s", err)
+	}
+}
+
+// savePendingRequest writes a request received before the node was in sync.
+func (server *Server) savePendingRequest(ctx context.Context, request pendingRequest) {
+	var buf bytes.Buffer
+	if err := request.write(&buf); err != nil {
+		node.LogWarn(ctx, "Failed to serialize pending request : %s", err)
+		return
+	}
+
+	if err := server.MasterDB.Put(ctx, buildPendingPath(pendingRequestsKey, request.Itx.Hash),
+		buf.Bytes()); err != nil {
+		node.LogWarn(ctx, "Failed to save pending request : %s", err)
+	}
+}
+
+// removePendingRequest removes a request received before the node was in sync.
+func (server *Server) removePendingRequest(ctx context.Context, txid *bitcoin.Hash32) {
+	if err := server.MasterDB.Remove(ctx, buildPendingPath(pendingRequestsKey, txid)); err != nil &&
+		err != db.ErrNotFound {
+		node.LogWarn(ctx, "Failed to remove pending request : %s", err)
+	}
+}
+
+func (intx *IncomingTxData) write(buf *bytes.Buffer) error {
+	buf.WriteByte(pendingTxVersion)
+
+	flags := uint8(0)
+	if intx.IsReady {
+		flags |= pendingFlagReady
+	}
+	if intx.InReady {
+		flags |= pendingFlagInReady
+	}
+	if intx.Itx.RejectCode == actions.RejectionsDoubleSpend {
+		flags |= pendingFlagUnsafe
+	}
+	buf.WriteByte(flags)
+
+	if err := binary.Write(buf, binary.LittleEndian, intx.ReadyIndex); err != nil {
+		return err
+	}
+
+	if flags&pendingFlagUnsafe != 0 {
+		if err := binary.Write(buf, binary.LittleEndian, intx.UnsafeAt); err != nil {
+			return err
+		}
+	}
+
+	return intx.Itx.MsgTx.Serialize(buf)
+}
+
+func readIncomingTxData(ctx context.Context, data []byte) (*IncomingTxData, error) {
+	buf := bytes.NewReader(data)
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version > pendingTxVersion {
+		return nil, fmt.Errorf("Unknown version : %d", version)
+	}
+
+	flags, err := buf.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var readyIndex uint64
+	if err := binary.Read(buf, binary.LittleEndian, &readyIndex); err != nil {
+		return nil, err
+	}
+
+	var unsafeAt uint64
+	if flags&pendingFlagUnsafe != 0 {
+		if version == 0 {
+			// The time wasn't saved, so start the expiration now.
+			unsafeAt = uint64(time.Now().UnixNano())
+		} else if err := binary.Read(buf, binary.LittleEndian, &unsafeAt); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := wire.MsgTx{}
+	if err := msg.Deserialize(buf); err != nil {
+		return nil, err
+	}
+
+	result, err := NewIncomingTxData(ctx, &msg)
+	if err != nil {
+		return nil, err
+	}
+
+	result.IsReady = flags&pendingFlagReady != 0
+	result.InReady = flags&pendingFlagInReady != 0
+	result.ReadyIndex = readyIndex
+	if flags&pendingFlagUnsafe != 0 {
+		result.Itx.RejectCode = actions.RejectionsDoubleSpend
+		result.UnsafeAt = unsafeAt
+	}
+	return result, nil
+}
+
+func (request *pendingRequest) write(buf *bytes.Buffer) error {
+	buf.WriteByte(pendingVersion)
+
+	if err := binary.Write(buf, binary.LittleEndian, uint32(request.ContractIndex)); err != nil {
+		return err
+	}
+
+	return request.Itx.Write(buf)
+}
+
+func readPendingRequest(data []byte, isTest bool) (pendingRequest, error) {
+	result := pendingRequest{}
+	buf := bytes.NewReader(data)
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return result, err
+	}
+	if version != pendingVersion {
+		return result, fmt.Errorf("Unknown version : %d", version)
+	}
+
+	var index uint32
+	if err := binary.Read(buf, binary.LittleEndian, &index); err != nil {
+		return result, err
+	}
+	result.ContractIndex = int(index)
+
+	result.Itx = &inspector.Transaction{}
+	if err := result.Itx.Read(buf, isTest); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// Returns the storage path for a pending tx.
+func buildPendingPath(subKey string, txid *bitcoin.Hash32) string {
+	return fmt.Sprintf("%s/%s/%s", pendingStorageKey, subKey, txid.String())
+}
+
+// Returns the storage path prefix for a type of pending tx.
+func buildPendingPrefix(subKey string) string {
+	return fmt.Sprintf("%s/%s", pendingStorageKey, subKey)
+}
@@ -14,89 +14,101 @@ import (
 // ProcessTxs performs "core" processing on transactions.
 func (server *Server) ProcessTxs(ctx context.Context) error {
 	for ptx := range server.processingTxs.Channel {
-		node.Log(ctx, "Processing tx : %s", ptx.Itx.Hash)
-		server.lock.Lock()
-		server.Tracer.AddTx(ctx, ptx.Itx.MsgTx)
-		server.lock.Unlock()
-
-		server.walletLock.RLock()
-
-		if !ptx.Itx.IsTokenized() {
-			node.Log(ctx, "Not tokenized : %s", ptx.Itx.Hash)
-			server.utxos.Add(ptx.Itx.MsgTx, server.contractAddresses)
-			server.walletLock.RUnlock()
-			continue
+		server.processTx(ctx, ptx)
+
+		if ptx.Event == "SEE" && server.inSync {
+			server.addMovedContractKey(ctx, ptx.Itx)
 		}
 
-		if err := server.removeConflictingPending(ctx, ptx.Itx); err != nil {
-			node.LogError(ctx, "Failed to remove conflicting pending : %s", err)
-			server.walletLock.RUnlock()
-			continue
+		if ptx.Event == "SEE" {
+			// Processing is complete so the tx no longer needs to be restored after a restart.
+			server.removePendingTx(ctx, ptx.Itx.Hash, true)
 		}
+	}
+	return nil
+}
 
-		found := false
+func (server *Server) processTx(ctx context.Context, ptx ProcessingTx) {
+	node.Log(ctx, "Processing tx : %s", ptx.Itx.Hash)
+	server.lock.Lock()
+	server.Tracer.AddTx(ctx, ptx.Itx.MsgTx)
+	server.lock.Unlock()
 
-		// Save tx to cache so it can be used to process the response
-		for index, output := range ptx.Itx.Outputs {
-			for _, address := range server.contractAddresses {
-				if address.Equal(output.Address) {
-					found = true
-					if err := server.RpcNode.SaveTX(ctx, ptx.Itx.MsgTx); err != nil {
-						node.LogError(ctx, "Failed to save tx to RPC : %s", err)
-					}
-					if !server.inSync && ptx.Itx.IsIncomingMessageType() {
-						node.Log(ctx, "Request added to pending : %s", ptx.Itx.Hash)
-						// Save pending request to ensure it has a response, and process it if not.
-						server.pendingRequests = append(server.pendingRequests, pendingRequest{
-							Itx:           ptx.Itx,
-							ContractIndex: index,
-						})
+	server.walletLock.RLock()
+	defer server.walletLock.RUnlock()
+
+	if !ptx.Itx.IsTokenized() {
+		node.Log(ctx, "Not tokenized : %s", ptx.Itx.Hash)
+		server.utxos.Add(ptx.Itx.MsgTx, server.contractAddresses)
+		return
+	}
+
+	if err := server.removeConflictingPending(ctx, ptx.Itx); err != nil {
+		node.LogError(ctx, "Failed to remove conflicting pending : %s", err)
+		return
+	}
+
+	found := false
+
+	// Save tx to cache so it can be used to process the response
+	for index, output := range ptx.Itx.Outputs {
+		for _, address := range server.contractAddresses {
+			if address.Equal(output.Address) {
+				found = true
+				if err := server.RpcNode.SaveTX(ctx, ptx.Itx.MsgTx); err != nil {
+					node.LogError(ctx, "Failed to save tx to RPC : %s", err)
+				}
+				if !server.inSync && ptx.Itx.IsIncomingMessageType() {
+					node.Log(ctx, "Request added to pending : %s", ptx.Itx.Hash)
+					// Save pending request to ensure it has a response, and process it if not.
+					request := pendingRequest{
+						Itx:           ptx.Itx,
+						ContractIndex: index,
 					}
-					break
+					server.pendingRequests = append(server.pendingRequests, request)
+					server.savePendingRequest(ctx, request)
 				}
+				break
 			}
 		}
+	}
 
-		// Save pending responses so they can be processed in proper order, which may not be on
-		//   chain order.
-		if ptx.Itx.IsOutgoingMessageType() {
-			responseAdded := false
-			for _, input := range ptx.Itx.Inputs {
-				for _, address := range server.contractAddresses {
-					if address.Equal(input.Address) {
-						found = true
-						responseAdded = true
-						if !server.inSync {
-							node.Log(ctx, "Response added to pending : %s", ptx.Itx.Hash)
-							server.pendingResponses = append(server.pendingResponses, ptx.Itx)
-						}
-						break
+	// Save pending responses so they can be processed in proper order, which may not be on
+	//   chain order.
+	if ptx.Itx.IsOutgoingMessageType() {
+		responseAdded := false
+		for _, input := range ptx.Itx.Inputs {
+			for _, address := range server.contractAddresses {
+				if address.Equal(input.Address) {
+					found = true
+					responseAdded = true
+					if !server.inSync {
+						node.Log(ctx, "Response added to pending : %s", ptx.Itx.Hash)
+						server.pendingResponses = append(server.pendingResponses, ptx.Itx)
 					}
-				}
-				if responseAdded {
 					break
 				}
 			}
+			if responseAdded {
+				break
+			}
 		}
+	}
 
-		if found { // Tx is associated with one of our contracts.
-			if server.inSync {
-				// Process this tx
-				if err := server.Handler.Trigger(ctx, ptx.Event, ptx.Itx); err != nil {
-					node.LogError(ctx, "Failed to handle tx : %s", err)
-				}
-			} else {
-				// Save tx for response processing after smart contract is in sync with on chain
-				//   data.
-				if err := transactions.AddTx(ctx, server.MasterDB, ptx.Itx); err != nil {
-					node.LogError(ctx, "Failed to save tx : %s", err)
-				}
+	if found { // Tx is associated with one of our contracts.
+		if server.inSync {
+			// Process this tx
+			if err := server.Handler.Trigger(ctx, ptx.Event, ptx.Itx); err != nil {
+				node.LogError(ctx, "Failed to handle tx : %s", err)
+			}
+		} else {
+			// Save tx for response processing after smart contract is in sync with on chain
+			//   data.
+			if err := transactions.AddTx(ctx, server.MasterDB, ptx.Itx); err != nil {
+				node.LogError(ctx, "Failed to save tx : %s", err)
 			}
 		}
-
-		server.walletLock.RUnlock()
 	}
-	return nil
 }
 
 type ProcessingTx struct {
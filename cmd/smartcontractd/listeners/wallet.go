@@ -8,8 +8,11 @@ import (
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/wallet"
 
+	"github.com/tokenized/specification/dist/golang/actions"
+
 	"github.com/pkg/errors"
 )
 
@@ -26,6 +29,9 @@ func (server *Server) AddContractKey(ctx context.Context, key *wallet.Key) error
 	address, _ := bitcoin.NewAddressPKH(bitcoin.Hash160(key.Key.PublicKey().Bytes()),
 		server.Config.Net)
 	node.Log(ctx, "Adding key : %s", address.String())
+	if err := server.wallet.Add(key); err != nil {
+		return err
+	}
 	if err := server.SaveWallet(ctx); err != nil {
 		return err
 	}
@@ -95,7 +101,7 @@ func (server *Server) LoadWallet(ctx context.Context) error {
 	data, err := server.MasterDB.Fetch(ctx, walletKey)
 	if err != nil {
 		if err == db.ErrNotFound {
-			return nil // No keys yet
+			return server.SyncWallet(ctx) // No saved keys yet
 		}
 		return errors.Wrap(err, "fetch wallet")
 	}
@@ -126,3 +132,52 @@ func (server *Server) SyncWallet(ctx context.Context) error {
 
 	return nil
 }
+
+// SetMoveKeys sets the keys for addresses that contracts may be moved to. A key is added to those
+//   being monitored when a contract moves to its address.
+func (server *Server) SetMoveKeys(keys []*wallet.Key) {
+	server.moveKeys = keys
+}
+
+// addMovedContractKey starts monitoring the new address of a contract that was moved by the
+//   tx, if the tx is a contract address change.
+func (server *Server) addMovedContractKey(ctx context.Context, itx *inspector.Transaction) {
+	msg, ok := itx.MsgProto.(*actions.ContractAddressChange)
+	if !ok {
+		return
+	}
+
+	newAddress, err := bitcoin.DecodeRawAddress(msg.NewContractAddress)
+	if err != nil {
+		return
+	}
+
+	if err := server.monitorMovedContract(ctx, newAddress); err != nil {
+		node.LogError(ctx, "Failed to monitor moved contract : %s", err)
+	}
+}
+
+// monitorMovedContract adds the key for a contract's new address to those being monitored if it
+//   is one of the move keys and the contract has been moved to it.
+func (server *Server) monitorMovedContract(ctx context.Context, newAddress bitcoin.RawAddress) error {
+	if _, err := server.wallet.Get(newAddress); err == nil {
+		return nil // Already monitored
+	}
+
+	if _, err := contract.Retrieve(ctx, server.MasterDB, newAddress); err != nil {
+		if err == contract.ErrNotFound {
+			return nil // Not moved yet
+		}
+		return errors.Wrap(err, "retrieve contract")
+	}
+
+	for _, key := range server.moveKeys {
+		if key.Address.Equal(newAddress) {
+			return server.AddContractKey(ctx, key)
+		}
+	}
+
+	address := bitcoin.NewAddressFromRawAddress(newAddress, server.Config.Net)
+	node.LogWarn(ctx, "Contract moved to address without a key : %s", address.String())
+	return nil
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"os"
 	"os/signal"
-	"strings"
 	"sync"
 	"syscall"
 
@@ -16,8 +15,10 @@ import (
 	"github.com/tokenized/smart-contract/pkg/rpcnode"
 	"github.com/tokenized/smart-contract/pkg/scheduler"
 	"github.com/tokenized/smart-contract/pkg/spynode"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
-	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/txstore"
+	"github.com/tokenized/smart-contract/pkg/wallet"
 )
 
 var (
@@ -53,6 +54,8 @@ func main() {
 
 	cfg := bootstrap.NewConfigFromEnv(ctx)
 
+	bootstrap.StartLogAdmin(ctx, cfg)
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -70,15 +73,7 @@ func main() {
 
 	// -------------------------------------------------------------------------
 	// SPY Node
-	spyStorageConfig := storage.NewConfig(cfg.NodeStorage.Bucket,
-		cfg.NodeStorage.Root)
-
-	var spyStorage storage.Storage
-	if strings.ToLower(spyStorageConfig.Bucket) == "standalone" {
-		spyStorage = storage.NewFilesystemStorage(spyStorageConfig)
-	} else {
-		spyStorage = storage.NewS3Storage(spyStorageConfig)
-	}
+	spyStorage := bootstrap.NewSpyNodeStorage(ctx, cfg)
 
 	spyConfig, err := data.NewConfig(appConfig.Net, cfg.SpyNode.Address, cfg.SpyNode.UserAgent,
 		cfg.SpyNode.StartHash, cfg.SpyNode.UntrustedNodes, cfg.SpyNode.SafeTxDelay,
@@ -87,20 +82,32 @@ func main() {
 		logger.Fatal(ctx, "Failed to create spynode config : %s", err)
 		return
 	}
+	spyConfig.StaticPeers = cfg.SpyNode.StaticPeers
+	spyConfig.Dialer = dialer.New(cfg.SpyNode.ProxyAddress, cfg.SpyNode.ProxyUsername,
+		cfg.SpyNode.ProxyPassword)
+	spyConfig.TrustedTimeout = cfg.SpyNode.TrustedTimeout
+	spyConfig.UntrustedTimeout = cfg.SpyNode.UntrustedTimeout
 
 	spyNode := spynode.NewNode(spyConfig, spyStorage)
 
 	// -------------------------------------------------------------------------
-	// RPC Node
-	rpcConfig := &rpcnode.Config{
-		Host:     cfg.RpcNode.Host,
-		Username: cfg.RpcNode.Username,
-		Password: cfg.RpcNode.Password,
-	}
+	// Tx Store
+	txStore := txstore.NewStore(spyStorage, cfg.TxStore.MaxSize, cfg.TxStore.RecentCount)
+	spyNode.SetTxStore(txStore)
 
-	rpcNode, err := rpcnode.NewNode(rpcConfig)
-	if err != nil {
-		panic(err)
+	// -------------------------------------------------------------------------
+	// RPC Node
+	var broadcaster listeners.Broadcaster
+	txNode, closeTxNode := bootstrap.NewTxNode(ctx, cfg)
+	defer closeTxNode()
+	if txNode != nil {
+		// Txs not in the store are requested through the node.
+		txStore.Fallback = txNode
+
+		// Txs are broadcast through spynode when using RPC.
+		if _, isRPC := txNode.(*rpcnode.RPCNode); !isRPC {
+			broadcaster, _ = txNode.(listeners.Broadcaster)
+		}
 	}
 
 	// -------------------------------------------------------------------------
@@ -115,6 +122,15 @@ func main() {
 		appConfig.Net)
 	logger.Info(ctx, "Contract address : %s", contractAddress.String())
 
+	var moveKeys []*wallet.Key
+	for _, wif := range cfg.Contract.MovePrivateKeys {
+		key, err := bitcoin.KeyFromStr(wif)
+		if err != nil {
+			logger.Fatal(ctx, "Invalid move key : %s", err)
+		}
+		moveKeys = append(moveKeys, wallet.NewKey(key))
+	}
+
 	// -------------------------------------------------------------------------
 	// Tx Filter
 
@@ -160,7 +176,7 @@ func main() {
 		appHandlers,
 		appConfig,
 		masterDB,
-		rpcNode,
+		txStore,
 		spyNode,
 		spyNode,
 		&sch,
@@ -170,7 +186,20 @@ func main() {
 		holdingsChannel,
 	)
 
-	if err := node.SyncWallet(ctx); err != nil {
+	if broadcaster != nil {
+		node.SetBroadcaster(broadcaster)
+	}
+
+	node.SetMoveKeys(moveKeys)
+
+	if len(cfg.Alerts.WebhookURL) > 0 {
+		logger.Info(ctx, "Posting alerts to webhook")
+		node.SetAlertHandler(listeners.NewWebhookAlertHandler(cfg.Alerts.WebhookURL,
+			cfg.Alerts.Timeout))
+	}
+
+	// Load keys added when contracts moved.
+	if err := node.LoadWallet(ctx); err != nil {
 		logger.Fatal(ctx, "Load Wallet : %s", err)
 	}
 
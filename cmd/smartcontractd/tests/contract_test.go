@@ -11,13 +11,17 @@ import (
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/filters"
 	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
 	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/delegation"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/state"
 	"github.com/tokenized/smart-contract/internal/platform/tests"
+	"github.com/tokenized/smart-contract/internal/transfer"
+	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/scheduler"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
 	"github.com/tokenized/smart-contract/pkg/wallet"
 	"github.com/tokenized/smart-contract/pkg/wire"
 
@@ -31,10 +35,12 @@ func TestContracts(t *testing.T) {
 
 	t.Run("create", createContract)
 	t.Run("oracle", oracleContract)
+	t.Run("verifyInputs", contractVerifyInputs)
 	t.Run("amendment", contractAmendment)
 	t.Run("listAmendment", contractListAmendment)
 	t.Run("oracleAmendment", contractOracleAmendment)
 	t.Run("proposalAmendment", contractProposalAmendment)
+	t.Run("addressChange", contractAddressChange)
 }
 
 func createContract(t *testing.T) {
@@ -464,6 +470,175 @@ func oracleContract(t *testing.T) {
 	wg.Wait()
 }
 
+// contractVerifyInputs sends a contract offer with an unsigned input to a daemon that verifies
+//   inputs and checks that it is rejected, then signs the input and checks that it is accepted.
+func contractVerifyInputs(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+
+	offerData := actions.ContractOffer{
+		ContractName:        "Test Name",
+		BodyOfAgreementType: 2,
+		BodyOfAgreement:     []byte("This is a test contract and not to be used for any official purpose."),
+		Issuer: &actions.EntityField{
+			Type:           "I",
+			Administration: []*actions.AdministratorField{&actions.AdministratorField{Type: 1, Name: "John Smith"}},
+		},
+		VotingSystems: []*actions.VotingSystemField{
+			&actions.VotingSystemField{
+				Name:                "Relative 50",
+				VoteType:            "R",
+				ThresholdPercentage: 50,
+				HolderProposalFee:   50000,
+			},
+		},
+	}
+
+	// Create funding tx
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100005, issuerKey.Address)
+	fundingScript := fundingTx.TxOut[0].PkScript
+
+	// Build offer transaction
+	offerTx := wire.NewMsgTx(2)
+
+	// From issuer (Note: unsigned)
+	offerTx.TxIn = append(offerTx.TxIn, wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	// To contract
+	script, _ := test.ContractKey.Address.LockingScript()
+	offerTx.TxOut = append(offerTx.TxOut, wire.NewTxOut(750000, script))
+
+	// Data output
+	script, err := protocol.Serialize(&offerData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize offer : %v", tests.Failed, err)
+	}
+	offerTx.TxOut = append(offerTx.TxOut, wire.NewTxOut(0, script))
+
+	config := test.NodeConfig
+	config.PreprocessThreads = 4
+	config.VerifyInputs = true
+
+	tracer := filters.NewTracer()
+	holdingsChannel := &holdings.CacheChannel{}
+	txFilter := filters.NewTxFilter(tracer, true)
+	test.Scheduler = &scheduler.Scheduler{}
+
+	server := listeners.NewServer(test.Wallet, a, &config, test.MasterDB,
+		test.RPCNode, nil, test.Headers, test.Scheduler, tracer, test.UTXOs, txFilter,
+		holdingsChannel)
+
+	if err := server.SyncWallet(ctx); err != nil {
+		t.Fatalf("Failed to load wallet : %s", err)
+	}
+
+	server.SetAlternateResponder(respondTx)
+	server.SetInSync()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := server.Run(ctx); err != nil {
+			t.Logf("Server failed : %s", err)
+		}
+	}()
+
+	time.Sleep(time.Second)
+
+	t.Logf("Contract offer tx : %s", offerTx.TxHash().String())
+	if _, err := server.HandleTx(ctx, offerTx); err != nil {
+		t.Fatalf("\t%s\tContract handle failed : %v", tests.Failed, err)
+	}
+
+	if err := server.HandleTxState(ctx, handlers.ListenerMsgTxStateSafe, *offerTx.TxHash()); err != nil {
+		t.Fatalf("\t%s\tContract offer handle state failed : %v", tests.Failed, err)
+	}
+
+	var firstResponse *wire.MsgTx // Request tx is re-broadcast now
+	var response *wire.MsgTx
+	for {
+		if firstResponse == nil {
+			firstResponse = getResponse()
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		response = getResponse()
+		if response != nil {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if code := responseType(response); code != actions.CodeRejection {
+		t.Fatalf("\t%s\tContract offer response not a reject : %s", tests.Failed, code)
+	}
+	var reject *actions.Rejection
+	for _, output := range response.TxOut {
+		msg, err := protocol.Deserialize(output.PkScript, test.NodeConfig.IsTest)
+		if err == nil {
+			reject, _ = msg.(*actions.Rejection)
+			break
+		}
+	}
+	if reject == nil {
+		t.Fatalf("\t%s\tFailed to convert response to rejection", tests.Failed)
+	}
+	if reject.RejectionCode != actions.RejectionsTxMalformed {
+		t.Fatalf("\t%s\tWrong reject code for unsigned input : %d", tests.Failed,
+			reject.RejectionCode)
+	}
+
+	t.Logf("\t%s\tContract offer with unsigned input rejection : (%d) %s", tests.Success,
+		reject.RejectionCode, reject.Message)
+
+	// Sign input and retry
+	offerTx.TxIn[0].SignatureScript, err = txbuilder.P2PKHUnlockingScript(issuerKey.Key, offerTx,
+		0, fundingScript, fundingTx.TxOut[0].Value, txbuilder.SigHashAll+txbuilder.SigHashForkID,
+		&txbuilder.SigHashCache{})
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to sign input : %v", tests.Failed, err)
+	}
+
+	t.Logf("Contract offer tx : %s", offerTx.TxHash().String())
+	if _, err := server.HandleTx(ctx, offerTx); err != nil {
+		t.Fatalf("\t%s\tContract handle failed : %v", tests.Failed, err)
+	}
+
+	if err := server.HandleTxState(ctx, handlers.ListenerMsgTxStateSafe, *offerTx.TxHash()); err != nil {
+		t.Fatalf("\t%s\tContract offer handle state failed : %v", tests.Failed, err)
+	}
+
+	firstResponse = nil // Request is re-broadcast
+	for {
+		if firstResponse == nil {
+			firstResponse = getResponse()
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		response = getResponse()
+		if response != nil {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	if code := responseType(response); code != actions.CodeContractFormation {
+		t.Fatalf("\t%s\tContract offer response not a formation : %s", tests.Failed, code)
+	}
+
+	t.Logf("\t%s\tContract offer with signed input accepted", tests.Success)
+
+	server.Stop(ctx)
+	wg.Wait()
+}
+
 func contractAmendment(t *testing.T) {
 	ctx := test.Context
 
@@ -919,6 +1094,300 @@ func contractProposalAmendment(t *testing.T) {
 	t.Logf("\t%s\tVerified contract type : %s", tests.Success, ct.ContractType)
 }
 
+func contractAddressChange(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 300)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	if _, err := mockUpFreeze(ctx, t, userKey.Address, 200); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up freeze : %v", tests.Failed, err)
+	}
+
+	// Open vote. The finalizer is run manually after the move.
+	err = mockUpVote(ctx, 0)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+	voteHash, _ := bitcoin.NewHash32(testVoteTxId.Bytes())
+	voteTx, err := test.RPCNode.GetTX(ctx, voteHash)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get vote tx : %v", tests.Failed, err)
+	}
+	voteItx, err := inspector.NewTransactionFromWire(ctx, voteTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create vote itx : %v", tests.Failed, err)
+	}
+	if err := voteItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote vote itx : %v", tests.Failed, err)
+	}
+	test.Scheduler.CancelJob(ctx, listeners.NewVoteFinalizer(a, voteItx, protocol.Timestamp{}))
+
+	// Voting delegation
+	now := protocol.CurrentTimestamp()
+	d := state.Delegation{
+		Delegator: userKey.Address,
+		Proxy:     user2Key.Address,
+		VoteTxId:  &testVoteTxId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := delegation.Save(ctx, test.MasterDB, test.ContractKey.Address, &d); err != nil {
+		t.Fatalf("\t%s\tFailed to save delegation : %v", tests.Failed, err)
+	}
+
+	// Pending transfer
+	pendingTransfer := state.PendingTransfer{
+		TransferTxId: protocol.TxIdFromBytes(bitcoin.DoubleSha256([]byte("pending transfer"))),
+		Timeout:      protocol.NewTimestamp(now.Nano() + 1000000000000),
+	}
+	if err := transfer.Save(ctx, test.MasterDB, test.ContractKey.Address, &pendingTransfer); err != nil {
+		t.Fatalf("\t%s\tFailed to save pending transfer : %v", tests.Failed, err)
+	}
+
+	// Bitcoin held by the contract
+	contractFundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100000, test.ContractKey.Address)
+	test.UTXOs.Add(contractFundingTx, []bitcoin.RawAddress{test.ContractKey.Address})
+
+	if err := holdings.WriteCache(ctx, test.MasterDB); err != nil {
+		t.Fatalf("\t%s\tFailed to write holdings : %v", tests.Failed, err)
+	}
+	oldHoldings, err := holdings.FetchAll(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0])
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch holdings : %v", tests.Failed, err)
+	}
+	responses = nil
+
+	// Build address change transaction
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100012, test.MasterKey.Address)
+
+	changeData := actions.ContractAddressChange{
+		NewContractAddress: test.Contract2Key.Address.Bytes(),
+		Timestamp:          now.Nano(),
+	}
+
+	changeTx := wire.NewMsgTx(2)
+
+	// From master
+	changeTx.TxIn = append(changeTx.TxIn, wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	// To current contract
+	script, _ := test.ContractKey.Address.LockingScript()
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(2000, script))
+
+	// To new contract
+	script, _ = test.Contract2Key.Address.LockingScript()
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(1000, script))
+
+	// Data output
+	script, err = protocol.Serialize(&changeData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize contract address change : %v", tests.Failed, err)
+	}
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(0, script))
+
+	changeItx, err := inspector.NewTransactionFromWire(ctx, changeTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create contract address change itx : %v", tests.Failed, err)
+	}
+
+	err = changeItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote contract address change itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, changeTx)
+
+	err = a.Trigger(ctx, "SEE", changeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept contract address change : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tContract address change accepted", tests.Success)
+
+	// Check the sweep
+	sweepTx := getResponse()
+	if sweepTx == nil {
+		t.Fatalf("\t%s\tSweep tx not created", tests.Failed)
+	}
+	if len(sweepTx.TxIn) != 2 || len(sweepTx.TxOut) != 1 {
+		t.Fatalf("\t%s\tSweep tx has %d inputs and %d outputs, expected 2 and 1", tests.Failed,
+			len(sweepTx.TxIn), len(sweepTx.TxOut))
+	}
+	sweepAddress, err := bitcoin.RawAddressFromLockingScript(sweepTx.TxOut[0].PkScript)
+	if err != nil || !sweepAddress.Equal(test.Contract2Key.Address) {
+		t.Fatalf("\t%s\tSweep tx not to new contract address", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified sweep of %d to new contract address", tests.Success,
+		sweepTx.TxOut[0].Value)
+
+	verifyContractMoved(t, oldHoldings, &pendingTransfer)
+
+	// Resume an interrupted move. It continues from the last completed step.
+	migration, err := contract.FetchMigration(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch migration : %v", tests.Failed, err)
+	}
+	migration.Step = contract.MoveStepTransfers
+	migration.SweepTxId = nil
+	migration.CompletedAt = protocol.Timestamp{}
+	if err := contract.SaveMigration(ctx, test.MasterDB, migration); err != nil {
+		t.Fatalf("\t%s\tFailed to save migration : %v", tests.Failed, err)
+	}
+
+	err = a.Trigger(ctx, "SEE", changeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to resume contract address change : %v", tests.Failed, err)
+	}
+
+	if getResponse() == nil {
+		t.Fatalf("\t%s\tSweep tx not created on resume", tests.Failed)
+	}
+
+	t.Logf("\t%s\tResumed contract address change", tests.Success)
+
+	verifyContractMoved(t, oldHoldings, &pendingTransfer)
+
+	// Vote that was open during the move is finalized for the new contract address.
+	err = a.Trigger(ctx, "END", voteItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to finalize vote : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "G5")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.Contract2Key.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if vt.CompletedAt.Nano() == 0 {
+		t.Fatalf("\t%s\tVote not completed at new contract address", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified vote completed at new contract address", tests.Success)
+}
+
+// verifyContractMoved checks that the contract's state is at the new address.
+func verifyContractMoved(t *testing.T, oldHoldings []*state.Holding,
+	pendingTransfer *state.PendingTransfer) {
+	ctx := test.Context
+
+	migration, err := contract.FetchMigration(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch migration : %v", tests.Failed, err)
+	}
+	if migration.CompletedAt.Nano() == 0 || migration.SweepTxId == nil {
+		t.Fatalf("\t%s\tMigration not complete", tests.Failed)
+	}
+
+	oldContract, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve old contract : %v", tests.Failed, err)
+	}
+	if !oldContract.MovedTo.Equal(test.Contract2Key.Address) {
+		t.Fatalf("\t%s\tOld contract not marked as moved", tests.Failed)
+	}
+
+	newContract, err := contract.Retrieve(ctx, test.MasterDB, test.Contract2Key.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve new contract : %v", tests.Failed, err)
+	}
+	if newContract.ContractName != oldContract.ContractName || !newContract.MovedTo.IsEmpty() {
+		t.Fatalf("\t%s\tNew contract doesn't match", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified contract moved", tests.Success)
+
+	newHoldings, err := holdings.FetchAll(ctx, test.MasterDB, test.Contract2Key.Address,
+		&testAssetCodes[0])
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch new holdings : %v", tests.Failed, err)
+	}
+	if len(newHoldings) != len(oldHoldings) {
+		t.Fatalf("\t%s\tWrong holding count : %d != %d", tests.Failed, len(newHoldings),
+			len(oldHoldings))
+	}
+
+	for _, oldHolding := range oldHoldings {
+		var newHolding *state.Holding
+		for _, h := range newHoldings {
+			if h.Address.Equal(oldHolding.Address) {
+				newHolding = h
+				break
+			}
+		}
+		if newHolding == nil {
+			t.Fatalf("\t%s\tHolding not moved : %x", tests.Failed, oldHolding.Address.Bytes())
+		}
+
+		if newHolding.FinalizedBalance != oldHolding.FinalizedBalance ||
+			newHolding.PendingBalance != oldHolding.PendingBalance {
+			t.Fatalf("\t%s\tHolding balance changed : %d/%d != %d/%d", tests.Failed,
+				newHolding.FinalizedBalance, newHolding.PendingBalance,
+				oldHolding.FinalizedBalance, oldHolding.PendingBalance)
+		}
+
+		if len(newHolding.HoldingStatuses) != len(oldHolding.HoldingStatuses) {
+			t.Fatalf("\t%s\tHolding statuses changed : %d != %d", tests.Failed,
+				len(newHolding.HoldingStatuses), len(oldHolding.HoldingStatuses))
+		}
+		for txid, status := range oldHolding.HoldingStatuses {
+			newStatus, exists := newHolding.HoldingStatuses[txid]
+			if !exists || newStatus.Code != status.Code || newStatus.Amount != status.Amount {
+				t.Fatalf("\t%s\tHolding status changed : %s", tests.Failed, txid.String())
+			}
+		}
+	}
+
+	t.Logf("\t%s\tVerified %d holdings identical", tests.Success, len(oldHoldings))
+
+	if _, err := vote.Fetch(ctx, test.MasterDB, test.Contract2Key.Address,
+		&testVoteTxId); err != nil {
+		t.Fatalf("\t%s\tVote not moved : %v", tests.Failed, err)
+	}
+	if _, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address,
+		&testVoteTxId); err != vote.ErrNotFound {
+		t.Fatalf("\t%s\tVote not removed from old contract", tests.Failed)
+	}
+
+	if _, err := delegation.Fetch(ctx, test.MasterDB, test.Contract2Key.Address, userKey.Address,
+		&testVoteTxId); err != nil {
+		t.Fatalf("\t%s\tDelegation not moved : %v", tests.Failed, err)
+	}
+	if _, err := delegation.Fetch(ctx, test.MasterDB, test.ContractKey.Address, userKey.Address,
+		&testVoteTxId); err != delegation.ErrNotFound {
+		t.Fatalf("\t%s\tDelegation not removed from old contract", tests.Failed)
+	}
+
+	if _, err := transfer.Fetch(ctx, test.MasterDB, test.Contract2Key.Address,
+		pendingTransfer.TransferTxId); err != nil {
+		t.Fatalf("\t%s\tPending transfer not moved : %v", tests.Failed, err)
+	}
+	if _, err := transfer.Fetch(ctx, test.MasterDB, test.ContractKey.Address,
+		pendingTransfer.TransferTxId); err != transfer.ErrNotFound {
+		t.Fatalf("\t%s\tPending transfer not removed from old contract", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified vote, delegation, and pending transfer moved", tests.Success)
+}
+
 func mockUpContract(ctx context.Context, name, agreement string, issuerType string, issuerRole uint32, issuerName string,
 	issuerProposal, holderProposal, permitted, issuer, holder bool) error {
 
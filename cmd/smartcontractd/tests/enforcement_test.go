@@ -3,13 +3,20 @@ package tests
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
+	"github.com/tokenized/smart-contract/internal/authority"
+	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/enforcement"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/internal/platform/tests"
+	"github.com/tokenized/smart-contract/internal/thaw"
 	"github.com/tokenized/smart-contract/internal/transactions"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/wallet"
 	"github.com/tokenized/smart-contract/pkg/wire"
 	"github.com/tokenized/specification/dist/golang/actions"
 	"github.com/tokenized/specification/dist/golang/protocol"
@@ -21,8 +28,13 @@ func TestEnforcement(t *testing.T) {
 
 	t.Run("freeze", freezeOrder)
 	t.Run("authority", freezeAuthorityOrder)
+	t.Run("registry", authorityRegistry)
 	t.Run("thaw", thawOrder)
+	t.Run("expire", freezeExpiration)
+	t.Run("expireMoved", freezeExpirationMoved)
 	t.Run("confiscate", confiscateOrder)
+	t.Run("batch", batchConfiscateOrder)
+	t.Run("batchFreeze", batchFreezeOrder)
 	t.Run("reconcile", reconcileOrder)
 }
 
@@ -216,6 +228,232 @@ func freezeAuthorityOrder(t *testing.T) {
 	}
 }
 
+func authorityRegistry(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 250)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	// Only the administration can change the registry.
+	err = sendAuthorityMessage(t, userKey, 100030, &authority.Payload{
+		Action:    authority.ActionRegister,
+		Name:      "District Court #345",
+		PublicKey: authorityKey.Key.PublicKey(),
+	})
+	if err != node.ErrRejected {
+		t.Fatalf("\t%s\tFailed to reject registry change : %v", tests.Failed, err)
+	}
+	checkResponse(t, "M2")
+
+	t.Logf("\t%s\tRegistry change from non-administration rejected", tests.Success)
+
+	err = sendAuthorityMessage(t, issuerKey, 100031, &authority.Payload{
+		Action:    authority.ActionRegister,
+		Name:      "District Court #345",
+		PublicKey: authorityKey.Key.PublicKey(),
+	})
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept registration : %v", tests.Failed, err)
+	}
+	if getResponse() != nil {
+		t.Fatalf("\t%s\tRegistration created a response", tests.Failed)
+	}
+
+	a, err := authority.Fetch(ctx, test.MasterDB, test.ContractKey.Address,
+		authorityKey.Key.PublicKey())
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch authority : %v", tests.Failed, err)
+	}
+	if a.Name != "District Court #345" {
+		t.Fatalf("\t%s\tAuthority name incorrect : %s", tests.Failed, a.Name)
+	}
+
+	t.Logf("\t%s\tRegistered authority", tests.Success)
+
+	err = sendAuthorityMessage(t, issuerKey, 100032, &authority.Payload{
+		Action: authority.ActionRequireConfiscationSignature,
+	})
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept requirement : %v", tests.Failed, err)
+	}
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+	if !ct.ConfiscationAuthorityRequired {
+		t.Fatalf("\t%s\tConfiscation authority not required", tests.Failed)
+	}
+
+	t.Logf("\t%s\tRequired confiscation authority signature", tests.Success)
+
+	if err := sendConfiscateOrder(t, nil, 100033); err != node.ErrRejected {
+		t.Fatalf("\t%s\tFailed to reject order : %v", tests.Failed, err)
+	}
+	checkResponse(t, "M2")
+
+	t.Logf("\t%s\tUnsigned confiscation order rejected", tests.Success)
+
+	if err := sendConfiscateOrder(t, user2Key, 100034); err != node.ErrRejected {
+		t.Fatalf("\t%s\tFailed to reject order : %v", tests.Failed, err)
+	}
+	checkResponse(t, "M2")
+
+	t.Logf("\t%s\tConfiscation order signed by unregistered authority rejected", tests.Success)
+
+	if err := sendConfiscateOrder(t, authorityKey, 100035); err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+	checkResponse(t, "E4")
+
+	t.Logf("\t%s\tConfiscation order signed by registered authority accepted", tests.Success)
+
+	err = sendAuthorityMessage(t, issuerKey, 100036, &authority.Payload{
+		Action:    authority.ActionRevoke,
+		PublicKey: authorityKey.Key.PublicKey(),
+	})
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept revocation : %v", tests.Failed, err)
+	}
+
+	if err := sendConfiscateOrder(t, authorityKey, 100037); err != node.ErrRejected {
+		t.Fatalf("\t%s\tFailed to reject order : %v", tests.Failed, err)
+	}
+	checkResponse(t, "M2")
+
+	t.Logf("\t%s\tConfiscation order signed by revoked authority rejected", tests.Success)
+}
+
+// sendAuthorityMessage sends an authority registry message to the contract from the key and
+//   returns the result of processing it.
+func sendAuthorityMessage(t *testing.T, key *wallet.Key, fundingValue uint64,
+	payload *authority.Payload) error {
+	ctx := test.Context
+
+	now := protocol.CurrentTimestamp()
+	payload.Timestamp = now.Nano()
+	payloadData, err := payload.Serialize()
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize authority payload : %v", tests.Failed, err)
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, fundingValue, key.Address)
+
+	messageTx := wire.NewMsgTx(2)
+	messageInputHash := fundingTx.TxHash()
+	messageTx.TxIn = append(messageTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(messageInputHash, 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	messageTx.TxOut = append(messageTx.TxOut, wire.NewTxOut(1000, script))
+
+	script, err = protocol.Serialize(&actions.Message{
+		MessageCode:    authority.MessageCode,
+		MessagePayload: payloadData,
+	}, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize message : %v", tests.Failed, err)
+	}
+	messageTx.TxOut = append(messageTx.TxOut, wire.NewTxOut(0, script))
+
+	messageItx, err := inspector.NewTransactionFromWire(ctx, messageTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create message itx : %v", tests.Failed, err)
+	}
+
+	err = messageItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote message itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, messageTx)
+
+	return a.Trigger(ctx, "SEE", messageItx)
+}
+
+// sendConfiscateOrder sends an order confiscating 10 tokens from the user, signed by the authority
+//   key when it isn't nil, and returns the result of processing it.
+func sendConfiscateOrder(t *testing.T, authorityKey *wallet.Key, fundingValue uint64) error {
+	ctx := test.Context
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, fundingValue, issuerKey.Address)
+
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionConfiscation,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		DepositAddress:   issuerKey.Address.Bytes(),
+		Message:          "Court order",
+	}
+
+	orderData.TargetAddresses = append(orderData.TargetAddresses,
+		&actions.TargetAddressField{Address: userKey.Address.Bytes(), Quantity: 10})
+
+	if authorityKey != nil {
+		orderData.AuthorityName = "District Court #345"
+		orderData.AuthorityPublicKey = authorityKey.Key.PublicKey().Bytes()
+		orderData.SignatureAlgorithm = 1
+
+		sigHash, err := protocol.OrderAuthoritySigHash(ctx, test.ContractKey.Address, &orderData)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed generate authority signature hash : %v", tests.Failed, err)
+		}
+
+		sig, err := authorityKey.Key.Sign(sigHash)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to sign authority sig hash : %v", tests.Failed, err)
+		}
+		orderData.OrderSignature = sig.Bytes()
+	}
+
+	// Build order transaction
+	orderTx := wire.NewMsgTx(2)
+
+	orderInputHash := fundingTx.TxHash()
+
+	// From issuer
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
+
+	// To contract
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2500, script))
+
+	// Data output
+	script, err := protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	return a.Trigger(ctx, "SEE", orderItx)
+}
+
 func thawOrder(t *testing.T) {
 	ctx := test.Context
 
@@ -231,25 +469,566 @@ func thawOrder(t *testing.T) {
 	if err != nil {
 		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
 	}
-	err = mockUpHolding(ctx, userKey.Address, 300)
+	err = mockUpHolding(ctx, userKey.Address, 300)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	freezeTxId, err := mockUpFreeze(ctx, t, userKey.Address, 200)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up freeze : %v", tests.Failed, err)
+	}
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100006, issuerKey.Address)
+
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionThaw,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		FreezeTxId:       freezeTxId.Bytes(),
+		Message:          "Court order lifted",
+	}
+
+	// Build order transaction
+	orderTx := wire.NewMsgTx(2)
+
+	orderInputHash := fundingTx.TxHash()
+
+	// From issuer
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
+
+	// To contract
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2000, script))
+
+	// Data output
+	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	err = a.Trigger(ctx, "SEE", orderItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tThaw order accepted", tests.Success)
+
+	// Check the response
+	checkResponse(t, "E3")
+
+	// Check balance status
+	h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address, &testAssetCodes[0], userKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+	}
+
+	balance := holdings.UnfrozenBalance(h, v.Now)
+	if balance != 300 {
+		t.Fatalf("\t%s\tUser unfrozen balance incorrect : %d != %d", tests.Failed, balance, 300)
+	}
+}
+
+func freezeExpiration(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I", 1,
+		"John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 300)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100007, issuerKey.Address)
+
+	now := protocol.CurrentTimestamp()
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionFreeze,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		FreezePeriod:     now.Nano() + uint64(time.Hour),
+		Message:          "Court order",
+	}
+
+	orderData.TargetAddresses = append(orderData.TargetAddresses, &actions.TargetAddressField{
+		Address:  userKey.Address.Bytes(),
+		Quantity: 200,
+	})
+
+	// Build order transaction
+	orderTx := wire.NewMsgTx(2)
+
+	orderInputHash := fundingTx.TxHash()
+
+	// From issuer
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
+
+	// To contract, with funding for the thaw
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(5000, script))
+
+	// Data output
+	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	err = a.Trigger(ctx, "SEE", orderItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tFreeze order accepted", tests.Success)
+
+	freezeTx := checkResponse(t, "E2")
+
+	freezeItx, err := inspector.NewTransactionFromWire(ctx, freezeTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create freeze itx : %v", tests.Failed, err)
+	}
+	if err := freezeItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote freeze itx : %v", tests.Failed, err)
+	}
+
+	freezeTxId := protocol.TxIdFromBytes(freezeItx.Hash[:])
+	pt, err := thaw.Fetch(ctx, test.MasterDB, test.ContractKey.Address, freezeTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch pending thaw : %v", tests.Failed, err)
+	}
+	if pt.Expires.Nano() != orderData.FreezePeriod {
+		t.Fatalf("\t%s\tPending thaw expiration incorrect : %d != %d", tests.Failed,
+			pt.Expires.Nano(), orderData.FreezePeriod)
+	}
+
+	t.Logf("\t%s\tVerified pending thaw", tests.Success)
+
+	// Expire the freeze now instead of waiting for the scheduler.
+	test.Scheduler.CancelJob(ctx, listeners.NewFreezeThawer(a, freezeItx, protocol.Timestamp{}))
+
+	err = a.Trigger(ctx, "END", freezeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to expire freeze : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "E3")
+
+	t.Logf("\t%s\tExpired freeze thawed", tests.Success)
+
+	// Check balance status
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address, &testAssetCodes[0],
+		userKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+	}
+
+	if _, exists := h.HoldingStatuses[*freezeTxId]; exists {
+		t.Fatalf("\t%s\tFreeze holding status not removed", tests.Failed)
+	}
+
+	balance := holdings.UnfrozenBalance(h, v.Now)
+	if balance != 300 {
+		t.Fatalf("\t%s\tUser unfrozen balance incorrect : %d != %d", tests.Failed, balance, 300)
+	}
+
+	if _, err := thaw.Fetch(ctx, test.MasterDB, test.ContractKey.Address,
+		freezeTxId); err != thaw.ErrNotFound {
+		t.Fatalf("\t%s\tPending thaw not removed : %v", tests.Failed, err)
+	}
+
+	// A freeze that was already thawed isn't thawed again.
+	err = a.Trigger(ctx, "END", freezeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to expire thawed freeze : %v", tests.Failed, err)
+	}
+
+	if getResponse() != nil {
+		t.Fatalf("\t%s\tThawed freeze created a response", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified freeze released", tests.Success)
+}
+
+func freezeExpirationMoved(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I", 1,
+		"John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 300)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100007, issuerKey.Address)
+
+	now := protocol.CurrentTimestamp()
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionFreeze,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		FreezePeriod:     now.Nano() + uint64(time.Hour),
+		Message:          "Court order",
+	}
+
+	orderData.TargetAddresses = append(orderData.TargetAddresses, &actions.TargetAddressField{
+		Address:  userKey.Address.Bytes(),
+		Quantity: 200,
+	})
+
+	// Build order transaction
+	orderTx := wire.NewMsgTx(2)
+
+	// From issuer
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	// To contract, with funding for the thaw
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(5000, script))
+
+	// Data output
+	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	err = a.Trigger(ctx, "SEE", orderItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+
+	freezeTx := checkResponse(t, "E2")
+
+	freezeItx, err := inspector.NewTransactionFromWire(ctx, freezeTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create freeze itx : %v", tests.Failed, err)
+	}
+	if err := freezeItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote freeze itx : %v", tests.Failed, err)
+	}
+	freezeTxId := protocol.TxIdFromBytes(freezeItx.Hash[:])
+	test.Scheduler.CancelJob(ctx, listeners.NewFreezeThawer(a, freezeItx, protocol.Timestamp{}))
+
+	t.Logf("\t%s\tTime limited freeze created", tests.Success)
+
+	// Bitcoin held by the contract, including the freeze change that funds the thaw.
+	contractFundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100000, test.ContractKey.Address)
+	test.UTXOs.Add(contractFundingTx, []bitcoin.RawAddress{test.ContractKey.Address})
+	test.UTXOs.Add(freezeTx, []bitcoin.RawAddress{test.ContractKey.Address})
+
+	if err := holdings.WriteCache(ctx, test.MasterDB); err != nil {
+		t.Fatalf("\t%s\tFailed to write holdings : %v", tests.Failed, err)
+	}
+
+	// Move the contract
+	changeFundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100012, test.MasterKey.Address)
+
+	changeData := actions.ContractAddressChange{
+		NewContractAddress: test.Contract2Key.Address.Bytes(),
+		Timestamp:          now.Nano(),
+	}
+
+	changeTx := wire.NewMsgTx(2)
+
+	// From master
+	changeTx.TxIn = append(changeTx.TxIn, wire.NewTxIn(wire.NewOutPoint(changeFundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	// To current contract
+	script, _ = test.ContractKey.Address.LockingScript()
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(2000, script))
+
+	// To new contract
+	script, _ = test.Contract2Key.Address.LockingScript()
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(1000, script))
+
+	// Data output
+	script, err = protocol.Serialize(&changeData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize contract address change : %v", tests.Failed, err)
+	}
+	changeTx.TxOut = append(changeTx.TxOut, wire.NewTxOut(0, script))
+
+	changeItx, err := inspector.NewTransactionFromWire(ctx, changeTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create contract address change itx : %v", tests.Failed, err)
+	}
+
+	err = changeItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote contract address change itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, changeTx)
+
+	err = a.Trigger(ctx, "SEE", changeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept contract address change : %v", tests.Failed, err)
+	}
+
+	sweepTx := getResponse()
+	if sweepTx == nil {
+		t.Fatalf("\t%s\tSweep tx not created", tests.Failed)
+	}
+	freezeHash := freezeTx.TxHash()
+	for _, input := range sweepTx.TxIn {
+		if input.PreviousOutPoint.Hash.Equal(freezeHash) {
+			t.Fatalf("\t%s\tSweep spent the freeze funding for the thaw", tests.Failed)
+		}
+	}
+
+	if _, err := thaw.Fetch(ctx, test.MasterDB, test.Contract2Key.Address,
+		freezeTxId); err != nil {
+		t.Fatalf("\t%s\tPending thaw not moved : %v", tests.Failed, err)
+	}
+	if _, err := thaw.Fetch(ctx, test.MasterDB, test.ContractKey.Address,
+		freezeTxId); err != thaw.ErrNotFound {
+		t.Fatalf("\t%s\tPending thaw not removed from old address : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tContract moved with pending thaw", tests.Success)
+
+	// The freeze expires after the move. It is thawed with the state at the new address.
+	err = a.Trigger(ctx, "END", freezeItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to expire freeze : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "E3")
+
+	v := ctx.Value(node.KeyValues).(*node.Values)
+	h, err := holdings.GetHolding(ctx, test.MasterDB, test.Contract2Key.Address,
+		&testAssetCodes[0], userKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+	}
+
+	if _, exists := h.HoldingStatuses[*freezeTxId]; exists {
+		t.Fatalf("\t%s\tFreeze holding status not removed", tests.Failed)
+	}
+
+	balance := holdings.UnfrozenBalance(h, v.Now)
+	if balance != 300 {
+		t.Fatalf("\t%s\tUser unfrozen balance incorrect : %d != %d", tests.Failed, balance, 300)
+	}
+
+	if _, err := thaw.Fetch(ctx, test.MasterDB, test.Contract2Key.Address,
+		freezeTxId); err != thaw.ErrNotFound {
+		t.Fatalf("\t%s\tPending thaw not removed : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tVerified freeze released at new contract address", tests.Success)
+}
+
+func confiscateOrder(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 250)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100007, issuerKey.Address)
+
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionConfiscation,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		DepositAddress:   issuerKey.Address.Bytes(),
+		Message:          "Court order",
+	}
+
+	orderData.TargetAddresses = append(orderData.TargetAddresses,
+		&actions.TargetAddressField{Address: userKey.Address.Bytes(), Quantity: 50})
+
+	// Build order transaction
+	orderTx := wire.NewMsgTx(2)
+
+	orderInputHash := fundingTx.TxHash()
+
+	// From issuer
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
+
+	// To contract
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2500, script))
+
+	// Data output
+	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	err = a.Trigger(ctx, "SEE", orderItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tConfiscate order accepted", tests.Success)
+
+	// Check the response
+	checkResponse(t, "E4")
+
+	// Check balance status
+	v := ctx.Value(node.KeyValues).(*node.Values)
+
+	issuerHolding, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], issuerKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+	}
+	if issuerHolding.FinalizedBalance != testTokenQty+50 {
+		t.Fatalf("\t%s\tIssuer token balance incorrect : %d != %d", tests.Failed,
+			issuerHolding.FinalizedBalance, testTokenQty+50)
+	}
+	t.Logf("\t%s\tIssuer token balance verified : %d", tests.Success, issuerHolding.FinalizedBalance)
+
+	userHolding, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], userKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+	}
+	if !userKey.Address.Equal(userHolding.Address) {
+		t.Fatalf("\t%s\tFailed to get correct user holding : %x != %x", tests.Failed,
+			userKey.Address.Bytes(), userHolding.Address.Bytes())
+	}
+	if userHolding.FinalizedBalance != 200 {
+		t.Fatalf("\t%s\tUser token balance incorrect : %d/%d != %d : %x", tests.Failed,
+			userHolding.FinalizedBalance, userHolding.PendingBalance, 200, userHolding.Address.Bytes())
+	}
+	t.Logf("\t%s\tUser token balance verified : %d", tests.Success, userHolding.FinalizedBalance)
+}
+
+func batchConfiscateOrder(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+
+	user3Key, err := tests.GenerateKey(test.NodeConfig.Net)
 	if err != nil {
-		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+		t.Fatalf("\t%s\tFailed to generate key : %v", tests.Failed, err)
 	}
-	freezeTxId, err := mockUpFreeze(ctx, t, userKey.Address, 200)
-	if err != nil {
-		t.Fatalf("\t%s\tFailed to mock up freeze : %v", tests.Failed, err)
+
+	targetKeys := []*wallet.Key{userKey, user2Key, user3Key}
+	for _, key := range targetKeys {
+		if err := mockUpHolding(ctx, key.Address, 250); err != nil {
+			t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+		}
 	}
 
-	v := ctx.Value(node.KeyValues).(*node.Values)
+	// Respond to at most two targets at a time.
+	test.NodeConfig.MaxEnforcementTargets = 2
+	defer func() { test.NodeConfig.MaxEnforcementTargets = 0 }()
 
-	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100006, issuerKey.Address)
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100007, issuerKey.Address)
 
 	orderData := actions.Order{
-		ComplianceAction: actions.ComplianceActionThaw,
+		ComplianceAction: actions.ComplianceActionConfiscation,
 		AssetType:        testAssetType,
 		AssetCode:        testAssetCodes[0].Bytes(),
-		FreezeTxId:       freezeTxId.Bytes(),
-		Message:          "Court order lifted",
+		DepositAddress:   issuerKey.Address.Bytes(),
+		Message:          "Court order",
+	}
+
+	for _, key := range targetKeys {
+		orderData.TargetAddresses = append(orderData.TargetAddresses,
+			&actions.TargetAddressField{Address: key.Address.Bytes(), Quantity: 50})
 	}
 
 	// Build order transaction
@@ -260,9 +1039,9 @@ func thawOrder(t *testing.T) {
 	// From issuer
 	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
 
-	// To contract
+	// To contract, with funding for every response
 	script, _ := test.ContractKey.Address.LockingScript()
-	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2000, script))
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(10000, script))
 
 	// Data output
 	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
@@ -288,24 +1067,112 @@ func thawOrder(t *testing.T) {
 		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
 	}
 
-	t.Logf("\t%s\tThaw order accepted", tests.Success)
+	t.Logf("\t%s\tBatch confiscate order accepted", tests.Success)
 
-	// Check the response
-	checkResponse(t, "E3")
+	v := ctx.Value(node.KeyValues).(*node.Values)
 
-	// Check balance status
-	h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address, &testAssetCodes[0], userKey.Address, v.Now)
+	// Every target is debited by the order, before its response.
+	for _, key := range targetKeys {
+		h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+			&testAssetCodes[0], key.Address, v.Now)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		}
+		if h.PendingBalance != 200 {
+			t.Fatalf("\t%s\tUser pending balance incorrect : %d != %d", tests.Failed,
+				h.PendingBalance, 200)
+		}
+	}
+
+	t.Logf("\t%s\tVerified all targets held", tests.Success)
+
+	// Two targets, deposit, contract, fee, and payload. Processing it responds to the remaining
+	//   target.
+	response := processBatchResponse(t, "E4")
+	if len(response.TxOut) != 6 {
+		t.Fatalf("\t%s\tFirst response output count incorrect : %d != %d", tests.Failed,
+			len(response.TxOut), 6)
+	}
+	checkDepositQty(t, response, testTokenQty+100)
+
+	// Only the first response's share is deposited.
+	issuerHolding, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], issuerKey.Address, v.Now)
 	if err != nil {
-		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		t.Fatalf("\t%s\tFailed to get issuer holding : %s", tests.Failed, err)
+	}
+	if issuerHolding.FinalizedBalance != testTokenQty+100 {
+		t.Fatalf("\t%s\tIssuer token balance incorrect : %d != %d", tests.Failed,
+			issuerHolding.FinalizedBalance, testTokenQty+100)
 	}
+	t.Logf("\t%s\tVerified first deposit : %d", tests.Success, issuerHolding.FinalizedBalance)
 
-	balance := holdings.UnfrozenBalance(h, v.Now)
-	if balance != 300 {
-		t.Fatalf("\t%s\tUser unfrozen balance incorrect : %d != %d", tests.Failed, balance, 300)
+	// Drop the second response to simulate a restart before it was sent.
+	if getResponse() == nil {
+		t.Fatalf("\t%s\tSecond response not created", tests.Failed)
+	}
+
+	orderTxId := protocol.TxIdFromBytes(orderItx.Hash[:])
+	batch, err := enforcement.Fetch(ctx, test.MasterDB, test.ContractKey.Address, orderTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch enforcement batch : %v", tests.Failed, err)
+	}
+	if batch.Next != 2 || batch.CompletedAt.Nano() != 0 {
+		t.Fatalf("\t%s\tEnforcement batch progress incorrect : %d", tests.Failed, batch.Next)
+	}
+
+	t.Logf("\t%s\tVerified enforcement batch progress", tests.Success)
+
+	err = a.Trigger(ctx, "END", orderItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to resume enforcement batch : %v", tests.Failed, err)
+	}
+
+	response = checkResponse(t, "E4")
+	if len(response.TxOut) != 5 {
+		t.Fatalf("\t%s\tSecond response output count incorrect : %d != %d", tests.Failed,
+			len(response.TxOut), 5)
+	}
+	checkDepositQty(t, response, testTokenQty+150)
+
+	batch, err = enforcement.Fetch(ctx, test.MasterDB, test.ContractKey.Address, orderTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch enforcement batch : %v", tests.Failed, err)
+	}
+	if batch.CompletedAt.Nano() == 0 || len(batch.ResponseTxIds) != 2 {
+		t.Fatalf("\t%s\tEnforcement batch not completed : %d responses", tests.Failed,
+			len(batch.ResponseTxIds))
+	}
+
+	t.Logf("\t%s\tVerified enforcement batch completed", tests.Success)
+
+	// Check balances
+	issuerHolding, err = holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], issuerKey.Address, v.Now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get issuer holding : %s", tests.Failed, err)
+	}
+	if issuerHolding.FinalizedBalance != testTokenQty+150 {
+		t.Fatalf("\t%s\tIssuer token balance incorrect : %d != %d", tests.Failed,
+			issuerHolding.FinalizedBalance, testTokenQty+150)
+	}
+	t.Logf("\t%s\tIssuer token balance verified : %d", tests.Success, issuerHolding.FinalizedBalance)
+
+	for _, key := range targetKeys {
+		h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+			&testAssetCodes[0], key.Address, v.Now)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		}
+		if h.FinalizedBalance != 200 || len(h.HoldingStatuses) != 0 {
+			t.Fatalf("\t%s\tUser token balance incorrect : %d != %d", tests.Failed,
+				h.FinalizedBalance, 200)
+		}
 	}
+	t.Logf("\t%s\tUser token balances verified", tests.Success)
 }
 
-func confiscateOrder(t *testing.T) {
+func batchFreezeOrder(t *testing.T) {
 	ctx := test.Context
 
 	if err := resetTest(ctx); err != nil {
@@ -320,23 +1187,36 @@ func confiscateOrder(t *testing.T) {
 	if err != nil {
 		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
 	}
-	err = mockUpHolding(ctx, userKey.Address, 250)
+
+	user3Key, err := tests.GenerateKey(test.NodeConfig.Net)
 	if err != nil {
-		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+		t.Fatalf("\t%s\tFailed to generate key : %v", tests.Failed, err)
 	}
 
-	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100007, issuerKey.Address)
+	targetKeys := []*wallet.Key{userKey, user2Key, user3Key}
+	for _, key := range targetKeys {
+		if err := mockUpHolding(ctx, key.Address, 300); err != nil {
+			t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+		}
+	}
+
+	// Respond to at most two targets at a time.
+	test.NodeConfig.MaxEnforcementTargets = 2
+	defer func() { test.NodeConfig.MaxEnforcementTargets = 0 }()
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100008, issuerKey.Address)
 
 	orderData := actions.Order{
-		ComplianceAction: actions.ComplianceActionConfiscation,
+		ComplianceAction: actions.ComplianceActionFreeze,
 		AssetType:        testAssetType,
 		AssetCode:        testAssetCodes[0].Bytes(),
-		DepositAddress:   issuerKey.Address.Bytes(),
 		Message:          "Court order",
 	}
 
-	orderData.TargetAddresses = append(orderData.TargetAddresses,
-		&actions.TargetAddressField{Address: userKey.Address.Bytes(), Quantity: 50})
+	for _, key := range targetKeys {
+		orderData.TargetAddresses = append(orderData.TargetAddresses,
+			&actions.TargetAddressField{Address: key.Address.Bytes(), Quantity: 200})
+	}
 
 	// Build order transaction
 	orderTx := wire.NewMsgTx(2)
@@ -346,9 +1226,9 @@ func confiscateOrder(t *testing.T) {
 	// From issuer
 	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
 
-	// To contract
+	// To contract, with funding for every response
 	script, _ := test.ContractKey.Address.LockingScript()
-	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2500, script))
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(10000, script))
 
 	// Data output
 	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
@@ -374,39 +1254,143 @@ func confiscateOrder(t *testing.T) {
 		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
 	}
 
-	t.Logf("\t%s\tConfiscate order accepted", tests.Success)
-
-	// Check the response
-	checkResponse(t, "E4")
+	t.Logf("\t%s\tBatch freeze order accepted", tests.Success)
 
-	// Check balance status
+	// Every target is held by the order, before its response.
 	v := ctx.Value(node.KeyValues).(*node.Values)
+	checkUnfrozenBalances := func(expected uint64) {
+		for _, key := range targetKeys {
+			h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+				&testAssetCodes[0], key.Address, v.Now)
+			if err != nil {
+				t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+			}
+			if balance := holdings.UnfrozenBalance(h, v.Now); balance != expected {
+				t.Fatalf("\t%s\tUser unfrozen balance incorrect : %d != %d", tests.Failed,
+					balance, expected)
+			}
+		}
+	}
+	checkUnfrozenBalances(100)
 
-	issuerHolding, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
-		&testAssetCodes[0], issuerKey.Address, v.Now)
+	t.Logf("\t%s\tVerified all targets held", tests.Success)
+
+	// Two targets, contract, fee, and payload. Processing it responds to the remaining target.
+	response := processBatchResponse(t, "E2")
+	if len(response.TxOut) != 5 {
+		t.Fatalf("\t%s\tFirst response output count incorrect : %d != %d", tests.Failed,
+			len(response.TxOut), 5)
+	}
+
+	// Drop the second response to simulate a restart before it was sent.
+	if getResponse() == nil {
+		t.Fatalf("\t%s\tSecond response not created", tests.Failed)
+	}
+
+	orderTxId := protocol.TxIdFromBytes(orderItx.Hash[:])
+	batch, err := enforcement.Fetch(ctx, test.MasterDB, test.ContractKey.Address, orderTxId)
 	if err != nil {
-		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		t.Fatalf("\t%s\tFailed to fetch enforcement batch : %v", tests.Failed, err)
 	}
-	if issuerHolding.FinalizedBalance != testTokenQty+50 {
-		t.Fatalf("\t%s\tIssuer token balance incorrect : %d != %d", tests.Failed,
-			issuerHolding.FinalizedBalance, testTokenQty+50)
+	if batch.Next != 2 || batch.CompletedAt.Nano() != 0 {
+		t.Fatalf("\t%s\tEnforcement batch progress incorrect : %d", tests.Failed, batch.Next)
 	}
-	t.Logf("\t%s\tIssuer token balance verified : %d", tests.Success, issuerHolding.FinalizedBalance)
 
-	userHolding, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
-		&testAssetCodes[0], userKey.Address, v.Now)
+	// The remaining target is still held while the batch is interrupted.
+	checkUnfrozenBalances(100)
+
+	t.Logf("\t%s\tVerified enforcement batch progress", tests.Success)
+
+	err = a.Trigger(ctx, "END", orderItx)
 	if err != nil {
-		t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		t.Fatalf("\t%s\tFailed to resume enforcement batch : %v", tests.Failed, err)
 	}
-	if !userKey.Address.Equal(userHolding.Address) {
-		t.Fatalf("\t%s\tFailed to get correct user holding : %x != %x", tests.Failed,
-			userKey.Address.Bytes(), userHolding.Address.Bytes())
+
+	response = checkResponse(t, "E2")
+	if len(response.TxOut) != 4 {
+		t.Fatalf("\t%s\tSecond response output count incorrect : %d != %d", tests.Failed,
+			len(response.TxOut), 4)
 	}
-	if userHolding.FinalizedBalance != 200 {
-		t.Fatalf("\t%s\tUser token balance incorrect : %d/%d != %d : %x", tests.Failed,
-			userHolding.FinalizedBalance, userHolding.PendingBalance, 200, userHolding.Address.Bytes())
+
+	batch, err = enforcement.Fetch(ctx, test.MasterDB, test.ContractKey.Address, orderTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch enforcement batch : %v", tests.Failed, err)
 	}
-	t.Logf("\t%s\tUser token balance verified : %d", tests.Success, userHolding.FinalizedBalance)
+	if batch.CompletedAt.Nano() == 0 || len(batch.ResponseTxIds) != 2 {
+		t.Fatalf("\t%s\tEnforcement batch not completed : %d responses", tests.Failed,
+			len(batch.ResponseTxIds))
+	}
+
+	t.Logf("\t%s\tVerified enforcement batch completed", tests.Success)
+
+	// Every order hold was replaced by the freeze of its response.
+	checkUnfrozenBalances(100)
+	for _, key := range targetKeys {
+		h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+			&testAssetCodes[0], key.Address, v.Now)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to get user holding : %s", tests.Failed, err)
+		}
+		if _, exists := h.HoldingStatuses[*orderTxId]; exists || len(h.HoldingStatuses) != 1 {
+			t.Fatalf("\t%s\tOrder hold not replaced by freeze : %d statuses", tests.Failed,
+				len(h.HoldingStatuses))
+		}
+	}
+	t.Logf("\t%s\tUser freezes verified", tests.Success)
+}
+
+// checkDepositQty fails the test if a confiscation response doesn't have the deposit quantity.
+func checkDepositQty(t testing.TB, response *wire.MsgTx, expected uint64) {
+	for _, output := range response.TxOut {
+		action, err := protocol.Deserialize(output.PkScript, test.NodeConfig.IsTest)
+		if err != nil {
+			continue
+		}
+		confiscation, ok := action.(*actions.Confiscation)
+		if !ok {
+			break
+		}
+		if confiscation.DepositQty != expected {
+			t.Fatalf("\t%s\tDeposit quantity incorrect : %d != %d", tests.Failed,
+				confiscation.DepositQty, expected)
+		}
+		t.Logf("\t%s\tVerified deposit quantity : %d", tests.Success, confiscation.DepositQty)
+		return
+	}
+	t.Fatalf("\t%s\tResponse is not a confiscation", tests.Failed)
+}
+
+// processBatchResponse processes a response of an enforcement batch, which may create the next
+//   response.
+func processBatchResponse(t testing.TB, responseCode string) *wire.MsgTx {
+	ctx := test.Context
+
+	response := getResponse()
+	if response == nil {
+		t.Fatalf("\t%s\t%s Response not created", tests.Failed, responseCode)
+	}
+
+	if code := responseType(response); code != responseCode {
+		t.Fatalf("\t%s\tResponse is the wrong type : %s != %s", tests.Failed, code, responseCode)
+	}
+
+	responseItx, err := inspector.NewTransactionFromWire(ctx, response, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create response itx : %v", tests.Failed, err)
+	}
+
+	if err := responseItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote response itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, response)
+
+	if err := a.Trigger(ctx, "SEE", responseItx); err != nil {
+		t.Fatalf("\t%s\tFailed to process response : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tResponse processed : %s", tests.Success, responseCode)
+	return response
 }
 
 func reconcileOrder(t *testing.T) {
@@ -530,6 +1514,72 @@ func reconcileOrder(t *testing.T) {
 			userHolding.FinalizedBalance, 75)
 	}
 	t.Logf("\t%s\tVerified user balance : %d", tests.Success, userHolding.FinalizedBalance)
+
+	// Check the audit record
+	orderTxId := protocol.TxIdFromBytes(orderItx.Hash[:])
+	record, err := enforcement.FetchReconciliation(ctx, test.MasterDB, test.ContractKey.Address,
+		orderTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to fetch reconciliation record : %v", tests.Failed, err)
+	}
+	if record.CompletedAt.Nano() == 0 || record.ResponseTxId == nil {
+		t.Fatalf("\t%s\tReconciliation record not completed", tests.Failed)
+	}
+	if record.Message != orderData.Message {
+		t.Fatalf("\t%s\tReconciliation record message incorrect : %s", tests.Failed,
+			record.Message)
+	}
+	if len(record.Holdings) != 1 {
+		t.Fatalf("\t%s\tReconciliation record holding count incorrect : %d != %d", tests.Failed,
+			len(record.Holdings), 1)
+	}
+	rh := record.Holdings[0]
+	if !rh.Address.Equal(userKey.Address) || rh.Quantity != 75 || rh.BalanceBefore != 150 ||
+		rh.BalanceAfter != 75 || rh.Dispersion != 75000 {
+		t.Fatalf("\t%s\tReconciliation record holding incorrect : %+v", tests.Failed, rh)
+	}
+	t.Logf("\t%s\tVerified reconciliation record", tests.Success)
+
+	// Dispersions must be funded by the order.
+	fundingTx = tests.MockFundingTx(ctx, test.RPCNode, 100009, issuerKey.Address)
+
+	orderTx = wire.NewMsgTx(2)
+	orderInputHash = fundingTx.TxHash()
+	orderTx.TxIn = append(orderTx.TxIn, wire.NewTxIn(wire.NewOutPoint(orderInputHash, 0), make([]byte, 130)))
+
+	script, _ = test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(50000, script))
+
+	script, err = protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err = inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	err = orderItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	err = a.Trigger(ctx, "SEE", orderItx)
+	if err != node.ErrRejected {
+		t.Fatalf("\t%s\tFailed to reject underfunded order : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "M2")
+
+	if _, err := enforcement.FetchReconciliation(ctx, test.MasterDB, test.ContractKey.Address,
+		protocol.TxIdFromBytes(orderItx.Hash[:])); err != enforcement.ErrReconciliationNotFound {
+		t.Fatalf("\t%s\tRejected order created reconciliation record : %v", tests.Failed, err)
+	}
+	t.Logf("\t%s\tUnderfunded reconcile order rejected", tests.Success)
 }
 
 func mockUpFreeze(ctx context.Context, t *testing.T, address bitcoin.RawAddress, quantity uint64) (*protocol.TxId, error) {
@@ -2,9 +2,13 @@ package tests
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/delegation"
+	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/internal/platform/state"
 	"github.com/tokenized/smart-contract/internal/platform/tests"
@@ -12,6 +16,7 @@ import (
 	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/wallet"
 	"github.com/tokenized/smart-contract/pkg/wire"
 
 	"github.com/tokenized/specification/dist/golang/actions"
@@ -28,6 +33,17 @@ func TestGovernance(t *testing.T) {
 	t.Run("result", voteResult)
 	t.Run("relativeResult", voteResultRelative)
 	t.Run("absoluteResult", voteResultAbsolute)
+	t.Run("autoApply", autoApplyAmendments)
+	t.Run("autoApplyFunding", autoApplyFunding)
+	t.Run("noQuorum", voteResultNoQuorum)
+	t.Run("quorumRequirements", quorumRequirements)
+	t.Run("delegatedBallot", delegatedBallot)
+	t.Run("ballotBalanceChanged", ballotBalanceChanged)
+	t.Run("recastBallot", recastBallot)
+	t.Run("revokeBallot", revokeBallot)
+	t.Run("recastAfterTransfer", recastAfterTransfer)
+	t.Run("voteBalanceLock", voteBalanceLock)
+	t.Run("voteLockedConfiscation", voteLockedConfiscation)
 }
 
 func holderProposal(t *testing.T) {
@@ -593,6 +609,1094 @@ func voteResultAbsolute(t *testing.T) {
 	t.Logf("\t%s\tVerified result : \"%s\"", tests.Success, vt.Result)
 }
 
+func voteResultNoQuorum(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 250)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	// Require half of the tokens to vote.
+	err = mockUpSupportingDoc(ctx, test.ContractKey.Address, contract.VotingRequirementsDocument,
+		`[{"QuorumPercentage": 50}]`)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up voting requirements : %v", tests.Failed, err)
+	}
+
+	err = mockUpVote(ctx, 0)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	err = mockUpBallot(ctx, userKey.Address, 250, "A")
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up ballot : %v", tests.Failed, err)
+	}
+
+	// Wait for vote expiration
+	time.Sleep(time.Second)
+
+	// Check the response
+	checkResponse(t, "G5")
+
+	// Verify result
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if vt.CompletedAt.Nano() == 0 {
+		t.Fatalf("\t%s\tVote not completed", tests.Failed)
+	}
+
+	if vt.OptionTally[0] != uint64(250) {
+		t.Fatalf("\t%s\tVote option tally 0 incorrect : %d != 250", tests.Failed, vt.OptionTally[0])
+	}
+
+	t.Logf("\t%s\tVerified option tally 0 : %d", tests.Success, vt.OptionTally[0])
+
+	// No options pass without quorum.
+	if len(vt.Result) > 0 {
+		t.Fatalf("\t%s\tVote result incorrect : \"%s\" != \"\"", tests.Failed, vt.Result)
+	}
+
+	t.Logf("\t%s\tVerified result : \"%s\"", tests.Success, vt.Result)
+}
+
+// quorumRequirements verifies quorums of large token quantities and that requirements are read
+//   from the contract terms and validated when the terms are amended.
+func quorumRequirements(t *testing.T) {
+	ctx := test.Context
+
+	vt := &state.Vote{
+		TokenQty:    math.MaxUint64,
+		Requirement: &state.VotingRequirement{QuorumPercentage: 50},
+	}
+	if !vote.MeetsRequirement(vt, math.MaxUint64/2+1) {
+		t.Fatalf("\t%s\tQuorum of large quantity not met", tests.Failed)
+	}
+	if vote.MeetsRequirement(vt, math.MaxUint64/4) {
+		t.Fatalf("\t%s\tQuorum of large quantity met", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified quorum of large quantity", tests.Success)
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, true, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+
+	// Require a quorum for the second voting system.
+	err = mockUpSupportingDoc(ctx, test.ContractKey.Address, contract.VotingRequirementsDocument,
+		`[null, {"QuorumPercentage": 60}]`)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up voting requirements : %v", tests.Failed, err)
+	}
+
+	checkRequirement := func(index uint32, quorum uint32) {
+		ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+		}
+
+		for i := range ct.VotingSystems {
+			requirement, err := contract.VotingRequirement(ct, uint32(i))
+			if err != nil {
+				t.Fatalf("\t%s\tFailed to get voting requirement : %v", tests.Failed, err)
+			}
+			if uint32(i) != index {
+				if requirement != nil {
+					t.Fatalf("\t%s\tVoting system %d has a requirement", tests.Failed, i)
+				}
+				continue
+			}
+			if requirement == nil || requirement.QuorumPercentage != quorum {
+				t.Fatalf("\t%s\tVoting system %d requirement wrong : %+v", tests.Failed, i,
+					requirement)
+			}
+		}
+	}
+
+	checkRequirement(1, 60)
+	t.Logf("\t%s\tRequirement read from contract terms", tests.Success)
+
+	// Invalid requirements are rejected.
+	invalid := []string{
+		`{"QuorumPercentage": 60}`,
+		`[null, {"QuorumPercentage": 101}]`,
+		`[null, null, {"QuorumPercentage": 60}]`,
+	}
+	for i, contents := range invalid {
+		if err := amendVotingRequirements(t, uint64(100020+i), contents); err != node.ErrRejected {
+			t.Fatalf("\t%s\tFailed to reject amendment : %v", tests.Failed, err)
+		}
+		checkResponse(t, "M2")
+		checkRequirement(1, 60)
+	}
+	t.Logf("\t%s\tInvalid requirement amendments rejected", tests.Success)
+
+	if err := amendVotingRequirements(t, 100030, `[{"QuorumPercentage": 40}]`); err != nil {
+		t.Fatalf("\t%s\tFailed to accept amendment : %v", tests.Failed, err)
+	}
+	checkResponse(t, "C2")
+	checkRequirement(0, 40)
+	t.Logf("\t%s\tRequirement amended", tests.Success)
+}
+
+// amendVotingRequirements sends an amendment of the contents of the test contract's voting
+//   requirements document and returns the result of handling it.
+func amendVotingRequirements(t *testing.T, funding uint64, contents string) error {
+	ctx := test.Context
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, funding, issuerKey.Address)
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+
+	amendmentData := actions.ContractAmendment{
+		ContractRevision: ct.Revision,
+	}
+
+	fip := actions.FieldIndexPath{
+		actions.ContractFieldSupportingDocs,
+		0, // Voting requirements document
+		actions.DocumentFieldContents,
+	}
+	fipBytes, _ := fip.Bytes()
+	amendmentData.Amendments = append(amendmentData.Amendments, &actions.AmendmentField{
+		FieldIndexPath: fipBytes,
+		Data:           []byte(contents),
+	})
+
+	amendmentTx := wire.NewMsgTx(2)
+	amendmentTx.TxIn = append(amendmentTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	amendmentTx.TxOut = append(amendmentTx.TxOut, wire.NewTxOut(2000, script))
+
+	script, err = protocol.Serialize(&amendmentData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize contract amendment : %v", tests.Failed, err)
+	}
+	amendmentTx.TxOut = append(amendmentTx.TxOut, wire.NewTxOut(0, script))
+
+	amendmentItx, err := inspector.NewTransactionFromWire(ctx, amendmentTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create contract amendment itx : %v", tests.Failed, err)
+	}
+
+	if err := amendmentItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote contract amendment itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, amendmentTx)
+
+	return a.Trigger(ctx, "SEE", amendmentItx)
+}
+
+// autoApplyAmendments verifies a contract that opted in applies the amendments of a passed
+//   proposal that funds them, and a contract that didn't leaves them for an amendment request.
+func autoApplyAmendments(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, true)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 150)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	err = mockUpContract2(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, true)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract 2 : %v", tests.Failed, err)
+	}
+	err = mockUpAsset2(ctx, true, true, true, 1000, &sampleAssetPayload, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset 2 : %v", tests.Failed, err)
+	}
+	err = mockUpHolding2(ctx, userKey.Address, 150)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding 2 : %v", tests.Failed, err)
+	}
+
+	// Only the first contract opts in.
+	err = mockUpSupportingDoc(ctx, test.ContractKey.Address, contract.AutoApplyAmendmentsDocument,
+		"true")
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up auto apply : %v", tests.Failed, err)
+	}
+	err = mockUpSupportingDoc(ctx, test.Contract2Key.Address, contract.AutoApplyAmendmentsDocument,
+		"false")
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up auto apply 2 : %v", tests.Failed, err)
+	}
+
+	proposalTx, voteTxId := passContractNameProposal(t, test.ContractKey, "Test Name 2", 3000)
+
+	// Processing the result posts the amendments.
+	formationTx := checkResponse(t, "C2")
+
+	hash := proposalTx.TxHash()
+	if !formationTx.TxIn[0].PreviousOutPoint.Hash.Equal(hash) ||
+		formationTx.TxIn[0].PreviousOutPoint.Index != 2 {
+		t.Fatalf("\t%s\tAmendments not funded by proposal", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified amendments funded by proposal", tests.Success)
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+
+	if ct.ContractName != "Test Name 2" {
+		t.Fatalf("\t%s\tContract name incorrect : \"%s\" != \"%s\"", tests.Failed,
+			ct.ContractName, "Test Name 2")
+	}
+
+	t.Logf("\t%s\tVerified contract name : %s", tests.Success, ct.ContractName)
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, voteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	formationHash := formationTx.TxHash()
+	if !vt.AppliedTxId.Equal(*protocol.TxIdFromBytes(formationHash[:])) {
+		t.Fatalf("\t%s\tVote applied tx incorrect : %s", tests.Failed, vt.AppliedTxId.String())
+	}
+
+	t.Logf("\t%s\tVerified vote applied : %s", tests.Success, vt.AppliedTxId.String())
+
+	// The second contract doesn't apply the amendments itself.
+	checkNotApplied(t, test.Contract2Key, "Test Name 2", 3000, "Test Contract")
+	t.Logf("\t%s\tVerified contract that didn't opt in left amendments", tests.Success)
+}
+
+// autoApplyFunding verifies a contract that opted in doesn't apply the amendments of a passed
+//   proposal that doesn't fund them.
+func autoApplyFunding(t *testing.T) {
+	ctx := test.Context
+
+	fundings := []struct {
+		name  string
+		value uint64
+	}{
+		{name: "missing", value: 0},
+		{name: "too low", value: 1000},
+	}
+
+	for _, funding := range fundings {
+		if err := resetTest(ctx); err != nil {
+			t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+		}
+		err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.",
+			"I", 1, "John Bitcoin", true, true, false, false, true)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+		}
+		err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, false, false, false)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+		}
+		err = mockUpHolding(ctx, userKey.Address, 150)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+		}
+		err = mockUpSupportingDoc(ctx, test.ContractKey.Address,
+			contract.AutoApplyAmendmentsDocument, "true")
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to mock up auto apply : %v", tests.Failed, err)
+		}
+
+		checkNotApplied(t, test.ContractKey, "Test Name 2", funding.value, "Test Contract")
+		t.Logf("\t%s\tVerified amendments with %s funding not applied", tests.Success,
+			funding.name)
+	}
+}
+
+// checkNotApplied passes a contract name proposal and verifies its amendments aren't applied.
+func checkNotApplied(t *testing.T, contractKey *wallet.Key, name string, amendmentFunding uint64,
+	currentName string) {
+	ctx := test.Context
+
+	_, voteTxId := passContractNameProposal(t, contractKey, name, amendmentFunding)
+
+	if getResponse() != nil {
+		t.Fatalf("\t%s\tAmendments applied", tests.Failed)
+	}
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, contractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+
+	if ct.ContractName != currentName {
+		t.Fatalf("\t%s\tContract name incorrect : \"%s\" != \"%s\"", tests.Failed,
+			ct.ContractName, currentName)
+	}
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, contractKey.Address, voteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if !vt.AppliedTxId.IsZero() {
+		t.Fatalf("\t%s\tVote applied : %s", tests.Failed, vt.AppliedTxId.String())
+	}
+}
+
+// passContractNameProposal creates a proposal to change the contract name, votes it through, and
+//   processes the result. The proposal's third output funds the amendments unless amendmentFunding
+//   is zero. Returns the proposal tx and the vote's txid.
+func passContractNameProposal(t *testing.T, contractKey *wallet.Key, name string,
+	amendmentFunding uint64) (*wire.MsgTx, *protocol.TxId) {
+	ctx := test.Context
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100009, userKey.Address)
+
+	now := protocol.CurrentTimestamp()
+
+	proposalData := actions.Proposal{
+		Type:                1,
+		VoteSystem:          0,
+		VoteOptions:         "AB",
+		VoteMax:             1,
+		ProposalDescription: "Change contract name",
+		VoteCutOffTimestamp: now.Nano() + 500000000,
+	}
+
+	fip := actions.FieldIndexPath{actions.ContractFieldContractName}
+	fipBytes, _ := fip.Bytes()
+	proposalData.ProposedAmendments = append(proposalData.ProposedAmendments, &actions.AmendmentField{
+		FieldIndexPath: fipBytes,
+		Data:           []byte(name),
+	})
+
+	// Build proposal transaction
+	proposalTx := wire.NewMsgTx(2)
+
+	proposalInputHash := fundingTx.TxHash()
+
+	// From user
+	proposalTx.TxIn = append(proposalTx.TxIn, wire.NewTxIn(wire.NewOutPoint(proposalInputHash, 0), make([]byte, 130)))
+
+	// To contract (for vote response)
+	script, _ := contractKey.Address.LockingScript()
+	proposalTx.TxOut = append(proposalTx.TxOut, wire.NewTxOut(52000, script))
+
+	// To contract (second output to fund result)
+	proposalTx.TxOut = append(proposalTx.TxOut, wire.NewTxOut(3000, script))
+
+	// To contract (third output to fund amendments)
+	if amendmentFunding > 0 {
+		proposalTx.TxOut = append(proposalTx.TxOut, wire.NewTxOut(amendmentFunding, script))
+	}
+
+	// Data output
+	script, err := protocol.Serialize(&proposalData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize proposal : %v", tests.Failed, err)
+	}
+	proposalTx.TxOut = append(proposalTx.TxOut, wire.NewTxOut(0, script))
+
+	proposalItx, err := inspector.NewTransactionFromWire(ctx, proposalTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create proposal itx : %v", tests.Failed, err)
+	}
+
+	err = proposalItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote proposal itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, proposalTx)
+
+	err = a.Trigger(ctx, "SEE", proposalItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept proposal : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tProposal accepted", tests.Success)
+
+	voteTx := checkResponse(t, "G2")
+	voteHash := voteTx.TxHash()
+	voteTxId := protocol.TxIdFromBytes(voteHash[:])
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, contractKey.Address, voteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	vt.Ballots = append(vt.Ballots, &state.Ballot{
+		Address:   userKey.Address,
+		Vote:      "A",
+		Quantity:  150,
+		Timestamp: protocol.CurrentTimestamp(),
+	})
+
+	if err := vote.Save(ctx, test.MasterDB, contractKey.Address, vt); err != nil {
+		t.Fatalf("\t%s\tFailed to save ballot : %v", tests.Failed, err)
+	}
+
+	// Wait for vote expiration
+	var resultTx *wire.MsgTx
+	for i := 0; i < 100 && resultTx == nil; i++ {
+		time.Sleep(50 * time.Millisecond)
+		resultTx = getResponse()
+	}
+	if resultTx == nil || responseType(resultTx) != "G5" {
+		t.Fatalf("\t%s\tResult not created", tests.Failed)
+	}
+
+	resultItx, err := inspector.NewTransactionFromWire(ctx, resultTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create result itx : %v", tests.Failed, err)
+	}
+
+	err = resultItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote result itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, resultTx)
+
+	err = a.Trigger(ctx, "SEE", resultItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to process result : %v", tests.Failed, err)
+	}
+
+	t.Logf("\t%s\tResult processed", tests.Success)
+	return proposalTx, voteTxId
+}
+
+// delegatedBallot tests a proxy ballot carrying a delegated balance.
+func delegatedBallot(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, true)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 250)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, user2Key.Address, 100)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	err = mockUpProposal(ctx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up proposal : %v", tests.Failed, err)
+	}
+
+	// Delegate user's voting power to user 2
+	now := protocol.CurrentTimestamp()
+	payload := delegation.Payload{
+		Proxy:     user2Key.Address,
+		Timestamp: now.Nano(),
+	}
+	payloadData, err := payload.Serialize()
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize delegation : %v", tests.Failed, err)
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100021, userKey.Address)
+
+	messageTx := wire.NewMsgTx(2)
+	messageInputHash := fundingTx.TxHash()
+	messageTx.TxIn = append(messageTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(messageInputHash, 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	messageTx.TxOut = append(messageTx.TxOut, wire.NewTxOut(1000, script))
+
+	script, err = protocol.Serialize(&actions.Message{
+		MessageCode:    delegation.MessageCode,
+		MessagePayload: payloadData,
+	}, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize message : %v", tests.Failed, err)
+	}
+	messageTx.TxOut = append(messageTx.TxOut, wire.NewTxOut(0, script))
+
+	messageItx, err := inspector.NewTransactionFromWire(ctx, messageTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create message itx : %v", tests.Failed, err)
+	}
+
+	err = messageItx.Promote(ctx, test.RPCNode)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to promote message itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, messageTx)
+
+	err = a.Trigger(ctx, "SEE", messageItx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to accept delegation : %v", tests.Failed, err)
+	}
+
+	if len(responses) > 0 {
+		t.Fatalf("\t%s\tDelegation created a response", tests.Failed)
+	}
+
+	proxy, err := delegation.Proxy(ctx, test.MasterDB, test.ContractKey.Address, userKey.Address,
+		&testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve proxy : %v", tests.Failed, err)
+	}
+	if !proxy.Equal(user2Key.Address) {
+		t.Fatalf("\t%s\tWrong proxy : %x", tests.Failed, proxy.Bytes())
+	}
+	t.Logf("\t%s\tVerified delegation", tests.Success)
+
+	// Proxy ballot carries both balances
+	if err := castBallot(ctx, user2Key, 100022, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept proxy ballot : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "G4")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if len(vt.Ballots) != 1 || vt.Ballots[0].Quantity != 350 {
+		t.Fatalf("\t%s\tWrong proxy ballot", tests.Failed)
+	}
+	t.Logf("\t%s\tVerified proxy ballot quantity : %d", tests.Success, vt.Ballots[0].Quantity)
+
+	shares := vt.Ballots[0].Shares
+	if len(shares) != 2 || !shares[0].Address.Equal(user2Key.Address) ||
+		shares[0].Quantity != 100 || !shares[1].Address.Equal(userKey.Address) ||
+		shares[1].Quantity != 250 {
+		t.Fatalf("\t%s\tWrong proxy ballot shares", tests.Failed)
+	}
+	t.Logf("\t%s\tVerified proxy ballot shares", tests.Success)
+
+	// Delegator's ballot is rejected because its balance was already counted
+	if err := castBallot(ctx, userKey, 100023, "B"); err == nil {
+		t.Fatalf("\t%s\tFailed to reject delegator ballot", tests.Failed)
+	}
+
+	response := checkResponse(t, "M2")
+
+	rejectCode := uint32(0)
+	for _, output := range response.TxOut {
+		action, err := protocol.Deserialize(output.PkScript, test.NodeConfig.IsTest)
+		if err != nil {
+			continue
+		}
+		if rejection, ok := action.(*actions.Rejection); ok {
+			rejectCode = rejection.RejectionCode
+		}
+	}
+	if rejectCode != actions.RejectionsBallotAlreadyCounted {
+		t.Fatalf("\t%s\tWrong reject code : %d", tests.Failed, rejectCode)
+	}
+	t.Logf("\t%s\tVerified delegator ballot rejected", tests.Success)
+}
+
+// ballotBalanceChanged tests that a ballot counts the balances found when it was accepted, even
+//   if they change before the response is processed.
+func ballotBalanceChanged(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, true)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up contract : %v", tests.Failed, err)
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, false, false, false)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up asset : %v", tests.Failed, err)
+	}
+	err = mockUpHolding(ctx, userKey.Address, 250)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	err = mockUpProposal(ctx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up proposal : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100022, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+
+	// Balance changes before the response is processed.
+	err = mockUpHolding(ctx, userKey.Address, 100)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	checkResponse(t, "G4")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if len(vt.Ballots) != 1 || vt.Ballots[0].Quantity != 250 {
+		t.Fatalf("\t%s\tWrong ballot", tests.Failed)
+	}
+
+	shares := vt.Ballots[0].Shares
+	if len(shares) != 1 || !shares[0].Address.Equal(userKey.Address) ||
+		shares[0].Quantity != 250 {
+		t.Fatalf("\t%s\tBallot shares don't match accepted ballot : %+v", tests.Failed, shares)
+	}
+
+	if len(vt.PendingBallots) != 0 {
+		t.Fatalf("\t%s\tPending ballot not removed", tests.Failed)
+	}
+
+	t.Logf("\t%s\tVerified ballot counted balances found when accepted", tests.Success)
+}
+
+// recastBallot tests replacing a ballot while the vote is open.
+func recastBallot(t *testing.T) {
+	ctx := test.Context
+
+	if err := mockUpBallotVote(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100031, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	if err := castBallot(ctx, userKey, 100032, "B"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept recast ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if len(vt.Ballots) != 1 || vt.Ballots[0].Vote != "B" || vt.Ballots[0].Quantity != 250 {
+		t.Fatalf("\t%s\tWrong ballots after recast", tests.Failed)
+	}
+	t.Logf("\t%s\tVerified recast ballot : %s", tests.Success, vt.Ballots[0].Vote)
+
+	if len(vt.BallotHistory) != 2 || vt.BallotHistory[0].Vote != "A" ||
+		vt.BallotHistory[1].Vote != "B" {
+		t.Fatalf("\t%s\tWrong ballot history after recast", tests.Failed)
+	}
+	t.Logf("\t%s\tVerified ballot history", tests.Success)
+
+	checkBallotTally(ctx, t, []uint64{0, 250})
+}
+
+// revokeBallot tests revoking a ballot while the vote is open.
+func revokeBallot(t *testing.T) {
+	ctx := test.Context
+
+	if err := mockUpBallotVote(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	// Revoking without a ballot is rejected
+	if err := castBallot(ctx, userKey, 100041, ""); err == nil {
+		t.Fatalf("\t%s\tFailed to reject revocation without ballot", tests.Failed)
+	}
+	checkResponse(t, "M2")
+
+	if err := castBallot(ctx, userKey, 100042, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	if err := castBallot(ctx, userKey, 100043, ""); err != nil {
+		t.Fatalf("\t%s\tFailed to accept revocation : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if len(vt.Ballots) != 0 {
+		t.Fatalf("\t%s\tBallot not revoked", tests.Failed)
+	}
+	if len(vt.BallotHistory) != 2 || len(vt.BallotHistory[1].Vote) != 0 {
+		t.Fatalf("\t%s\tWrong ballot history after revocation", tests.Failed)
+	}
+	t.Logf("\t%s\tVerified ballot revoked", tests.Success)
+
+	checkBallotTally(ctx, t, []uint64{0, 0})
+
+	// Voting again after revoking is accepted
+	if err := castBallot(ctx, userKey, 100044, "B"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot after revocation : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	checkBallotTally(ctx, t, []uint64{0, 250})
+}
+
+// recastAfterTransfer tests that a recast ballot carries the holder's balance at the time of the
+//   recast.
+func recastAfterTransfer(t *testing.T) {
+	ctx := test.Context
+
+	if err := mockUpBallotVote(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100051, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	// User sends 100 tokens to user 2
+	if err := mockUpHolding(ctx, userKey.Address, 150); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+	if err := mockUpHolding(ctx, user2Key.Address, 100); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up holding : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100052, "B"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept recast ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	if len(vt.Ballots) != 1 || vt.Ballots[0].Quantity != 150 {
+		t.Fatalf("\t%s\tWrong ballot after transfer", tests.Failed)
+	}
+	if vt.BallotHistory[0].Quantity != 250 {
+		t.Fatalf("\t%s\tBallot history changed : %d", tests.Failed,
+			vt.BallotHistory[0].Quantity)
+	}
+	t.Logf("\t%s\tVerified recast quantity : %d", tests.Success, vt.Ballots[0].Quantity)
+
+	checkBallotTally(ctx, t, []uint64{0, 150})
+}
+
+// voteBalanceLock tests that balances counted in a ballot can't be transferred while the vote
+//   is open.
+func voteBalanceLock(t *testing.T) {
+	ctx := test.Context
+
+	test.NodeConfig.VoteBalanceLock = true
+	defer func() { test.NodeConfig.VoteBalanceLock = false }()
+
+	if err := mockUpBallotVote(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100061, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	now := protocol.CurrentTimestamp()
+	h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], userKey.Address, now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get holding : %v", tests.Failed, err)
+	}
+	if locked := holdings.VoteLockedBalance(h, now); locked != 250 {
+		t.Fatalf("\t%s\tWrong vote locked balance : %d != %d", tests.Failed, locked, 250)
+	}
+	t.Logf("\t%s\tVerified vote locked balance : %d", tests.Success, 250)
+
+	// Transfer of voted tokens is rejected
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100062, userKey.Address)
+
+	transferData := actions.Transfer{
+		Assets: []*actions.AssetTransferField{
+			{
+				ContractIndex: 0,
+				AssetType:     testAssetType,
+				AssetCode:     testAssetCodes[0].Bytes(),
+				AssetSenders: []*actions.QuantityIndexField{
+					{Index: 0, Quantity: 100},
+				},
+				AssetReceivers: []*actions.AssetReceiverField{
+					{Address: user2Key.Address.Bytes(), Quantity: 100},
+				},
+			},
+		},
+	}
+
+	transferTx := wire.NewMsgTx(2)
+	transferInputHash := fundingTx.TxHash()
+	transferTx.TxIn = append(transferTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(transferInputHash, 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	transferTx.TxOut = append(transferTx.TxOut, wire.NewTxOut(2000, script))
+
+	script, err = protocol.Serialize(&transferData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize transfer : %v", tests.Failed, err)
+	}
+	transferTx.TxOut = append(transferTx.TxOut, wire.NewTxOut(0, script))
+
+	transferItx, err := inspector.NewTransactionFromWire(ctx, transferTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create transfer itx : %v", tests.Failed, err)
+	}
+
+	if err := transferItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote transfer itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, transferTx)
+
+	if err := a.Trigger(ctx, "SEE", transferItx); err == nil {
+		t.Fatalf("\t%s\tAccepted transfer of vote locked tokens", tests.Failed)
+	}
+
+	response := checkResponse(t, "M2")
+	rejectCode := uint32(0)
+	for _, output := range response.TxOut {
+		action, err := protocol.Deserialize(output.PkScript, test.NodeConfig.IsTest)
+		if err != nil {
+			continue
+		}
+		if rejection, ok := action.(*actions.Rejection); ok {
+			rejectCode = rejection.RejectionCode
+		}
+	}
+	if rejectCode != actions.RejectionsHoldingsLocked {
+		t.Fatalf("\t%s\tWrong reject code : %d", tests.Failed, rejectCode)
+	}
+	t.Logf("\t%s\tTransfer of vote locked tokens rejected", tests.Success)
+
+	// Revoking the ballot releases the lock
+	if err := castBallot(ctx, userKey, 100063, ""); err != nil {
+		t.Fatalf("\t%s\tFailed to accept revocation : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	h, err = holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], userKey.Address, now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get holding : %v", tests.Failed, err)
+	}
+	if locked := holdings.VoteLockedBalance(h, now); locked != 0 {
+		t.Fatalf("\t%s\tVote lock not released : %d", tests.Failed, locked)
+	}
+	t.Logf("\t%s\tVerified vote lock released", tests.Success)
+}
+
+// voteLockedConfiscation tests that vote locked balances can still be confiscated by an
+//   enforcement order.
+func voteLockedConfiscation(t *testing.T) {
+	ctx := test.Context
+
+	test.NodeConfig.VoteBalanceLock = true
+	defer func() { test.NodeConfig.VoteBalanceLock = false }()
+
+	if err := mockUpBallotVote(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to mock up vote : %v", tests.Failed, err)
+	}
+
+	if err := castBallot(ctx, userKey, 100071, "A"); err != nil {
+		t.Fatalf("\t%s\tFailed to accept ballot : %v", tests.Failed, err)
+	}
+	checkResponse(t, "G4")
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100072, issuerKey.Address)
+
+	orderData := actions.Order{
+		ComplianceAction: actions.ComplianceActionConfiscation,
+		AssetType:        testAssetType,
+		AssetCode:        testAssetCodes[0].Bytes(),
+		DepositAddress:   issuerKey.Address.Bytes(),
+		Message:          "Court order",
+		TargetAddresses: []*actions.TargetAddressField{
+			{Address: userKey.Address.Bytes(), Quantity: 100},
+		},
+	}
+
+	orderTx := wire.NewMsgTx(2)
+	orderTx.TxIn = append(orderTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(2500, script))
+
+	script, err := protocol.Serialize(&orderData, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to serialize order : %v", tests.Failed, err)
+	}
+	orderTx.TxOut = append(orderTx.TxOut, wire.NewTxOut(0, script))
+
+	orderItx, err := inspector.NewTransactionFromWire(ctx, orderTx, test.NodeConfig.IsTest)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create order itx : %v", tests.Failed, err)
+	}
+
+	if err := orderItx.Promote(ctx, test.RPCNode); err != nil {
+		t.Fatalf("\t%s\tFailed to promote order itx : %v", tests.Failed, err)
+	}
+
+	test.RPCNode.SaveTX(ctx, orderTx)
+
+	if err := a.Trigger(ctx, "SEE", orderItx); err != nil {
+		t.Fatalf("\t%s\tFailed to accept order : %v", tests.Failed, err)
+	}
+	checkResponse(t, "E4")
+	t.Logf("\t%s\tConfiscation of vote locked tokens accepted", tests.Success)
+
+	now := protocol.CurrentTimestamp()
+	h, err := holdings.GetHolding(ctx, test.MasterDB, test.ContractKey.Address,
+		&testAssetCodes[0], userKey.Address, now)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get holding : %v", tests.Failed, err)
+	}
+	if h.FinalizedBalance != 150 {
+		t.Fatalf("\t%s\tWrong balance after confiscation : %d != %d", tests.Failed,
+			h.FinalizedBalance, 150)
+	}
+	if locked := holdings.VoteLockedBalance(h, now); locked != 150 {
+		t.Fatalf("\t%s\tWrong vote locked balance : %d != %d", tests.Failed, locked, 150)
+	}
+	t.Logf("\t%s\tVerified confiscated balance : %d", tests.Success, h.FinalizedBalance)
+}
+
+// mockUpBallotVote sets up a contract wide vote with a holder of 250 tokens.
+func mockUpBallotVote(ctx context.Context) error {
+	if err := resetTest(ctx); err != nil {
+		return err
+	}
+	err := mockUpContract(ctx, "Test Contract", "This is a mock contract and means nothing.", "I",
+		1, "John Bitcoin", true, true, false, false, true)
+	if err != nil {
+		return err
+	}
+	err = mockUpAsset(ctx, true, true, true, 1000, 0, &sampleAssetPayload, false, false, false)
+	if err != nil {
+		return err
+	}
+	if err := mockUpHolding(ctx, userKey.Address, 250); err != nil {
+		return err
+	}
+	return mockUpProposal(ctx)
+}
+
+// checkBallotTally fails the test if the current ballots don't tally to the expected values.
+func checkBallotTally(ctx context.Context, t *testing.T, expected []uint64) {
+	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve vote : %v", tests.Failed, err)
+	}
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+
+	proposal := actions.Proposal{VoteOptions: "AB", VoteMax: 1}
+	tally, _, err := vote.CalculateResults(ctx, vt, &proposal, ct.VotingSystems[0])
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to calculate results : %v", tests.Failed, err)
+	}
+
+	for i, quantity := range expected {
+		if tally[i] != quantity {
+			t.Fatalf("\t%s\tWrong tally for option %d : %d != %d", tests.Failed, i, tally[i],
+				quantity)
+		}
+	}
+	t.Logf("\t%s\tVerified tally : %v", tests.Success, tally)
+}
+
+// castBallot sends a ballot for the test vote from the key.
+func castBallot(ctx context.Context, key *wallet.Key, funding uint64, v string) error {
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, funding, key.Address)
+
+	ballotData := actions.BallotCast{
+		VoteTxId: testVoteTxId.Bytes(),
+		Vote:     v,
+	}
+
+	ballotTx := wire.NewMsgTx(2)
+	ballotInputHash := fundingTx.TxHash()
+	ballotTx.TxIn = append(ballotTx.TxIn,
+		wire.NewTxIn(wire.NewOutPoint(ballotInputHash, 0), make([]byte, 130)))
+
+	script, _ := test.ContractKey.Address.LockingScript()
+	ballotTx.TxOut = append(ballotTx.TxOut, wire.NewTxOut(2000, script))
+
+	script, err := protocol.Serialize(&ballotData, test.NodeConfig.IsTest)
+	if err != nil {
+		return err
+	}
+	ballotTx.TxOut = append(ballotTx.TxOut, wire.NewTxOut(0, script))
+
+	ballotItx, err := inspector.NewTransactionFromWire(ctx, ballotTx, test.NodeConfig.IsTest)
+	if err != nil {
+		return err
+	}
+
+	if err := ballotItx.Promote(ctx, test.RPCNode); err != nil {
+		return err
+	}
+
+	test.RPCNode.SaveTX(ctx, ballotTx)
+
+	return a.Trigger(ctx, "SEE", ballotItx)
+}
+
 func mockUpBallot(ctx context.Context, address bitcoin.RawAddress, quantity uint64, v string) error {
 	vt, err := vote.Fetch(ctx, test.MasterDB, test.ContractKey.Address, &testVoteTxId)
 	if err != nil {
@@ -891,3 +1995,23 @@ func mockUpVoteResultTx(ctx context.Context, result string) error {
 
 	return vote.Save(ctx, test.MasterDB, test.ContractKey.Address, vt)
 }
+
+// mockUpSupportingDoc adds a supporting document to the terms of a test contract.
+func mockUpSupportingDoc(ctx context.Context, contractAddress bitcoin.RawAddress, name,
+	contents string) error {
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, contractAddress)
+	if err != nil {
+		return err
+	}
+
+	docs := append(ct.SupportingDocs, &actions.DocumentField{
+		Name:     name,
+		Type:     "application/json",
+		Contents: []byte(contents),
+	})
+
+	uc := contract.UpdateContract{SupportingDocs: &docs}
+	return contract.Update(ctx, test.MasterDB, contractAddress, &uc,
+		protocol.CurrentTimestamp())
+}
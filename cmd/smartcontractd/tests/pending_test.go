@@ -0,0 +1,383 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/filters"
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
+	"github.com/tokenized/smart-contract/internal/holdings"
+	"github.com/tokenized/smart-contract/internal/platform/protomux"
+	"github.com/tokenized/smart-contract/internal/platform/tests"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/scheduler"
+	spynodeHandlers "github.com/tokenized/smart-contract/pkg/spynode/handlers"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+// TestPending is the entry point for testing pending tx recovery.
+func TestPending(t *testing.T) {
+	defer tests.Recover(t)
+
+	t.Run("restart", pendingRestart)
+	t.Run("responded", pendingResponded)
+	t.Run("unsafe", pendingUnsafe)
+}
+
+// pendingRestart kills the daemon while a request is being processed and verifies that after a
+//   restart every request is processed exactly once.
+func pendingRestart(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+
+	requests := make([]*wire.MsgTx, 0, 3)
+	for i := 0; i < 3; i++ {
+		tx, err := mockRequestTx(ctx, i)
+		if err != nil {
+			t.Fatalf("\t%s\tFailed to create request : %v", tests.Failed, err)
+		}
+		requests = append(requests, tx)
+	}
+
+	handler := newCountingHandler()
+
+	// Block processing of the second request so the daemon can be "killed" in the middle of it.
+	handler.block = requests[1].TxHash()
+
+	server, serverWG := startPendingServer(t, ctx, handler)
+
+	for _, tx := range requests {
+		if _, err := server.HandleTx(ctx, tx); err != nil {
+			t.Fatalf("\t%s\tFailed to handle request : %v", tests.Failed, err)
+		}
+	}
+
+	// First request is processed normally.
+	if err := server.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateSafe,
+		*requests[0].TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request safe : %v", tests.Failed, err)
+	}
+	if !handler.waitFor(requests[0].TxHash(), 1) {
+		t.Fatalf("\t%s\tFirst request not processed", tests.Failed)
+	}
+
+	// Second request starts processing and never finishes.
+	if err := server.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateSafe,
+		*requests[1].TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request safe : %v", tests.Failed, err)
+	}
+	select {
+	case <-handler.blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("\t%s\tSecond request processing not started", tests.Failed)
+	}
+
+	// Kill the first server. Its processing thread stays blocked until the end of the test and
+	//   never completes the second request. The third request was received but never marked safe.
+	t.Logf("\t%s\tKilled daemon during processing", tests.Success)
+
+	restarted, restartedWG := startPendingServer(t, ctx, handler)
+
+	// Spynode re-announces txs it saw before the restart.
+	for _, tx := range requests {
+		restarted.HandleTx(ctx, tx)
+	}
+
+	if !handler.waitFor(requests[1].TxHash(), 1) {
+		t.Fatalf("\t%s\tInterrupted request not processed after restart", tests.Failed)
+	}
+	t.Logf("\t%s\tInterrupted request processed after restart", tests.Success)
+
+	if err := restarted.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateSafe,
+		*requests[2].TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request safe : %v", tests.Failed, err)
+	}
+	if !handler.waitFor(requests[2].TxHash(), 1) {
+		t.Fatalf("\t%s\tRestored request not processed after restart", tests.Failed)
+	}
+	t.Logf("\t%s\tRestored request processed after restart", tests.Success)
+
+	// Give any duplicate processing a chance to happen.
+	time.Sleep(100 * time.Millisecond)
+
+	for i, tx := range requests {
+		if count := handler.count(tx.TxHash()); count != 1 {
+			t.Fatalf("\t%s\tRequest %d processed %d times", tests.Failed, i, count)
+		}
+	}
+	t.Logf("\t%s\tAll requests processed exactly once", tests.Success)
+
+	restarted.Stop(ctx)
+	restartedWG.Wait()
+
+	server.Stop(ctx)
+	close(handler.release)
+	serverWG.Wait()
+}
+
+// pendingResponded kills the daemon after a response is sent, but before processing of the
+//   request completes, and verifies that the request isn't processed again after a restart.
+func pendingResponded(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+
+	request, err := mockRequestTx(ctx, 0)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create request : %v", tests.Failed, err)
+	}
+
+	handler := newCountingHandler()
+	handler.block = request.TxHash()
+	handler.respond = true
+
+	server, serverWG := startPendingServer(t, ctx, handler)
+
+	if _, err := server.HandleTx(ctx, request); err != nil {
+		t.Fatalf("\t%s\tFailed to handle request : %v", tests.Failed, err)
+	}
+	if err := server.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateSafe,
+		*request.TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request safe : %v", tests.Failed, err)
+	}
+	select {
+	case <-handler.blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("\t%s\tRequest processing not started", tests.Failed)
+	}
+
+	t.Logf("\t%s\tKilled daemon after response was sent", tests.Success)
+
+	restarted, restartedWG := startPendingServer(t, ctx, handler)
+
+	// Spynode re-announces txs it saw before the restart.
+	restarted.HandleTx(ctx, request)
+	restarted.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateSafe, *request.TxHash())
+
+	// Give any duplicate processing a chance to happen.
+	time.Sleep(100 * time.Millisecond)
+
+	if count := handler.count(request.TxHash()); count != 1 {
+		t.Fatalf("\t%s\tRequest processed %d times", tests.Failed, count)
+	}
+	t.Logf("\t%s\tResponded request not processed again", tests.Success)
+
+	restarted.Stop(ctx)
+	restartedWG.Wait()
+
+	server.Stop(ctx)
+	close(handler.release)
+	serverWG.Wait()
+}
+
+// pendingUnsafe restarts the daemon after a request is double spent and verifies that the request
+//   is still held until it confirms.
+func pendingUnsafe(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+
+	request, err := mockRequestTx(ctx, 0)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create request : %v", tests.Failed, err)
+	}
+
+	handler := newCountingHandler()
+
+	server, serverWG := startPendingServer(t, ctx, handler)
+
+	if _, err := server.HandleTx(ctx, request); err != nil {
+		t.Fatalf("\t%s\tFailed to handle request : %v", tests.Failed, err)
+	}
+	if err := server.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateUnsafe,
+		*request.TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request unsafe : %v", tests.Failed, err)
+	}
+
+	server.Stop(ctx)
+	close(handler.release)
+	serverWG.Wait()
+
+	restarted, restartedWG := startPendingServer(t, ctx, handler)
+
+	// Spynode re-announces txs it saw before the restart.
+	restarted.HandleTx(ctx, request)
+
+	// Give any processing a chance to happen.
+	time.Sleep(100 * time.Millisecond)
+
+	if count := handler.count(request.TxHash()); count != 0 {
+		t.Fatalf("\t%s\tUnsafe request processed %d times", tests.Failed, count)
+	}
+	t.Logf("\t%s\tUnsafe request held after restart", tests.Success)
+
+	if err := restarted.HandleTxState(ctx, spynodeHandlers.ListenerMsgTxStateConfirm,
+		*request.TxHash()); err != nil {
+		t.Fatalf("\t%s\tFailed to mark request confirmed : %v", tests.Failed, err)
+	}
+	if !handler.waitFor(request.TxHash(), 1) {
+		t.Fatalf("\t%s\tUnsafe request not processed after confirm", tests.Failed)
+	}
+	t.Logf("\t%s\tUnsafe request processed after confirm", tests.Success)
+
+	restarted.Stop(ctx)
+	restartedWG.Wait()
+}
+
+func startPendingServer(t *testing.T, ctx context.Context,
+	handler protomux.Handler) (*listeners.Server, *sync.WaitGroup) {
+
+	config := test.NodeConfig
+	config.PreprocessThreads = 2
+
+	tracer := filters.NewTracer()
+	txFilter := filters.NewTxFilter(tracer, true)
+
+	server := listeners.NewServer(test.Wallet, handler, &config, test.MasterDB,
+		test.RPCNode, nil, test.Headers, &scheduler.Scheduler{}, tracer, test.UTXOs, txFilter,
+		&holdings.CacheChannel{})
+
+	if err := server.SyncWallet(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to load wallet : %v", tests.Failed, err)
+	}
+
+	server.SetInSync()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := server.Run(ctx); err != nil {
+			t.Logf("Server failed : %s", err)
+		}
+	}()
+
+	time.Sleep(time.Second)
+	return server, wg
+}
+
+// mockRequestTx creates a request tx sent to the test contract.
+func mockRequestTx(ctx context.Context, index int) (*wire.MsgTx, error) {
+	offerData := actions.ContractOffer{
+		ContractName: "Pending Test",
+		Issuer: &actions.EntityField{
+			Type: "I",
+		},
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100000+uint64(index), issuerKey.Address)
+
+	tx := wire.NewMsgTx(2)
+	tx.TxIn = append(tx.TxIn, wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	script, err := test.ContractKey.Address.LockingScript()
+	if err != nil {
+		return nil, err
+	}
+	tx.TxOut = append(tx.TxOut, wire.NewTxOut(1000, script))
+
+	script, err = protocol.Serialize(&offerData, test.NodeConfig.IsTest)
+	if err != nil {
+		return nil, err
+	}
+	tx.TxOut = append(tx.TxOut, wire.NewTxOut(0, script))
+
+	test.RPCNode.SaveTX(ctx, tx)
+	return tx, nil
+}
+
+// countingHandler counts completed triggers for each tx.
+type countingHandler struct {
+	counts    map[bitcoin.Hash32]int
+	block     *bitcoin.Hash32
+	respond   bool // Respond to the blocked tx before blocking
+	blocked   chan bool
+	release   chan bool
+	responder protomux.ResponderFunc
+	lock      sync.Mutex
+}
+
+func newCountingHandler() *countingHandler {
+	return &countingHandler{
+		counts:  make(map[bitcoin.Hash32]int),
+		blocked: make(chan bool, 1),
+		release: make(chan bool),
+	}
+}
+
+func (h *countingHandler) Trigger(ctx context.Context, event string,
+	itx *inspector.Transaction) error {
+
+	h.lock.Lock()
+	if h.block != nil && h.block.Equal(itx.Hash) {
+		h.block = nil
+		responder := h.responder
+		if h.respond {
+			h.counts[*itx.Hash]++
+		}
+		h.lock.Unlock()
+
+		if h.respond {
+			response := wire.NewMsgTx(2)
+			response.TxIn = append(response.TxIn, wire.NewTxIn(wire.NewOutPoint(itx.Hash, 0),
+				make([]byte, 130)))
+			if err := responder(ctx, response); err != nil {
+				return err
+			}
+		}
+
+		h.blocked <- true
+		<-h.release // Doesn't complete until the test ends
+		return nil
+	}
+	h.counts[*itx.Hash]++
+	h.lock.Unlock()
+	return nil
+}
+
+func (h *countingHandler) count(txid *bitcoin.Hash32) int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.counts[*txid]
+}
+
+// waitFor returns true when the tx has been processed the specified number of times.
+func (h *countingHandler) waitFor(txid *bitcoin.Hash32, count int) bool {
+	for i := 0; i < 500; i++ {
+		if h.count(txid) >= count {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func (h *countingHandler) Respond(ctx context.Context, m wire.Message) error {
+	return nil
+}
+
+func (h *countingHandler) Reprocess(ctx context.Context, itx *inspector.Transaction) error {
+	return nil
+}
+
+func (h *countingHandler) SetResponder(responder protomux.ResponderFunc) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.responder = responder
+}
+
+func (h *countingHandler) SetReprocessor(protomux.ReprocessFunc) {}
@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/filters"
+	"github.com/tokenized/smart-contract/cmd/smartcontractd/listeners"
+	"github.com/tokenized/smart-contract/internal/contract"
+	"github.com/tokenized/smart-contract/internal/platform/tests"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/scheduler"
+	"github.com/tokenized/smart-contract/pkg/spynode"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
+	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
+	"github.com/tokenized/smart-contract/pkg/spynode/simnet"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+)
+
+// TestSimulatedNetwork is the entry point for testing the daemon against a simulated network.
+func TestSimulatedNetwork(t *testing.T) {
+	defer tests.Recover(t)
+
+	t.Run("daemon", simulatedDaemon)
+}
+
+// simulatedDaemon runs the listeners server and spynode against a simulated trusted node and
+//   drives a request through confirm, revert and double spend sequences.
+func simulatedDaemon(t *testing.T) {
+	ctx := test.Context
+
+	if err := resetTest(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to reset test : %v", tests.Failed, err)
+	}
+
+	store := storage.NewFilesystemStorage(storage.NewConfig("standalone", "./tmp/simnet"))
+	if err := store.Clear(ctx, map[string]string{"path": "spynode"}); err != nil {
+		t.Fatalf("\t%s\tFailed to clear spynode storage : %v", tests.Failed, err)
+	}
+
+	network := simnet.NewNetwork(test.NodeConfig.Net)
+	network.MineBlocks(3)
+	startBlock, err := network.Block(2)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to get start block : %v", tests.Failed, err)
+	}
+
+	memory := dialer.NewMemoryDialer()
+	memory.Register("simnet", network.Connect)
+
+	spyConfig, err := data.NewConfig(test.NodeConfig.Net, "simnet", "/Tokenized:Test/",
+		startBlock.BlockHash().String(), 0, 500, 0)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create spynode config : %v", tests.Failed, err)
+	}
+	spyConfig.Dialer = memory
+
+	spyNode := spynode.NewNode(spyConfig.Copy(), store)
+
+	test.NodeConfig.PreprocessThreads = 2
+	tracer := filters.NewTracer()
+	txFilter := filters.NewTxFilter(tracer, true)
+	test.Scheduler = &scheduler.Scheduler{}
+
+	server := listeners.NewServer(test.Wallet, a, &test.NodeConfig, test.MasterDB,
+		test.RPCNode, spyNode, spyNode, test.Scheduler, tracer, test.UTXOs, txFilter,
+		test.HoldingsChannel)
+
+	if err := server.SyncWallet(ctx); err != nil {
+		t.Fatalf("\t%s\tFailed to load wallet : %v", tests.Failed, err)
+	}
+
+	// Responses still reach the network through spynode when the secondary broadcaster fails.
+	server.SetBroadcaster(&failingBroadcaster{})
+
+	// Alerts are posted to a webhook that doesn't respond until the test ends, so double spend
+	//   handling in spynode must not wait for it.
+	alertReceived := make(chan bool, 10)
+	releaseWebhook := make(chan bool)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		alertReceived <- true
+		<-releaseWebhook
+	}))
+	defer webhook.Close()
+	defer close(releaseWebhook)
+	server.SetAlertHandler(listeners.NewWebhookAlertHandler(webhook.URL, time.Minute))
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := server.Run(ctx); err != nil {
+			t.Logf("Server failed : %s", err)
+		}
+	}()
+	defer func() {
+		server.Stop(ctx)
+		wg.Wait()
+		resetTest(ctx)
+	}()
+
+	// Contract offer is seen in the mempool and the formation is broadcast to the network.
+	offerTx, err := mockSimulatedOfferTx(ctx)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create offer : %v", tests.Failed, err)
+	}
+	if err := network.AddTx(offerTx); err != nil {
+		t.Fatalf("\t%s\tFailed to add offer : %v", tests.Failed, err)
+	}
+
+	formationTx := waitForReceived(t, network, offerTx.TxHash())
+	if code := responseType(formationTx); code != actions.CodeContractFormation {
+		t.Fatalf("\t%s\tWrong offer response : got %s, want %s", tests.Failed, code,
+			actions.CodeContractFormation)
+	}
+	waitForCondition(t, "contract formed", func() bool {
+		ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+		return err == nil && ct.ContractName == "Simulated Network"
+	})
+	t.Logf("\t%s\tContract formation broadcast : %s", tests.Success,
+		formationTx.TxHash().String())
+
+	// Offer and formation confirm, are reverted by a reorg and confirm again.
+	network.MineBlock()
+	if len(network.MemPool()) != 0 {
+		t.Fatalf("\t%s\tTxs not mined", tests.Failed)
+	}
+	if _, err := network.Reorg(1, 2); err != nil {
+		t.Fatalf("\t%s\tFailed to reorg : %v", tests.Failed, err)
+	}
+	waitForCondition(t, "reorg", func() bool { return spyNode.LastHeight(ctx) == network.Height() })
+	network.MineBlock()
+	waitForCondition(t, "reconfirm", func() bool {
+		return spyNode.LastHeight(ctx) == network.Height()
+	})
+
+	ct, err := contract.Retrieve(ctx, test.MasterDB, test.ContractKey.Address)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to retrieve contract : %v", tests.Failed, err)
+	}
+	if ct.ContractName != "Simulated Network" || ct.Revision != 0 {
+		t.Fatalf("\t%s\tContract changed by reorg", tests.Failed)
+	}
+	t.Logf("\t%s\tContract survived reorg", tests.Success)
+
+	// Request is double spent before it is safe and the double spend is mined, so the request is
+	//   canceled without a response.
+	requestTx, err := mockRequestTx(ctx, 1)
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create request : %v", tests.Failed, err)
+	}
+	doubleSpendTx := wire.NewMsgTx(2)
+	doubleSpendTx.TxIn = append(doubleSpendTx.TxIn,
+		wire.NewTxIn(&requestTx.TxIn[0].PreviousOutPoint, make([]byte, 130)))
+	script, err := userKey.Address.LockingScript()
+	if err != nil {
+		t.Fatalf("\t%s\tFailed to create locking script : %v", tests.Failed, err)
+	}
+	doubleSpendTx.TxOut = append(doubleSpendTx.TxOut, wire.NewTxOut(1000, script))
+
+	if err := network.AddTx(requestTx); err != nil {
+		t.Fatalf("\t%s\tFailed to add request : %v", tests.Failed, err)
+	}
+	network.InjectTx(doubleSpendTx)
+	waitForCondition(t, "double spend", func() bool {
+		_, err := spyNode.DoubleSpend(ctx, requestTx.TxHash())
+		return err == nil
+	})
+
+	select {
+	case <-alertReceived:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("\t%s\tDouble spend alert not posted", tests.Failed)
+	}
+	t.Logf("\t%s\tDouble spend alert posted to hanging webhook", tests.Success)
+
+	if _, err := network.MineBlockWith(doubleSpendTx); err != nil {
+		t.Fatalf("\t%s\tFailed to mine double spend : %v", tests.Failed, err)
+	}
+	waitForCondition(t, "double spend confirm", func() bool {
+		ds, err := spyNode.DoubleSpend(ctx, requestTx.TxHash())
+		return err == nil && ds.Confirmed != nil && ds.Confirmed.Equal(doubleSpendTx.TxHash())
+	})
+	t.Logf("\t%s\tDouble spend confirm not blocked by webhook", tests.Success)
+
+	// Give the canceled request a chance to be processed.
+	time.Sleep(time.Second)
+	for _, tx := range network.Received() {
+		for _, input := range tx.TxIn {
+			if input.PreviousOutPoint.Hash.Equal(requestTx.TxHash()) {
+				t.Fatalf("\t%s\tResponse sent to canceled request : %s", tests.Failed,
+					tx.TxHash().String())
+			}
+		}
+	}
+	t.Logf("\t%s\tDouble spent request canceled : %s", tests.Success,
+		requestTx.TxHash().String())
+}
+
+// mockSimulatedOfferTx creates a contract offer tx sent to the test contract.
+func mockSimulatedOfferTx(ctx context.Context) (*wire.MsgTx, error) {
+	offerData := actions.ContractOffer{
+		ContractName:        "Simulated Network",
+		BodyOfAgreementType: 2,
+		BodyOfAgreement:     []byte("This is a test contract and not to be used for any official purpose."),
+		Issuer: &actions.EntityField{
+			Type:           "I",
+			Administration: []*actions.AdministratorField{&actions.AdministratorField{Type: 1, Name: "John Smith"}},
+		},
+		VotingSystems: []*actions.VotingSystemField{
+			&actions.VotingSystemField{
+				Name:                "Relative 50",
+				VoteType:            "R",
+				ThresholdPercentage: 50,
+				HolderProposalFee:   50000,
+			},
+		},
+	}
+
+	fundingTx := tests.MockFundingTx(ctx, test.RPCNode, 100010, issuerKey.Address)
+
+	tx := wire.NewMsgTx(2)
+	tx.TxIn = append(tx.TxIn, wire.NewTxIn(wire.NewOutPoint(fundingTx.TxHash(), 0),
+		make([]byte, 130)))
+
+	script, err := test.ContractKey.Address.LockingScript()
+	if err != nil {
+		return nil, err
+	}
+	tx.TxOut = append(tx.TxOut, wire.NewTxOut(750000, script))
+
+	script, err = protocol.Serialize(&offerData, test.NodeConfig.IsTest)
+	if err != nil {
+		return nil, err
+	}
+	tx.TxOut = append(tx.TxOut, wire.NewTxOut(0, script))
+
+	test.RPCNode.SaveTX(ctx, tx)
+	return tx, nil
+}
+
+// failingBroadcaster is a secondary broadcaster that is always unavailable.
+type failingBroadcaster struct{}
+
+func (b *failingBroadcaster) SendTX(ctx context.Context, tx *wire.MsgTx) (*bitcoin.Hash32, error) {
+	return nil, errors.New("Broadcaster unavailable")
+}
+
+// waitForReceived returns the first tx sent to the network that spends an output of the specified
+//   tx.
+func waitForReceived(t *testing.T, network *simnet.Network, txid *bitcoin.Hash32) *wire.MsgTx {
+	var result *wire.MsgTx
+	waitForCondition(t, "response to "+txid.String(), func() bool {
+		for _, tx := range network.Received() {
+			for _, input := range tx.TxIn {
+				if input.PreviousOutPoint.Hash.Equal(txid) {
+					result = tx
+					return true
+				}
+			}
+		}
+		return false
+	})
+	return result
+}
+
+func waitForCondition(t *testing.T, name string, check func() bool) {
+	for i := 0; i < 200; i++ {
+		if check() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("\t%s\tTimed out waiting for %s", tests.Failed, name)
+}
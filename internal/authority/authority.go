@@ -0,0 +1,67 @@
+package authority
+
+import (
+	"context"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+var (
+	// ErrNotFound abstracts the standard not found error.
+	ErrNotFound = errors.New("Enforcement authority not found")
+
+	// ErrNotActive is returned when an authority is revoked or outside of its validity window.
+	ErrNotActive = errors.New("Enforcement authority not active")
+)
+
+// IsActive returns true if the authority is not revoked and the time is within its validity
+//   window.
+func IsActive(a *state.EnforcementAuthority, now protocol.Timestamp) bool {
+	if a.RevokedAt.Nano() != 0 && a.RevokedAt.Nano() <= now.Nano() {
+		return false
+	}
+	if a.ValidFrom.Nano() != 0 && now.Nano() < a.ValidFrom.Nano() {
+		return false
+	}
+	if a.ValidUntil.Nano() != 0 && now.Nano() >= a.ValidUntil.Nano() {
+		return false
+	}
+	return true
+}
+
+// Check returns the authority registered by the contract for the public key. ErrNotFound is
+//   returned if the key isn't registered and ErrNotActive if the authority can't sign orders at
+//   the specified time.
+func Check(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	publicKey bitcoin.PublicKey, now protocol.Timestamp) (*state.EnforcementAuthority, error) {
+	ctx, span := trace.StartSpan(ctx, "internal.authority.Check")
+	defer span.End()
+
+	a, err := Fetch(ctx, dbConn, contractAddress, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsActive(a, now) {
+		return a, ErrNotActive
+	}
+
+	return a, nil
+}
+
+// HasRegistry returns true if the contract has registered any enforcement authorities, including
+//   revoked ones. Orders on contracts without a registry may be signed by any authority.
+func HasRegistry(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress) (bool, error) {
+	as, err := List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return false, err
+	}
+	return len(as) > 0, nil
+}
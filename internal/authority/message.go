@@ -0,0 +1,182 @@
+package authority
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/pkg/errors"
+)
+
+// MessageCode is the message code of an authority registry payload. It is not defined by the
+//   protocol specification so it is handled by the contract before the specification messages.
+const MessageCode = uint32(2002)
+
+// payloadVersion is the version of the authority registry payload serialization.
+const payloadVersion = uint8(0)
+
+const (
+	// ActionRegister adds an authority or replaces the name and validity window of a registered
+	//   authority. Registering a revoked authority reinstates it.
+	ActionRegister = uint8(0)
+
+	// ActionRevoke stops an authority from signing orders.
+	ActionRevoke = uint8(1)
+
+	// ActionRequireConfiscationSignature rejects confiscation orders that aren't signed by a
+	//   registered authority.
+	ActionRequireConfiscationSignature = uint8(2)
+
+	// ActionAllowUnsignedConfiscation accepts confiscation orders without an authority signature.
+	ActionAllowUnsignedConfiscation = uint8(3)
+)
+
+// Payload is a change to a contract's enforcement authority registry sent by the contract
+//   administration in a Message action.
+type Payload struct {
+	Action uint8
+
+	// Name and PublicKey identify the authority. They are empty for the confiscation
+	//   requirement actions. Name is only used by ActionRegister.
+	Name      string
+	PublicKey bitcoin.PublicKey
+
+	// ValidFrom and ValidUntil are the window in which the authority can sign orders. Zero values
+	//   leave that side of the window open.
+	ValidFrom  uint64
+	ValidUntil uint64
+
+	Timestamp uint64
+}
+
+// Serialize returns the binary format of the payload.
+func (p *Payload) Serialize() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := buf.WriteByte(payloadVersion); err != nil {
+		return nil, err
+	}
+
+	if err := buf.WriteByte(p.Action); err != nil {
+		return nil, err
+	}
+
+	if err := bitcoin.WriteBase128VarInt(&buf, len(p.Name)); err != nil {
+		return nil, err
+	}
+	if _, err := buf.Write([]byte(p.Name)); err != nil {
+		return nil, err
+	}
+
+	var publicKey []byte
+	if !p.PublicKey.IsEmpty() {
+		publicKey = p.PublicKey.Bytes()
+	}
+	if err := bitcoin.WriteBase128VarInt(&buf, len(publicKey)); err != nil {
+		return nil, err
+	}
+	if _, err := buf.Write(publicKey); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&buf, binary.LittleEndian, p.ValidFrom); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, p.ValidUntil); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, p.Timestamp); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Deserialize parses the binary format of a payload and checks that it has the fields its action
+//   requires.
+func Deserialize(b []byte) (*Payload, error) {
+	buf := bytes.NewReader(b)
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "version")
+	}
+	if version != payloadVersion {
+		return nil, fmt.Errorf("Unsupported authority version : %d", version)
+	}
+
+	result := Payload{}
+
+	result.Action, err = buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "action")
+	}
+
+	size, err := bitcoin.ReadBase128VarInt(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "name size")
+	}
+	if size > buf.Len() {
+		return nil, errors.New("Name size exceeds payload")
+	}
+	name := make([]byte, size)
+	if _, err := io.ReadFull(buf, name); err != nil {
+		return nil, errors.Wrap(err, "name")
+	}
+	result.Name = string(name)
+
+	size, err = bitcoin.ReadBase128VarInt(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "public key size")
+	}
+	if size > buf.Len() {
+		return nil, errors.New("Public key size exceeds payload")
+	}
+	if size > 0 {
+		publicKey := make([]byte, size)
+		if _, err := io.ReadFull(buf, publicKey); err != nil {
+			return nil, errors.Wrap(err, "public key")
+		}
+		result.PublicKey, err = bitcoin.PublicKeyFromBytes(publicKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "public key")
+		}
+	}
+
+	if err := binary.Read(buf, binary.LittleEndian, &result.ValidFrom); err != nil {
+		return nil, errors.Wrap(err, "valid from")
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &result.ValidUntil); err != nil {
+		return nil, errors.Wrap(err, "valid until")
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &result.Timestamp); err != nil {
+		return nil, errors.Wrap(err, "timestamp")
+	}
+
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("Authority payload has %d extra bytes", buf.Len())
+	}
+
+	switch result.Action {
+	case ActionRegister:
+		if len(result.Name) == 0 {
+			return nil, errors.New("Missing authority name")
+		}
+		if result.ValidUntil != 0 && result.ValidUntil <= result.ValidFrom {
+			return nil, errors.New("Authority validity window is empty")
+		}
+		fallthrough
+	case ActionRevoke:
+		if result.PublicKey.IsEmpty() {
+			return nil, errors.New("Missing authority public key")
+		}
+	case ActionRequireConfiscationSignature, ActionAllowUnsignedConfiscation:
+	default:
+		return nil, fmt.Errorf("Unknown authority action : %d", result.Action)
+	}
+
+	return &result, nil
+}
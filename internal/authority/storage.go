@@ -0,0 +1,97 @@
+package authority
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+)
+
+const storageKey = "contracts"
+const storageSubKey = "authorities"
+
+// Put a single authority in storage
+func Save(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	a *state.EnforcementAuthority) error {
+
+	key, err := buildStoragePath(contractAddress, a.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	return dbConn.Put(ctx, key, data)
+}
+
+// Fetch a single authority from storage
+func Fetch(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	publicKey bitcoin.PublicKey) (*state.EnforcementAuthority, error) {
+
+	key, err := buildStoragePath(contractAddress, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Fetch(ctx, key)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	result := state.EnforcementAuthority{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// List all authorities for a specified contract.
+func List(ctx context.Context, dbConn *db.DB,
+	contractAddress bitcoin.RawAddress) ([]*state.EnforcementAuthority, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Search(ctx, fmt.Sprintf("%s/%s/%s", storageKey, contractHash.String(),
+		storageSubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*state.EnforcementAuthority, 0, len(data))
+	for _, b := range data {
+		a := state.EnforcementAuthority{}
+
+		if err := json.Unmarshal(b, &a); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &a)
+	}
+
+	return result, nil
+}
+
+// Returns the storage path for an authority.
+func buildStoragePath(contractAddress bitcoin.RawAddress, publicKey bitcoin.PublicKey) (string, error) {
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s", storageKey, contractHash.String(), storageSubKey,
+		publicKey.String()), nil
+}
@@ -2,12 +2,19 @@ package contract
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/tokenized/smart-contract/internal/asset"
+	"github.com/tokenized/smart-contract/internal/authority"
+	"github.com/tokenized/smart-contract/internal/delegation"
+	"github.com/tokenized/smart-contract/internal/enforcement"
 	"github.com/tokenized/smart-contract/internal/holdings"
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
 	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/internal/thaw"
+	"github.com/tokenized/smart-contract/internal/transfer"
 	"github.com/tokenized/smart-contract/internal/vote"
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/specification/dist/golang/actions"
@@ -23,6 +30,23 @@ var (
 
 	// ErrInvalidID occurs when an ID is not in a valid form.
 	ErrInvalidID = errors.New("ID is not in its proper form")
+
+	// ErrMigrationNotFound occurs when a contract has not been moved.
+	ErrMigrationNotFound = errors.New("Contract migration not found")
+
+	// ErrMoveConflict occurs when a contract is already being moved to a different address.
+	ErrMoveConflict = errors.New("Contract already moving to a different address")
+)
+
+// Contract move steps. A migration's Step is the last one completed.
+const (
+	MoveStepStarted = uint32(iota)
+	MoveStepAssets
+	MoveStepHoldings
+	MoveStepVotes
+	MoveStepTransfers
+	MoveStepContract
+	MoveStepSwept
 )
 
 // Retrieve gets the specified contract from the database.
@@ -39,6 +63,36 @@ func Retrieve(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAdd
 	return contract, nil
 }
 
+// RetrieveCurrent gets the contract from the address it was last moved to, following any moves
+//   from the specified address.
+func RetrieveCurrent(ctx context.Context, dbConn *db.DB,
+	contractAddress bitcoin.RawAddress) (*state.Contract, error) {
+	ctx, span := trace.StartSpan(ctx, "internal.contract.RetrieveCurrent")
+	defer span.End()
+
+	contract, err := Fetch(ctx, dbConn, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := []bitcoin.RawAddress{contractAddress}
+	for !contract.MovedTo.IsEmpty() {
+		for _, address := range visited {
+			if address.Equal(contract.MovedTo) {
+				return nil, errors.New("Contract moves form a loop")
+			}
+		}
+		visited = append(visited, contract.MovedTo)
+
+		contract, err = Fetch(ctx, dbConn, contract.MovedTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return contract, nil
+}
+
 // Create the contract
 func Create(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress, nu *NewContract,
 	now protocol.Timestamp) error {
@@ -174,15 +228,25 @@ func Update(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddre
 	if upd.FreezePeriod != nil {
 		c.FreezePeriod = *upd.FreezePeriod
 	}
+	if upd.ConfiscationAuthorityRequired != nil {
+		c.ConfiscationAuthorityRequired = *upd.ConfiscationAuthorityRequired
+	}
 
 	c.UpdatedAt = now
 
 	return Save(ctx, dbConn, c)
 }
 
-// Move marks the contract as moved and copies the data to the new address.
+// Move copies the contract's assets, holdings, votes, pending transfers and thaws, enforcement
+//   authorities, and reconciliation records to the new address and then marks the contract as
+//   moved. Progress is saved after each step so a move that is interrupted continues where it left
+//   off when Move is called again with the same request. The returned migration has Step
+//   MoveStepContract. Moving the contract's bitcoin is left to the caller, which should record it
+//   with SaveMigration. Tracer entries follow outpoints, not contract addresses, so they don't
+//   need to be moved.
 func Move(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
-	newContractAddress bitcoin.RawAddress, now protocol.Timestamp) error {
+	newContractAddress bitcoin.RawAddress, requestTxId *protocol.TxId,
+	now protocol.Timestamp) (*state.ContractMigration, error) {
 
 	ctx, span := trace.StartSpan(ctx, "internal.contract.Move")
 	defer span.End()
@@ -190,49 +254,234 @@ func Move(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress
 	// Find contract
 	c, err := Fetch(ctx, dbConn, contractAddress)
 	if err != nil {
-		return ErrNotFound
+		return nil, ErrNotFound
+	}
+
+	migration, err := FetchMigration(ctx, dbConn, contractAddress)
+	if err == ErrMigrationNotFound {
+		migration = &state.ContractMigration{
+			From:        contractAddress,
+			To:          newContractAddress,
+			RequestTxId: requestTxId,
+			Step:        MoveStepStarted,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := SaveMigration(ctx, dbConn, migration); err != nil {
+			return nil, errors.Wrap(err, "save migration")
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "fetch migration")
+	} else if !migration.To.Equal(newContractAddress) {
+		return nil, ErrMoveConflict
+	}
+
+	for migration.Step < MoveStepContract {
+		step := migration.Step + 1
+		switch step {
+		case MoveStepAssets:
+			err = moveAssets(ctx, dbConn, c, newContractAddress)
+		case MoveStepHoldings:
+			err = moveHoldings(ctx, dbConn, c, newContractAddress)
+		case MoveStepVotes:
+			err = moveVotes(ctx, dbConn, contractAddress, newContractAddress)
+		case MoveStepTransfers:
+			if err = moveTransfers(ctx, dbConn, contractAddress, newContractAddress); err != nil {
+				break
+			}
+			err = moveThaws(ctx, dbConn, contractAddress, newContractAddress)
+		case MoveStepContract:
+			if err = moveAuthorities(ctx, dbConn, contractAddress, newContractAddress); err != nil {
+				break
+			}
+			if err = moveReconciliations(ctx, dbConn, contractAddress,
+				newContractAddress); err != nil {
+				break
+			}
+
+			newContract := *c
+			newContract.Address = newContractAddress
+			newContract.MovedTo = bitcoin.RawAddress{}
+			if err = Save(ctx, dbConn, &newContract); err != nil {
+				break
+			}
+
+			movedContract := *c
+			movedContract.MovedTo = newContractAddress
+			err = Save(ctx, dbConn, &movedContract)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "move step %d", step)
+		}
+
+		migration.Step = step
+		migration.UpdatedAt = now
+		if err := SaveMigration(ctx, dbConn, migration); err != nil {
+			return nil, errors.Wrap(err, "save migration")
+		}
+	}
+
+	return migration, nil
+}
+
+// moveAssets copies the contract's assets to the new address.
+func moveAssets(ctx context.Context, dbConn *db.DB, c *state.Contract,
+	newContractAddress bitcoin.RawAddress) error {
+
+	for _, assetCode := range c.AssetCodes {
+		as, err := asset.Retrieve(ctx, dbConn, c.Address, assetCode)
+		if err != nil {
+			return err
+		}
+
+		if err := asset.Save(ctx, dbConn, newContractAddress, as); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveHoldings copies every holding, including its statuses, to the new address.
+func moveHoldings(ctx context.Context, dbConn *db.DB, c *state.Contract,
+	newContractAddress bitcoin.RawAddress) error {
+
+	// Holdings are only iterated from storage, so write any cached changes first.
+	if err := holdings.WriteCache(ctx, dbConn); err != nil {
+		return errors.Wrap(err, "write holdings cache")
 	}
 
-	// Get assets
-	assets := make([]*state.Asset, 0, len(c.AssetCodes))
 	for _, assetCode := range c.AssetCodes {
-		as, err := asset.Retrieve(ctx, dbConn, contractAddress, assetCode)
+		err := holdings.ForEach(ctx, dbConn, c.Address, assetCode, func(h *state.Holding) error {
+			ci, err := holdings.Save(ctx, dbConn, newContractAddress, assetCode, h)
+			if err != nil {
+				return err
+			}
+			return ci.Write(ctx, dbConn)
+		})
 		if err != nil {
 			return err
 		}
-		assets = append(assets, as)
 	}
 
-	// Get votes
+	return nil
+}
+
+// moveVotes moves all votes to the new address. Finalizer jobs are keyed by the vote tx, so
+//   they still run for open votes and retrieve the vote from the new address. Voting delegations
+//   are moved with them.
+func moveVotes(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	newContractAddress bitcoin.RawAddress) error {
+
 	vts, err := vote.List(ctx, dbConn, contractAddress)
 	if err != nil {
 		return err
 	}
 
-	newContract := *c
-	newContract.Address = newContractAddress
+	for _, vt := range vts {
+		if err := vote.Save(ctx, dbConn, newContractAddress, vt); err != nil {
+			return err
+		}
+		if err := vote.Remove(ctx, dbConn, contractAddress,
+			vt.VoteTxId); err != nil && err != vote.ErrNotFound {
+			return err
+		}
+	}
+
+	ds, err := delegation.List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range ds {
+		if err := delegation.Save(ctx, dbConn, newContractAddress, d); err != nil {
+			return err
+		}
+		if err := delegation.Remove(ctx, dbConn, contractAddress, d.Delegator,
+			d.VoteTxId); err != nil && err != delegation.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	c.MovedTo = newContractAddress
+// moveAuthorities copies the contract's enforcement authority registry to the new address.
+func moveAuthorities(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	newContractAddress bitcoin.RawAddress) error {
 
-	if err = Save(ctx, dbConn, c); err != nil {
+	as, err := authority.List(ctx, dbConn, contractAddress)
+	if err != nil {
 		return err
 	}
-	if err = Save(ctx, dbConn, &newContract); err != nil {
+
+	for _, a := range as {
+		if err := authority.Save(ctx, dbConn, newContractAddress, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveReconciliations copies the contract's reconciliation records to the new address so they
+//   can still be reported.
+func moveReconciliations(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	newContractAddress bitcoin.RawAddress) error {
+
+	rs, err := enforcement.ListReconciliations(ctx, dbConn, contractAddress)
+	if err != nil {
 		return err
 	}
 
-	// Copy assets
-	for _, as := range assets {
-		err = asset.Save(ctx, dbConn, newContractAddress, as)
-		if err != nil {
+	for _, r := range rs {
+		if err := enforcement.SaveReconciliation(ctx, dbConn, newContractAddress, r); err != nil {
 			return err
 		}
 	}
 
-	// Copy votes
-	for _, vt := range vts {
-		err = vote.Save(ctx, dbConn, newContractAddress, vt)
-		if err != nil {
+	return nil
+}
+
+// moveTransfers moves pending transfers to the new address. Timeout jobs are keyed by the
+//   transfer tx, so they still run and find the pending transfer at the new address.
+func moveTransfers(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	newContractAddress bitcoin.RawAddress) error {
+
+	pts, err := transfer.List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return err
+	}
+
+	for _, pt := range pts {
+		if err := transfer.Save(ctx, dbConn, newContractAddress, pt); err != nil {
+			return err
+		}
+		if err := transfer.Remove(ctx, dbConn, contractAddress,
+			pt.TransferTxId); err != nil && err != transfer.ErrNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveThaws moves pending thaws of time limited freezes to the new address. Thaw jobs are keyed by
+//   the freeze tx, so the scheduled jobs still find them.
+func moveThaws(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	newContractAddress bitcoin.RawAddress) error {
+
+	pts, err := thaw.List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return err
+	}
+
+	for _, pt := range pts {
+		if err := thaw.Save(ctx, dbConn, newContractAddress, pt); err != nil {
+			return err
+		}
+		if err := thaw.Remove(ctx, dbConn, contractAddress,
+			pt.FreezeTxId); err != nil && err != thaw.ErrNotFound {
 			return err
 		}
 	}
@@ -360,6 +609,99 @@ func IsOperator(ctx context.Context, ct *state.Contract, address bitcoin.RawAddr
 	return false
 }
 
+// VotingRequirementsDocument is the name of the supporting document in the contract terms that
+//   holds the participation requirements of the voting systems. The protocol's voting system
+//   doesn't have these fields, so they are published in the terms where holders can see them. The
+//   contents are a JSON array of requirements in the same order as the voting systems, with null
+//   for systems that don't have one.
+//   Eg: [null, {"QuorumPercentage": 50, "MinimumBallots": 10}]
+const VotingRequirementsDocument = "Voting Requirements"
+
+// VotingRequirements returns the participation requirements in the supporting documents, or nil if
+//   there isn't a voting requirements document.
+func VotingRequirements(docs []*actions.DocumentField,
+	votingSystemCount int) ([]*state.VotingRequirement, error) {
+
+	for _, doc := range docs {
+		if doc.Name != VotingRequirementsDocument {
+			continue
+		}
+
+		var result []*state.VotingRequirement
+		if err := json.Unmarshal(doc.Contents, &result); err != nil {
+			return nil, errors.Wrap(err, "unmarshal voting requirements")
+		}
+
+		if len(result) > votingSystemCount {
+			return nil, fmt.Errorf("More voting requirements than voting systems : %d > %d",
+				len(result), votingSystemCount)
+		}
+
+		for _, requirement := range result {
+			if requirement == nil {
+				continue
+			}
+			if err := vote.ValidateVotingRequirement(requirement); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+// AutoApplyAmendmentsDocument is the name of the supporting document in the contract terms that
+//   opts the contract in to applying the amendments of passed proposals itself, without waiting for
+//   an amendment request. The contents are the JSON boolean true or false. The amendments are
+//   funded by the third output of the proposal.
+const AutoApplyAmendmentsDocument = "Auto Apply Amendments"
+
+// AutoApplyAmendments returns true if the supporting documents opt in to applying the amendments
+//   of passed proposals.
+func AutoApplyAmendments(docs []*actions.DocumentField) (bool, error) {
+	for _, doc := range docs {
+		if doc.Name != AutoApplyAmendmentsDocument {
+			continue
+		}
+
+		var result bool
+		if err := json.Unmarshal(doc.Contents, &result); err != nil {
+			return false, errors.Wrap(err, "unmarshal auto apply amendments")
+		}
+		return result, nil
+	}
+
+	return false, nil
+}
+
+// ValidateSupportingDocs returns an error if a supporting document that configures the contract
+//   is invalid.
+func ValidateSupportingDocs(docs []*actions.DocumentField, votingSystemCount int) error {
+	if _, err := VotingRequirements(docs, votingSystemCount); err != nil {
+		return err
+	}
+	if _, err := AutoApplyAmendments(docs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// VotingRequirement returns the participation requirement of the specified voting system, or nil
+//   if it doesn't have one.
+func VotingRequirement(ct *state.Contract, votingSystem uint32) (*state.VotingRequirement, error) {
+	requirements, err := VotingRequirements(ct.SupportingDocs, len(ct.VotingSystems))
+	if err != nil {
+		return nil, err
+	}
+
+	if int(votingSystem) >= len(requirements) {
+		return nil, nil
+	}
+	return requirements[votingSystem], nil
+}
+
 // ValidateVoting returns an error if voting is not allowed.
 func ValidateVoting(ctx context.Context, ct *state.Contract, initiatorType uint32) error {
 	if len(ct.VotingSystems) == 0 {
@@ -78,4 +78,6 @@ type UpdateContract struct {
 	Oracles                   *[]*actions.OracleField       `json:"Oracles,omitempty"`
 
 	FreezePeriod *protocol.Timestamp `json:"FreezePeriod,omitempty"`
+
+	ConfiscationAuthorityRequired *bool `json:"ConfiscationAuthorityRequired,omitempty"`
 }
@@ -86,11 +86,58 @@ func Reset(ctx context.Context) {
 	cache = nil
 }
 
+// SaveMigration puts the move progress for the contract at migration.From in storage.
+func SaveMigration(ctx context.Context, dbConn *db.DB, migration *state.ContractMigration) error {
+	contractHash, err := migration.From.Hash()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(migration)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal contract migration")
+	}
+
+	return dbConn.Put(ctx, buildMigrationStoragePath(contractHash), b)
+}
+
+// FetchMigration gets the move progress for a contract from storage. ErrMigrationNotFound is
+//   returned if the contract was never moved.
+func FetchMigration(ctx context.Context, dbConn *db.DB,
+	contractAddress bitcoin.RawAddress) (*state.ContractMigration, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := dbConn.Fetch(ctx, buildMigrationStoragePath(contractHash))
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, ErrMigrationNotFound
+		}
+
+		return nil, errors.Wrap(err, "Failed to fetch contract migration")
+	}
+
+	result := state.ContractMigration{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal contract migration")
+	}
+
+	return &result, nil
+}
+
 // Returns the storage path prefix for a given identifier.
 func buildStoragePath(contractHash *bitcoin.Hash20) string {
 	return fmt.Sprintf("%s/%s/contract", storageKey, contractHash.String())
 }
 
+// Returns the storage path for a contract's migration.
+func buildMigrationStoragePath(contractHash *bitcoin.Hash20) string {
+	return fmt.Sprintf("%s/%s/migration", storageKey, contractHash.String())
+}
+
 func ExpandOracles(ctx context.Context, data *state.Contract) error {
 	logger.Info(ctx, "Expanding %d oracle public keys", len(data.Oracles))
 
@@ -0,0 +1,94 @@
+package delegation
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+var (
+	// ErrNotFound abstracts the standard not found error.
+	ErrNotFound = errors.New("Delegation not found")
+)
+
+// Proxy returns the address that votes on behalf of the delegator in the specified vote. A
+//   delegation for the vote overrides a delegation for all votes. An empty address is returned
+//   when the delegator hasn't delegated.
+func Proxy(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	delegator bitcoin.RawAddress, voteTxId *protocol.TxId) (bitcoin.RawAddress, error) {
+	ctx, span := trace.StartSpan(ctx, "internal.delegation.Proxy")
+	defer span.End()
+
+	if voteTxId != nil {
+		d, err := Fetch(ctx, dbConn, contractAddress, delegator, voteTxId)
+		if err == nil {
+			return d.Proxy, nil
+		}
+		if err != ErrNotFound {
+			return bitcoin.RawAddress{}, errors.Wrap(err, "fetch vote delegation")
+		}
+	}
+
+	d, err := Fetch(ctx, dbConn, contractAddress, delegator, nil)
+	if err == ErrNotFound {
+		return bitcoin.RawAddress{}, nil
+	}
+	if err != nil {
+		return bitcoin.RawAddress{}, errors.Wrap(err, "fetch delegation")
+	}
+
+	return d.Proxy, nil
+}
+
+// Delegators returns the addresses that have delegated their voting power in the specified vote
+//   to the proxy. They are sorted so ballots are counted the same way every time.
+func Delegators(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	proxy bitcoin.RawAddress, voteTxId *protocol.TxId) ([]bitcoin.RawAddress, error) {
+	ctx, span := trace.StartSpan(ctx, "internal.delegation.Delegators")
+	defer span.End()
+
+	delegations, err := List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "list delegations")
+	}
+
+	// Find the effective delegation of each delegator.
+	effective := make(map[string]*state.Delegation)
+	for _, d := range delegations {
+		key := string(d.Delegator.Bytes())
+		if d.VoteTxId != nil {
+			if voteTxId == nil || !d.VoteTxId.Equal(*voteTxId) {
+				continue // delegation for another vote
+			}
+			effective[key] = d
+			continue
+		}
+
+		if current, exists := effective[key]; exists && current.VoteTxId != nil {
+			continue // vote delegation overrides
+		}
+		effective[key] = d
+	}
+
+	var result []bitcoin.RawAddress
+	for _, d := range effective {
+		if d.Proxy.Equal(proxy) {
+			result = append(result, d.Delegator)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Bytes(), result[j].Bytes()) < 0
+	})
+
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package delegation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
+)
+
+// MessageCode is the message code of a delegation payload. It is not defined by the protocol
+//   specification so it is handled by the contract before the specification messages.
+const MessageCode = uint32(2001)
+
+// payloadVersion is the version of the delegation payload serialization.
+const payloadVersion = uint8(0)
+
+// Payload is a delegation request sent by a holder to a contract in a Message action.
+type Payload struct {
+	// Proxy is the address that receives the holder's voting power. An empty proxy revokes the
+	//   delegation.
+	Proxy bitcoin.RawAddress
+
+	// VoteTxId limits the delegation to a single vote. When it is nil the delegation applies to
+	//   all of the contract's votes.
+	VoteTxId *protocol.TxId
+
+	Timestamp uint64
+}
+
+// Serialize returns the binary format of the payload.
+func (p *Payload) Serialize() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := buf.WriteByte(payloadVersion); err != nil {
+		return nil, err
+	}
+
+	proxy := p.Proxy.Bytes()
+	if err := bitcoin.WriteBase128VarInt(&buf, len(proxy)); err != nil {
+		return nil, err
+	}
+	if _, err := buf.Write(proxy); err != nil {
+		return nil, err
+	}
+
+	if p.VoteTxId == nil {
+		if err := buf.WriteByte(0); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := buf.WriteByte(1); err != nil {
+			return nil, err
+		}
+		if err := p.VoteTxId.Serialize(&buf); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := binary.Write(&buf, binary.LittleEndian, p.Timestamp); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Deserialize parses the binary format of a payload.
+func Deserialize(b []byte) (*Payload, error) {
+	buf := bytes.NewReader(b)
+
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "version")
+	}
+	if version != payloadVersion {
+		return nil, fmt.Errorf("Unsupported delegation version : %d", version)
+	}
+
+	result := Payload{}
+
+	size, err := bitcoin.ReadBase128VarInt(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "proxy size")
+	}
+	if size > buf.Len() {
+		return nil, errors.New("Proxy size exceeds payload")
+	}
+	if size > 0 {
+		proxy := make([]byte, size)
+		if _, err := io.ReadFull(buf, proxy); err != nil {
+			return nil, errors.Wrap(err, "proxy")
+		}
+		result.Proxy, err = bitcoin.DecodeRawAddress(proxy)
+		if err != nil {
+			return nil, errors.Wrap(err, "proxy")
+		}
+	}
+
+	hasVote, err := buf.ReadByte()
+	if err != nil {
+		return nil, errors.Wrap(err, "vote flag")
+	}
+	switch hasVote {
+	case 0:
+	case 1:
+		result.VoteTxId, err = protocol.DeserializeTxId(buf)
+		if err != nil {
+			return nil, errors.Wrap(err, "vote tx id")
+		}
+	default:
+		return nil, fmt.Errorf("Invalid vote flag : %d", hasVote)
+	}
+
+	if err := binary.Read(buf, binary.LittleEndian, &result.Timestamp); err != nil {
+		return nil, errors.Wrap(err, "timestamp")
+	}
+
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("Delegation payload has %d extra bytes", buf.Len())
+	}
+
+	return &result, nil
+}
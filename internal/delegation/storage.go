@@ -0,0 +1,132 @@
+package delegation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+const storageKey = "contracts"
+const storageSubKey = "delegations"
+
+// allVotesKey is used in place of a vote tx id for delegations that apply to all votes.
+const allVotesKey = "all"
+
+// Put a single delegation in storage
+func Save(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	d *state.Delegation) error {
+
+	key, err := buildStoragePath(contractAddress, d.Delegator, d.VoteTxId)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	return dbConn.Put(ctx, key, data)
+}
+
+// Fetch a single delegation from storage
+func Fetch(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	delegator bitcoin.RawAddress, voteTxId *protocol.TxId) (*state.Delegation, error) {
+
+	key, err := buildStoragePath(contractAddress, delegator, voteTxId)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Fetch(ctx, key)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	result := state.Delegation{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Remove a single delegation from storage
+func Remove(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	delegator bitcoin.RawAddress, voteTxId *protocol.TxId) error {
+
+	key, err := buildStoragePath(contractAddress, delegator, voteTxId)
+	if err != nil {
+		return err
+	}
+
+	if err := dbConn.Remove(ctx, key); err != nil {
+		if err == db.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// List all delegations for a specified contract.
+func List(ctx context.Context, dbConn *db.DB,
+	contractAddress bitcoin.RawAddress) ([]*state.Delegation, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Search(ctx, fmt.Sprintf("%s/%s/%s", storageKey, contractHash.String(),
+		storageSubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*state.Delegation, 0, len(data))
+	for _, b := range data {
+		d := state.Delegation{}
+
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &d)
+	}
+
+	return result, nil
+}
+
+// Returns the storage path for a delegator's delegation.
+func buildStoragePath(contractAddress, delegator bitcoin.RawAddress,
+	voteTxId *protocol.TxId) (string, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	delegatorHash, err := delegator.Hash()
+	if err != nil {
+		return "", err
+	}
+
+	vote := allVotesKey
+	if voteTxId != nil {
+		vote = voteTxId.String()
+	}
+
+	return fmt.Sprintf("%s/%s/%s/%s_%s", storageKey, contractHash.String(), storageSubKey,
+		delegatorHash.String(), vote), nil
+}
@@ -0,0 +1,105 @@
+package enforcement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+const reconciliationSubKey = "reconciliations"
+
+var (
+	// ErrReconciliationNotFound occurs when there is no record for a reconciliation order.
+	ErrReconciliationNotFound = errors.New("Reconciliation not found")
+)
+
+// Put a single reconciliation record in storage
+func SaveReconciliation(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	r *state.ReconciliationRecord) error {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return err
+	}
+	key := buildReconciliationPath(contractHash, r.OrderTxId)
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return dbConn.Put(ctx, key, data)
+}
+
+// Fetch a single reconciliation record from storage
+func FetchReconciliation(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	orderTxId *protocol.TxId) (*state.ReconciliationRecord, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+	key := buildReconciliationPath(contractHash, orderTxId)
+
+	data, err := dbConn.Fetch(ctx, key)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = ErrReconciliationNotFound
+		}
+
+		return nil, err
+	}
+
+	result := state.ReconciliationRecord{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ListReconciliations returns all reconciliation records for a specified contract, oldest first.
+func ListReconciliations(ctx context.Context, dbConn *db.DB,
+	contractAddress bitcoin.RawAddress) ([]*state.ReconciliationRecord, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Search(ctx, fmt.Sprintf("%s/%s/%s", storageKey, contractHash.String(),
+		reconciliationSubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*state.ReconciliationRecord, 0, len(data))
+	for _, d := range data {
+		r := state.ReconciliationRecord{}
+
+		if err := json.Unmarshal(d, &r); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &r)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Nano() < result[j].CreatedAt.Nano()
+	})
+
+	return result, nil
+}
+
+// Returns the storage path of a reconciliation record.
+func buildReconciliationPath(contractHash *bitcoin.Hash20, txid *protocol.TxId) string {
+	return fmt.Sprintf("%s/%s/%s/%s", storageKey, contractHash.String(), reconciliationSubKey,
+		txid.String())
+}
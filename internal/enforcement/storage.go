@@ -0,0 +1,97 @@
+package enforcement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+const storageKey = "contracts"
+const storageSubKey = "enforcement"
+
+var (
+	// ErrNotFound abstracts the standard not found error.
+	ErrNotFound = errors.New("Enforcement batch not found")
+)
+
+// Put a single enforcement batch in storage
+func Save(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress, b *state.EnforcementBatch) error {
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return err
+	}
+	key := buildStoragePath(contractHash, b.OrderTxId)
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+
+	return dbConn.Put(ctx, key, data)
+}
+
+// Fetch a single enforcement batch from storage
+func Fetch(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	orderTxId *protocol.TxId) (*state.EnforcementBatch, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+	key := buildStoragePath(contractHash, orderTxId)
+
+	data, err := dbConn.Fetch(ctx, key)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	result := state.EnforcementBatch{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// List all enforcement batches for a specified contract.
+func List(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress) ([]*state.EnforcementBatch, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Search(ctx, fmt.Sprintf("%s/%s/%s", storageKey, contractHash.String(),
+		storageSubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*state.EnforcementBatch, 0, len(data))
+	for _, d := range data {
+		batch := state.EnforcementBatch{}
+
+		if err := json.Unmarshal(d, &batch); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &batch)
+	}
+
+	return result, nil
+}
+
+// Returns the storage path prefix for a given identifier.
+func buildStoragePath(contractHash *bitcoin.Hash20, txid *protocol.TxId) string {
+	return fmt.Sprintf("%s/%s/%s/%s", storageKey, contractHash.String(), storageSubKey, txid.String())
+}
@@ -24,6 +24,10 @@ var (
 	// ErrHoldingsLocked occurs when the address holdings are locked for a multi-contract transfer.
 	ErrHoldingsLocked = errors.New("Holdings are locked")
 
+	// ErrHoldingsVoteLocked occurs when the address holdings were counted in a vote that is still
+	//   open.
+	ErrHoldingsVoteLocked = errors.New("Holdings are locked by a vote")
+
 	// ErrDuplicateEntry occurs when more than one send or receive is specified for an address.
 	ErrDuplicateEntry = errors.New("Holdings duplicate entry")
 )
@@ -34,6 +38,7 @@ const (
 	DepositCode              = byte('R')
 	MultiContractDebitCode   = byte('-')
 	MultiContractDepositCode = byte('+')
+	VoteLockCode             = byte('V')
 )
 
 // GetHolding returns the holding data for a PKH.
@@ -101,6 +106,29 @@ func UnfrozenBalance(h *state.Holding, now protocol.Timestamp) uint64 {
 	return result
 }
 
+// VoteLockedBalance returns the balance locked by open votes. The same tokens can be locked by
+//   more than one vote, so it is the largest lock rather than the sum. It is never more than the
+//   safe balance.
+func VoteLockedBalance(h *state.Holding, now protocol.Timestamp) uint64 {
+	result := uint64(0)
+	for _, status := range h.HoldingStatuses {
+		if status.Code != VoteLockCode {
+			continue
+		}
+		if statusExpired(status, now) {
+			continue
+		}
+		if status.Amount > result {
+			result = status.Amount
+		}
+	}
+
+	if safe := SafeBalance(h); result > safe {
+		return safe
+	}
+	return result
+}
+
 // FinalizeTx finalizes any pending changes involved with a tx.
 // When a holding status does not exist to finalize, like when in recovery mode, the balance is just
 //   set to the specified balance.
@@ -133,6 +161,35 @@ func FinalizeTx(h *state.Holding, txid *protocol.TxId, balance uint64, now proto
 	return nil
 }
 
+// FinalizeDepositPart finalizes part of a pending deposit, like for each response of a batched
+//   confiscation. The status is removed when all of it is finalized. When the status does not
+//   exist, like when in recovery mode, the balance is just set to the specified balance.
+func FinalizeDepositPart(h *state.Holding, txid *protocol.TxId, amount uint64, balance uint64,
+	now protocol.Timestamp) error {
+	h.UpdatedAt = now
+
+	hs, exists := h.HoldingStatuses[*txid]
+	if !exists {
+		h.FinalizedBalance = balance
+		h.PendingBalance = balance
+		return nil
+	}
+
+	if hs.Code != DepositCode {
+		return fmt.Errorf("Holding status is not a deposit : %c", hs.Code)
+	}
+	if amount > hs.Amount {
+		return fmt.Errorf("Deposit part more than pending : %d > %d", amount, hs.Amount)
+	}
+
+	h.FinalizedBalance += amount
+	hs.Amount -= amount
+	if hs.Amount == 0 {
+		delete(h.HoldingStatuses, *txid)
+	}
+	return nil
+}
+
 // AddDebit adds a pending send amount to a holding.
 func AddDebit(h *state.Holding, txid *protocol.TxId, amount uint64, isSingleContract bool,
 	now protocol.Timestamp) error {
@@ -175,6 +232,20 @@ func AddDebit(h *state.Holding, txid *protocol.TxId, amount uint64, isSingleCont
 	return nil
 }
 
+// AddTransferDebit adds a pending send amount for a transfer to a holding. Unlike other debits,
+//   transfers can't spend balances locked by open votes.
+func AddTransferDebit(h *state.Holding, txid *protocol.TxId, amount uint64,
+	isSingleContract bool, now protocol.Timestamp) error {
+
+	// Insufficient and frozen balances are reported by AddDebit first.
+	if SafeBalance(h) >= amount && UnfrozenBalance(h, now) >= amount &&
+		SafeBalance(h)-VoteLockedBalance(h, now) < amount {
+		return ErrHoldingsVoteLocked
+	}
+
+	return AddDebit(h, txid, amount, isSingleContract, now)
+}
+
 // AddDeposit adds a pending receive amount to a holding.
 func AddDeposit(h *state.Holding, txid *protocol.TxId, amount uint64, isSingleContract bool,
 	now protocol.Timestamp) error {
@@ -231,6 +302,38 @@ func AddFreeze(h *state.Holding, txid *protocol.TxId, amount uint64,
 	return nil
 }
 
+// AddVoteLock locks an amount of a holding until the vote closes. It replaces any previous lock
+//   from the same vote, like when a ballot is recast.
+func AddVoteLock(h *state.Holding, voteTxId *protocol.TxId, amount uint64,
+	expires protocol.Timestamp, now protocol.Timestamp) error {
+
+	hs, exists := h.HoldingStatuses[*voteTxId]
+	if exists && hs.Code != VoteLockCode {
+		return ErrDuplicateEntry
+	}
+
+	h.UpdatedAt = now
+	h.HoldingStatuses[*voteTxId] = &state.HoldingStatus{
+		Code:    VoteLockCode,
+		Expires: expires,
+		Amount:  amount,
+		TxId:    voteTxId,
+	}
+	return nil
+}
+
+// RemoveVoteLock removes the lock of a vote from a holding. It returns false if there wasn't one.
+func RemoveVoteLock(h *state.Holding, voteTxId *protocol.TxId, now protocol.Timestamp) bool {
+	hs, exists := h.HoldingStatuses[*voteTxId]
+	if !exists || hs.Code != VoteLockCode {
+		return false
+	}
+
+	delete(h.HoldingStatuses, *voteTxId)
+	h.UpdatedAt = now
+	return true
+}
+
 // CheckDebit checks that the debit amount matches that specified.
 func CheckDebit(h *state.Holding, txid *protocol.TxId, amount uint64) (uint64, error) {
 	hs, exists := h.HoldingStatuses[*txid]
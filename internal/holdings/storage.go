@@ -103,49 +103,60 @@ func List(ctx context.Context,
 	return dbConn.List(ctx, path)
 }
 
-// FetchAll fetches a single holding from storage for a specified asset.
+// FetchAll fetches all holdings from storage for a specified asset.
 func FetchAll(ctx context.Context,
 	dbConn *db.DB,
 	contractAddress bitcoin.RawAddress,
 	assetCode *protocol.AssetCode) ([]*state.Holding, error) {
 
-	contractHash, err := contractAddress.Hash()
+	var results []*state.Holding
+	err := ForEach(ctx, dbConn, contractAddress, assetCode, func(h *state.Holding) error {
+		results = append(results, h)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	path := fmt.Sprintf("%s/%s/%s/%s",
-		storageKey,
-		contractHash.String(),
-		storageSubKey,
-		assetCode.String())
+	return results, nil
+}
 
-	keys, err := dbConn.List(ctx, path)
+// ForEach calls f with each holding in storage for a specified asset. Holdings are fetched one at a
+//   time so memory use doesn't grow with the number of holdings. Iteration stops at the first
+//   error returned by f.
+func ForEach(ctx context.Context,
+	dbConn *db.DB,
+	contractAddress bitcoin.RawAddress,
+	assetCode *protocol.AssetCode,
+	f func(*state.Holding) error) error {
+
+	keys, err := List(ctx, dbConn, contractAddress, assetCode)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	results := make([]*state.Holding, 0, len(keys))
 	for _, key := range keys {
 		b, err := dbConn.Fetch(ctx, key)
 		if err != nil {
 			if err == db.ErrNotFound {
-				return nil, ErrNotFound
+				return ErrNotFound
 			}
 
-			return nil, errors.Wrap(err, "Failed to fetch holding")
+			return errors.Wrap(err, "Failed to fetch holding")
 		}
 
 		// Prepare the asset object
-		readResult, err := deserializeHolding(bytes.NewReader(b))
+		h, err := deserializeHolding(bytes.NewReader(b))
 		if err != nil {
-			return nil, errors.Wrap(err, "Failed to deserialize holding")
+			return errors.Wrap(err, "Failed to deserialize holding")
 		}
 
-		results = append(results, readResult)
+		if err := f(h); err != nil {
+			return err
+		}
 	}
 
-	return results, nil
+	return nil
 }
 
 // Fetch fetches a single holding from storage and places it in the cache.
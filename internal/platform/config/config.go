@@ -1,37 +1,88 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
 // Config is used to hold all runtime configuration.
 type Config struct {
 	Contract struct {
-		PrivateKey        string  `envconfig:"PRIV_KEY"`
-		OperatorName      string  `envconfig:"OPERATOR_NAME"`
-		Version           string  `envconfig:"VERSION"`
-		FeeAddress        string  `envconfig:"FEE_ADDRESS"`
-		FeeRate           float32 `default:"1.0" envconfig:"FEE_RATE"`
-		DustLimit         uint64  `default:"546" envconfig:"DUST_LIMIT"`
-		RequestTimeout    uint64  `default:"60000000000" envconfig:"REQUEST_TIMEOUT"` // Default 1 minute
-		PreprocessThreads int     `default:"4" envconfig:"PREPROCESS_THREADS"`
-		IsTest            bool    `default:"true" envconfig:"IS_TEST"`
+		PrivateKey            string   `envconfig:"PRIV_KEY"`
+		MovePrivateKeys       []string `envconfig:"MOVE_PRIV_KEYS"` // Keys for addresses the contract may move to
+		OperatorName          string   `envconfig:"OPERATOR_NAME"`
+		Version               string   `envconfig:"VERSION"`
+		FeeAddress            string   `envconfig:"FEE_ADDRESS"`
+		FeeRate               float32  `default:"1.0" envconfig:"FEE_RATE"`
+		DustLimit             uint64   `default:"546" envconfig:"DUST_LIMIT"`
+		RequestTimeout        uint64   `default:"60000000000" envconfig:"REQUEST_TIMEOUT"` // Default 1 minute
+		PreprocessThreads     int      `default:"4" envconfig:"PREPROCESS_THREADS"`
+		IsTest                bool     `default:"true" envconfig:"IS_TEST"`
+		VerifyInputs          bool     `default:"false" envconfig:"VERIFY_INPUTS"`
+		VoteBalanceLock       bool     `default:"false" envconfig:"VOTE_BALANCE_LOCK"`
+		MaxEnforcementTargets int      `default:"500" envconfig:"MAX_ENFORCEMENT_TARGETS"`
 	}
 	Bitcoin struct {
 		Network string `default:"mainnet" envconfig:"BITCOIN_CHAIN"`
 	}
 	SpyNode struct {
-		Address        string `default:"127.0.0.1:8333" envconfig:"NODE_ADDRESS"`
-		UserAgent      string `default:"/Tokenized:0.1.0/" envconfig:"NODE_USER_AGENT"`
-		StartHash      string `envconfig:"START_HASH"`
-		UntrustedNodes int    `default:"25" envconfig:"UNTRUSTED_NODES"`
-		SafeTxDelay    int    `default:"2000" envconfig:"SAFE_TX_DELAY"`
-		ShotgunCount   int    `default:"100" envconfig:"SHOTGUN_COUNT"`
+		Address        string   `default:"127.0.0.1:8333" envconfig:"NODE_ADDRESS"`
+		UserAgent      string   `default:"/Tokenized:0.1.0/" envconfig:"NODE_USER_AGENT"`
+		StartHash      string   `envconfig:"START_HASH"`
+		UntrustedNodes int      `default:"25" envconfig:"UNTRUSTED_NODES"`
+		SafeTxDelay    int      `default:"2000" envconfig:"SAFE_TX_DELAY"`
+		ShotgunCount   int      `default:"100" envconfig:"SHOTGUN_COUNT"`
+		StaticPeers    []string `envconfig:"NODE_STATIC_PEERS"`
+
+		// SOCKS5 proxy for all peer connections. Peers are connected to directly if empty.
+		ProxyAddress  string `envconfig:"NODE_PROXY_ADDRESS"`
+		ProxyUsername string `envconfig:"NODE_PROXY_USERNAME"`
+		ProxyPassword string `envconfig:"NODE_PROXY_PASSWORD"`
+
+		TrustedTimeout   time.Duration `default:"30s" envconfig:"NODE_TRUSTED_TIMEOUT"`
+		UntrustedTimeout time.Duration `default:"15s" envconfig:"NODE_UNTRUSTED_TIMEOUT"`
+	}
+	TxStore struct {
+		MaxSize     uint64 `default:"100000000" envconfig:"TX_STORE_MAX_SIZE"` // Default 100 MB
+		RecentCount int    `default:"50000" envconfig:"TX_STORE_RECENT_COUNT"`
 	}
 	RpcNode struct {
-		Host     string `envconfig:"RPC_HOST"`
+		Host     string `envconfig:"RPC_HOST"` // RPC is disabled if empty
 		Username string `envconfig:"RPC_USERNAME"`
 		Password string `envconfig:"RPC_PASSWORD"`
+
+		TLS      bool   `envconfig:"RPC_TLS"`
+		CAFile   string `envconfig:"RPC_CA_FILE"`
+		CertFile string `envconfig:"RPC_CERT_FILE"`
+		KeyFile  string `envconfig:"RPC_KEY_FILE"`
+
+		Timeout          time.Duration `default:"30s" envconfig:"RPC_TIMEOUT"`
+		MaxAttempts      int           `default:"5" envconfig:"RPC_MAX_ATTEMPTS"`
+		RetryDelay       time.Duration `default:"500ms" envconfig:"RPC_RETRY_DELAY"`
+		BreakerThreshold int           `default:"10" envconfig:"RPC_BREAKER_THRESHOLD"`
+		BreakerCooldown  time.Duration `default:"30s" envconfig:"RPC_BREAKER_COOLDOWN"`
+	}
+	Electrum struct {
+		Address     string        `envconfig:"ELECTRUM_ADDRESS"` // Electrum is disabled if empty
+		TLS         bool          `default:"true" envconfig:"ELECTRUM_TLS"`
+		Timeout     time.Duration `default:"30s" envconfig:"ELECTRUM_TIMEOUT"`
+		MaxAttempts int           `default:"5" envconfig:"ELECTRUM_MAX_ATTEMPTS"`
+		RetryDelay  time.Duration `default:"500ms" envconfig:"ELECTRUM_RETRY_DELAY"`
+	}
+	Explorer struct {
+		URL           string        `envconfig:"EXPLORER_URL"` // Explorer is disabled if empty
+		TxPath        string        `default:"/tx/%s/hex" envconfig:"EXPLORER_TX_PATH"`
+		BroadcastPath string        `default:"/tx/raw" envconfig:"EXPLORER_BROADCAST_PATH"`
+		APIKey        string        `envconfig:"EXPLORER_API_KEY"`
+		APIKeyHeader  string        `default:"Authorization" envconfig:"EXPLORER_API_KEY_HEADER"`
+		Timeout       time.Duration `default:"30s" envconfig:"EXPLORER_TIMEOUT"`
+		MaxAttempts   int           `default:"5" envconfig:"EXPLORER_MAX_ATTEMPTS"`
+		RetryDelay    time.Duration `default:"500ms" envconfig:"EXPLORER_RETRY_DELAY"`
+	}
+	Alerts struct {
+		WebhookURL string        `envconfig:"ALERT_WEBHOOK_URL"` // Alerts are only logged if empty
+		Timeout    time.Duration `default:"10s" envconfig:"ALERT_TIMEOUT"`
 	}
 	AWS struct {
 		Region          string `default:"ap-southeast-2" envconfig:"AWS_REGION" json:"AWS_REGION"`
@@ -46,6 +97,19 @@ type Config struct {
 		Bucket string `default:"standalone" envconfig:"CONTRACT_STORAGE_BUCKET"`
 		Root   string `default:"./tmp" envconfig:"CONTRACT_STORAGE_ROOT"`
 	}
+	Log LogConfig
+}
+
+// LogConfig is the logging configuration. It is separate so it can be read before the rest of the
+//   config.
+type LogConfig struct {
+	FilePath     string        `envconfig:"LOG_FILE_PATH"` // Stdout if empty
+	Format       string        `default:"text" envconfig:"LOG_FORMAT"`
+	MaxSize      int64         `default:"104857600" envconfig:"LOG_MAX_SIZE"` // Default 100 MB
+	MaxAge       time.Duration `envconfig:"LOG_MAX_AGE"`
+	MaxBackups   int           `default:"10" envconfig:"LOG_MAX_BACKUPS"`
+	Levels       string        `envconfig:"LOG_LEVELS"`
+	AdminAddress string        `envconfig:"LOG_ADMIN_ADDRESS"` // Admin endpoint disabled if empty
 }
 
 // SafeConfig masks sensitive config values
@@ -55,9 +119,18 @@ func SafeConfig(cfg Config) *Config {
 	if len(cfgSafe.Contract.PrivateKey) > 0 {
 		cfgSafe.Contract.PrivateKey = "*** Masked ***"
 	}
+	if len(cfgSafe.Contract.MovePrivateKeys) > 0 {
+		cfgSafe.Contract.MovePrivateKeys = []string{"*** Masked ***"}
+	}
+	if len(cfgSafe.SpyNode.ProxyPassword) > 0 {
+		cfgSafe.SpyNode.ProxyPassword = "*** Masked ***"
+	}
 	if len(cfgSafe.RpcNode.Password) > 0 {
 		cfgSafe.RpcNode.Password = "*** Masked ***"
 	}
+	if len(cfgSafe.Explorer.APIKey) > 0 {
+		cfgSafe.Explorer.APIKey = "*** Masked ***"
+	}
 	if len(cfgSafe.AWS.AccessKeyID) > 0 {
 		cfgSafe.AWS.AccessKeyID = "*** Masked ***"
 	}
@@ -78,3 +151,14 @@ func Environment() (*Config, error) {
 
 	return &cfg, nil
 }
+
+// LogEnvironment returns the logging configuration sourced from environment variables
+func LogEnvironment() (*LogConfig, error) {
+	var cfg LogConfig
+
+	if err := envconfig.Process("NODE", &cfg); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
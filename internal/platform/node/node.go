@@ -58,6 +58,15 @@ type Config struct {
 	RequestTimeout     uint64 // Nanoseconds until a request to another contract times out and the original request is rejected.
 	PreprocessThreads  int
 	IsTest             bool
+	VerifyInputs       bool // Execute input scripts to verify signatures before processing requests.
+
+	// Lock the balances counted in a ballot until the vote closes so they can't be transferred
+	//   and voted again from another address.
+	VoteBalanceLock bool
+
+	// The most targets in one freeze or confiscation response. Orders with more targets are
+	//   split into chained responses. Zero is unlimited.
+	MaxEnforcementTargets int
 }
 
 // New creates an App value that handle a set of routes for the application.
@@ -104,12 +113,17 @@ func (a *App) Handle(verb, event string, handler Handler, mw ...Middleware) {
 			ctx = context.WithValue(ctx, KeyValues, &v)
 
 			// Add logger trace of beginning of contract and tx ids.
-			ctx = logger.ContextWithLogTrace(ctx, v.TraceID)
-			Log(ctx, "Trace Data : Contract %x Tx %s", bitcoin.Hash160(walletKey.Key.PublicKey().Bytes()), itx.Hash)
+			keyCtx := logger.ContextWithLogTrace(ctx, v.TraceID)
+			keyCtx = logger.ContextWithLogFields(keyCtx,
+				logger.Field{Name: logger.FieldContract,
+					Value: bitcoin.NewAddressFromRawAddress(walletKey.Address, a.config.Net).String()},
+				logger.Field{Name: logger.FieldTxID, Value: itx.Hash.String()},
+				logger.Field{Name: logger.FieldAction, Value: itx.MsgProto.Code()})
+			Log(keyCtx, "Trace Data : Contract %x Tx %s", bitcoin.Hash160(walletKey.Key.PublicKey().Bytes()), itx.Hash)
 
 			// Call the wrapped handler functions.
 			handled = true
-			if err := handler(ctx, w, itx, walletKey); err != nil {
+			if err := handler(keyCtx, w, itx, walletKey); err != nil {
 				return err
 			}
 		}
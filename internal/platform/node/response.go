@@ -56,12 +56,16 @@ func RespondReject(ctx context.Context, w *ResponseWriter, itx *inspector.Transa
 func RespondRejectText(ctx context.Context, w *ResponseWriter, itx *inspector.Transaction,
 	wk *wallet.Key, code uint32, text string) error {
 
+	ctx = logger.ContextWithLogField(ctx, logger.FieldRejection, code)
+
 	rejectionCode := actions.RejectionsData(code)
 	if rejectionCode == nil {
 		Error(ctx, w, fmt.Errorf("Rejection code %d not found", code))
 		return ErrNoResponse
 	}
 
+	LogWarn(ctx, "Rejecting request : %s", rejectionCode.Label)
+
 	v := ctx.Value(KeyValues).(*Values)
 
 	// Build rejection
@@ -48,6 +48,9 @@ type Contract struct {
 
 	AssetCodes []*protocol.AssetCode `json:"AssetCodes,omitempty"`
 
+	// Confiscation orders must be signed by an authority in the contract's registry.
+	ConfiscationAuthorityRequired bool `json:"ConfiscationAuthorityRequired,omitempty"`
+
 	FullOracles []bitcoin.PublicKey `json:"_,omitempty"`
 }
 
@@ -86,7 +89,7 @@ type Holding struct {
 }
 
 type HoldingStatus struct {
-	// Code F = Freeze, R = Pending Receive, S = Pending Send
+	// Code F = Freeze, R = Pending Receive, S = Pending Send, V = Vote Lock
 	Code byte `json:"Code,omitempty"`
 
 	Expires        protocol.Timestamp `json:"Expires,omitempty"`
@@ -116,12 +119,36 @@ type Vote struct {
 	CreatedAt    protocol.Timestamp `json:"CreatedAt,omitempty"`
 	UpdatedAt    protocol.Timestamp `json:"UpdatedAt,omitempty"`
 
+	// Requirement of the voting system when the vote was created. Nil if there wasn't one.
+	Requirement *VotingRequirement `json:"Requirement,omitempty"`
+
 	OptionTally []uint64           `json:"OptionTally,omitempty"`
 	Result      string             `json:"Result,omitempty"`
 	AppliedTxId *protocol.TxId     `json:"AppliedTxId,omitempty"`
 	CompletedAt protocol.Timestamp `json:"CompletedAt,omitempty"`
 
+	// Ballots are the current ballot of each address. They are what is tallied.
 	Ballots []*Ballot `json:"Ballots,omitempty"`
+
+	// BallotHistory is every ballot counted, including ones later recast or revoked, in the order
+	//   they were counted. Revocations have an empty Vote.
+	BallotHistory []*Ballot `json:"BallotHistory,omitempty"`
+
+	// PendingBallots are ballots accepted by a request whose response hasn't been processed yet.
+	//   They keep the balances found when the request was accepted so the response counts the
+	//   same ones.
+	PendingBallots []*Ballot `json:"PendingBallots,omitempty"`
+}
+
+// VotingRequirement defines participation a vote needs for its result to count. The protocol's
+//   voting system doesn't have these fields so they are published in a supporting document of the
+//   contract terms.
+type VotingRequirement struct {
+	// Percentage of the vote's token quantity that must be voted.
+	QuorumPercentage uint32 `json:"QuorumPercentage,omitempty"`
+
+	// Number of ballots that must be cast.
+	MinimumBallots uint32 `json:"MinimumBallots,omitempty"`
 }
 
 type Ballot struct {
@@ -129,6 +156,29 @@ type Ballot struct {
 	Vote      string             `json:"Vote,omitempty"`
 	Quantity  uint64             `json:"Quantity,omitempty"`
 	Timestamp protocol.Timestamp `json:"Timestamp,omitempty"`
+	TxId      *protocol.TxId     `json:"TxId,omitempty"` // Ballot cast tx
+
+	// Balances carried by the ballot. Empty for ballots counted before delegation, which only
+	//   carry the balance of Address.
+	Shares []*BallotShare `json:"Shares,omitempty"`
+}
+
+// BallotShare is the balance of a holder that was counted in a ballot.
+type BallotShare struct {
+	Address  bitcoin.RawAddress `json:"Address,omitempty"`
+	Quantity uint64             `json:"Quantity,omitempty"`
+}
+
+// Delegation gives a proxy the voting power of a holder for all of a contract's votes, or for a
+//   single vote when VoteTxId is set.
+type Delegation struct {
+	Delegator bitcoin.RawAddress `json:"Delegator,omitempty"`
+	Proxy     bitcoin.RawAddress `json:"Proxy,omitempty"`
+	VoteTxId  *protocol.TxId     `json:"VoteTxId,omitempty"`
+	TxId      *protocol.TxId     `json:"TxId,omitempty"` // Message that set the delegation
+	Timestamp protocol.Timestamp `json:"Timestamp,omitempty"`
+	CreatedAt protocol.Timestamp `json:"CreatedAt,omitempty"`
+	UpdatedAt protocol.Timestamp `json:"UpdatedAt,omitempty"`
 }
 
 // PendingTransfer defines the information required to monitor pending multi-contract transfers.
@@ -136,3 +186,79 @@ type PendingTransfer struct {
 	TransferTxId *protocol.TxId     `json:"TransferTxId,omitempty"`
 	Timeout      protocol.Timestamp `json:"Timeout,omitempty"`
 }
+
+// PendingThaw defines the information required to thaw a time-limited freeze when it expires.
+type PendingThaw struct {
+	FreezeTxId *protocol.TxId     `json:"FreezeTxId,omitempty"`
+	Expires    protocol.Timestamp `json:"Expires,omitempty"`
+}
+
+// EnforcementBatch tracks the progress of an enforcement order with too many targets for one
+//   response. Each response spends the contract output of the previous one, starting with the
+//   order.
+type EnforcementBatch struct {
+	OrderTxId     *protocol.TxId     `json:"OrderTxId,omitempty"`
+	Next          uint32             `json:"Next,omitempty"`     // Index of next target to respond to
+	PrevTxId      *protocol.TxId     `json:"PrevTxId,omitempty"` // Tx funding the next response
+	ResponseTxIds []*protocol.TxId   `json:"ResponseTxIds,omitempty"`
+	CreatedAt     protocol.Timestamp `json:"CreatedAt,omitempty"`
+	UpdatedAt     protocol.Timestamp `json:"UpdatedAt,omitempty"`
+	CompletedAt   protocol.Timestamp `json:"CompletedAt,omitempty"`
+}
+
+// EnforcementAuthority is an authority recognized by a contract as a signer of enforcement
+//   orders.
+type EnforcementAuthority struct {
+	Name       string             `json:"Name,omitempty"`
+	PublicKey  bitcoin.PublicKey  `json:"PublicKey,omitempty"`
+	ValidFrom  protocol.Timestamp `json:"ValidFrom,omitempty"`  // Zero when valid since registered
+	ValidUntil protocol.Timestamp `json:"ValidUntil,omitempty"` // Zero when valid until revoked
+	RevokedAt  protocol.Timestamp `json:"RevokedAt,omitempty"`
+	TxId       *protocol.TxId     `json:"TxId,omitempty"` // Message that last changed the authority
+	CreatedAt  protocol.Timestamp `json:"CreatedAt,omitempty"`
+	UpdatedAt  protocol.Timestamp `json:"UpdatedAt,omitempty"`
+}
+
+// ReconciliationRecord is the audit record of a reconciliation order. It is created when the
+//   order is accepted and completed when its response is processed.
+type ReconciliationRecord struct {
+	OrderTxId    *protocol.TxId      `json:"OrderTxId,omitempty"`
+	ResponseTxId *protocol.TxId      `json:"ResponseTxId,omitempty"`
+	AssetType    string              `json:"AssetType,omitempty"`
+	AssetCode    *protocol.AssetCode `json:"AssetCode,omitempty"`
+	Message      string              `json:"Message,omitempty"`
+	Operator     bitcoin.RawAddress  `json:"Operator,omitempty"` // Sender of the order
+
+	AuthorityName      string `json:"AuthorityName,omitempty"`
+	AuthorityPublicKey []byte `json:"AuthorityPublicKey,omitempty"`
+	SignatureAlgorithm uint32 `json:"SignatureAlgorithm,omitempty"`
+	OrderSignature     []byte `json:"OrderSignature,omitempty"`
+
+	Holdings []*ReconciledHolding `json:"Holdings,omitempty"`
+
+	CreatedAt   protocol.Timestamp `json:"CreatedAt,omitempty"`
+	UpdatedAt   protocol.Timestamp `json:"UpdatedAt,omitempty"`
+	CompletedAt protocol.Timestamp `json:"CompletedAt,omitempty"`
+}
+
+// ReconciledHolding is the change a reconciliation order made to a holding.
+type ReconciledHolding struct {
+	Address       bitcoin.RawAddress `json:"Address,omitempty"`
+	Quantity      uint64             `json:"Quantity,omitempty"` // Tokens removed
+	BalanceBefore uint64             `json:"BalanceBefore,omitempty"`
+	BalanceAfter  uint64             `json:"BalanceAfter,omitempty"`
+	Dispersion    uint64             `json:"Dispersion,omitempty"` // Satoshis paid to the holder
+}
+
+// ContractMigration tracks the progress of moving a contract to a new address so an interrupted
+//   move can be resumed.
+type ContractMigration struct {
+	From        bitcoin.RawAddress `json:"From,omitempty"`
+	To          bitcoin.RawAddress `json:"To,omitempty"`
+	RequestTxId *protocol.TxId     `json:"RequestTxId,omitempty"`
+	Step        uint32             `json:"Step,omitempty"` // Last step completed
+	SweepTxId   *protocol.TxId     `json:"SweepTxId,omitempty"`
+	CreatedAt   protocol.Timestamp `json:"CreatedAt,omitempty"`
+	UpdatedAt   protocol.Timestamp `json:"UpdatedAt,omitempty"`
+	CompletedAt protocol.Timestamp `json:"CompletedAt,omitempty"`
+}
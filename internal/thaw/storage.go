@@ -0,0 +1,114 @@
+package thaw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/internal/platform/db"
+	"github.com/tokenized/smart-contract/internal/platform/state"
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+const storageKey = "contracts"
+const storageSubKey = "thaws"
+
+var (
+	// ErrNotFound abstracts the standard not found error.
+	ErrNotFound = errors.New("Pending thaw not found")
+)
+
+// Put a single pending thaw in storage
+func Save(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress, t *state.PendingThaw) error {
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return err
+	}
+	key := buildStoragePath(contractHash, t.FreezeTxId)
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	return dbConn.Put(ctx, key, data)
+}
+
+// Fetch a single pending thaw from storage
+func Fetch(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	freezeTxId *protocol.TxId) (*state.PendingThaw, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+	key := buildStoragePath(contractHash, freezeTxId)
+
+	data, err := dbConn.Fetch(ctx, key)
+	if err != nil {
+		if err == db.ErrNotFound {
+			err = ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	result := state.PendingThaw{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func Remove(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	freezeTxId *protocol.TxId) error {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return err
+	}
+	err = dbConn.Remove(ctx, buildStoragePath(contractHash, freezeTxId))
+	if err != nil {
+		if err == db.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// List all pending thaws for a specified contract.
+func List(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress) ([]*state.PendingThaw, error) {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dbConn.Search(ctx, fmt.Sprintf("%s/%s/%s", storageKey, contractHash.String(),
+		storageSubKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*state.PendingThaw, 0, len(data))
+	for _, b := range data {
+		pendingThaw := state.PendingThaw{}
+
+		if err := json.Unmarshal(b, &pendingThaw); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &pendingThaw)
+	}
+
+	return result, nil
+}
+
+// Returns the storage path prefix for a given identifier.
+func buildStoragePath(contractHash *bitcoin.Hash20, txid *protocol.TxId) string {
+	return fmt.Sprintf("%s/%s/%s/%s", storageKey, contractHash.String(), storageSubKey, txid.String())
+}
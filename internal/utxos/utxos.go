@@ -111,3 +111,20 @@ func (us *UTXOs) Get(amount uint64, address bitcoin.RawAddress) ([]*UTXO, error)
 
 	return result, errors.New("Not enough funds")
 }
+
+// Unspent returns all UTXOs for the address that haven't been spent.
+func (us *UTXOs) Unspent(address bitcoin.RawAddress) []*UTXO {
+	var result []*UTXO
+	for _, existing := range us.list {
+		if !bytes.Equal(existing.SpentBy[:], zeroTxId[:]) {
+			continue
+		}
+		outputAddress, err := bitcoin.RawAddressFromLockingScript(existing.Output.PkScript)
+		if err != nil || !address.Equal(outputAddress) {
+			continue
+		}
+		result = append(result, existing)
+	}
+
+	return result
+}
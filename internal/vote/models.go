@@ -20,6 +20,8 @@ type NewVote struct {
 	TokenQty     uint64             `json:"TokenQty,omitempty"`
 	Expires      protocol.Timestamp `json:"Expires,omitempty"`
 	Timestamp    protocol.Timestamp `json:"Timestamp,omitempty"`
+
+	Requirement *state.VotingRequirement `json:"Requirement,omitempty"`
 }
 
 // UpdateVote struct { defines what information may be provided to modify an
@@ -30,4 +32,6 @@ type UpdateVote struct {
 	OptionTally *[]uint64           `json:"OptionTally,omitempty"`
 	Result      *string             `json:"Result,omitempty"`
 	NewBallot   *state.Ballot       `json:"NewBallot,omitempty"`
+
+	PendingBallot *state.Ballot `json:"PendingBallot,omitempty"`
 }
@@ -60,6 +60,24 @@ func Fetch(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddres
 	return &result, nil
 }
 
+// Remove a single vote from storage
+func Remove(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	voteTxId *protocol.TxId) error {
+
+	contractHash, err := contractAddress.Hash()
+	if err != nil {
+		return err
+	}
+
+	if err := dbConn.Remove(ctx, buildStoragePath(contractHash, voteTxId)); err != nil {
+		if err == db.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
 // List all votes for a specified contract.
 func List(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress) ([]*state.Vote, error) {
 	contractHash, err := contractAddress.Hash()
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/big"
 
 	"github.com/tokenized/smart-contract/internal/platform/db"
 	"github.com/tokenized/smart-contract/internal/platform/node"
@@ -37,6 +38,26 @@ func Retrieve(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAdd
 	return v, nil
 }
 
+// RetrieveByProposal gets the vote created for the specified proposal.
+func RetrieveByProposal(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	proposalTxId *protocol.TxId) (*state.Vote, error) {
+	ctx, span := trace.StartSpan(ctx, "internal.vote.RetrieveByProposal")
+	defer span.End()
+
+	votes, err := List(ctx, dbConn, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range votes {
+		if v.ProposalTxId != nil && v.ProposalTxId.Equal(*proposalTxId) {
+			return v, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
 // Create the vote
 func Create(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress, voteID *protocol.TxId, nv *NewVote,
 	now protocol.Timestamp) error {
@@ -88,7 +109,11 @@ func Update(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddre
 		v.AppliedTxId = uv.AppliedTxId
 	}
 	if uv.NewBallot != nil {
-		v.Ballots = append(v.Ballots, uv.NewBallot)
+		applyBallot(v, uv.NewBallot)
+	}
+	if uv.PendingBallot != nil {
+		v.PendingBallots = append(removePendingBallot(v.PendingBallots, uv.PendingBallot.TxId),
+			uv.PendingBallot)
 	}
 
 	v.UpdatedAt = now
@@ -114,34 +139,107 @@ func MarkApplied(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.Raw
 	return Save(ctx, dbConn, contractAddress, v)
 }
 
-func CheckBallot(ctx context.Context, vt *state.Vote, holderAddress bitcoin.RawAddress) error {
+// FindPendingBallot returns the ballot accepted by the ballot cast tx, or nil if it isn't pending.
+func FindPendingBallot(vt *state.Vote, txid *protocol.TxId) *state.Ballot {
+	for _, bt := range vt.PendingBallots {
+		if bt.TxId != nil && bt.TxId.Equal(*txid) {
+			return bt
+		}
+	}
+
+	return nil
+}
+
+// removePendingBallot returns the pending ballots without the ballot of the ballot cast tx.
+func removePendingBallot(ballots []*state.Ballot, txid *protocol.TxId) []*state.Ballot {
+	result := make([]*state.Ballot, 0, len(ballots))
+	for _, bt := range ballots {
+		if bt.TxId == nil || txid == nil || !bt.TxId.Equal(*txid) {
+			result = append(result, bt)
+		}
+	}
+
+	return result
+}
+
+// FindBallot returns the current ballot of the address, or nil if it doesn't have one.
+func FindBallot(vt *state.Vote, address bitcoin.RawAddress) *state.Ballot {
 	for _, bt := range vt.Ballots {
-		if bt.Address.Equal(holderAddress) {
-			return errors.New("Ballot already accepted for pkh")
+		if bt.Address.Equal(address) {
+			return bt
 		}
 	}
 
 	return nil
 }
 
-func AddBallot(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
-	vt *state.Vote, ballot *state.Ballot, now protocol.Timestamp) error {
+// WithoutBallot returns a copy of the vote without the current ballot of the address. Balances
+//   for a recast ballot are found against it so the ballot being replaced doesn't count them.
+func WithoutBallot(vt *state.Vote, address bitcoin.RawAddress) *state.Vote {
+	result := *vt
+	result.Ballots = make([]*state.Ballot, 0, len(vt.Ballots))
+	for _, bt := range vt.Ballots {
+		if !bt.Address.Equal(address) {
+			result.Ballots = append(result.Ballots, bt)
+		}
+	}
+
+	return &result
+}
+
+// BalanceCounted returns true if the holder's balance has already been counted in a ballot,
+//   either its own or a proxy's.
+func BalanceCounted(vt *state.Vote, holderAddress bitcoin.RawAddress) bool {
 	for _, bt := range vt.Ballots {
-		if bytes.Equal(bt.Address.Bytes(), ballot.Address.Bytes()) {
-			return errors.New("Ballot already accepted for pkh")
+		if len(bt.Shares) == 0 {
+			if bt.Address.Equal(holderAddress) {
+				return true
+			}
+			continue
+		}
+
+		for _, share := range bt.Shares {
+			if share.Address.Equal(holderAddress) {
+				return true
+			}
 		}
 	}
 
+	return false
+}
+
+// AddBallot counts a ballot. It replaces any current ballot of the same address, so holders can
+//   recast while the vote is open. A ballot with an empty vote revokes the current ballot.
+func AddBallot(ctx context.Context, dbConn *db.DB, contractAddress bitcoin.RawAddress,
+	vt *state.Vote, ballot *state.Ballot, now protocol.Timestamp) error {
+
 	uv := UpdateVote{NewBallot: ballot}
 
 	if err := Update(ctx, dbConn, contractAddress, vt.VoteTxId, &uv, now); err != nil {
 		return errors.Wrap(err, "Failed to update vote")
 	}
 
-	vt.Ballots = append(vt.Ballots, ballot)
+	applyBallot(vt, ballot)
 	return nil
 }
 
+// applyBallot replaces the current ballot of the ballot's address and records it in the history.
+func applyBallot(vt *state.Vote, ballot *state.Ballot) {
+	ballots := make([]*state.Ballot, 0, len(vt.Ballots)+1)
+	for _, bt := range vt.Ballots {
+		if !bt.Address.Equal(ballot.Address) {
+			ballots = append(ballots, bt)
+		}
+	}
+	if len(ballot.Vote) > 0 {
+		ballots = append(ballots, ballot)
+	}
+
+	vt.Ballots = ballots
+	vt.BallotHistory = append(vt.BallotHistory, ballot)
+	vt.PendingBallots = removePendingBallot(vt.PendingBallots, ballot.TxId)
+}
+
 // CalculateResults calculates the result of a completed vote.
 func CalculateResults(ctx context.Context, vt *state.Vote, proposal *actions.Proposal,
 	votingSystem *actions.VotingSystemField) ([]uint64, string, error) {
@@ -171,6 +269,19 @@ func CalculateResults(ctx context.Context, vt *state.Vote, proposal *actions.Pro
 		votedQuantity += ballot.Quantity
 	}
 
+	// Convert tallys back to integers
+	tallys := make([]uint64, len(proposal.VoteOptions))
+	for i, floatTally := range floatTallys {
+		logger.Verbose(ctx, "Vote result %c : %d", proposal.VoteOptions[i], uint64(floatTally))
+		tallys[i] = uint64(floatTally)
+	}
+
+	if !MeetsRequirement(vt, votedQuantity) {
+		logger.Verbose(ctx, "Processed vote : no quorum %d/%d voted in %d ballots", votedQuantity,
+			vt.TokenQty, len(vt.Ballots))
+		return tallys, "", nil // No options pass
+	}
+
 	var winners bytes.Buffer
 	var highestIndex int
 	var highestScore float32
@@ -212,17 +323,37 @@ func CalculateResults(ctx context.Context, vt *state.Vote, proposal *actions.Pro
 		scored[highestIndex] = true
 	}
 
-	// Convert tallys back to integers
-	tallys := make([]uint64, len(proposal.VoteOptions))
-	for i, floatTally := range floatTallys {
-		logger.Verbose(ctx, "Vote result %c : %d", proposal.VoteOptions[i], uint64(floatTally))
-		tallys[i] = uint64(floatTally)
-	}
-
 	logger.Verbose(ctx, "Processed vote : winners %s", winners.String())
 	return tallys, winners.String(), nil
 }
 
+// MeetsRequirement returns true if the ballots of the vote meet the vote's requirement. Votes
+//   without a requirement always meet it.
+func MeetsRequirement(vt *state.Vote, votedQuantity uint64) bool {
+	if vt.Requirement == nil {
+		return true
+	}
+
+	if uint64(len(vt.Ballots)) < uint64(vt.Requirement.MinimumBallots) {
+		return false
+	}
+
+	// Compare as integers to avoid rounding. Token quantities can use the full range of uint64, so
+	//   the products can't.
+	voted := new(big.Int).Mul(new(big.Int).SetUint64(votedQuantity), big.NewInt(100))
+	quorum := new(big.Int).Mul(new(big.Int).SetUint64(vt.TokenQty),
+		new(big.Int).SetUint64(uint64(vt.Requirement.QuorumPercentage)))
+	return voted.Cmp(quorum) >= 0
+}
+
+// ValidateVotingRequirement returns an error if the requirement's values are out of range.
+func ValidateVotingRequirement(requirement *state.VotingRequirement) error {
+	if requirement.QuorumPercentage > 100 {
+		return fmt.Errorf("Quorum Percentage out of range : %d", requirement.QuorumPercentage)
+	}
+	return nil
+}
+
 func ValidateVotingSystem(system *actions.VotingSystemField) error {
 	if system.VoteType != "R" && system.VoteType != "A" && system.VoteType != "P" {
 		return fmt.Errorf("Unsupported vote type : %s", system.VoteType)
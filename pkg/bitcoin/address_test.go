@@ -208,5 +208,65 @@ func TestSH(t *testing.T) {
 	}
 }
 
-// TODO func TestMultiPKH(t *testing.T) {
+func TestMultiPKH(t *testing.T) {
+	var pkhs [][]byte
+	for i := 1; i <= 3; i++ {
+		pkhs = append(pkhs, bytes.Repeat([]byte{byte(i)}, ScriptHashLength))
+	}
+
+	address, err := NewAddressMultiPKH(2, pkhs, MainNet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := NewRawAddressFromAddress(address).LockingScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The valid signature count must be greater than or equal to the required count.
+	required := PushNumberScript(2)
+	tail := append(append([]byte{OP_FROMALTSTACK}, required...), OP_GREATERTHANOREQUAL)
+	if !bytes.HasSuffix(script, tail) {
+		t.Fatalf("Invalid MultiPKH locking script generated : %x", script)
+	}
+
+	parsed, err := RawAddressFromLockingScript(script)
+	if err != nil {
+		t.Fatalf("Failed to parse MultiPKH locking script : %s", err)
+	}
+	parsedPKHs, err := parsed.GetMultiPKH()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsedPKHs) != len(pkhs) {
+		t.Fatalf("MultiPKH parse script invalid pkh count\ngot:%d\nwant:%d", len(parsedPKHs),
+			len(pkhs))
+	}
+	for i, pkh := range pkhs {
+		if !bytes.Equal(parsedPKHs[i], pkh) {
+			t.Fatalf("MultiPKH parse script invalid pkh %d\ngot:%x\nwant:%x", i, parsedPKHs[i], pkh)
+		}
+	}
+
+	// Scripts in the legacy operand order are parsed to the same address.
+	legacy := append([]byte{}, script[:len(script)-len(tail)]...)
+	legacy = append(append(legacy, required...), OP_FROMALTSTACK, OP_GREATERTHANOREQUAL)
+	legacyParsed, err := RawAddressFromLockingScript(legacy)
+	if err != nil {
+		t.Fatalf("Failed to parse legacy MultiPKH locking script : %s", err)
+	}
+	if !legacyParsed.Equal(parsed) {
+		t.Fatalf("Legacy MultiPKH parse script invalid\ngot:%x\nwant:%x", legacyParsed.Bytes(),
+			parsed.Bytes())
+	}
+
+	// Other orders are not MultiPKH.
+	invalid := append([]byte{}, script[:len(script)-len(tail)]...)
+	invalid = append(append(invalid, OP_FROMALTSTACK, OP_GREATERTHANOREQUAL), required...)
+	if _, err := RawAddressFromLockingScript(invalid); err != ErrUnknownScriptTemplate {
+		t.Fatalf("Wrong error for invalid MultiPKH script : %v", err)
+	}
+}
+
 // TODO func TestRPuzzle(t *testing.T) {
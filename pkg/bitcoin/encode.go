@@ -115,6 +115,11 @@ func BIP0276Decode(url string) (Network, string, []byte, error) {
 	}
 	b = b[1:] // Drop network
 
+	if len(b) < 4 {
+		return InvalidNet, "", nil, errors.New("Too Short")
+	}
+	b = b[:len(b)-4] // Drop check hash
+
 	return net, parts[0], b, nil
 }
 
@@ -193,5 +198,10 @@ func BIP0276Decode58(url string) (Network, string, []byte, error) {
 	}
 	b = b[1:] // Drop network
 
+	if len(b) < 4 {
+		return InvalidNet, "", nil, errors.New("Too Short")
+	}
+	b = b[:len(b)-4] // Drop check hash
+
 	return net, parts[0], b, nil
 }
@@ -0,0 +1,36 @@
+package bitcoin
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBIP0276(t *testing.T) {
+	data := []byte{0x20, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}
+
+	for _, net := range []Network{MainNet, TestNet} {
+		decodedNet, prefix, decoded, err := BIP0276Decode(BIP0276Encode(net, "test-prefix", data))
+		if err != nil {
+			t.Fatalf("Failed to decode hex : %s", err)
+		}
+		if decodedNet != net || prefix != "test-prefix" || !bytes.Equal(decoded, data) {
+			t.Fatalf("Wrong hex decode : %d %s %x", decodedNet, prefix, decoded)
+		}
+
+		decodedNet, prefix, decoded, err = BIP0276Decode58(BIP0276Encode58(net, "test-prefix",
+			data))
+		if err != nil {
+			t.Fatalf("Failed to decode base58 : %s", err)
+		}
+		if decodedNet != net || prefix != "test-prefix" || !bytes.Equal(decoded, data) {
+			t.Fatalf("Wrong base58 decode : %d %s %x", decodedNet, prefix, decoded)
+		}
+	}
+
+	// Modified data
+	encoded := []byte(BIP0276Encode(MainNet, "test-prefix", data))
+	encoded[len("test-prefix:0101")] ^= 1
+	if _, _, _, err := BIP0276Decode(string(encoded)); err != ErrCheckHashInvalid {
+		t.Fatalf("Decoded invalid check hash : %v", err)
+	}
+}
@@ -225,6 +225,14 @@ func RawAddressFromLockingScript(lockingScript []byte) (RawAddress, error) {
 			return RawAddress{}, ErrUnknownScriptTemplate
 		}
 
+		// Legacy scripts push the required count before the valid signature count, so they only
+		//   check that there are at most the required count of signatures. They are still parsed
+		//   so existing outputs keep their address.
+		legacy := script[0] != OP_FROMALTSTACK
+		if !legacy {
+			script = script[1:]
+		}
+
 		// Parse required signature count
 		required, length, err := ParsePushNumberScript(script)
 		if err != nil {
@@ -232,14 +240,14 @@ func RawAddressFromLockingScript(lockingScript []byte) (RawAddress, error) {
 		}
 		script = script[length:]
 
-		if len(script) != 2 {
-			return RawAddress{}, ErrUnknownScriptTemplate
-		}
-
-		if script[0] != OP_FROMALTSTACK {
+		if legacy {
+			if len(script) != 2 || script[0] != OP_FROMALTSTACK {
+				return RawAddress{}, ErrUnknownScriptTemplate
+			}
+			script = script[1:]
+		} else if len(script) != 1 {
 			return RawAddress{}, ErrUnknownScriptTemplate
 		}
-		script = script[1:]
 
 		if script[0] != OP_GREATERTHANOREQUAL {
 			return RawAddress{}, ErrUnknownScriptTemplate
@@ -352,9 +360,9 @@ func (ra RawAddress) LockingScript() ([]byte, error) {
 			result = append(result, OP_ENDIF)
 		}
 
-		// Check required signature count
-		result = append(result, PushNumberScript(int64(required))...)
+		// Check valid signature count is at least the required count
 		result = append(result, OP_FROMALTSTACK)
+		result = append(result, PushNumberScript(int64(required))...)
 		result = append(result, OP_GREATERTHANOREQUAL)
 		return result, nil
 	}
@@ -10,30 +10,44 @@ import (
 )
 
 const (
-	OP_FALSE              = 0x00
-	OP_TRUE               = 0x51
-	OP_1NEGATE            = 0x4f
-	OP_1                  = 0x51
-	OP_3                  = 0x53
-	OP_16                 = 0x60
-	OP_RETURN             = 0x6a
-	OP_DUP                = 0x76
-	OP_HASH160            = 0xa9
-	OP_PUSH_DATA_20       = 0x14
-	OP_EQUAL              = 0x87
-	OP_EQUALVERIFY        = 0x88
-	OP_GREATERTHANOREQUAL = 0xa2
-	OP_CHECKSIG           = 0xac
-	OP_CHECKSIGVERIFY     = 0xad
-	OP_IF                 = 0x63
-	OP_ENDIF              = 0x68
-	OP_TOALTSTACK         = 0x6b
-	OP_FROMALTSTACK       = 0x6c
-	OP_1ADD               = 0x8b
-	OP_SPLIT              = 0x7f
-	OP_NIP                = 0x77
-	OP_SWAP               = 0x7c
-	OP_DROP               = 0x75
+	OP_FALSE               = 0x00
+	OP_TRUE                = 0x51
+	OP_1NEGATE             = 0x4f
+	OP_1                   = 0x51
+	OP_3                   = 0x53
+	OP_16                  = 0x60
+	OP_RETURN              = 0x6a
+	OP_DUP                 = 0x76
+	OP_HASH160             = 0xa9
+	OP_PUSH_DATA_20        = 0x14
+	OP_EQUAL               = 0x87
+	OP_EQUALVERIFY         = 0x88
+	OP_GREATERTHANOREQUAL  = 0xa2
+	OP_CHECKSIG            = 0xac
+	OP_CHECKSIGVERIFY      = 0xad
+	OP_IF                  = 0x63
+	OP_ENDIF               = 0x68
+	OP_TOALTSTACK          = 0x6b
+	OP_FROMALTSTACK        = 0x6c
+	OP_1ADD                = 0x8b
+	OP_SPLIT               = 0x7f
+	OP_NIP                 = 0x77
+	OP_SWAP                = 0x7c
+	OP_DROP                = 0x75
+	OP_2DROP               = 0x6d
+	OP_CHECKMULTISIG       = 0xae
+	OP_NOP                 = 0x61
+	OP_NOTIF               = 0x64
+	OP_ELSE                = 0x67
+	OP_VERIFY              = 0x69
+	OP_2DUP                = 0x6e
+	OP_OVER                = 0x78
+	OP_ROT                 = 0x7b
+	OP_SIZE                = 0x82
+	OP_NOT                 = 0x91
+	OP_SHA256              = 0xa8
+	OP_HASH256             = 0xaa
+	OP_CHECKMULTISIGVERIFY = 0xaf
 
 	// OP_MAX_SINGLE_BYTE_PUSH_DATA represents the max length for a single byte push
 	OP_MAX_SINGLE_BYTE_PUSH_DATA = byte(0x4b)
@@ -0,0 +1,270 @@
+package bitcoin
+
+// ScriptTemplate identifies the form of a script.
+type ScriptTemplate uint8
+
+const (
+	ScriptTemplateNonStandard ScriptTemplate = iota
+	ScriptTemplatePKH                        // Pay to public key hash
+	ScriptTemplateSH                         // Pay to script hash
+	ScriptTemplateMultiPKH                   // Multiple public key hashes
+	ScriptTemplateRPH                        // Pay to R puzzle hash
+	ScriptTemplatePK                         // Pay to public key
+	ScriptTemplateMultiSig                   // Bare multi-sig with OP_CHECKMULTISIG
+	ScriptTemplateOpReturn                   // Unspendable data
+)
+
+// String returns the name of the template.
+func (t ScriptTemplate) String() string {
+	switch t {
+	case ScriptTemplatePKH:
+		return "P2PKH"
+	case ScriptTemplateSH:
+		return "P2SH"
+	case ScriptTemplateMultiPKH:
+		return "MultiPKH"
+	case ScriptTemplateRPH:
+		return "RPH"
+	case ScriptTemplatePK:
+		return "P2PK"
+	case ScriptTemplateMultiSig:
+		return "MultiSig"
+	case ScriptTemplateOpReturn:
+		return "OP_RETURN"
+	}
+	return "NonStandard"
+}
+
+// LockingScriptInfo is the result of classifying a locking script.
+type LockingScriptInfo struct {
+	Template ScriptTemplate
+
+	// Address is set for templates that have a raw address. P2PKH, P2SH, Multi-PKH, and RPH.
+	Address RawAddress
+
+	// PublicKeys are set for P2PK and bare multi-sig.
+	PublicKeys [][]byte
+
+	// Required is the number of signatures required for bare multi-sig.
+	Required int
+
+	// Data is the pushes of an OP_RETURN script, or data pushed and dropped before the template.
+	Data [][]byte
+}
+
+// ParseLockingScript classifies a locking script. Data pushes followed by OP_DROP or OP_2DROP
+//   before a template are removed and returned in Data.
+func ParseLockingScript(script []byte) LockingScriptInfo {
+	result := LockingScriptInfo{Template: ScriptTemplateNonStandard}
+
+	items, err := ParseScript(script)
+	if err != nil || len(items) == 0 {
+		return result
+	}
+
+	// OP_RETURN or OP_FALSE OP_RETURN
+	if items[0].OpCode == OP_RETURN ||
+		(len(items) > 1 && items[0].OpCode == OP_FALSE && items[1].OpCode == OP_RETURN) {
+		result.Template = ScriptTemplateOpReturn
+		for _, item := range items {
+			if item.OpCode != OP_RETURN && item.OpCode != OP_FALSE && item.IsPush() {
+				result.Data = append(result.Data, item.Data)
+			}
+		}
+		return result
+	}
+
+	// Remove leading dropped data.
+	for {
+		if len(items) > 2 && items[0].IsPush() && items[1].OpCode == OP_DROP {
+			result.Data = append(result.Data, items[0].Data)
+			items = items[2:]
+			continue
+		}
+		if len(items) > 3 && items[0].IsPush() && items[1].IsPush() &&
+			items[2].OpCode == OP_2DROP {
+			result.Data = append(result.Data, items[0].Data, items[1].Data)
+			items = items[3:]
+			continue
+		}
+		break
+	}
+	remaining := script[items[0].Offset:]
+
+	if ra, err := RawAddressFromLockingScript(remaining); err == nil {
+		result.Address = ra
+		switch ra.Type() {
+		case ScriptTypePKH:
+			result.Template = ScriptTemplatePKH
+		case ScriptTypeSH:
+			result.Template = ScriptTemplateSH
+		case ScriptTypeMultiPKH:
+			result.Template = ScriptTemplateMultiPKH
+		case ScriptTypeRPH:
+			result.Template = ScriptTemplateRPH
+		}
+		return result
+	}
+
+	// P2PK : <public key> OP_CHECKSIG
+	if len(items) == 2 && isAnyPublicKey(items[0].Data) && items[1].OpCode == OP_CHECKSIG {
+		result.Template = ScriptTemplatePK
+		result.PublicKeys = [][]byte{items[0].Data}
+		return result
+	}
+
+	// Bare multi-sig : OP_m <public key> ... OP_n OP_CHECKMULTISIG
+	if len(items) >= 4 && items[len(items)-1].OpCode == OP_CHECKMULTISIG {
+		required, ok := smallNumber(items[0].OpCode)
+		count, countOk := smallNumber(items[len(items)-2].OpCode)
+		keys := items[1 : len(items)-2]
+		if ok && countOk && count == len(keys) && required > 0 && required <= count {
+			for _, item := range keys {
+				if !isAnyPublicKey(item.Data) {
+					return LockingScriptInfo{Template: ScriptTemplateNonStandard}
+				}
+				result.PublicKeys = append(result.PublicKeys, item.Data)
+			}
+			result.Template = ScriptTemplateMultiSig
+			result.Required = required
+			return result
+		}
+	}
+
+	return LockingScriptInfo{Template: ScriptTemplateNonStandard}
+}
+
+// UnlockingScriptInfo is the result of classifying an unlocking script.
+type UnlockingScriptInfo struct {
+	// Template is the template of the locking script it appears to unlock.
+	Template ScriptTemplate
+
+	// Address is set for P2PKH, P2SH and RPH.
+	Address RawAddress
+
+	PublicKeys [][]byte
+	Signatures [][]byte
+
+	// RedeemScript is the script that hashes to the P2SH address.
+	RedeemScript []byte
+}
+
+// ParseUnlockingScript classifies an unlocking script by the template of the locking script it
+//   appears to unlock.
+func ParseUnlockingScript(script []byte) UnlockingScriptInfo {
+	result := UnlockingScriptInfo{Template: ScriptTemplateNonStandard}
+
+	items, err := ParseScript(script)
+	if err != nil || len(items) == 0 {
+		return result
+	}
+	for _, item := range items {
+		if !item.IsPush() {
+			return result
+		}
+	}
+
+	if len(items) == 2 {
+		if ra, err := RawAddressFromUnlockingScript(script); err == nil {
+			result.Address = ra
+			if ra.Type() == ScriptTypePKH {
+				result.Template = ScriptTemplatePKH
+				result.Signatures = [][]byte{items[0].Data}
+				result.PublicKeys = [][]byte{items[1].Data}
+			} else {
+				result.Template = ScriptTemplateRPH
+				result.PublicKeys = [][]byte{items[0].Data}
+				result.Signatures = [][]byte{items[1].Data}
+			}
+			return result
+		}
+	}
+
+	// P2PK : <signature>
+	if len(items) == 1 && isSignature(items[0].Data) {
+		result.Template = ScriptTemplatePK
+		result.Signatures = [][]byte{items[0].Data}
+		return result
+	}
+
+	// Bare multi-sig : OP_0 <signature> ...
+	if len(items) > 1 && items[0].OpCode == OP_FALSE {
+		allSignatures := true
+		for _, item := range items[1:] {
+			if !isSignature(item.Data) {
+				allSignatures = false
+				break
+			}
+			result.Signatures = append(result.Signatures, item.Data)
+		}
+		if allSignatures {
+			result.Template = ScriptTemplateMultiSig
+			return result
+		}
+		result.Signatures = nil
+	}
+
+	// Multi-PKH : (<signature> <public key> OP_TRUE | OP_FALSE) ...
+	if info, ok := parseMultiPKHUnlock(items); ok {
+		return info
+	}
+
+	// P2SH : <unlock> ... <redeem script>
+	redeemScript := items[len(items)-1].Data
+	if len(redeemScript) > 0 {
+		redeem := ParseLockingScript(redeemScript)
+		if redeem.Template != ScriptTemplateNonStandard &&
+			redeem.Template != ScriptTemplateOpReturn {
+			ra, err := NewRawAddressSH(Hash160(redeemScript))
+			if err == nil {
+				result.Template = ScriptTemplateSH
+				result.Address = ra
+				result.RedeemScript = redeemScript
+				for _, item := range items[:len(items)-1] {
+					if isSignature(item.Data) {
+						result.Signatures = append(result.Signatures, item.Data)
+					} else if isAnyPublicKey(item.Data) {
+						result.PublicKeys = append(result.PublicKeys, item.Data)
+					}
+				}
+				return result
+			}
+		}
+	}
+
+	return UnlockingScriptInfo{Template: ScriptTemplateNonStandard}
+}
+
+func parseMultiPKHUnlock(items []ScriptItem) (UnlockingScriptInfo, bool) {
+	result := UnlockingScriptInfo{Template: ScriptTemplateMultiPKH}
+	signed := 0
+	for len(items) > 0 {
+		if items[0].OpCode == OP_FALSE {
+			items = items[1:]
+			continue
+		}
+		if len(items) < 3 || !isSignature(items[0].Data) || !isAnyPublicKey(items[1].Data) ||
+			items[2].OpCode != OP_TRUE {
+			return UnlockingScriptInfo{}, false
+		}
+		result.Signatures = append(result.Signatures, items[0].Data)
+		result.PublicKeys = append(result.PublicKeys, items[1].Data)
+		signed++
+		items = items[3:]
+	}
+
+	return result, signed > 0
+}
+
+// isAnyPublicKey returns true if the data is an encoded compressed or uncompressed public key.
+func isAnyPublicKey(b []byte) bool {
+	return isPublicKey(b) || (len(b) == 65 && b[0] == 0x04)
+}
+
+// smallNumber returns the value of a small number op code (OP_1 - OP_16).
+func smallNumber(opCode byte) (int, bool) {
+	if opCode >= OP_1 && opCode <= OP_16 {
+		return int(opCode-OP_1) + 1, true
+	}
+	return 0, false
+}
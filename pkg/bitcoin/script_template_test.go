@@ -0,0 +1,158 @@
+package bitcoin
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDisassembleScript(t *testing.T) {
+	pkh := bytes.Repeat([]byte{0xab}, 20)
+	ra, _ := NewRawAddressPKH(pkh)
+	script, _ := ra.LockingScript()
+
+	want := "OP_DUP OP_HASH160 abababababababababababababababababababab OP_EQUALVERIFY OP_CHECKSIG"
+	if got := DisassembleScript(script); got != want {
+		t.Fatalf("Wrong disassembly :\n  got  %s\n  want %s", got, want)
+	}
+
+	if got := DisassembleScript([]byte{OP_FALSE, OP_RETURN, 0x02, 0x01}); got != "OP_0 OP_RETURN [error]" {
+		t.Fatalf("Wrong disassembly of malformed script : %s", got)
+	}
+
+	if got := DisassembleScript([]byte{OP_1, 0x60, OP_CHECKMULTISIG}); got != "OP_1 OP_16 OP_CHECKMULTISIG" {
+		t.Fatalf("Wrong disassembly of small numbers : %s", got)
+	}
+}
+
+func TestParseLockingScript(t *testing.T) {
+	key1, _ := GenerateKey(MainNet)
+	key2, _ := GenerateKey(MainNet)
+	pk1 := key1.PublicKey().Bytes()
+	pk2 := key2.PublicKey().Bytes()
+
+	ra, _ := key1.RawAddress()
+	pkhScript, _ := ra.LockingScript()
+
+	var p2pk bytes.Buffer
+	WritePushDataScript(&p2pk, pk1)
+	p2pk.WriteByte(OP_CHECKSIG)
+
+	var multiSig bytes.Buffer
+	multiSig.WriteByte(OP_1)
+	WritePushDataScript(&multiSig, pk1)
+	WritePushDataScript(&multiSig, pk2)
+	multiSig.WriteByte(OP_1 + 1)
+	multiSig.WriteByte(OP_CHECKMULTISIG)
+
+	var dropped bytes.Buffer
+	WritePushDataScript(&dropped, []byte("data"))
+	dropped.WriteByte(OP_DROP)
+	dropped.Write(pkhScript)
+
+	var opReturn bytes.Buffer
+	opReturn.Write([]byte{OP_FALSE, OP_RETURN})
+	WritePushDataScript(&opReturn, []byte("hello"))
+
+	tests := []struct {
+		name     string
+		script   []byte
+		template ScriptTemplate
+		keys     int
+		data     int
+	}{
+		{"P2PKH", pkhScript, ScriptTemplatePKH, 0, 0},
+		{"P2PK", p2pk.Bytes(), ScriptTemplatePK, 1, 0},
+		{"MultiSig", multiSig.Bytes(), ScriptTemplateMultiSig, 2, 0},
+		{"Dropped data", dropped.Bytes(), ScriptTemplatePKH, 0, 1},
+		{"OP_RETURN", opReturn.Bytes(), ScriptTemplateOpReturn, 0, 1},
+		{"NonStandard", []byte{OP_TRUE}, ScriptTemplateNonStandard, 0, 0},
+		{"Malformed", []byte{0x05, 0x01}, ScriptTemplateNonStandard, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ParseLockingScript(tt.script)
+			if info.Template != tt.template {
+				t.Fatalf("Wrong template : got %s, want %s", info.Template, tt.template)
+			}
+			if len(info.PublicKeys) != tt.keys {
+				t.Fatalf("Wrong public key count : %d", len(info.PublicKeys))
+			}
+			if len(info.Data) != tt.data {
+				t.Fatalf("Wrong data count : %d", len(info.Data))
+			}
+			if tt.template == ScriptTemplatePKH && !info.Address.Equal(ra) {
+				t.Fatalf("Wrong address")
+			}
+			if tt.template == ScriptTemplateMultiSig && info.Required != 1 {
+				t.Fatalf("Wrong required count : %d", info.Required)
+			}
+		})
+	}
+}
+
+func TestParseUnlockingScript(t *testing.T) {
+	key, _ := GenerateKey(MainNet)
+	pk := key.PublicKey().Bytes()
+	signature, err := key.Sign(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("Failed to sign : %s", err)
+	}
+	sig := append(signature.Bytes(), 0x41) // Sig hash type
+
+	var pkh bytes.Buffer
+	WritePushDataScript(&pkh, sig)
+	WritePushDataScript(&pkh, pk)
+
+	var p2pk bytes.Buffer
+	WritePushDataScript(&p2pk, sig)
+
+	var multiSig bytes.Buffer
+	multiSig.WriteByte(OP_FALSE)
+	WritePushDataScript(&multiSig, sig)
+
+	var multiPKH bytes.Buffer
+	multiPKH.WriteByte(OP_FALSE)
+	WritePushDataScript(&multiPKH, sig)
+	WritePushDataScript(&multiPKH, pk)
+	multiPKH.WriteByte(OP_TRUE)
+
+	var redeem bytes.Buffer
+	redeem.WriteByte(OP_1)
+	WritePushDataScript(&redeem, pk)
+	redeem.WriteByte(OP_1)
+	redeem.WriteByte(OP_CHECKMULTISIG)
+
+	var p2sh bytes.Buffer
+	p2sh.WriteByte(OP_FALSE)
+	WritePushDataScript(&p2sh, sig)
+	WritePushDataScript(&p2sh, redeem.Bytes())
+
+	tests := []struct {
+		name     string
+		script   []byte
+		template ScriptTemplate
+	}{
+		{"P2PKH", pkh.Bytes(), ScriptTemplatePKH},
+		{"P2PK", p2pk.Bytes(), ScriptTemplatePK},
+		{"MultiSig", multiSig.Bytes(), ScriptTemplateMultiSig},
+		{"MultiPKH", multiPKH.Bytes(), ScriptTemplateMultiPKH},
+		{"P2SH", p2sh.Bytes(), ScriptTemplateSH},
+		{"NonStandard", []byte{OP_TRUE, OP_DUP}, ScriptTemplateNonStandard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ParseUnlockingScript(tt.script)
+			if info.Template != tt.template {
+				t.Fatalf("Wrong template : got %s, want %s", info.Template, tt.template)
+			}
+		})
+	}
+
+	info := ParseUnlockingScript(p2sh.Bytes())
+	want, _ := NewRawAddressSH(Hash160(redeem.Bytes()))
+	if !info.Address.Equal(want) || !bytes.Equal(info.RedeemScript, redeem.Bytes()) {
+		t.Fatalf("Wrong P2SH address or redeem script")
+	}
+}
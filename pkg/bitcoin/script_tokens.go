@@ -0,0 +1,187 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ScriptItem is one op code of a script, with the data it pushes if it is a push op.
+type ScriptItem struct {
+	OpCode byte
+	Data   []byte
+	Offset int // Offset of the op code in the script
+}
+
+// IsPush returns true if the item pushes data, including small number op codes.
+func (item ScriptItem) IsPush() bool {
+	return item.OpCode <= OP_PUSH_DATA_4 || item.OpCode == OP_1NEGATE ||
+		(item.OpCode >= OP_1 && item.OpCode <= OP_16)
+}
+
+// String returns the assembly representation of the item. Data pushes are hex.
+func (item ScriptItem) String() string {
+	if item.OpCode == OP_FALSE {
+		return "OP_0"
+	}
+	if item.OpCode <= OP_PUSH_DATA_4 {
+		return hex.EncodeToString(item.Data)
+	}
+	return OpCodeName(item.OpCode)
+}
+
+// ParseScript tokenizes a script into op codes and push data. If the script is malformed the items
+//   before the error are returned with the error.
+func ParseScript(script []byte) ([]ScriptItem, error) {
+	buf := bytes.NewReader(script)
+	var result []ScriptItem
+
+	for buf.Len() > 0 {
+		offset := len(script) - buf.Len()
+		opCode, data, err := ParsePushDataScript(buf)
+		if err == ErrNotPushOp {
+			result = append(result, ScriptItem{OpCode: opCode, Offset: offset})
+			continue
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return result, err
+		}
+
+		result = append(result, ScriptItem{OpCode: opCode, Data: data, Offset: offset})
+	}
+
+	return result, nil
+}
+
+// DisassembleScript returns the assembly representation of a script, like
+//   "OP_DUP OP_HASH160 <hex> OP_EQUALVERIFY OP_CHECKSIG". A malformed script ends in "[error]".
+func DisassembleScript(script []byte) string {
+	items, err := ParseScript(script)
+
+	parts := make([]string, 0, len(items)+1)
+	for _, item := range items {
+		parts = append(parts, item.String())
+	}
+	if err != nil {
+		parts = append(parts, "[error]")
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// OpCodeName returns the name of an op code.
+func OpCodeName(opCode byte) string {
+	if opCode > OP_FALSE && opCode <= OP_MAX_SINGLE_BYTE_PUSH_DATA {
+		return fmt.Sprintf("OP_DATA_%d", opCode)
+	}
+	if opCode >= OP_1 && opCode <= OP_16 {
+		return fmt.Sprintf("OP_%d", opCode-OP_1+1)
+	}
+	if name, exists := opCodeNames[opCode]; exists {
+		return name
+	}
+	return fmt.Sprintf("OP_UNKNOWN_0x%02x", opCode)
+}
+
+var opCodeNames = map[byte]string{
+	0x00: "OP_0",
+	0x4c: "OP_PUSHDATA1",
+	0x4d: "OP_PUSHDATA2",
+	0x4e: "OP_PUSHDATA4",
+	0x4f: "OP_1NEGATE",
+	0x50: "OP_RESERVED",
+	0x61: "OP_NOP",
+	0x62: "OP_VER",
+	0x63: "OP_IF",
+	0x64: "OP_NOTIF",
+	0x65: "OP_VERIF",
+	0x66: "OP_VERNOTIF",
+	0x67: "OP_ELSE",
+	0x68: "OP_ENDIF",
+	0x69: "OP_VERIFY",
+	0x6a: "OP_RETURN",
+	0x6b: "OP_TOALTSTACK",
+	0x6c: "OP_FROMALTSTACK",
+	0x6d: "OP_2DROP",
+	0x6e: "OP_2DUP",
+	0x6f: "OP_3DUP",
+	0x70: "OP_2OVER",
+	0x71: "OP_2ROT",
+	0x72: "OP_2SWAP",
+	0x73: "OP_IFDUP",
+	0x74: "OP_DEPTH",
+	0x75: "OP_DROP",
+	0x76: "OP_DUP",
+	0x77: "OP_NIP",
+	0x78: "OP_OVER",
+	0x79: "OP_PICK",
+	0x7a: "OP_ROLL",
+	0x7b: "OP_ROT",
+	0x7c: "OP_SWAP",
+	0x7d: "OP_TUCK",
+	0x7e: "OP_CAT",
+	0x7f: "OP_SPLIT",
+	0x80: "OP_NUM2BIN",
+	0x81: "OP_BIN2NUM",
+	0x82: "OP_SIZE",
+	0x83: "OP_INVERT",
+	0x84: "OP_AND",
+	0x85: "OP_OR",
+	0x86: "OP_XOR",
+	0x87: "OP_EQUAL",
+	0x88: "OP_EQUALVERIFY",
+	0x89: "OP_RESERVED1",
+	0x8a: "OP_RESERVED2",
+	0x8b: "OP_1ADD",
+	0x8c: "OP_1SUB",
+	0x8d: "OP_2MUL",
+	0x8e: "OP_2DIV",
+	0x8f: "OP_NEGATE",
+	0x90: "OP_ABS",
+	0x91: "OP_NOT",
+	0x92: "OP_0NOTEQUAL",
+	0x93: "OP_ADD",
+	0x94: "OP_SUB",
+	0x95: "OP_MUL",
+	0x96: "OP_DIV",
+	0x97: "OP_MOD",
+	0x98: "OP_LSHIFT",
+	0x99: "OP_RSHIFT",
+	0x9a: "OP_BOOLAND",
+	0x9b: "OP_BOOLOR",
+	0x9c: "OP_NUMEQUAL",
+	0x9d: "OP_NUMEQUALVERIFY",
+	0x9e: "OP_NUMNOTEQUAL",
+	0x9f: "OP_LESSTHAN",
+	0xa0: "OP_GREATERTHAN",
+	0xa1: "OP_LESSTHANOREQUAL",
+	0xa2: "OP_GREATERTHANOREQUAL",
+	0xa3: "OP_MIN",
+	0xa4: "OP_MAX",
+	0xa5: "OP_WITHIN",
+	0xa6: "OP_RIPEMD160",
+	0xa7: "OP_SHA1",
+	0xa8: "OP_SHA256",
+	0xa9: "OP_HASH160",
+	0xaa: "OP_HASH256",
+	0xab: "OP_CODESEPARATOR",
+	0xac: "OP_CHECKSIG",
+	0xad: "OP_CHECKSIGVERIFY",
+	0xae: "OP_CHECKMULTISIG",
+	0xaf: "OP_CHECKMULTISIGVERIFY",
+	0xb0: "OP_NOP1",
+	0xb1: "OP_CHECKLOCKTIMEVERIFY",
+	0xb2: "OP_CHECKSEQUENCEVERIFY",
+	0xb3: "OP_NOP4",
+	0xb4: "OP_NOP5",
+	0xb5: "OP_NOP6",
+	0xb6: "OP_NOP7",
+	0xb7: "OP_NOP8",
+	0xb8: "OP_NOP9",
+	0xb9: "OP_NOP10",
+}
@@ -0,0 +1,21 @@
+package electrum
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/retry"
+)
+
+type Config struct {
+	Address string        // host:port of the ElectrumX server
+	TLS     bool          // Connect with TLS
+	Timeout time.Duration // Timeout for each request, including connecting
+	Retry   retry.Policy
+}
+
+// String returns a custom string representation.
+func (c Config) String() string {
+	return fmt.Sprintf("{Address:%v TLS:%v Timeout:%v Attempts:%v}", c.Address, c.TLS, c.Timeout,
+		c.Retry.MaxAttempts)
+}
@@ -0,0 +1,440 @@
+package electrum
+
+/**
+ * Electrum Node Kit
+ *
+ * What is my purpose?
+ * - You connect to an ElectrumX server
+ * - You get and broadcast txs for me with the Electrum JSON-RPC protocol
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SubSystem is used by the logger package
+	SubSystem = "Electrum"
+
+	clientName      = "Tokenized"
+	protocolVersion = "1.4"
+)
+
+var (
+	// ErrNotFound is returned when the server doesn't know the tx.
+	ErrNotFound = inspector.ErrTxNotFound
+)
+
+type Node struct {
+	config  Config
+	conn    net.Conn
+	reader  *bufio.Reader
+	nextID  uint64
+	txCache map[bitcoin.Hash32]*wire.MsgTx
+	lock    sync.Mutex
+}
+
+type request struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type response struct {
+	ID     *uint64          `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  *json.RawMessage `json:"error"`
+}
+
+// serverError is an error returned by the server, rather than a connection failure.
+type serverError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("Electrum error %d : %s", e.Code, e.Message)
+}
+
+// NewNode returns a new instance of an Electrum node. The connection is made on first use.
+func NewNode(config *Config) *Node {
+	return &Node{
+		config:  *config,
+		txCache: make(map[bitcoin.Hash32]*wire.MsgTx),
+	}
+}
+
+// Close closes the connection to the server.
+func (n *Node) Close() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return n.disconnect()
+}
+
+// GetTX requests a tx from the server.
+func (n *Node) GetTX(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "GetTX")
+
+	if tx := n.cachedTx(txid); tx != nil {
+		return tx, nil
+	}
+
+	var raw string
+	if err := n.call(ctx, "blockchain.transaction.get", []interface{}{txid.String(), false},
+		&raw); err != nil {
+		return nil, errors.Wrap(err, txid.String())
+	}
+
+	return decodeTx(raw)
+}
+
+// GetTXs requests a list of txs from the server in one batch. Each tx is decoded on its own, so
+//   a tx the server doesn't know is left nil and the others are still returned. The error for the
+//   first tx that wasn't returned is returned with them.
+func (n *Node) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "GetTXs")
+
+	results := make([]*wire.MsgTx, len(txids))
+	var params [][]interface{}
+	var indexes []int
+	for i, txid := range txids {
+		if tx := n.cachedTx(txid); tx != nil {
+			results[i] = tx
+			continue
+		}
+		params = append(params, []interface{}{txid.String(), false})
+		indexes = append(indexes, i)
+	}
+
+	if len(params) == 0 {
+		return results, nil
+	}
+
+	raws := make([]string, len(params))
+	rawResults := make([]interface{}, len(params))
+	for i := range raws {
+		rawResults[i] = &raws[i]
+	}
+
+	errs, err := n.batch(ctx, "blockchain.transaction.get", params, rawResults)
+	if err != nil {
+		return results, err
+	}
+
+	var firstErr error
+	for i, raw := range raws {
+		txid := txids[indexes[i]]
+		if errs[i] != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrap(errs[i], txid.String())
+			}
+			continue
+		}
+
+		tx, err := decodeTx(raw)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.Wrap(err, txid.String())
+			}
+			continue
+		}
+		results[indexes[i]] = tx
+	}
+
+	return results, firstErr
+}
+
+// SaveTX saves a tx to be used later. It is returned by the next request for it instead of
+//   requesting it from the server.
+func (n *Node) SaveTX(ctx context.Context, tx *wire.MsgTx) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.txCache[*tx.TxHash()] = tx
+	return nil
+}
+
+// SendTX sends a tx to the server to be broadcast to the P2P network.
+func (n *Node) SendTX(ctx context.Context, tx *wire.MsgTx) (*bitcoin.Hash32, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "SendTX")
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, errors.Wrap(err, "serialize tx")
+	}
+
+	var txid string
+	if err := n.call(ctx, "blockchain.transaction.broadcast",
+		[]interface{}{hex.EncodeToString(buf.Bytes())}, &txid); err != nil {
+		return nil, err
+	}
+
+	return bitcoin.NewHash32FromStr(txid)
+}
+
+// cachedTx returns a tx saved with SaveTX, or nil. The tx is removed from the cache because saved
+//   txs are only needed once, to decode the tx that spends them.
+func (n *Node) cachedTx(txid *bitcoin.Hash32) *wire.MsgTx {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	tx, exists := n.txCache[*txid]
+	if exists {
+		delete(n.txCache, *txid)
+	}
+	return tx
+}
+
+// call makes a request, retrying according to the retry policy.
+func (n *Node) call(ctx context.Context, method string, params []interface{},
+	result interface{}) error {
+	return n.config.Retry.Do(ctx, func() error {
+		err := n.send(ctx, []request{n.newRequest(method, params)}, []interface{}{result}, nil)
+		if err != nil {
+			logger.Verbose(ctx, "%s failed : %s", method, err)
+		}
+		return err
+	})
+}
+
+// batch makes a JSON-RPC batch request of the same method, retrying according to the retry
+//   policy. Errors returned by the server for individual requests are returned in errs, in the
+//   same order as the params.
+func (n *Node) batch(ctx context.Context, method string, params [][]interface{},
+	results []interface{}) ([]error, error) {
+
+	errs := make([]error, len(params))
+	err := n.config.Retry.Do(ctx, func() error {
+		requests := make([]request, len(params))
+		for i, p := range params {
+			requests[i] = n.newRequest(method, p)
+			errs[i] = nil
+		}
+
+		err := n.send(ctx, requests, results, errs)
+		if err != nil {
+			logger.Verbose(ctx, "%s batch failed : %s", method, err)
+		}
+		return err
+	})
+
+	return errs, err
+}
+
+func (n *Node) newRequest(method string, params []interface{}) request {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.nextID++
+	return request{JSONRPC: "2.0", ID: n.nextID, Method: method, Params: params}
+}
+
+// send sends requests and decodes the results. Connection errors are returned so they can be
+//   retried. Server errors are returned as permanent, unless errs is provided, in which case the
+//   error for each request is put in errs and the other results are still decoded.
+func (n *Node) send(ctx context.Context, requests []request, results []interface{},
+	errs []error) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if err := n.connect(ctx); err != nil {
+		return errors.Wrap(err, "connect")
+	}
+
+	responses, err := n.roundTrip(requests)
+	if err != nil {
+		n.disconnect()
+		return err
+	}
+
+	for i, request := range requests {
+		response, exists := responses[request.ID]
+		if !exists {
+			n.disconnect()
+			return fmt.Errorf("Missing response to request %d", request.ID)
+		}
+
+		if response.Error != nil {
+			if errs == nil {
+				return retry.Permanent(parseError(*response.Error))
+			}
+			errs[i] = parseError(*response.Error)
+			continue
+		}
+
+		if err := json.Unmarshal(response.Result, results[i]); err != nil {
+			if errs == nil {
+				return retry.Permanent(errors.Wrap(err, "unmarshal result"))
+			}
+			errs[i] = errors.Wrap(err, "unmarshal result")
+		}
+	}
+
+	return nil
+}
+
+// roundTrip writes requests and reads responses until all of them are received. A single request
+//   is sent as an object and multiple as a batch array. The lock must be held.
+func (n *Node) roundTrip(requests []request) (map[uint64]*response, error) {
+	var body []byte
+	var err error
+	if len(requests) == 1 {
+		body, err = json.Marshal(requests[0])
+	} else {
+		body, err = json.Marshal(requests)
+	}
+	if err != nil {
+		return nil, retry.Permanent(errors.Wrap(err, "marshal request"))
+	}
+
+	if n.config.Timeout > 0 {
+		n.conn.SetDeadline(time.Now().Add(n.config.Timeout))
+		defer n.conn.SetDeadline(time.Time{})
+	}
+
+	if _, err := n.conn.Write(append(body, '\n')); err != nil {
+		return nil, errors.Wrap(err, "write request")
+	}
+
+	result := make(map[uint64]*response)
+	for len(result) < len(requests) {
+		line, err := n.reader.ReadBytes('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "read response")
+		}
+
+		line = bytes.TrimSpace(line)
+		var responses []*response
+		if len(line) > 0 && line[0] == '[' {
+			if err := json.Unmarshal(line, &responses); err != nil {
+				return nil, errors.Wrap(err, "unmarshal batch response")
+			}
+		} else {
+			r := &response{}
+			if err := json.Unmarshal(line, r); err != nil {
+				return nil, errors.Wrap(err, "unmarshal response")
+			}
+			responses = []*response{r}
+		}
+
+		for _, r := range responses {
+			if r.ID == nil {
+				continue // Subscription notification
+			}
+			result[*r.ID] = r
+		}
+	}
+
+	return result, nil
+}
+
+// connect connects to the server and negotiates the protocol version if not already connected.
+// The lock must be held.
+func (n *Node) connect(ctx context.Context) error {
+	if n.conn != nil {
+		return nil
+	}
+
+	dialer := &net.Dialer{Timeout: n.config.Timeout}
+	var conn net.Conn
+	var err error
+	if n.config.TLS {
+		host, _, _ := net.SplitHostPort(n.config.Address)
+		conn, err = tls.DialWithDialer(dialer, "tcp", n.config.Address,
+			&tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", n.config.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	n.conn = conn
+	n.reader = bufio.NewReader(conn)
+
+	n.nextID++
+	version := request{JSONRPC: "2.0", ID: n.nextID, Method: "server.version",
+		Params: []interface{}{clientName, protocolVersion}}
+	responses, err := n.roundTrip([]request{version})
+	if err != nil {
+		n.disconnect()
+		return errors.Wrap(err, "server version")
+	}
+	if response := responses[version.ID]; response.Error != nil {
+		n.disconnect()
+		return retry.Permanent(errors.Wrap(parseError(*response.Error), "server version"))
+	}
+
+	logger.Verbose(ctx, "Connected to %s", n.config.Address)
+	return nil
+}
+
+// disconnect closes the connection. The lock must be held.
+func (n *Node) disconnect() error {
+	if n.conn == nil {
+		return nil
+	}
+
+	err := n.conn.Close()
+	n.conn = nil
+	n.reader = nil
+	return err
+}
+
+// parseError converts an error response to an error. Missing txs return ErrNotFound.
+func parseError(raw json.RawMessage) error {
+	sErr := &serverError{}
+	if err := json.Unmarshal(raw, sErr); err != nil {
+		// Some servers return a string
+		var message string
+		if err := json.Unmarshal(raw, &message); err != nil {
+			return fmt.Errorf("Electrum error : %s", string(raw))
+		}
+		sErr.Message = message
+	}
+
+	message := strings.ToLower(sErr.Message)
+	if strings.Contains(message, "no such mempool or blockchain transaction") ||
+		strings.Contains(message, "not found") {
+		return errors.Wrap(ErrNotFound, sErr.Message)
+	}
+
+	return sErr
+}
+
+func decodeTx(raw string) (*wire.MsgTx, error) {
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode hex")
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+		return nil, errors.Wrap(err, "deserialize tx")
+	}
+
+	return tx, nil
+}
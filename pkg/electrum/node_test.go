@@ -0,0 +1,255 @@
+package electrum
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func TestGetAndSend(t *testing.T) {
+	ctx := context.Background()
+
+	tx := testTx(1)
+	server := newStubServer(t, tx)
+	defer server.Close()
+
+	// Drop the first connection to exercise retry.
+	server.drops = 1
+
+	node := NewNode(testConfig(server.Address()))
+	defer node.Close()
+
+	got, err := node.GetTX(ctx, tx.TxHash())
+	if err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if !got.TxHash().Equal(tx.TxHash()) {
+		t.Fatalf("Wrong tx")
+	}
+	if server.Connections() != 2 {
+		t.Fatalf("Wrong connection count : %d", server.Connections())
+	}
+
+	if _, err := node.GetTX(ctx, &bitcoin.Hash32{9}); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("Wrong error for missing tx : %v", err)
+	}
+
+	other := testTx(2)
+	server.Add(other)
+	txs, err := node.GetTXs(ctx, []*bitcoin.Hash32{other.TxHash(), tx.TxHash()})
+	if err != nil {
+		t.Fatalf("Failed to get txs : %s", err)
+	}
+	if !txs[0].TxHash().Equal(other.TxHash()) || !txs[1].TxHash().Equal(tx.TxHash()) {
+		t.Fatalf("Wrong txs")
+	}
+
+	// A missing tx doesn't fail the rest of the batch.
+	txs, err = node.GetTXs(ctx, []*bitcoin.Hash32{other.TxHash(), &bitcoin.Hash32{9},
+		tx.TxHash()})
+	if errors.Cause(err) != ErrNotFound {
+		t.Fatalf("Wrong error for missing batch tx : %v", err)
+	}
+	if txs[0] == nil || !txs[0].TxHash().Equal(other.TxHash()) || txs[1] != nil ||
+		txs[2] == nil || !txs[2].TxHash().Equal(tx.TxHash()) {
+		t.Fatalf("Wrong txs with missing tx")
+	}
+
+	sent := testTx(3)
+	txid, err := node.SendTX(ctx, sent)
+	if err != nil {
+		t.Fatalf("Failed to send tx : %s", err)
+	}
+	if !txid.Equal(sent.TxHash()) {
+		t.Fatalf("Wrong txid returned : %s", txid.String())
+	}
+	if !server.Has(sent.TxHash()) {
+		t.Fatalf("Sent tx not received")
+	}
+
+	// Only the dropped connection was reconnected.
+	if server.Connections() != 2 {
+		t.Fatalf("Wrong connection count : %d", server.Connections())
+	}
+}
+
+func testConfig(address string) *Config {
+	return &Config{
+		Address: address,
+		Timeout: time.Second,
+		Retry:   retry.Policy{MaxAttempts: 3, Delay: 10 * time.Millisecond},
+	}
+}
+
+func testTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{1}, index), []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	return tx
+}
+
+// stubServer is a minimal ElectrumX server holding a set of txs.
+type stubServer struct {
+	t           *testing.T
+	listener    net.Listener
+	txs         map[bitcoin.Hash32]string
+	drops       int
+	connections int
+	lock        sync.Mutex
+}
+
+func newStubServer(t *testing.T, txs ...*wire.MsgTx) *stubServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen : %s", err)
+	}
+
+	s := &stubServer{t: t, listener: listener, txs: make(map[bitcoin.Hash32]string)}
+	for _, tx := range txs {
+		s.Add(tx)
+	}
+
+	go s.serve()
+	return s
+}
+
+func (s *stubServer) Address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *stubServer) Close() {
+	s.listener.Close()
+}
+
+func (s *stubServer) Add(tx *wire.MsgTx) {
+	var buf bytes.Buffer
+	tx.Serialize(&buf)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.txs[*tx.TxHash()] = hex.EncodeToString(buf.Bytes())
+}
+
+func (s *stubServer) Has(txid *bitcoin.Hash32) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, exists := s.txs[*txid]
+	return exists
+}
+
+func (s *stubServer) Connections() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.connections
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.lock.Lock()
+		s.connections++
+		drop := s.drops > 0
+		if drop {
+			s.drops--
+		}
+		s.lock.Unlock()
+
+		if drop {
+			conn.Close()
+			continue
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		line = bytes.TrimSpace(line)
+		var out []byte
+		if line[0] == '[' {
+			var requests []request
+			if err := json.Unmarshal(line, &requests); err != nil {
+				s.t.Errorf("Invalid batch request : %s", err)
+				return
+			}
+			responses := make([]map[string]interface{}, len(requests))
+			for i, r := range requests {
+				responses[i] = s.respond(r)
+			}
+			out, _ = json.Marshal(responses)
+		} else {
+			var r request
+			if err := json.Unmarshal(line, &r); err != nil {
+				s.t.Errorf("Invalid request : %s", err)
+				return
+			}
+			out, _ = json.Marshal(s.respond(r))
+		}
+
+		if _, err := conn.Write(append(out, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+func (s *stubServer) respond(r request) map[string]interface{} {
+	result := map[string]interface{}{"jsonrpc": "2.0", "id": r.ID}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch r.Method {
+	case "server.version":
+		result["result"] = []string{"ElectrumX 1.16", protocolVersion}
+	case "blockchain.transaction.get":
+		txid, _ := bitcoin.NewHash32FromStr(r.Params[0].(string))
+		raw, exists := s.txs[*txid]
+		if !exists {
+			result["error"] = map[string]interface{}{"code": 2,
+				"message": fmt.Sprintf("daemon error: No such mempool or blockchain transaction. %s",
+					txid.String())}
+		} else {
+			result["result"] = raw
+		}
+	case "blockchain.transaction.broadcast":
+		raw := r.Params[0].(string)
+		b, _ := hex.DecodeString(raw)
+		tx := &wire.MsgTx{}
+		if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+			result["error"] = map[string]interface{}{"code": 1, "message": err.Error()}
+		} else {
+			s.txs[*tx.TxHash()] = raw
+			result["result"] = tx.TxHash().String()
+		}
+	default:
+		result["error"] = map[string]interface{}{"code": -32601, "message": "unknown method"}
+	}
+
+	return result
+}
@@ -0,0 +1,36 @@
+package explorer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/retry"
+)
+
+const (
+	DefaultTxPath        = "/tx/%s/hex"
+	DefaultBroadcastPath = "/tx/raw"
+	DefaultAPIKeyHeader  = "Authorization"
+)
+
+type Config struct {
+	URL           string // Base URL of the explorer API
+	TxPath        string // Path to get raw tx hex, with %s replaced by the txid
+	BroadcastPath string // Path to post raw txs to
+	APIKey        string
+	APIKeyHeader  string        // Header containing the API key
+	Timeout       time.Duration // Timeout for each request
+	Retry         retry.Policy
+}
+
+// String returns a custom string representation.
+//
+// This is important so we don't log sensitive config values.
+func (c Config) String() string {
+	apiKey := ""
+	if len(c.APIKey) > 0 {
+		apiKey = "*** Masked ***"
+	}
+	return fmt.Sprintf("{URL:%v TxPath:%v BroadcastPath:%v APIKey:%v Timeout:%v Attempts:%v}",
+		c.URL, c.TxPath, c.BroadcastPath, apiKey, c.Timeout, c.Retry.MaxAttempts)
+}
@@ -0,0 +1,206 @@
+package explorer
+
+/**
+ * Explorer Node Kit
+ *
+ * What is my purpose?
+ * - You get and broadcast txs for me through a block explorer's REST API
+ */
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SubSystem is used by the logger package
+	SubSystem = "Explorer"
+)
+
+var (
+	// ErrNotFound is returned when the explorer doesn't know the tx.
+	ErrNotFound = inspector.ErrTxNotFound
+)
+
+type Node struct {
+	config  Config
+	client  *http.Client
+	txCache map[bitcoin.Hash32]*wire.MsgTx
+	lock    sync.Mutex
+}
+
+type broadcastRequest struct {
+	TxHex string `json:"txhex"`
+}
+
+// NewNode returns a new instance of an explorer node.
+func NewNode(config *Config) *Node {
+	c := *config
+	if len(c.TxPath) == 0 {
+		c.TxPath = DefaultTxPath
+	}
+	if len(c.BroadcastPath) == 0 {
+		c.BroadcastPath = DefaultBroadcastPath
+	}
+	if len(c.APIKeyHeader) == 0 {
+		c.APIKeyHeader = DefaultAPIKeyHeader
+	}
+
+	return &Node{
+		config:  c,
+		client:  &http.Client{Timeout: c.Timeout},
+		txCache: make(map[bitcoin.Hash32]*wire.MsgTx),
+	}
+}
+
+// GetTX requests a tx from the explorer.
+func (n *Node) GetTX(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "GetTX")
+
+	n.lock.Lock()
+	tx, exists := n.txCache[*txid]
+	if exists {
+		delete(n.txCache, *txid)
+	}
+	n.lock.Unlock()
+	if exists {
+		return tx, nil
+	}
+
+	body, err := n.request(ctx, http.MethodGet, fmt.Sprintf(n.config.TxPath, txid.String()), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, txid.String())
+	}
+
+	b, err := hex.DecodeString(unquote(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "decode hex")
+	}
+
+	tx = &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+		return nil, errors.Wrap(err, "deserialize tx")
+	}
+
+	return tx, nil
+}
+
+// GetTXs requests a list of txs from the explorer.
+func (n *Node) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	results := make([]*wire.MsgTx, len(txids))
+	for i, txid := range txids {
+		tx, err := n.GetTX(ctx, txid)
+		if err != nil {
+			return results, err
+		}
+		results[i] = tx
+	}
+
+	return results, nil
+}
+
+// SaveTX saves a tx to be used later.
+func (n *Node) SaveTX(ctx context.Context, tx *wire.MsgTx) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.txCache[*tx.TxHash()] = tx
+	return nil
+}
+
+// SendTX posts a tx to the explorer to be broadcast to the P2P network.
+func (n *Node) SendTX(ctx context.Context, tx *wire.MsgTx) (*bitcoin.Hash32, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "SendTX")
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, errors.Wrap(err, "serialize tx")
+	}
+
+	request, err := json.Marshal(broadcastRequest{TxHex: hex.EncodeToString(buf.Bytes())})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal request")
+	}
+
+	body, err := n.request(ctx, http.MethodPost, n.config.BroadcastPath, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitcoin.NewHash32FromStr(unquote(body))
+}
+
+// request makes an HTTP request, retrying according to the retry policy. Not found and other
+//   client errors are not retried.
+func (n *Node) request(ctx context.Context, method, path string, body []byte) (string, error) {
+	url := strings.TrimRight(n.config.URL, "/") + path
+
+	var result string
+	err := n.config.Retry.Do(ctx, func() error {
+		request, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err != nil {
+			return retry.Permanent(errors.Wrap(err, "create request"))
+		}
+		request = request.WithContext(ctx)
+		if body != nil {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		if len(n.config.APIKey) > 0 {
+			request.Header.Set(n.config.APIKeyHeader, n.config.APIKey)
+		}
+
+		response, err := n.client.Do(request)
+		if err != nil {
+			logger.Verbose(ctx, "%s %s failed : %s", method, path, err)
+			return err
+		}
+		defer response.Body.Close()
+
+		b, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return errors.Wrap(err, "read response")
+		}
+
+		switch {
+		case response.StatusCode == http.StatusOK:
+			result = strings.TrimSpace(string(b))
+			return nil
+		case response.StatusCode == http.StatusNotFound:
+			return retry.Permanent(ErrNotFound)
+		case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+			logger.Verbose(ctx, "%s %s failed : %s", method, path, response.Status)
+			return fmt.Errorf("HTTP %s : %s", response.Status, string(b))
+		default:
+			return retry.Permanent(fmt.Errorf("HTTP %s : %s", response.Status, string(b)))
+		}
+	})
+
+	return result, err
+}
+
+// unquote removes JSON quotes from a string response.
+func unquote(s string) string {
+	var result string
+	if err := json.Unmarshal([]byte(s), &result); err == nil {
+		return result
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package explorer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func TestGetAndSend(t *testing.T) {
+	ctx := context.Background()
+
+	tx := testTx(1)
+	raw := hex.EncodeToString(serialize(t, tx))
+
+	var lock sync.Mutex
+	requests := 0
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests++
+
+		if r.Header.Get("Authorization") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == fmt.Sprintf("/tx/%s/hex", tx.TxHash()):
+			// Fail the first request to exercise retry.
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(raw))
+		case r.Method == http.MethodPost && r.URL.Path == "/tx/raw":
+			var request broadcastRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received = append(received, request.TxHex)
+
+			b, _ := hex.DecodeString(request.TxHex)
+			sent := &wire.MsgTx{}
+			sent.Deserialize(bytes.NewReader(b))
+			fmt.Fprintf(w, "\"%s\"", sent.TxHash().String())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	node := NewNode(&Config{
+		URL:     server.URL,
+		APIKey:  "test-key",
+		Timeout: time.Second,
+		Retry:   retry.Policy{MaxAttempts: 3, Delay: 10 * time.Millisecond},
+	})
+
+	got, err := node.GetTX(ctx, tx.TxHash())
+	if err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if !got.TxHash().Equal(tx.TxHash()) {
+		t.Fatalf("Wrong tx")
+	}
+	if requests != 2 {
+		t.Fatalf("Wrong request count : %d", requests)
+	}
+
+	// Not found is not retried.
+	if _, err := node.GetTX(ctx, &bitcoin.Hash32{9}); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("Wrong error for missing tx : %v", err)
+	}
+	if requests != 3 {
+		t.Fatalf("Not found retried : %d requests", requests)
+	}
+
+	sent := testTx(2)
+	txid, err := node.SendTX(ctx, sent)
+	if err != nil {
+		t.Fatalf("Failed to send tx : %s", err)
+	}
+	if !txid.Equal(sent.TxHash()) {
+		t.Fatalf("Wrong txid returned : %s", txid.String())
+	}
+	if len(received) != 1 || !strings.EqualFold(received[0], hex.EncodeToString(serialize(t, sent))) {
+		t.Fatalf("Sent tx not received")
+	}
+}
+
+func testTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{1}, index), []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	return tx
+}
+
+func serialize(t *testing.T, tx *wire.MsgTx) []byte {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Failed to serialize tx : %s", err)
+	}
+	return buf.Bytes()
+}
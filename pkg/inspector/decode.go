@@ -0,0 +1,245 @@
+package inspector
+
+import (
+	"context"
+	"encoding/hex"
+	"reflect"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/assets"
+	"github.com/tokenized/specification/dist/golang/messages"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+// Script type names used in decoded outputs.
+const (
+	ScriptNameP2PKH       = "P2PKH"
+	ScriptNameP2SH        = "P2SH"
+	ScriptNameMultiPKH    = "MultiPKH"
+	ScriptNameRPH         = "RPH"
+	ScriptNameP2PK        = "P2PK"
+	ScriptNameMultiSig    = "MultiSig"
+	ScriptNameOpReturn    = "OP_RETURN"
+	ScriptNameNonStandard = "NonStandard"
+)
+
+// DecodedTx is a complete JSON representation of a tx. It is the shared representation of txs
+//   for the CLI and any other interface that outputs txs.
+type DecodedTx struct {
+	TxID     string          `json:"txid"`
+	Version  int32           `json:"version"`
+	LockTime uint32          `json:"locktime"`
+	Size     int             `json:"size"`
+	Fee      *uint64         `json:"fee,omitempty"` // Only when all input values are known
+	Inputs   []DecodedInput  `json:"inputs"`
+	Outputs  []DecodedOutput `json:"outputs"`
+	Action   *DecodedAction  `json:"action,omitempty"`
+}
+
+type DecodedInput struct {
+	TxID            string  `json:"txid"`
+	Index           uint32  `json:"index"`
+	Sequence        uint32  `json:"sequence"`
+	UnlockingScript string  `json:"unlocking_script"`
+	Asm             string  `json:"asm"`
+	ScriptType      string  `json:"script_type"` // Template of the locking script it unlocks
+	Coinbase        bool    `json:"coinbase,omitempty"`
+	Address         string  `json:"address,omitempty"`
+	Value           *uint64 `json:"value,omitempty"` // Only when the input tx is available
+}
+
+type DecodedOutput struct {
+	Index         int    `json:"index"`
+	Value         uint64 `json:"value"`
+	LockingScript string `json:"locking_script"`
+	Asm           string `json:"asm"`
+	ScriptType    string `json:"script_type"`
+	Address       string `json:"address,omitempty"`
+}
+
+// DecodedAction is the Tokenized action within a tx.
+type DecodedAction struct {
+	Code         string            `json:"code"`
+	Name         string            `json:"name"`
+	OutputIndex  int               `json:"output_index"`
+	IsTest       bool              `json:"is_test,omitempty"`
+	Data         actions.Action    `json:"data"`
+	Payload      interface{}       `json:"payload,omitempty"` // Asset or message payload
+	PayloadError string            `json:"payload_error,omitempty"`
+	Rejection    *DecodedRejection `json:"rejection,omitempty"`
+}
+
+type DecodedRejection struct {
+	Code        uint32 `json:"code"`
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// DecodeTx converts a tx to its JSON representation. When node is not nil it is used to find the
+//   addresses and values of inputs. Inputs it can't find are left unresolved.
+func DecodeTx(ctx context.Context, tx *wire.MsgTx, node NodeInterface,
+	net bitcoin.Network) *DecodedTx {
+
+	result := &DecodedTx{
+		TxID:     tx.TxHash().String(),
+		Version:  tx.Version,
+		LockTime: tx.LockTime,
+		Size:     tx.SerializeSize(),
+		Inputs:   make([]DecodedInput, 0, len(tx.TxIn)),
+		Outputs:  make([]DecodedOutput, 0, len(tx.TxOut)),
+	}
+
+	inputValue := uint64(0)
+	inputValuesKnown := true
+	for _, txin := range tx.TxIn {
+		input := DecodedInput{
+			TxID:            txin.PreviousOutPoint.Hash.String(),
+			Index:           txin.PreviousOutPoint.Index,
+			Sequence:        txin.Sequence,
+			UnlockingScript: hex.EncodeToString(txin.SignatureScript),
+			Asm:             bitcoin.DisassembleScript(txin.SignatureScript),
+			ScriptType:      bitcoin.ParseUnlockingScript(txin.SignatureScript).Template.String(),
+		}
+
+		if txin.PreviousOutPoint.Index == 0xffffffff {
+			input.Coinbase = true
+			inputValuesKnown = false
+			result.Inputs = append(result.Inputs, input)
+			continue
+		}
+
+		if address, err := bitcoin.AddressFromUnlockingScript(txin.SignatureScript,
+			net); err == nil {
+			input.Address = address.String()
+		}
+
+		var inputTx *wire.MsgTx
+		if node != nil {
+			var err error
+			inputTx, err = node.GetTX(ctx, &txin.PreviousOutPoint.Hash)
+			if err != nil {
+				logger.Verbose(ctx, "Input tx not available %s : %s",
+					txin.PreviousOutPoint.Hash.String(), err)
+			}
+		}
+
+		if inputTx != nil && int(txin.PreviousOutPoint.Index) < len(inputTx.TxOut) {
+			txout := inputTx.TxOut[txin.PreviousOutPoint.Index]
+			value := txout.Value
+			input.Value = &value
+			inputValue += value
+			if address, err := bitcoin.AddressFromLockingScript(txout.PkScript, net); err == nil {
+				input.Address = address.String()
+			}
+		} else {
+			inputValuesKnown = false
+		}
+
+		result.Inputs = append(result.Inputs, input)
+	}
+
+	outputValue := uint64(0)
+	for i, txout := range tx.TxOut {
+		output := DecodedOutput{
+			Index:         i,
+			Value:         txout.Value,
+			LockingScript: hex.EncodeToString(txout.PkScript),
+			Asm:           bitcoin.DisassembleScript(txout.PkScript),
+			ScriptType:    ScriptTypeName(txout.PkScript),
+		}
+		outputValue += txout.Value
+
+		if address, err := bitcoin.AddressFromLockingScript(txout.PkScript, net); err == nil {
+			output.Address = address.String()
+		}
+
+		if result.Action == nil && output.ScriptType == ScriptNameOpReturn {
+			result.Action = DecodeAction(txout.PkScript)
+			if result.Action != nil {
+				result.Action.OutputIndex = i
+			}
+		}
+
+		result.Outputs = append(result.Outputs, output)
+	}
+
+	if inputValuesKnown && len(tx.TxIn) > 0 && inputValue >= outputValue {
+		fee := inputValue - outputValue
+		result.Fee = &fee
+	}
+
+	return result
+}
+
+// DecodeAction decodes a Tokenized action from a locking script, including any asset or message
+//   payload. It returns nil if the script doesn't contain a Tokenized action.
+func DecodeAction(script []byte) *DecodedAction {
+	isTest := false
+	action, err := protocol.Deserialize(script, isTest)
+	if err == protocol.ErrNotTokenized {
+		isTest = true
+		action, err = protocol.Deserialize(script, isTest)
+	}
+	if err != nil {
+		return nil
+	}
+
+	result := &DecodedAction{
+		Code:   action.Code(),
+		Name:   reflect.TypeOf(action).Elem().Name(),
+		IsTest: isTest,
+		Data:   action,
+	}
+
+	var payload interface{}
+	switch m := action.(type) {
+	case *actions.AssetDefinition:
+		payload, err = decodeAssetPayload(m.AssetType, m.AssetPayload)
+	case *actions.AssetCreation:
+		payload, err = decodeAssetPayload(m.AssetType, m.AssetPayload)
+	case *actions.Message:
+		if len(m.MessagePayload) > 0 {
+			payload, err = messages.Deserialize(m.MessageCode, m.MessagePayload)
+		}
+	case *actions.Rejection:
+		result.Rejection = DecodeRejection(m.RejectionCode)
+	}
+
+	if err != nil {
+		result.PayloadError = err.Error()
+	} else if payload != nil {
+		result.Payload = payload
+	}
+
+	return result
+}
+
+// DecodeRejection returns the human readable details of a rejection code.
+func DecodeRejection(code uint32) *DecodedRejection {
+	result := &DecodedRejection{Code: code}
+	if data := actions.RejectionsData(code); data != nil {
+		result.Name = data.Name
+		result.Label = data.Label
+		result.Description = data.Description
+	} else {
+		result.Label = "Unknown"
+	}
+	return result
+}
+
+// ScriptTypeName returns the name of the template a locking script matches.
+func ScriptTypeName(script []byte) string {
+	return bitcoin.ParseLockingScript(script).Template.String()
+}
+
+func decodeAssetPayload(assetType string, payload []byte) (interface{}, error) {
+	if len(payload) == 0 {
+		return nil, nil
+	}
+	return assets.Deserialize([]byte(assetType), payload)
+}
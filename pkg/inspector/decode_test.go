@@ -0,0 +1,144 @@
+package inspector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/assets"
+	"github.com/tokenized/specification/dist/golang/protocol"
+)
+
+func TestDecodeTx(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+	ra, err := key.RawAddress()
+	if err != nil {
+		t.Fatalf("Failed to get address : %s", err)
+	}
+	lockingScript, err := ra.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	parent := wire.NewMsgTx(1)
+	parent.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{}, 0), nil))
+	parent.AddTxOut(wire.NewTxOut(10000, lockingScript))
+	node := &decodeNode{txs: map[bitcoin.Hash32]*wire.MsgTx{*parent.TxHash(): parent}}
+
+	currency := &assets.Currency{CurrencyCode: "AUD", Description: "Australian Dollar"}
+	payload, err := currency.Bytes()
+	if err != nil {
+		t.Fatalf("Failed to serialize asset payload : %s", err)
+	}
+	definition := &actions.AssetDefinition{
+		AssetType:    assets.CodeCurrency,
+		AssetPayload: payload,
+	}
+	script, err := protocol.Serialize(definition, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	tx.AddTxOut(wire.NewTxOut(9000, lockingScript))
+	tx.AddTxOut(wire.NewTxOut(0, script))
+
+	decoded := DecodeTx(ctx, tx, node, bitcoin.MainNet)
+
+	address := bitcoin.NewAddressFromRawAddress(ra, bitcoin.MainNet).String()
+	if decoded.Inputs[0].Address != address {
+		t.Fatalf("Wrong input address : got %s, want %s", decoded.Inputs[0].Address, address)
+	}
+	if decoded.Inputs[0].Value == nil || *decoded.Inputs[0].Value != 10000 {
+		t.Fatalf("Wrong input value")
+	}
+	if decoded.Fee == nil || *decoded.Fee != 1000 {
+		t.Fatalf("Wrong fee")
+	}
+
+	if decoded.Outputs[0].ScriptType != ScriptNameP2PKH || decoded.Outputs[0].Address != address {
+		t.Fatalf("Wrong output 0 : %+v", decoded.Outputs[0])
+	}
+	if decoded.Outputs[1].ScriptType != ScriptNameOpReturn {
+		t.Fatalf("Wrong output 1 script type : %s", decoded.Outputs[1].ScriptType)
+	}
+
+	if decoded.Action == nil {
+		t.Fatalf("Action not decoded")
+	}
+	if decoded.Action.Code != actions.CodeAssetDefinition || decoded.Action.Name != "AssetDefinition" ||
+		!decoded.Action.IsTest || decoded.Action.OutputIndex != 1 {
+		t.Fatalf("Wrong action : %+v", decoded.Action)
+	}
+	asset, ok := decoded.Action.Payload.(*assets.Currency)
+	if !ok || asset.CurrencyCode != "AUD" {
+		t.Fatalf("Wrong asset payload : %+v", decoded.Action.Payload)
+	}
+
+	js, err := json.MarshalIndent(decoded, "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to marshal : %s", err)
+	}
+	t.Logf("Decoded : %s", js)
+
+	// Inputs are left unresolved without a node.
+	decoded = DecodeTx(ctx, tx, nil, bitcoin.MainNet)
+	if decoded.Inputs[0].Value != nil || decoded.Fee != nil {
+		t.Fatalf("Input resolved without node")
+	}
+}
+
+func TestDecodeRejection(t *testing.T) {
+	rejection := &actions.Rejection{RejectionCode: actions.RejectionsInsufficientQuantity}
+	script, err := protocol.Serialize(rejection, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	decoded := DecodeAction(script)
+	if decoded == nil || decoded.Rejection == nil {
+		t.Fatalf("Rejection not decoded")
+	}
+	if decoded.Rejection.Name != "InsufficientQuantity" || len(decoded.Rejection.Label) == 0 {
+		t.Fatalf("Wrong rejection : %+v", decoded.Rejection)
+	}
+
+	if DecodeRejection(9999).Label != "Unknown" {
+		t.Fatalf("Unknown rejection code labeled")
+	}
+
+	if DecodeAction([]byte{bitcoin.OP_FALSE, bitcoin.OP_RETURN, 0x01, 0x01}) != nil {
+		t.Fatalf("Non Tokenized script decoded")
+	}
+}
+
+// decodeNode is a node containing a fixed set of txs.
+type decodeNode struct {
+	txs map[bitcoin.Hash32]*wire.MsgTx
+}
+
+func (n *decodeNode) GetTX(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	tx, exists := n.txs[*txid]
+	if !exists {
+		return nil, ErrTxNotFound
+	}
+	return tx, nil
+}
+
+func (n *decodeNode) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	return nil, nil
+}
+
+func (n *decodeNode) SaveTX(ctx context.Context, tx *wire.MsgTx) error {
+	return nil
+}
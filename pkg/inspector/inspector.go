@@ -38,6 +38,10 @@ var (
 	// ErrMissingOutputs
 	ErrMissingOutputs = errors.New("Message is missing outputs")
 
+	// ErrTxNotFound is returned by a NodeInterface when it doesn't know a tx, as opposed to failing
+	//   to reach the node.
+	ErrTxNotFound = errors.New("Tx not found")
+
 	// prefixP2PKH Pay to PKH prefix
 	prefixP2PKH = []byte{0x76, 0xA9}
 )
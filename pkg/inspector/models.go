@@ -15,8 +15,9 @@ type Input struct {
 }
 
 type Output struct {
-	Address bitcoin.RawAddress
-	UTXO    bitcoin.UTXO
+	Address  bitcoin.RawAddress
+	UTXO     bitcoin.UTXO
+	Template bitcoin.ScriptTemplate // Identifies outputs that don't have an address
 }
 
 // UTXOs is a wrapper for a []UTXO.
@@ -7,10 +7,13 @@ import (
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
 	"github.com/tokenized/smart-contract/pkg/wire"
 
 	"github.com/tokenized/specification/dist/golang/actions"
 	"github.com/tokenized/specification/dist/golang/protocol"
+
+	"github.com/pkg/errors"
 )
 
 var (
@@ -91,6 +94,27 @@ func (itx *Transaction) Validate(ctx context.Context) error {
 	return nil
 }
 
+// VerifyInputs executes the unlocking script of every input against the output it spends, so a tx
+//   can't claim an input address, such as an administrator's, that it didn't sign for. The tx must
+//   be promoted first.
+func (itx *Transaction) VerifyInputs(ctx context.Context) error {
+	if len(itx.Inputs) != len(itx.MsgTx.TxIn) {
+		return errors.New("Inputs not promoted")
+	}
+
+	hashCache := &txbuilder.SigHashCache{}
+	for i, input := range itx.Inputs {
+		if err := txbuilder.VerifyInput(itx.MsgTx, i, input.UTXO.LockingScript, input.UTXO.Value,
+			hashCache); err != nil {
+			logger.Warn(ctx, "Input %d failed verification : %s", i, err)
+			itx.RejectCode = actions.RejectionsTxMalformed
+			return nil
+		}
+	}
+
+	return nil
+}
+
 // Promote will populate the inputs and outputs accordingly
 func (itx *Transaction) Promote(ctx context.Context, node NodeInterface) error {
 
@@ -138,8 +162,9 @@ func buildOutput(hash *bitcoin.Hash32, tx *wire.MsgTx, n int) (*Output, error) {
 	utxo := NewUTXOFromHashWire(hash, tx, uint32(n))
 
 	output := Output{
-		Address: address,
-		UTXO:    utxo,
+		Address:  address,
+		UTXO:     utxo,
+		Template: bitcoin.ParseLockingScript(txout.PkScript).Template,
 	}
 
 	return &output, nil
@@ -164,6 +189,9 @@ func (itx *Transaction) ParseInputs(ctx context.Context, node NodeInterface) err
 		h := txin.PreviousOutPoint.Hash
 		inputTX, err := node.GetTX(ctx, &h)
 		if err != nil {
+			if errors.Cause(err) == ErrTxNotFound {
+				return errors.Wrap(ErrMissingInputs, h.String())
+			}
 			return err
 		}
 
@@ -1,10 +1,16 @@
 package inspector
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/txbuilder"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/tokenized/specification/dist/golang/actions"
+	"github.com/tokenized/specification/dist/golang/protocol"
 )
 
 func TestAddressesUnique(t *testing.T) {
@@ -80,3 +86,89 @@ func TestAddressesUnique(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateInputs(t *testing.T) {
+	ctx := context.Background()
+
+	adminKey, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+	adminAddress, err := adminKey.RawAddress()
+	if err != nil {
+		t.Fatalf("Failed to get address : %s", err)
+	}
+	lockingScript, err := adminAddress.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	otherKey, err := bitcoin.GenerateKey(bitcoin.MainNet)
+	if err != nil {
+		t.Fatalf("Failed to generate key : %s", err)
+	}
+
+	parent := wire.NewMsgTx(1)
+	parent.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{}, 0), nil))
+	parent.AddTxOut(wire.NewTxOut(10000, lockingScript))
+	node := &decodeNode{txs: map[bitcoin.Hash32]*wire.MsgTx{*parent.TxHash(): parent}}
+
+	script, err := protocol.Serialize(&actions.ContractAddressChange{}, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize action : %s", err)
+	}
+
+	tests := []struct {
+		name string
+		key  bitcoin.Key
+		want uint32
+	}{
+		{name: "signed by admin", key: adminKey, want: 0},
+		{name: "signed by other", key: otherKey, want: actions.RejectionsTxMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := wire.NewMsgTx(1)
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+			tx.AddTxOut(wire.NewTxOut(9000, lockingScript))
+			tx.AddTxOut(wire.NewTxOut(0, script))
+
+			sig, err := txbuilder.InputSignature(tt.key, tx, 0, lockingScript, 10000,
+				txbuilder.SigHashAll+txbuilder.SigHashForkID, &txbuilder.SigHashCache{})
+			if err != nil {
+				t.Fatalf("Failed to sign : %s", err)
+			}
+
+			// Always claim the admin's public key so only the signature can be wrong.
+			unlockingScript := append(bitcoin.PushDataScriptSize(uint64(len(sig))), sig...)
+			publicKey := adminKey.PublicKey().Bytes()
+			unlockingScript = append(unlockingScript,
+				bitcoin.PushDataScriptSize(uint64(len(publicKey)))...)
+			tx.TxIn[0].SignatureScript = append(unlockingScript, publicKey...)
+
+			itx, err := NewTransactionFromWire(ctx, tx, true)
+			if err != nil {
+				t.Fatalf("Failed to create itx : %s", err)
+			}
+			if err := itx.Promote(ctx, node); err != nil {
+				t.Fatalf("Failed to promote itx : %s", err)
+			}
+
+			// Without verification the spoofed input is accepted.
+			if err := itx.Validate(ctx); err != nil {
+				t.Fatalf("Failed to validate : %s", err)
+			}
+			if itx.RejectCode != 0 {
+				t.Fatalf("Rejected without verification : %d", itx.RejectCode)
+			}
+
+			if err := itx.VerifyInputs(ctx); err != nil {
+				t.Fatalf("Failed to verify inputs : %s", err)
+			}
+			if itx.RejectCode != tt.want {
+				t.Fatalf("Wrong reject code : got %d, want %d", itx.RejectCode, tt.want)
+			}
+		})
+	}
+}
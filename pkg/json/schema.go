@@ -0,0 +1,268 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// SchemaVersion is the JSON Schema draft that NewSchema documents declare.
+const SchemaVersion = "http://json-schema.org/draft-07/schema#"
+
+// hexPattern matches the strings produced by Marshal for byte slices.
+const hexPattern = "^([0-9a-fA-F]{2})*$"
+
+// Schema is a JSON Schema document. It only contains the keywords needed to
+// describe values produced by Marshal.
+type Schema struct {
+	Version              string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              Number             `json:"minimum,omitempty"`
+	Maximum              Number             `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// SchemaOptions adds constraints to a generated schema that can't be found
+// by reflection.
+//
+// Enums and Required are keyed by the Go type name of a struct and the JSON
+// name of one of its fields, for example "ContractOffer.BodyOfAgreementType".
+// A key of just the JSON name applies to that field in every struct that
+// doesn't have a more specific entry. An enum on a slice or array field
+// constrains its elements.
+type SchemaOptions struct {
+	Title    string
+	Enums    map[string][]interface{}
+	Required map[string]bool
+}
+
+// NewSchema returns a JSON Schema describing the encoding Marshal produces
+// for values of the same type as v.
+//
+// Field names and omitempty are read from the "json" struct tags the same way
+// Marshal reads them. Byte slices are strings with a "base16" content encoding.
+// Named struct types are placed in the definitions section and referenced by
+// name. A field is required when its tag doesn't specify omitempty, when
+// opts marks it required, or when its enum doesn't include the zero value.
+func NewSchema(v interface{}, opts *SchemaOptions) (*Schema, error) {
+	if v == nil {
+		return nil, &UnsupportedTypeError{Type: nil}
+	}
+	if opts == nil {
+		opts = &SchemaOptions{}
+	}
+
+	g := &schemaGenerator{
+		opts:        opts,
+		definitions: make(map[string]*Schema),
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var result *Schema
+	var err error
+	if t.Kind() == reflect.Struct {
+		// The root type is described inline rather than by reference.
+		g.root = t
+		result = &Schema{}
+		err = g.structSchema(t, result)
+	} else {
+		result, err = g.schema(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Version = SchemaVersion
+	result.Title = opts.Title
+	if len(result.Title) == 0 {
+		result.Title = t.Name()
+	}
+	if len(g.definitions) > 0 {
+		result.Definitions = g.definitions
+	}
+	return result, nil
+}
+
+type schemaGenerator struct {
+	root        reflect.Type
+	opts        *SchemaOptions
+	definitions map[string]*Schema
+}
+
+// schema returns the schema for a value of type t.
+func (g *schemaGenerator) schema(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		// Custom encodings can be any JSON value.
+		return &Schema{}, nil
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := uint(t.Bits())
+		return &Schema{
+			Type:    "integer",
+			Minimum: Number(strconv.FormatInt(-1<<(bits-1), 10)),
+			Maximum: Number(strconv.FormatInt(1<<(bits-1)-1, 10)),
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return &Schema{
+			Type:    "integer",
+			Minimum: "0",
+			Maximum: Number(strconv.FormatUint(math.MaxUint64>>(64-uint(t.Bits())), 10)),
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Struct:
+		return g.structRef(t)
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !t.Key().Implements(textMarshalerType) {
+				return nil, &UnsupportedTypeError{t}
+			}
+		}
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			p := reflect.PtrTo(t.Elem())
+			if !p.Implements(marshalerType) && !p.Implements(textMarshalerType) {
+				return &Schema{Type: "string", ContentEncoding: "base16", Pattern: hexPattern}, nil
+			}
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Array:
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		l := t.Len()
+		return &Schema{Type: "array", Items: items, MinItems: &l, MaxItems: &l}, nil
+	}
+
+	return nil, &UnsupportedTypeError{t}
+}
+
+// structRef returns a reference to the definition of a named struct,
+// generating the definition if it doesn't exist yet. Anonymous structs are
+// described inline.
+func (g *schemaGenerator) structRef(t reflect.Type) (*Schema, error) {
+	name := t.Name()
+	if len(name) > 0 && t == g.root {
+		return &Schema{Ref: "#"}, nil
+	}
+	if len(name) == 0 {
+		result := &Schema{}
+		if err := g.structSchema(t, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if _, exists := g.definitions[name]; !exists {
+		// Add before generating so recursive types reference it instead of looping.
+		definition := &Schema{}
+		g.definitions[name] = definition
+		if err := g.structSchema(t, definition); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Schema{Ref: "#/definitions/" + name}, nil
+}
+
+// structSchema fills in the object schema for struct type t.
+func (g *schemaGenerator) structSchema(t reflect.Type, result *Schema) error {
+	result.Type = "object"
+	result.Properties = make(map[string]*Schema)
+
+	for _, f := range cachedTypeFields(t).list {
+		fieldSchema, err := g.schema(f.typ)
+		if err != nil {
+			return err
+		}
+
+		if f.quoted {
+			// The ",string" option only applies to scalars and encodes them inside a string.
+			fieldSchema = &Schema{Type: "string"}
+		}
+
+		key := fmt.Sprintf("%s.%s", t.Name(), f.name)
+		enum, hasEnum := g.opts.Enums[key]
+		if !hasEnum {
+			enum, hasEnum = g.opts.Enums[f.name]
+		}
+		if hasEnum {
+			if fieldSchema.Type == "array" && fieldSchema.Items != nil {
+				items := *fieldSchema.Items
+				items.Enum = enum
+				fieldSchema.Items = &items
+			} else {
+				fieldSchema.Enum = enum
+			}
+		}
+
+		required := !f.omitEmpty || g.opts.Required[key] || g.opts.Required[f.name]
+		if hasEnum && fieldSchema.Enum != nil && !containsZero(enum, f.typ) {
+			required = true
+		}
+		if required {
+			result.Required = append(result.Required, f.name)
+		}
+
+		result.Properties[f.name] = fieldSchema
+	}
+
+	return nil
+}
+
+// containsZero returns true if one of the values is equal to the zero value of type t.
+func containsZero(values []interface{}, t reflect.Type) bool {
+	zero := fmt.Sprint(reflect.Zero(t).Interface())
+	for _, v := range values {
+		if fmt.Sprint(v) == zero {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type schemaChild struct {
+	Name  string   `json:"name"`
+	Codes []string `json:"codes,omitempty"`
+}
+
+type schemaParent struct {
+	Kind     uint32         `json:"kind,omitempty"`
+	Mode     uint8          `json:"mode,omitempty"`
+	Payload  []byte         `json:"payload,omitempty"`
+	Hash     [4]byte        `json:"hash"`
+	Count    int64          `json:"count,string"`
+	Child    *schemaChild   `json:"child,omitempty"`
+	Children []*schemaChild `json:"children,omitempty"`
+	Next     *schemaParent  `json:"next,omitempty"`
+	Values   map[string]int `json:"values,omitempty"`
+	Skipped  string         `json:"-"`
+	hidden   string
+}
+
+func TestSchema(t *testing.T) {
+	opts := &SchemaOptions{
+		Enums: map[string][]interface{}{
+			"schemaParent.kind":  {1, 2},
+			"schemaParent.mode":  {0, 1},
+			"schemaChild.codes":  {"A", "B"},
+			"schemaParent.other": {1},
+			"name":               {"x", "y"},
+		},
+		Required: map[string]bool{
+			"schemaParent.payload": true,
+		},
+	}
+
+	s, err := NewSchema(&schemaParent{}, opts)
+	if err != nil {
+		t.Fatalf("Failed to generate schema : %s", err)
+	}
+
+	if s.Version != SchemaVersion {
+		t.Errorf("Wrong version : got %s", s.Version)
+	}
+	if s.Title != "schemaParent" {
+		t.Errorf("Wrong title : got %s", s.Title)
+	}
+	if s.Type != "object" {
+		t.Errorf("Wrong type : got %s", s.Type)
+	}
+
+	if len(s.Properties) != 9 {
+		t.Errorf("Wrong property count : got %d, want %d", len(s.Properties), 9)
+	}
+	if _, exists := s.Properties["Skipped"]; exists {
+		t.Errorf("Skipped field included")
+	}
+	if _, exists := s.Properties["hidden"]; exists {
+		t.Errorf("Unexported field included")
+	}
+
+	// "kind" has an enum without zero, "payload" is required by options, "hash" and "count"
+	// aren't omitempty.
+	wantRequired := []string{"kind", "payload", "hash", "count"}
+	if !reflect.DeepEqual(s.Required, wantRequired) {
+		t.Errorf("Wrong required : got %v, want %v", s.Required, wantRequired)
+	}
+
+	kind := s.Properties["kind"]
+	if kind.Type != "integer" || kind.Minimum != "0" || kind.Maximum != "4294967295" {
+		t.Errorf("Wrong kind schema : %+v", kind)
+	}
+	if !reflect.DeepEqual(kind.Enum, []interface{}{1, 2}) {
+		t.Errorf("Wrong kind enum : %v", kind.Enum)
+	}
+
+	payload := s.Properties["payload"]
+	if payload.Type != "string" || payload.ContentEncoding != "base16" || payload.Pattern != hexPattern {
+		t.Errorf("Byte slice not marked hex : %+v", payload)
+	}
+
+	hash := s.Properties["hash"]
+	if hash.Type != "array" || hash.MinItems == nil || *hash.MinItems != 4 ||
+		hash.MaxItems == nil || *hash.MaxItems != 4 {
+		t.Errorf("Wrong array schema : %+v", hash)
+	}
+
+	if s.Properties["count"].Type != "string" {
+		t.Errorf("Quoted field should be string : %+v", s.Properties["count"])
+	}
+
+	if s.Properties["child"].Ref != "#/definitions/schemaChild" {
+		t.Errorf("Wrong child ref : %s", s.Properties["child"].Ref)
+	}
+	if s.Properties["children"].Items.Ref != "#/definitions/schemaChild" {
+		t.Errorf("Wrong children ref : %+v", s.Properties["children"].Items)
+	}
+	if s.Properties["next"].Ref != "#" {
+		t.Errorf("Wrong recursive ref : %s", s.Properties["next"].Ref)
+	}
+	if s.Properties["values"].AdditionalProperties.Type != "integer" {
+		t.Errorf("Wrong map schema : %+v", s.Properties["values"])
+	}
+
+	child, exists := s.Definitions["schemaChild"]
+	if !exists {
+		t.Fatalf("Missing child definition")
+	}
+	if len(s.Definitions) != 1 {
+		t.Errorf("Wrong definition count : got %d, want %d", len(s.Definitions), 1)
+	}
+	if !reflect.DeepEqual(child.Required, []string{"name"}) {
+		t.Errorf("Wrong child required : %v", child.Required)
+	}
+	if !reflect.DeepEqual(child.Properties["name"].Enum, []interface{}{"x", "y"}) {
+		t.Errorf("Wrong name enum : %v", child.Properties["name"].Enum)
+	}
+	codes := child.Properties["codes"]
+	if codes.Enum != nil || !reflect.DeepEqual(codes.Items.Enum, []interface{}{"A", "B"}) {
+		t.Errorf("List enum should apply to items : %+v", codes)
+	}
+
+	b, err := Marshal(s)
+	if err != nil {
+		t.Fatalf("Failed to marshal schema : %s", err)
+	}
+	if !strings.Contains(string(b), `"$schema":"http://json-schema.org/draft-07/schema#"`) {
+		t.Errorf("Missing $schema : %s", b)
+	}
+	if !strings.Contains(string(b), `"minimum":0,"maximum":4294967295`) {
+		t.Errorf("Bounds not encoded as numbers : %s", b)
+	}
+}
+
+func TestSchemaUnsupported(t *testing.T) {
+	type bad struct {
+		F func()
+	}
+
+	if _, err := NewSchema(bad{}, nil); err == nil {
+		t.Errorf("Expected error for func field")
+	}
+	if _, err := NewSchema(nil, nil); err == nil {
+		t.Errorf("Expected error for nil")
+	}
+}
@@ -0,0 +1,178 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// An ArrayWriter writes a JSON array to an output stream one element at a
+// time, so arrays of unknown length can be written without holding them, or
+// their encoding, in memory. Only the current element is buffered.
+//
+// Elements are encoded the same way as Marshal, so []byte values are hex.
+type ArrayWriter struct {
+	w          io.Writer
+	escapeHTML bool
+	prefix     string
+	indent     string
+	count      int
+	closed     bool
+	err        error
+	indentBuf  bytes.Buffer
+}
+
+// ErrWriterClosed is returned when writing to a closed ArrayWriter.
+var ErrWriterClosed = errors.New("json: write to closed writer")
+
+// NewArrayWriter returns a new array writer that writes to w.
+func NewArrayWriter(w io.Writer) *ArrayWriter {
+	return &ArrayWriter{w: w, escapeHTML: true}
+}
+
+// Array returns an array writer that writes to the encoder's stream with the
+// encoder's indent and HTML escaping settings. The encoder should not be used
+// until the array writer is closed.
+func (enc *Encoder) Array() *ArrayWriter {
+	return &ArrayWriter{
+		w:          enc.w,
+		escapeHTML: enc.escapeHTML,
+		prefix:     enc.indentPrefix,
+		indent:     enc.indentValue,
+	}
+}
+
+// SetIndent instructs the writer to put each element on its own line, indented
+// as if by Indent(dst, src, prefix, indent). It must be called before the
+// first element is written.
+func (a *ArrayWriter) SetIndent(prefix, indent string) {
+	a.prefix = prefix
+	a.indent = indent
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be
+// escaped inside JSON quoted strings.
+func (a *ArrayWriter) SetEscapeHTML(on bool) {
+	a.escapeHTML = on
+}
+
+// Len returns the number of elements written.
+func (a *ArrayWriter) Len() int {
+	return a.count
+}
+
+// Write writes the JSON encoding of v as the next element of the array.
+func (a *ArrayWriter) Write(v interface{}) error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.closed {
+		return ErrWriterClosed
+	}
+
+	e := newEncodeState()
+	defer encodeStatePool.Put(e)
+	if err := e.marshal(v, encOpts{escapeHTML: a.escapeHTML}); err != nil {
+		return err
+	}
+
+	var separator []byte
+	if a.count == 0 {
+		separator = []byte{'['}
+	} else {
+		separator = []byte{','}
+	}
+	if a.isIndented() {
+		separator = append(separator, '\n')
+		separator = append(separator, a.prefix...)
+		separator = append(separator, a.indent...)
+	}
+
+	b := e.Bytes()
+	if a.isIndented() {
+		a.indentBuf.Reset()
+		if err := Indent(&a.indentBuf, b, a.prefix+a.indent, a.indent); err != nil {
+			return err
+		}
+		b = a.indentBuf.Bytes()
+	}
+
+	if err := a.write(separator); err != nil {
+		return err
+	}
+	if err := a.write(b); err != nil {
+		return err
+	}
+
+	a.count++
+	return nil
+}
+
+// Close terminates the array. It doesn't close the underlying writer.
+func (a *ArrayWriter) Close() error {
+	if a.err != nil {
+		return a.err
+	}
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	if a.count == 0 {
+		return a.write([]byte("[]\n"))
+	}
+
+	var end []byte
+	if a.isIndented() {
+		end = append(end, '\n')
+		end = append(end, a.prefix...)
+	}
+	end = append(end, ']', '\n')
+	return a.write(end)
+}
+
+func (a *ArrayWriter) isIndented() bool {
+	return a.prefix != "" || a.indent != ""
+}
+
+func (a *ArrayWriter) write(b []byte) error {
+	if _, err := a.w.Write(b); err != nil {
+		a.err = err
+		return err
+	}
+	return nil
+}
+
+// An NDJSONWriter writes newline delimited JSON, one compact value per line,
+// as described at http://ndjson.org.
+//
+// Values are encoded the same way as Marshal, so []byte values are hex.
+type NDJSONWriter struct {
+	enc   *Encoder
+	count int
+}
+
+// NewNDJSONWriter returns a new NDJSON writer that writes to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: NewEncoder(w)}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be
+// escaped inside JSON quoted strings.
+func (n *NDJSONWriter) SetEscapeHTML(on bool) {
+	n.enc.SetEscapeHTML(on)
+}
+
+// Len returns the number of values written.
+func (n *NDJSONWriter) Len() int {
+	return n.count
+}
+
+// Write writes the JSON encoding of v followed by a newline.
+func (n *NDJSONWriter) Write(v interface{}) error {
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+	n.count++
+	return nil
+}
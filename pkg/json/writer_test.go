@@ -0,0 +1,144 @@
+package json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type writerItem struct {
+	Name string
+	Data []byte
+}
+
+func TestArrayWriter(t *testing.T) {
+	items := []writerItem{
+		{Name: "a", Data: []byte{0x01, 0xab}},
+		{Name: "<b>", Data: nil},
+	}
+
+	var buf bytes.Buffer
+	a := NewArrayWriter(&buf)
+	for _, item := range items {
+		if err := a.Write(item); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want, err := Marshal(items)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got := buf.String(); got != string(want)+"\n" {
+		t.Errorf("ArrayWriter:\ngot  %s\nwant %s", got, want)
+	}
+	if !strings.Contains(buf.String(), `"01ab"`) {
+		t.Errorf("Bytes not hex encoded: %s", buf.String())
+	}
+	if a.Len() != 2 {
+		t.Errorf("Len = %d, want 2", a.Len())
+	}
+	if err := a.Write(items[0]); err != ErrWriterClosed {
+		t.Errorf("Write after close = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestArrayWriterIndent(t *testing.T) {
+	items := []writerItem{
+		{Name: "a", Data: []byte{0x01}},
+		{Name: "b", Data: []byte{0x02}},
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent(">", "  ")
+	a := enc.Array()
+	for _, item := range items {
+		if err := a.Write(item); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want, err := MarshalIndent(items, ">", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent: %v", err)
+	}
+	if got := buf.String(); got != string(want)+"\n" {
+		t.Errorf("Indented ArrayWriter:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestArrayWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewArrayWriter(&buf)
+	a.SetIndent("", "  ")
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := buf.String(); got != "[]\n" {
+		t.Errorf("Empty array = %q, want %q", got, "[]\n")
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewNDJSONWriter(&buf)
+	for _, item := range []writerItem{{Name: "a", Data: []byte{0xff}}, {Name: "b"}} {
+		if err := n.Write(item); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	want := `{"Name":"a","Data":"ff"}` + "\n" + `{"Name":"b","Data":null}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("NDJSON:\ngot  %s\nwant %s", got, want)
+	}
+
+	// Each line decodes on its own.
+	dec := NewDecoder(&buf)
+	for i := 0; i < n.Len(); i++ {
+		var item writerItem
+		if err := dec.Decode(&item); err != nil {
+			t.Fatalf("Decode line %d: %v", i, err)
+		}
+	}
+}
+
+// countingWriter discards output and records the largest single write.
+type countingWriter struct {
+	total   int
+	largest int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.total += len(b)
+	if len(b) > w.largest {
+		w.largest = len(b)
+	}
+	return len(b), nil
+}
+
+func TestArrayWriterBounded(t *testing.T) {
+	w := &countingWriter{}
+	a := NewArrayWriter(w)
+	item := writerItem{Name: "holding", Data: make([]byte, 32)}
+	for i := 0; i < 100000; i++ {
+		if err := a.Write(item); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Output is written per element rather than as one document.
+	if w.largest > 200 {
+		t.Errorf("Largest write %d bytes of %d", w.largest, w.total)
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// LevelHandler returns an HTTP handler that shows and changes log levels while running.
+//   GET responds with a JSON object of subsystem names to levels.
+//   PUT or POST with "subsystem" and "level" query parameters changes one level. Without a
+//     "level" parameter the body is read as a JSON object of subsystem names to levels.
+//   Both respond with the resulting levels.
+func LevelHandler(config *Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := setLevelsFromRequest(config, r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(config.Levels())
+	})
+}
+
+func setLevelsFromRequest(config *Config, r *http.Request) error {
+	query := r.URL.Query()
+	if levelName := query.Get("level"); len(levelName) > 0 {
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+		config.SetLevel(query.Get("subsystem"), level)
+		return nil
+	}
+
+	var levels map[string]Level
+	if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+		return fmt.Errorf("Invalid levels : %s", err)
+	}
+
+	for subsystem, level := range levels {
+		config.SetLevel(subsystem, level)
+	}
+	return nil
+}
@@ -2,14 +2,19 @@ package logger
 
 import (
 	"os"
+	"strings"
 	"sync"
 )
 
+// MainSystem is the name used for entries that aren't from a subsystem.
+const MainSystem = "Main"
+
 // Config defines the logging configuration for the context it is attached to.
 type Config struct {
 	Main               *SystemConfig
 	IncludedSubSystems map[string]bool          // If true, log in main log
 	SubSystems         map[string]*SystemConfig // SubSystem specific configs
+	SubSystemLevels    map[string]Level         // Overrides main log minimum level for subsystems
 	mutex              sync.Mutex
 }
 
@@ -33,6 +38,7 @@ func NewProductionConfig() *Config {
 	result := Config{
 		IncludedSubSystems: make(map[string]bool),
 		SubSystems:         make(map[string]*SystemConfig),
+		SubSystemLevels:    make(map[string]Level),
 	}
 
 	result.Main = NewProductionSystemConfig()
@@ -45,6 +51,7 @@ func NewDevelopmentConfig() *Config {
 	result := Config{
 		IncludedSubSystems: make(map[string]bool),
 		SubSystems:         make(map[string]*SystemConfig),
+		SubSystemLevels:    make(map[string]Level),
 	}
 
 	result.Main = NewDevelopmentSystemConfig()
@@ -58,3 +65,77 @@ func (config *Config) EnableSubSystem(subsystem string) {
 
 	config.IncludedSubSystems[subsystem] = true
 }
+
+// SetLevel changes the minimum level logged for a subsystem while the config is in use.
+//   "Main" or an empty subsystem sets the main log level. Setting the level of a subsystem also
+//   enables it in the main log, and sets the level of its specific config if it has one.
+func (config *Config) SetLevel(subsystem string, level Level) {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+
+	if len(subsystem) == 0 || subsystem == MainSystem {
+		config.Main.MinLevel = level
+		return
+	}
+
+	if config.IncludedSubSystems == nil {
+		config.IncludedSubSystems = make(map[string]bool)
+	}
+	if config.SubSystemLevels == nil {
+		config.SubSystemLevels = make(map[string]Level)
+	}
+
+	config.IncludedSubSystems[subsystem] = true
+	config.SubSystemLevels[subsystem] = level
+	if subConfig, exists := config.SubSystems[subsystem]; exists {
+		subConfig.MinLevel = level
+	}
+}
+
+// SetLevels sets levels from a comma separated list of subsystem=level values. For example
+//   "Main=info,SpyNode=debug". A value without a subsystem sets the main level.
+func (config *Config) SetLevels(levels string) error {
+	for _, item := range strings.Split(levels, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			continue
+		}
+
+		subsystem := MainSystem
+		levelName := item
+		if i := strings.Index(item, "="); i != -1 {
+			subsystem = strings.TrimSpace(item[:i])
+			levelName = item[i+1:]
+		}
+
+		level, err := ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+		config.SetLevel(subsystem, level)
+	}
+
+	return nil
+}
+
+// Levels returns the minimum level of the main log and of each enabled subsystem.
+func (config *Config) Levels() map[string]Level {
+	config.mutex.Lock()
+	defer config.mutex.Unlock()
+
+	result := make(map[string]Level)
+	result[MainSystem] = config.Main.MinLevel
+	for subsystem, included := range config.IncludedSubSystems {
+		if included {
+			result[subsystem] = config.Main.MinLevel
+		}
+	}
+	for subsystem, subConfig := range config.SubSystems {
+		result[subsystem] = subConfig.MinLevel
+	}
+	for subsystem, level := range config.SubSystemLevels {
+		result[subsystem] = level
+	}
+
+	return result
+}
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Common field names so entries from different packages can be searched the same way.
+const (
+	FieldContract  = "contract"
+	FieldTxID      = "txid"
+	FieldAction    = "action"
+	FieldRejection = "rejection_code"
+)
+
+// Field is a named value attached to log entries.
+type Field struct {
+	Name  string
+	Value interface{}
+}
+
+// String returns the field formatted as name=value for text log entries. Values containing
+//   spaces or quotes are quoted.
+func (f Field) String() string {
+	value := fmt.Sprintf("%v", f.Value)
+	if strings.ContainsAny(value, " \t\n\"=") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return f.Name + "=" + value
+}
+
+// ContextWithLogFields returns a context with the fields added to any fields already attached.
+//   Every entry logged with the context includes them. A field with the same name as an existing
+//   field replaces it.
+func ContextWithLogFields(ctx context.Context, fields ...Field) context.Context {
+	existing := getFields(ctx)
+	result := make([]Field, 0, len(existing)+len(fields))
+
+	for _, field := range existing {
+		replaced := false
+		for _, newField := range fields {
+			if newField.Name == field.Name {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, field)
+		}
+	}
+	result = append(result, fields...)
+
+	return context.WithValue(ctx, fieldsKey, result)
+}
+
+// ContextWithLogField returns a context with one field added.
+func ContextWithLogField(ctx context.Context, name string, value interface{}) context.Context {
+	return ContextWithLogFields(ctx, Field{Name: name, Value: value})
+}
+
+func getFields(ctx context.Context) []Field {
+	fieldsValue := ctx.Value(fieldsKey)
+	if fieldsValue == nil {
+		return nil
+	}
+
+	fields, ok := fieldsValue.([]Field)
+	if !ok {
+		return nil
+	}
+
+	return fields
+}
@@ -45,6 +45,7 @@ const (
 	IncludeFile   = 0x08 // file name and line number
 	IncludeSystem = 0x10 // system name
 	IncludeLevel  = 0x20 // level of log entry
+	FormatJSON    = 0x40 // JSON object per entry with time, level, message, and fields as values
 )
 
 // Returns a context with the logging config attached.
@@ -52,6 +53,15 @@ func ContextWithLogConfig(ctx context.Context, config *Config) context.Context {
 	return context.WithValue(ctx, configKey, config)
 }
 
+// Returns the logging config attached to the context, or nil if there isn't one.
+func ConfigFromContext(ctx context.Context) *Config {
+	config, ok := ctx.Value(configKey).(*Config)
+	if !ok || config == &emptyConfig {
+		return nil
+	}
+	return config
+}
+
 func ContextWithNoLogger(ctx context.Context) context.Context {
 	return context.WithValue(ctx, configKey, &emptyConfig)
 }
@@ -148,11 +158,12 @@ func LogDepth(ctx context.Context, level Level, depth int, format string, values
 	}
 
 	trace := getTrace(ctx)
+	fields := getFields(ctx)
 
 	config.mutex.Lock()
 	defer config.mutex.Unlock()
 
-	subsystem := "Main"
+	subsystem := MainSystem
 	subsystemValue := ctx.Value(subSystemKey)
 	if subsystemValue != nil {
 		var ok bool
@@ -163,7 +174,8 @@ func LogDepth(ctx context.Context, level Level, depth int, format string, values
 		// Log to subsystem specific config
 		subConfig, subExists := config.SubSystems[subsystem]
 		if subExists {
-			if err := subConfig.log(subsystem, level, depth, trace, format, values...); err != nil {
+			if err := subConfig.log(subsystem, level, subConfig.MinLevel, depth, trace, fields,
+				format, values...); err != nil {
 				return err
 			}
 		}
@@ -175,7 +187,11 @@ func LogDepth(ctx context.Context, level Level, depth int, format string, values
 	}
 
 	// Log to main config
-	return config.Main.log(subsystem, level, depth, trace, format, values...)
+	minLevel := config.Main.MinLevel
+	if subsystemLevel, exists := config.SubSystemLevels[subsystem]; exists {
+		minLevel = subsystemLevel
+	}
+	return config.Main.log(subsystem, level, minLevel, depth, trace, fields, format, values...)
 }
 
 // Keys for context key/pairs
@@ -185,4 +201,5 @@ const (
 	configKey    loggerkey = 1
 	subSystemKey loggerkey = 2
 	traceKey     loggerkey = 3
+	fieldsKey    loggerkey = 4
 )
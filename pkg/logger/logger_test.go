@@ -1,8 +1,14 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +36,150 @@ func TestLogger(test *testing.T) {
 		Log(ctx, LevelInfo, "Second main entry")
 	}
 }
+
+func TestFields(test *testing.T) {
+	var buf bytes.Buffer
+	logConfig := NewDevelopmentConfig()
+	logConfig.Main.SetWriter(&buf)
+	logConfig.Main.Format = IncludeLevel
+	ctx := ContextWithLogConfig(context.Background(), logConfig)
+	ctx = ContextWithLogTrace(ctx, "trace")
+
+	ctx = ContextWithLogFields(ctx, Field{FieldTxID, "abcd"}, Field{FieldAction, "T1"})
+	ctx = ContextWithLogField(ctx, FieldAction, "T2")
+	Info(ctx, "Processing %s\n", "request")
+
+	want := "Info - <trace> Processing request txid=abcd action=T2\n"
+	if buf.String() != want {
+		test.Errorf("Wrong text entry :\n  got  %q\n  want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	logConfig.Main.Format = IncludeSystem | FormatJSON
+	logConfig.EnableSubSystem("sub")
+	ctx = ContextWithLogField(ctx, "msg", "has spaces")
+	Warn(ContextWithLogSubSystem(ctx, "sub"), "Sub entry")
+	Info(ctx, "Main entry\n")
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) != 3 || len(lines[2]) != 0 {
+		test.Fatalf("Wrong entries : %q", buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		test.Fatalf("Failed to unmarshal JSON entry %q : %s", lines[0], err)
+	}
+	if entry["level"] != "warn" || entry["system"] != "sub" || entry["msg"] != "Sub entry" {
+		test.Errorf("Wrong sub system entry : %q", lines[0])
+	}
+
+	entry = nil
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		test.Fatalf("Failed to unmarshal JSON entry %q : %s", lines[1], err)
+	}
+
+	expected := map[string]string{
+		"level":     "info",
+		"system":    MainSystem,
+		"trace":     "trace",
+		"msg":       "Main entry",
+		"txid":      "abcd",
+		"action":    "T2",
+		"field_msg": "has spaces",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			test.Errorf("Wrong JSON value for %s : got %v, want %v", key, entry[key], value)
+		}
+	}
+	if _, exists := entry["time"]; !exists {
+		test.Errorf("Missing JSON time")
+	}
+}
+
+func TestSetLevel(test *testing.T) {
+	var buf bytes.Buffer
+	logConfig := NewProductionConfig()
+	logConfig.Main.SetWriter(&buf)
+	logConfig.Main.Format = 0
+	ctx := ContextWithLogConfig(context.Background(), logConfig)
+	subCtx := ContextWithLogSubSystem(ctx, "sub")
+
+	Verbose(ctx, "main hidden")
+	Info(subCtx, "sub hidden")
+	if buf.Len() != 0 {
+		test.Fatalf("Entries should be hidden : %q", buf.String())
+	}
+
+	if err := logConfig.SetLevels("sub=debug"); err != nil {
+		test.Fatalf("Failed to set levels : %s", err)
+	}
+
+	Debug(subCtx, "sub shown")
+	Verbose(ctx, "main hidden")
+	if buf.String() != "sub shown\n" {
+		test.Errorf("Wrong entries : %q", buf.String())
+	}
+
+	if err := logConfig.SetLevels("verbose,sub=error"); err != nil {
+		test.Fatalf("Failed to set levels : %s", err)
+	}
+
+	buf.Reset()
+	Warn(subCtx, "sub hidden")
+	Verbose(ctx, "main shown")
+	if buf.String() != "main shown\n" {
+		test.Errorf("Wrong entries : %q", buf.String())
+	}
+
+	if err := logConfig.SetLevels("sub=loud"); err == nil {
+		test.Errorf("Invalid level should fail")
+	}
+
+	levels := logConfig.Levels()
+	if levels[MainSystem] != LevelVerbose || levels["sub"] != LevelError {
+		test.Errorf("Wrong levels : %v", levels)
+	}
+}
+
+func TestLevelHandler(test *testing.T) {
+	logConfig := NewProductionConfig()
+	handler := LevelHandler(logConfig)
+
+	request := httptest.NewRequest(http.MethodPut, "/log/levels?subsystem=sub&level=debug", nil)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		test.Fatalf("Wrong status : %d %s", response.Code, response.Body.String())
+	}
+
+	request = httptest.NewRequest(http.MethodPost, "/log/levels",
+		strings.NewReader(`{"Main":"warn","other":"verbose"}`))
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		test.Fatalf("Wrong status : %d %s", response.Code, response.Body.String())
+	}
+
+	var levels map[string]string
+	if err := json.Unmarshal(response.Body.Bytes(), &levels); err != nil {
+		test.Fatalf("Failed to unmarshal levels : %s", err)
+	}
+
+	expected := map[string]string{
+		MainSystem: "warn",
+		"sub":      "debug",
+		"other":    "verbose",
+	}
+	if !reflect.DeepEqual(levels, expected) {
+		test.Errorf("Wrong levels :\n  got  %v\n  want %v", levels, expected)
+	}
+
+	request = httptest.NewRequest(http.MethodPut, "/log/levels?level=loud", nil)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest {
+		test.Errorf("Wrong status for invalid level : %d", response.Code)
+	}
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationConfig defines when a log file is rotated.
+type RotationConfig struct {
+	MaxSize    int64         // Rotate before the file grows past this many bytes. Zero disables.
+	MaxAge     time.Duration // Rotate when the file has been open this long. Zero disables.
+	MaxBackups int           // Rotated files to keep. Older files are removed. Zero keeps all.
+}
+
+// RotatingFile is a log output file that is renamed with a timestamp suffix and replaced by a new
+//   file when it reaches a maximum size or age.
+type RotatingFile struct {
+	path   string
+	config RotationConfig
+	file   *os.File
+	size   int64
+	opened time.Time
+	now    func() time.Time
+	lock   sync.Mutex
+}
+
+// NewRotatingFile opens, or creates, the file at filePath for appending.
+func NewRotatingFile(filePath string, config RotationConfig) (*RotatingFile, error) {
+	result := &RotatingFile{
+		path:   filepath.FromSlash(filePath),
+		config: config,
+		now:    time.Now,
+	}
+
+	if err := result.open(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Write writes to the file, rotating it first if the entry would exceed the size limit or the
+//   file is older than the age limit.
+func (f *RotatingFile) Write(b []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.shouldRotate(int64(len(b))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(b)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it, and opens a new one.
+func (f *RotatingFile) Rotate() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+
+	return f.rotate()
+}
+
+// Close closes the file.
+func (f *RotatingFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) shouldRotate(size int64) bool {
+	if f.size == 0 {
+		return false // Don't leave empty files, even if one entry is larger than the limit.
+	}
+	if f.config.MaxSize > 0 && f.size+size > f.config.MaxSize {
+		return true
+	}
+	if f.config.MaxAge > 0 && f.now().Sub(f.opened) >= f.config.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = f.now()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	// Add a counter if a file was already rotated in the same second.
+	base := f.path + "." + f.now().UTC().Format("20060102-150405")
+	backupPath := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			break
+		}
+		backupPath = fmt.Sprintf("%s.%d", base, i)
+	}
+
+	if err := os.Rename(f.path, backupPath); err != nil {
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	return f.removeOldBackups()
+}
+
+// removeOldBackups deletes the oldest rotated files beyond the maximum count.
+func (f *RotatingFile) removeOldBackups() error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return err
+	}
+
+	prefix := f.path + "."
+	var backups []string
+	for _, match := range matches {
+		if strings.HasPrefix(match, prefix) {
+			backups = append(backups, match)
+		}
+	}
+
+	if len(backups) <= f.config.MaxBackups {
+		return nil
+	}
+
+	// Timestamp suffixes sort oldest first.
+	sort.Strings(backups)
+	for _, backup := range backups[:len(backups)-f.config.MaxBackups] {
+		if err := os.Remove(backup); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
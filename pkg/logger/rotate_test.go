@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile(test *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		test.Fatalf("Failed to create temp dir : %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2019, 12, 20, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(dir, "logs", "main.log")
+	file, err := NewRotatingFile(path, RotationConfig{MaxSize: 10, MaxAge: time.Hour, MaxBackups: 2})
+	if err != nil {
+		test.Fatalf("Failed to open file : %s", err)
+	}
+	defer file.Close()
+	file.now = func() time.Time { return now }
+	file.opened = now
+
+	write := func(s string) {
+		if _, err := file.Write([]byte(s)); err != nil {
+			test.Fatalf("Failed to write : %s", err)
+		}
+	}
+
+	write("12345\n")
+	write("123\n") // Fits exactly
+	write("abc\n") // Over max size
+	now = now.Add(time.Second)
+	write("0123456789abcdef\n") // Over max size, but larger than file so written alone after rotate
+	now = now.Add(time.Hour)
+	write("a\n") // Over max age
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		test.Fatalf("Failed to list backups : %s", err)
+	}
+	sort.Strings(backups)
+
+	// The first rotation is removed because only 2 backups are kept.
+	expected := []string{
+		path + ".20191220-100001",
+		path + ".20191220-110001",
+	}
+	if len(backups) != len(expected) {
+		test.Fatalf("Wrong backups : got %v, want %v", backups, expected)
+	}
+	for i, backup := range backups {
+		if backup != expected[i] {
+			test.Errorf("Wrong backup %d : got %s, want %s", i, backup, expected[i])
+		}
+	}
+
+	contents := []string{"abc\n", "0123456789abcdef\n"}
+	for i, backup := range backups {
+		b, err := ioutil.ReadFile(backup)
+		if err != nil {
+			test.Fatalf("Failed to read backup : %s", err)
+		}
+		if string(b) != contents[i] {
+			test.Errorf("Wrong backup %d contents : got %q, want %q", i, b, contents[i])
+		}
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		test.Fatalf("Failed to read file : %s", err)
+	}
+	if string(b) != "a\n" {
+		test.Errorf("Wrong file contents : got %q", b)
+	}
+}
@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -67,18 +69,63 @@ func (config *SystemConfig) AddWriter(writer io.Writer) {
 	config.Output = io.MultiWriter(config.Output, writer)
 }
 
+// Adds a rotating file to the existing log outputs
+func (config *SystemConfig) AddRotatingFile(filePath string, rotation RotationConfig) error {
+	file, err := NewRotatingFile(filePath, rotation)
+	if err != nil {
+		return err
+	}
+
+	config.Output = io.MultiWriter(config.Output, file)
+	return nil
+}
+
+// Sets a rotating file as the only log output
+func (config *SystemConfig) SetRotatingFile(filePath string, rotation RotationConfig) error {
+	file, err := NewRotatingFile(filePath, rotation)
+	if err != nil {
+		return err
+	}
+
+	config.Output = file
+	return nil
+}
+
 // Sets a writer as the only log output
 func (config *SystemConfig) SetWriter(writer io.Writer) {
 	config.Output = writer
 }
 
 // Logs an entry based on the system config
-func (config *SystemConfig) log(system string, level Level, depth int, trace, format string, values ...interface{}) error {
-	if config.MinLevel > level {
+func (config *SystemConfig) log(system string, level, minLevel Level, depth int, trace string,
+	fields []Field, format string, values ...interface{}) error {
+	if minLevel > level {
 		return nil // Level is below minimum
 	}
 
-	// Create log entry
+	var entry []byte
+	if config.Format&FormatJSON != 0 {
+		entry = config.jsonEntry(system, level, depth, trace, fields, format, values...)
+	} else {
+		entry = config.textEntry(system, level, depth, trace, fields, format, values...)
+	}
+
+	// Write to output
+	_, err := config.Output.Write(entry)
+
+	if level == LevelFatal {
+		os.Exit(1)
+	}
+	if level == LevelPanic {
+		panic(entry)
+	}
+	return err
+}
+
+// textEntry returns a log entry as a line of text.
+func (config *SystemConfig) textEntry(system string, level Level, depth int, trace string,
+	fields []Field, format string, values ...interface{}) []byte {
+
 	now := time.Now()
 	entry := make([]byte, 0, 1024)
 
@@ -117,14 +164,7 @@ func (config *SystemConfig) log(system string, level Level, depth int, trace, fo
 
 	// Append File
 	if config.Format&IncludeFile != 0 {
-		_, file, line, ok := runtime.Caller(2 + depth) // Code of interest is 2 levels up in stack
-		if ok {
-			file = filepath.Base(file)
-		} else {
-			file = "???"
-			line = 0
-		}
-
+		file, line := caller(3 + depth) // Code of interest is 3 levels up in stack
 		entry = append(entry, file...)
 		entry = append(entry, ':')
 		entry = append(entry, fmt.Sprintf("%d ", line)...)
@@ -159,19 +199,97 @@ func (config *SystemConfig) log(system string, level Level, depth int, trace, fo
 	// Append actual log entry
 	entry = append(entry, fmt.Sprintf(format, values...)...)
 
+	// Append fields
+	if len(fields) > 0 {
+		for len(entry) > 0 && entry[len(entry)-1] == '\n' {
+			entry = entry[:len(entry)-1]
+		}
+		for _, field := range fields {
+			entry = append(entry, ' ')
+			entry = append(entry, field.String()...)
+		}
+	}
+
 	// Append new line
-	if entry[len(entry)-1] != '\n' {
+	if len(entry) == 0 || entry[len(entry)-1] != '\n' {
 		entry = append(entry, '\n')
 	}
 
-	// Write to output
-	_, err := config.Output.Write(entry)
+	return entry
+}
 
-	if level == LevelFatal {
-		os.Exit(1)
+// jsonEntry returns a log entry as a JSON object on one line. Time, level and message are always
+//   included. Fields are added as top level values.
+func (config *SystemConfig) jsonEntry(system string, level Level, depth int, trace string,
+	fields []Field, format string, values ...interface{}) []byte {
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	writeJSONValue(&buf, "time", time.Now().UTC().Format(time.RFC3339Nano), true)
+	writeJSONValue(&buf, "level", level.String(), false)
+
+	if config.Format&IncludeSystem != 0 {
+		writeJSONValue(&buf, "system", system, false)
 	}
-	if level == LevelPanic {
-		panic(entry)
+
+	if config.Format&IncludeFile != 0 {
+		file, line := caller(3 + depth) // Code of interest is 3 levels up in stack
+		writeJSONValue(&buf, "file", fmt.Sprintf("%s:%d", file, line), false)
 	}
-	return err
+
+	if len(trace) > 0 {
+		writeJSONValue(&buf, "trace", trace, false)
+	}
+
+	message := fmt.Sprintf(format, values...)
+	for len(message) > 0 && message[len(message)-1] == '\n' {
+		message = message[:len(message)-1]
+	}
+	writeJSONValue(&buf, "msg", message, false)
+
+	for _, field := range fields {
+		switch field.Name {
+		case "time", "level", "system", "file", "trace", "msg":
+			// Don't duplicate standard keys.
+			writeJSONValue(&buf, "field_"+field.Name, field.Value, false)
+		default:
+			writeJSONValue(&buf, field.Name, field.Value, false)
+		}
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// writeJSONValue writes a key and value to a JSON object. Values that can't be marshalled are
+//   written as their formatted string.
+func writeJSONValue(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	buf.Write(v)
+}
+
+// caller returns the file name and line number of the code depth levels above the function
+//   calling it.
+func caller(depth int) (string, int) {
+	_, file, line, ok := runtime.Caller(depth + 1)
+	if !ok {
+		return "???", 0
+	}
+	return filepath.Base(file), line
 }
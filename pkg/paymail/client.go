@@ -0,0 +1,319 @@
+package paymail
+
+/**
+ * Paymail Client Kit
+ *
+ * What is my purpose?
+ * - You turn a paymail handle (alias@domain.tld) into a locking script I can pay
+ */
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/logger"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SubSystem is used by the logger package
+	SubSystem = "Paymail"
+
+	// WellKnownPath is where a paymail host publishes its capabilities.
+	WellKnownPath = "/.well-known/bsvalias"
+
+	// Capability identifiers
+	CapabilityPaymentDestination    = "paymentDestination"
+	CapabilityP2PPaymentDestination = "2a40af698840"
+)
+
+var (
+	// ErrNotSupported is returned when the host doesn't publish a capability.
+	ErrNotSupported = errors.New("Capability not supported")
+
+	// ErrInvalidHandle is returned when a handle is not in the alias@domain.tld format.
+	ErrInvalidHandle = errors.New("Invalid paymail handle")
+)
+
+// Capabilities is the well known document published by a paymail host.
+type Capabilities struct {
+	BSVAlias     string                 `json:"bsvalias"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+}
+
+// Output is a payment destination provided by the host.
+type Output struct {
+	Script   []byte
+	Satoshis uint64
+}
+
+// Destination is the result of a payment destination request.
+type Destination struct {
+	Outputs   []Output
+	Reference string // Identifies the payment to the host when using P2P destinations.
+}
+
+type Client struct {
+	config       Config
+	client       *http.Client
+	scheme       string
+	capabilities map[string]*Capabilities
+	lock         sync.Mutex
+}
+
+type p2pDestinationRequest struct {
+	Satoshis uint64 `json:"satoshis"`
+}
+
+type p2pDestinationResponse struct {
+	Outputs []struct {
+		Script   string `json:"script"`
+		Satoshis uint64 `json:"satoshis"`
+	} `json:"outputs"`
+	Reference string `json:"reference"`
+}
+
+type destinationRequest struct {
+	SenderName   string `json:"senderName,omitempty"`
+	SenderHandle string `json:"senderHandle"`
+	DateTime     string `json:"dt"`
+	Amount       uint64 `json:"amount,omitempty"`
+	Purpose      string `json:"purpose,omitempty"`
+}
+
+type destinationResponse struct {
+	Output string `json:"output"`
+}
+
+// NewClient returns a new paymail client.
+func NewClient(config *Config) *Client {
+	return &Client{
+		config:       *config,
+		client:       &http.Client{Timeout: config.Timeout},
+		scheme:       "https",
+		capabilities: make(map[string]*Capabilities),
+	}
+}
+
+// IsHandle returns true if the value looks like a paymail handle.
+func IsHandle(value string) bool {
+	_, _, err := SplitHandle(value)
+	return err == nil
+}
+
+// SplitHandle returns the alias and domain of a paymail handle. A leading "$", used by some
+//   wallets, is removed.
+func SplitHandle(handle string) (string, string, error) {
+	handle = strings.TrimPrefix(strings.TrimSpace(handle), "$")
+	parts := strings.Split(handle, "@")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 ||
+		strings.ContainsAny(handle, " \t/") {
+		return "", "", errors.Wrap(ErrInvalidHandle, handle)
+	}
+	return strings.ToLower(parts[0]), strings.ToLower(parts[1]), nil
+}
+
+// Capabilities returns the capabilities published by the host of a domain. They are cached for the
+//   life of the client.
+func (c *Client) Capabilities(ctx context.Context, domain string) (*Capabilities, error) {
+	c.lock.Lock()
+	result, exists := c.capabilities[domain]
+	c.lock.Unlock()
+	if exists {
+		return result, nil
+	}
+
+	url := fmt.Sprintf("%s://%s%s", c.scheme, c.host(ctx, domain), WellKnownPath)
+	body, err := c.request(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "capabilities")
+	}
+
+	result = &Capabilities{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, errors.Wrap(err, "unmarshal capabilities")
+	}
+
+	c.lock.Lock()
+	c.capabilities[domain] = result
+	c.lock.Unlock()
+	return result, nil
+}
+
+// URL returns the endpoint for a capability with the handle filled in.
+func (caps *Capabilities) URL(capability, alias, domain string) (string, error) {
+	value, exists := caps.Capabilities[capability]
+	if !exists {
+		return "", errors.Wrap(ErrNotSupported, capability)
+	}
+	template, ok := value.(string)
+	if !ok || len(template) == 0 {
+		return "", errors.Wrap(ErrNotSupported, capability)
+	}
+
+	result := strings.Replace(template, "{alias}", alias, -1)
+	return strings.Replace(result, "{domain.tld}", domain, -1), nil
+}
+
+// PaymentDestination returns the outputs to pay a handle. The P2P payment destination endpoint is
+//   used when the host supports it, otherwise the basic address resolution endpoint is used.
+func (c *Client) PaymentDestination(ctx context.Context, handle string,
+	satoshis uint64) (*Destination, error) {
+
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	defer logger.Elapsed(ctx, time.Now(), "PaymentDestination")
+
+	alias, domain, err := SplitHandle(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	caps, err := c.Capabilities(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if url, err := caps.URL(CapabilityP2PPaymentDestination, alias, domain); err == nil {
+		return c.p2pDestination(ctx, url, satoshis)
+	}
+
+	url, err := caps.URL(CapabilityPaymentDestination, alias, domain)
+	if err != nil {
+		return nil, err
+	}
+	return c.basicDestination(ctx, url, satoshis)
+}
+
+// LockingScript returns a single locking script that pays the handle.
+func (c *Client) LockingScript(ctx context.Context, handle string) ([]byte, error) {
+	destination, err := c.PaymentDestination(ctx, handle, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(destination.Outputs) == 0 {
+		return nil, fmt.Errorf("No outputs provided for %s", handle)
+	}
+
+	return destination.Outputs[0].Script, nil
+}
+
+func (c *Client) p2pDestination(ctx context.Context, url string,
+	satoshis uint64) (*Destination, error) {
+
+	request, err := json.Marshal(p2pDestinationRequest{Satoshis: satoshis})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal request")
+	}
+
+	body, err := c.request(ctx, http.MethodPost, url, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "p2p destination")
+	}
+
+	var response p2pDestinationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshal p2p destination")
+	}
+
+	result := &Destination{Reference: response.Reference}
+	for i, output := range response.Outputs {
+		script, err := hex.DecodeString(output.Script)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("decode output %d script", i))
+		}
+		result.Outputs = append(result.Outputs, Output{Script: script, Satoshis: output.Satoshis})
+	}
+
+	return result, nil
+}
+
+func (c *Client) basicDestination(ctx context.Context, url string,
+	satoshis uint64) (*Destination, error) {
+
+	request, err := json.Marshal(destinationRequest{
+		SenderName:   c.config.SenderName,
+		SenderHandle: c.config.SenderEmail,
+		DateTime:     time.Now().UTC().Format(time.RFC3339),
+		Amount:       satoshis,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal request")
+	}
+
+	body, err := c.request(ctx, http.MethodPost, url, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "destination")
+	}
+
+	var response destinationResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshal destination")
+	}
+
+	script, err := hex.DecodeString(response.Output)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode output script")
+	}
+
+	return &Destination{Outputs: []Output{Output{Script: script, Satoshis: satoshis}}}, nil
+}
+
+// host returns the host serving paymail for a domain, from its SRV record if it has one.
+func (c *Client) host(ctx context.Context, domain string) string {
+	if c.config.DisableSRV {
+		return domain
+	}
+
+	_, records, err := net.LookupSRV("bsvalias", "tcp", domain)
+	if err != nil || len(records) == 0 {
+		return domain
+	}
+
+	target := strings.TrimSuffix(records[0].Target, ".")
+	logger.Verbose(ctx, "Paymail host for %s : %s:%d", domain, target, records[0].Port)
+	return fmt.Sprintf("%s:%d", target, records[0].Port)
+}
+
+func (c *Client) request(ctx context.Context, method, url string, body []byte) ([]byte, error) {
+	httpRequest, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "create request")
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	httpRequest.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	result, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read response")
+	}
+
+	if httpResponse.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("Not found : %s", url)
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d : %s", httpResponse.StatusCode,
+			strings.TrimSpace(string(result)))
+	}
+
+	return result, nil
+}
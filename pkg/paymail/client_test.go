@@ -0,0 +1,134 @@
+package paymail
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSplitHandle(t *testing.T) {
+	tests := []struct {
+		handle string
+		alias  string
+		domain string
+		valid  bool
+	}{
+		{handle: "Alice@Example.com", alias: "alice", domain: "example.com", valid: true},
+		{handle: "$bob@example.com", alias: "bob", domain: "example.com", valid: true},
+		{handle: "example.com"},
+		{handle: "@example.com"},
+		{handle: "a@b@example.com"},
+		{handle: "bitcoin-script:0101"},
+	}
+
+	for _, tt := range tests {
+		alias, domain, err := SplitHandle(tt.handle)
+		if !tt.valid {
+			if errors.Cause(err) != ErrInvalidHandle {
+				t.Fatalf("%s : expected invalid handle, got %v", tt.handle, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s : %s", tt.handle, err)
+		}
+		if alias != tt.alias || domain != tt.domain {
+			t.Fatalf("%s : got %s %s, want %s %s", tt.handle, alias, domain, tt.alias, tt.domain)
+		}
+	}
+}
+
+func TestPaymentDestination(t *testing.T) {
+	ctx := context.Background()
+	script := []byte{0x76, 0xa9, 0x14, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17,
+		18, 19, 20, 0x88, 0xac}
+
+	wellKnownRequests := 0
+	supportP2P := true
+	var host string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == WellKnownPath:
+			wellKnownRequests++
+			capabilities := map[string]interface{}{
+				CapabilityPaymentDestination: "https://" + host +
+					"/api/address/{alias}@{domain.tld}",
+			}
+			if supportP2P {
+				capabilities[CapabilityP2PPaymentDestination] = "https://" + host +
+					"/api/p2p/{alias}@{domain.tld}"
+			}
+			json.NewEncoder(w).Encode(Capabilities{BSVAlias: "1.0", Capabilities: capabilities})
+
+		case r.URL.Path == "/api/p2p/alice@"+host:
+			var request p2pDestinationRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"outputs":[{"script":"%x","satoshis":%d}],"reference":"ref1"}`,
+				script, request.Satoshis)
+
+		case strings.HasPrefix(r.URL.Path, "/api/address/"):
+			var request destinationRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil ||
+				len(request.DateTime) == 0 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `{"output":"%s"}`, hex.EncodeToString(script))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	host = strings.TrimPrefix(server.URL, "https://")
+
+	client := NewClient(&Config{DisableSRV: true, SenderEmail: "sender@example.com"})
+	client.client = server.Client()
+
+	destination, err := client.PaymentDestination(ctx, "Alice@"+host, 1000)
+	if err != nil {
+		t.Fatalf("Failed to get destination : %s", err)
+	}
+	if destination.Reference != "ref1" || len(destination.Outputs) != 1 ||
+		destination.Outputs[0].Satoshis != 1000 {
+		t.Fatalf("Wrong destination : %+v", destination)
+	}
+	if hex.EncodeToString(destination.Outputs[0].Script) != hex.EncodeToString(script) {
+		t.Fatalf("Wrong script : %x", destination.Outputs[0].Script)
+	}
+
+	// Capabilities are cached.
+	if _, err := client.LockingScript(ctx, "alice@"+host); err != nil {
+		t.Fatalf("Failed to get locking script : %s", err)
+	}
+	if wellKnownRequests != 1 {
+		t.Fatalf("Capabilities requested %d times", wellKnownRequests)
+	}
+
+	// Unknown alias
+	if _, err := client.LockingScript(ctx, "carol@"+host); err == nil {
+		t.Fatalf("Resolved unknown alias")
+	}
+
+	// Hosts without P2P destinations use basic address resolution.
+	supportP2P = false
+	client = NewClient(&Config{DisableSRV: true, SenderEmail: "sender@example.com"})
+	client.client = server.Client()
+	lockingScript, err := client.LockingScript(ctx, "bob@"+host)
+	if err != nil {
+		t.Fatalf("Failed to get basic destination : %s", err)
+	}
+	if hex.EncodeToString(lockingScript) != hex.EncodeToString(script) {
+		t.Fatalf("Wrong basic script : %x", lockingScript)
+	}
+}
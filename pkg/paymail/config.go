@@ -0,0 +1,12 @@
+package paymail
+
+import (
+	"time"
+)
+
+type Config struct {
+	Timeout     time.Duration // Timeout for each request
+	SenderName  string        // Provided to hosts that require sender information
+	SenderEmail string        // Paymail handle of the sender
+	DisableSRV  bool          // Don't look up the _bsvalias SRV record of the domain
+}
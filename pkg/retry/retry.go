@@ -0,0 +1,84 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Policy determines how many times an operation is attempted and the delay between attempts.
+// The delay starts at Delay and is multiplied by Multiplier after each retry, up to MaxDelay.
+type Policy struct {
+	MaxAttempts int           // Total attempts including the first. Less than one means one.
+	Delay       time.Duration // Delay before the first retry
+	MaxDelay    time.Duration // Maximum delay between attempts. Zero means no maximum.
+	Multiplier  float64       // Less than one means the delay doesn't change.
+}
+
+// DefaultPolicy returns a policy of 5 attempts with the delay doubling from half a second.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 5,
+		Delay:       500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Multiplier:  2.0,
+	}
+}
+
+// permanentError wraps an error that should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Cause supports errors.Cause from github.com/pkg/errors.
+func (e *permanentError) Cause() error {
+	return e.err
+}
+
+// Permanent wraps an error so Do returns it without retrying.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if the error was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	_, ok := err.(*permanentError)
+	return ok
+}
+
+// Do calls f until it succeeds, returns a permanent error, the attempts are used, or the context
+//   is done. The error from the last attempt is returned with any permanent wrapping removed.
+func (p Policy) Do(ctx context.Context, f func() error) error {
+	delay := p.Delay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if pErr, ok := err.(*permanentError); ok {
+			return pErr.err
+		}
+		if attempt >= p.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+
+		if p.Multiplier > 1.0 {
+			delay = time.Duration(float64(delay) * p.Multiplier)
+		}
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	policy := Policy{MaxAttempts: 4, Delay: time.Millisecond, MaxDelay: 4 * time.Millisecond,
+		Multiplier: 2.0}
+
+	// Succeeds on third attempt
+	attempts := 0
+	err := policy.Do(ctx, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("Wrong result : attempts %d, err %v", attempts, err)
+	}
+
+	// Attempts exhausted
+	attempts = 0
+	temporary := errors.New("temporary")
+	err = policy.Do(ctx, func() error {
+		attempts++
+		return temporary
+	})
+	if err != temporary || attempts != 4 {
+		t.Fatalf("Wrong result : attempts %d, err %v", attempts, err)
+	}
+
+	// Permanent error isn't retried and is unwrapped
+	attempts = 0
+	permanent := errors.New("permanent")
+	err = policy.Do(ctx, func() error {
+		attempts++
+		return Permanent(permanent)
+	})
+	if err != permanent || attempts != 1 {
+		t.Fatalf("Wrong result : attempts %d, err %v", attempts, err)
+	}
+
+	// Canceled context stops retries
+	attempts = 0
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Policy{MaxAttempts: 10, Delay: time.Second}.Do(canceled, func() error {
+		attempts++
+		return temporary
+	})
+	if err != temporary || attempts != 1 {
+		t.Fatalf("Wrong result : attempts %d, err %v", attempts, err)
+	}
+}
@@ -0,0 +1,69 @@
+package rpcnode
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrCircuitOpen is returned without contacting the node after too many consecutive failures.
+	ErrCircuitOpen = errors.New("RPC circuit open")
+)
+
+// breaker is a circuit breaker that stops requests to a failing node for a cool down period.
+// After the cool down one request is allowed through as a probe while others are still refused.
+//   If it fails the circuit opens again.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	lock      sync.Mutex
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns ErrCircuitOpen if requests should not be made. A request that is allowed must be
+//   followed by a call to success or failure.
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+
+	b.probing = true
+	return nil
+}
+
+func (b *breaker) success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.probing = false
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
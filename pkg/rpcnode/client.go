@@ -0,0 +1,275 @@
+package rpcnode
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/retry"
+
+	"github.com/pkg/errors"
+)
+
+// client makes JSON-RPC calls to a bitcoind compatible node over HTTP.
+type client struct {
+	url      string
+	username string
+	password string
+	http     *http.Client
+	retry    retry.Policy
+	breaker  *breaker
+	nextID   uint64
+	lock     sync.Mutex
+}
+
+type request struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Error is an error returned by the node, rather than a transport failure.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("RPC error %d : %s", e.Code, e.Message)
+}
+
+const (
+	// errCodeInvalidAddressOrKey is returned by bitcoind when a tx is not known.
+	errCodeInvalidAddressOrKey = -5
+)
+
+func newClient(config *Config) (*client, error) {
+	transport := &http.Transport{}
+	scheme := "http"
+
+	if config.TLS {
+		scheme = "https"
+		tlsConfig := &tls.Config{}
+
+		if len(config.CAFile) > 0 {
+			pem, err := ioutil.ReadFile(config.CAFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "read CA file")
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("No certificates in CA file %s", config.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if len(config.CertFile) > 0 {
+			cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+			if err != nil {
+				return nil, errors.Wrap(err, "load client certificate")
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	url := config.Host
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = scheme + "://" + url
+	}
+
+	return &client{
+		url:      url,
+		username: config.Username,
+		password: config.Password,
+		http:     &http.Client{Transport: transport, Timeout: config.Timeout},
+		retry:    config.Retry,
+		breaker:  newBreaker(config.BreakerThreshold, config.BreakerCooldown),
+	}, nil
+}
+
+// call makes a single request and unmarshals the result into result, if it isn't nil. Errors
+//   returned by the node are returned as *Error.
+func (c *client) call(ctx context.Context, method string, params []interface{},
+	result interface{}) error {
+
+	return c.do(ctx, method, params, result, true)
+}
+
+// callOnce is like call, but for requests that must not be repeated once the node might have
+//   received them, like broadcasting a tx. It is only retried when the request wasn't written.
+func (c *client) callOnce(ctx context.Context, method string, params []interface{},
+	result interface{}) error {
+
+	return c.do(ctx, method, params, result, false)
+}
+
+func (c *client) do(ctx context.Context, method string, params []interface{},
+	result interface{}, retryWritten bool) error {
+
+	return c.retry.Do(ctx, func() error {
+		body, err := json.Marshal(c.newRequest(method, params))
+		if err != nil {
+			return retry.Permanent(errors.Wrap(err, "marshal request"))
+		}
+
+		var written uint32
+		b, err := c.post(ctx, body, &written)
+		if err != nil {
+			logger.Verbose(ctx, "%s failed : %s", method, err)
+			if !retryWritten && atomic.LoadUint32(&written) != 0 {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+
+		var r response
+		if err := json.Unmarshal(b, &r); err != nil {
+			return retry.Permanent(errors.Wrap(err, "unmarshal response"))
+		}
+		if r.Error != nil {
+			return retry.Permanent(r.Error)
+		}
+		if result == nil {
+			return nil
+		}
+		if err := json.Unmarshal(r.Result, result); err != nil {
+			return retry.Permanent(errors.Wrap(err, "unmarshal result"))
+		}
+		return nil
+	})
+}
+
+// batch makes one JSON-RPC batch request of the same method with each set of params. Responses
+//   are returned in the same order as the params. Errors for individual requests are in the
+//   responses.
+func (c *client) batch(ctx context.Context, method string,
+	params [][]interface{}) ([]*response, error) {
+
+	var result []*response
+	err := c.retry.Do(ctx, func() error {
+		requests := make([]request, len(params))
+		for i, p := range params {
+			requests[i] = c.newRequest(method, p)
+		}
+
+		body, err := json.Marshal(requests)
+		if err != nil {
+			return retry.Permanent(errors.Wrap(err, "marshal request"))
+		}
+
+		b, err := c.post(ctx, body, nil)
+		if err != nil {
+			logger.Verbose(ctx, "%s batch failed : %s", method, err)
+			return err
+		}
+
+		var responses []*response
+		if err := json.Unmarshal(b, &responses); err != nil {
+			// A failure of the whole batch is returned as a single response.
+			var r response
+			if err := json.Unmarshal(b, &r); err == nil && r.Error != nil {
+				return retry.Permanent(r.Error)
+			}
+			return retry.Permanent(errors.Wrap(err, "unmarshal batch response"))
+		}
+
+		byID := make(map[uint64]*response)
+		for _, r := range responses {
+			byID[r.ID] = r
+		}
+
+		result = make([]*response, len(requests))
+		for i, request := range requests {
+			r, exists := byID[request.ID]
+			if !exists {
+				return retry.Permanent(fmt.Errorf("Missing response to request %d", request.ID))
+			}
+			result[i] = r
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+func (c *client) newRequest(method string, params []interface{}) request {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.nextID++
+	return request{JSONRPC: "1.0", ID: c.nextID, Method: method, Params: params}
+}
+
+// post sends a request body and returns the response body. Transport failures and overloaded
+//   responses count against the circuit breaker and can be retried. If written isn't nil it is
+//   set to 1 when the whole request has been written to the node.
+func (c *client) post(ctx context.Context, body []byte, written *uint32) ([]byte, error) {
+	httpRequest, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, retry.Permanent(errors.Wrap(err, "create request"))
+	}
+	if written != nil {
+		ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+			WroteRequest: func(info httptrace.WroteRequestInfo) {
+				if info.Err == nil {
+					atomic.StoreUint32(written, 1)
+				}
+			},
+		})
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+
+	if err := c.breaker.allow(); err != nil {
+		return nil, retry.Permanent(err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.SetBasicAuth(c.username, c.password)
+
+	httpResponse, err := c.http.Do(httpRequest)
+	if err != nil {
+		c.breaker.failure()
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	b, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		c.breaker.failure()
+		return nil, errors.Wrap(err, "read response")
+	}
+
+	switch {
+	case httpResponse.StatusCode == http.StatusUnauthorized ||
+		httpResponse.StatusCode == http.StatusForbidden:
+		c.breaker.success()
+		return nil, retry.Permanent(fmt.Errorf("HTTP %s", httpResponse.Status))
+	case httpResponse.StatusCode == http.StatusServiceUnavailable ||
+		(httpResponse.StatusCode >= 500 && !json.Valid(b)):
+		// bitcoind returns errors from calls as 500 with a JSON body, so only other 500s are
+		//   failures of the node.
+		c.breaker.failure()
+		return nil, fmt.Errorf("HTTP %s : %s", httpResponse.Status, string(b))
+	}
+
+	c.breaker.success()
+	return b, nil
+}
@@ -2,19 +2,41 @@ package rpcnode
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/retry"
 )
 
 type Config struct {
 	Host     string
 	Username string
 	Password string
+
+	// TLS
+	TLS      bool   // Connect with https
+	CAFile   string // PEM CA certificates used to verify the node. System CAs are used if empty.
+	CertFile string // PEM client certificate. Client auth is not used if empty.
+	KeyFile  string // PEM client key
+
+	Timeout time.Duration // Timeout for each HTTP request
+	Retry   retry.Policy  // Retry policy for transport failures
+
+	// Circuit breaker. After BreakerThreshold consecutive transport failures requests fail
+	//   immediately with ErrCircuitOpen until BreakerCooldown has passed.
+	BreakerThreshold int // Zero disables the breaker
+	BreakerCooldown  time.Duration
 }
 
 // String returns a custom string representation.
 //
 // This is important so we don't log sensitive config values.
 func (c Config) String() string {
-	return fmt.Sprintf("{Host:%v Username:%v Password:%v}", c.Host,
+	return fmt.Sprintf("{Host:%v Username:%v Password:%v TLS:%v CAFile:%v CertFile:%v Timeout:%v}",
+		c.Host,
 		c.Username,
-		"****")
+		"****",
+		c.TLS,
+		c.CAFile,
+		c.CertFile,
+		c.Timeout)
 }
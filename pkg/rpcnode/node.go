@@ -13,20 +13,16 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
 	"github.com/tokenized/smart-contract/pkg/logger"
 	"github.com/tokenized/smart-contract/pkg/wire"
 
 	"github.com/btcsuite/btcd/btcjson"
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
-	"github.com/btcsuite/btcd/rpcclient"
-	btcwire "github.com/btcsuite/btcd/wire"
-	"github.com/btcsuite/btcutil"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -34,23 +30,21 @@ const (
 	SubSystem = "RPCNode"
 )
 
+var (
+	// ErrTxNotFound is returned when the node doesn't know a tx. Transport failures return other
+	//   errors.
+	ErrTxNotFound = inspector.ErrTxNotFound
+)
+
 type RPCNode struct {
-	client  *rpcclient.Client
+	client  *client
 	txCache map[bitcoin.Hash32]*wire.MsgTx
 	lock    sync.Mutex
 }
 
 // NewNode returns a new instance of an RPC node
 func NewNode(config *Config) (*RPCNode, error) {
-	rpcConfig := rpcclient.ConnConfig{
-		HTTPPostMode: true,
-		DisableTLS:   true,
-		Host:         config.Host,
-		User:         config.Username,
-		Pass:         config.Password,
-	}
-
-	client, err := rpcclient.New(&rpcConfig, nil)
+	client, err := newClient(config)
 	if err != nil {
 		return nil, err
 	}
@@ -79,34 +73,24 @@ func (r *RPCNode) GetTX(ctx context.Context, id *bitcoin.Hash32) (*wire.MsgTx, e
 	r.lock.Unlock()
 
 	logger.Verbose(ctx, "Requesting tx from RPC : %s\n", id.String())
-	ch, _ := chainhash.NewHash(id[:])
-	raw, err := r.client.GetRawTransactionVerbose(ch)
-	if err != nil {
-		return nil, err
+	var raw string
+	if err := r.client.call(ctx, "getrawtransaction", []interface{}{id.String(), 0},
+		&raw); err != nil {
+		return nil, txError(err, id)
 	}
 
-	b, err := hex.DecodeString(raw.Hex)
-	if err != nil {
-		return nil, err
-	}
-
-	tx := wire.MsgTx{}
-	buf := bytes.NewReader(b)
-
-	if err := tx.Deserialize(buf); err != nil {
-		return nil, err
-	}
-
-	return &tx, nil
+	return decodeTx(raw)
 }
 
-// GetTXs requests a list of txs from the remote server.
+// GetTXs requests a list of txs from the remote server in one batch. If a tx is not found then
+//   the txs before it are returned with the error.
 func (r *RPCNode) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
 	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
 	defer logger.Elapsed(ctx, time.Now(), "GetTXs")
 
 	results := make([]*wire.MsgTx, len(txids))
-	requests := make([]*rpcclient.FutureGetRawTransactionVerboseResult, len(txids))
+	var params [][]interface{}
+	var indexes []int
 
 	r.lock.Lock()
 	for i, txid := range txids {
@@ -117,35 +101,38 @@ func (r *RPCNode) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.
 			results[i] = msg
 		} else {
 			logger.Verbose(ctx, "Requesting tx from RPC : %s\n", txid.String())
-			ch, _ := chainhash.NewHash(txid[:])
-			request := r.client.GetRawTransactionVerboseAsync(ch)
-			requests[i] = &request
+			params = append(params, []interface{}{txid.String(), 0})
+			indexes = append(indexes, i)
 		}
 	}
 	r.lock.Unlock()
 
-	for i, request := range requests {
-		if request == nil {
-			continue
-		}
-		rawTx, err := request.Receive()
-		if err != nil {
-			return results, err // TODO Determine when error is tx not seen yet
-		}
+	if len(params) == 0 {
+		return results, nil
+	}
 
-		b, err := hex.DecodeString(rawTx.Hex)
-		if err != nil {
-			return results, err
+	responses, err := r.client.batch(ctx, "getrawtransaction", params)
+	if err != nil {
+		return results, err
+	}
+
+	for i, response := range responses {
+		txid := txids[indexes[i]]
+		if response.Error != nil {
+			return results, txError(response.Error, txid)
 		}
 
-		tx := wire.MsgTx{}
-		buf := bytes.NewReader(b)
+		var raw string
+		if err := json.Unmarshal(response.Result, &raw); err != nil {
+			return results, errors.Wrap(err, txid.String())
+		}
 
-		if err := tx.Deserialize(buf); err != nil {
-			return results, err
+		tx, err := decodeTx(raw)
+		if err != nil {
+			return results, errors.Wrap(err, txid.String())
 		}
 
-		results[i] = &tx
+		results[indexes[i]] = tx
 	}
 
 	return results, nil
@@ -156,45 +143,14 @@ func (r *RPCNode) WatchAddress(ctx context.Context, address bitcoin.Address) err
 	strAddr := address.String()
 
 	// Make address known to node without rescan
-	if err := r.client.ImportAddressRescan(strAddr, strAddr, false); err != nil {
-		return err
-	}
-
-	return nil
+	return r.client.call(ctx, "importaddress", []interface{}{strAddr, strAddr, false}, nil)
 }
 
 // ListTransactions returns all transactions for watched addresses
 func (r *RPCNode) ListTransactions(ctx context.Context) ([]btcjson.ListTransactionsResult, error) {
-
-	// Prepare listtransactions command
-	cmd := btcjson.NewListTransactionsCmd(
-		btcjson.String("*"),
-		btcjson.Int(99999),
-		btcjson.Int(0),
-		btcjson.Bool(true))
-
-	id := r.client.NextID()
-	marshalledJSON, err := btcjson.MarshalCmd(id, cmd)
-	if err != nil {
-		return nil, err
-	}
-
-	// Unmarhsal in to a request to extract the params
-	var request btcjson.Request
-	if err = json.Unmarshal(marshalledJSON, &request); err != nil {
-		return nil, err
-	}
-
-	// Submit raw request
-	out, err := r.client.RawRequest("listtransactions", request.Params)
-	if err != nil {
-		return nil, err
-	}
-
-	// Unmarshal response in to a ListTransactionsResult
 	var response []btcjson.ListTransactionsResult
-
-	if err = json.Unmarshal(out, &response); err != nil {
+	if err := r.client.call(ctx, "listtransactions", []interface{}{"*", 99999, 0, true},
+		&response); err != nil {
 		return nil, err
 	}
 
@@ -209,13 +165,9 @@ func (r *RPCNode) ListUnspent(ctx context.Context, address bitcoin.Address) ([]b
 		return nil, err
 	}
 
-	btcaddress, _ := btcutil.DecodeAddress(address.String(),
-		bitcoin.NewChainParams(bitcoin.NetworkName(address.Network())))
-	addresses := []btcutil.Address{btcaddress}
-
-	// out []btcjson.ListUnspentResult
-	out, err := r.client.ListUnspentMinMaxAddresses(0, 999999, addresses)
-	if err != nil {
+	var out []btcjson.ListUnspentResult
+	if err := r.client.call(ctx, "listunspent",
+		[]interface{}{0, 999999, []string{address.String()}}, &out); err != nil {
 		return nil, err
 	}
 
@@ -224,14 +176,7 @@ func (r *RPCNode) ListUnspent(ctx context.Context, address bitcoin.Address) ([]b
 
 // SendRawTransaction broadcasts a raw transaction
 func (r *RPCNode) SendRawTransaction(ctx context.Context, tx *wire.MsgTx) error {
-
-	nx, err := r.txToBtcdTX(tx)
-	if err != nil {
-		return err
-	}
-
-	_, err = r.client.SendRawTransaction(nx, false)
-
+	_, err := r.SendTX(ctx, tx)
 	return err
 }
 
@@ -247,70 +192,71 @@ func (r *RPCNode) SaveTX(ctx context.Context, msg *wire.MsgTx) error {
 	return nil
 }
 
-// SendTX sends a tx to the remote server to be broadcast to the P2P network.
+// SendTX sends a tx to the remote server to be broadcast to the P2P network. It isn't retried
+//   once the request was written because the node might already have broadcast the tx.
 func (r *RPCNode) SendTX(ctx context.Context, tx *wire.MsgTx) (*bitcoin.Hash32, error) {
 
 	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
 	defer logger.Elapsed(ctx, time.Now(), "SendTX")
 
-	nx, err := r.txToBtcdTX(tx)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
 		return nil, err
 	}
+	payload := hex.EncodeToString(buf.Bytes())
 
-	logger.Debug(ctx, "Sending tx payload : %s", r.getRawPayload(nx))
+	logger.Debug(ctx, "Sending tx payload : %s", payload)
 
-	ch, err := r.client.SendRawTransaction(nx, false)
-	if err != nil {
+	var txid string
+	if err := r.client.callOnce(ctx, "sendrawtransaction", []interface{}{payload, false},
+		&txid); err != nil {
 		return nil, err
 	}
-	return bitcoin.NewHash32(ch[:])
+	return bitcoin.NewHash32FromStr(txid)
 }
 
 func (r *RPCNode) GetLatestBlock() (*bitcoin.Hash32, int32, error) {
+	ctx := logger.ContextWithLogSubSystem(context.Background(), SubSystem)
+
 	// Get the best block hash
-	hash, err := r.client.GetBestBlockHash()
-	if err != nil {
+	var hash string
+	if err := r.client.call(ctx, "getbestblockhash", nil, &hash); err != nil {
 		return nil, -1, err
 	}
 
-	bhash, err := bitcoin.NewHash32(hash[:])
+	bhash, err := bitcoin.NewHash32FromStr(hash)
 	if err != nil {
 		return nil, -1, err
 	}
 
 	// The height is in the header
-	header, err := r.client.GetBlockHeaderVerbose(hash)
-	if err != nil {
+	var header btcjson.GetBlockHeaderVerboseResult
+	if err := r.client.call(ctx, "getblockheader", []interface{}{hash, true},
+		&header); err != nil {
 		return nil, -1, err
 	}
 
 	return bhash, header.Height, nil
 }
 
-func (r *RPCNode) getRawPayload(tx *btcwire.MsgTx) string {
-	var buf bytes.Buffer
-	tx.Serialize(&buf)
-
-	out := fmt.Sprintf("%#x", buf.Bytes())
-	s := fmt.Sprintf("%s", strings.Replace(out, "0x", "", 1))
-
-	return s
+// txError converts errors from the node for a tx request. Unknown txs return ErrTxNotFound.
+func txError(err error, txid *bitcoin.Hash32) error {
+	if rpcErr, ok := errors.Cause(err).(*Error); ok && rpcErr.Code == errCodeInvalidAddressOrKey {
+		return errors.Wrap(ErrTxNotFound, txid.String())
+	}
+	return errors.Wrap(err, txid.String())
 }
 
-// txToBtcdTx converts a "pkg/wire".MsgTx to a "btcsuite/btcd/wire".MsgTx".
-func (r *RPCNode) txToBtcdTX(tx *wire.MsgTx) (*btcwire.MsgTx, error) {
-	// Read the payload from the input TX, into the output TX.
-	var buf bytes.Buffer
-	tx.Serialize(&buf)
-
-	reader := bytes.NewReader(buf.Bytes())
-
-	nx := &btcwire.MsgTx{}
+func decodeTx(raw string) (*wire.MsgTx, error) {
+	b, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := nx.Deserialize(reader); err != nil {
+	tx := wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
 		return nil, err
 	}
 
-	return nx, nil
+	return &tx, nil
 }
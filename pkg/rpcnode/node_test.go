@@ -1,11 +1,33 @@
 package rpcnode
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/retry"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
 )
 
 // Prior to running test, set the following environment variables.
@@ -37,3 +59,316 @@ func ManualTestNode(test *testing.T) {
 		test.Logf("Tx : %s\n%+v", tx.TxHash().String(), tx)
 	}
 }
+
+func TestGetTXs(test *testing.T) {
+	ctx := context.Background()
+
+	server := newStubNode(test, testTx(1), testTx(2))
+	defer server.Close()
+
+	node, err := NewNode(server.config(test))
+	if err != nil {
+		test.Fatalf("Failed to create node : %s", err)
+	}
+
+	txids := []*bitcoin.Hash32{testTx(2).TxHash(), testTx(1).TxHash()}
+	txs, err := node.GetTXs(ctx, txids)
+	if err != nil {
+		test.Fatalf("Failed to get txs : %s", err)
+	}
+	for i, tx := range txs {
+		if !tx.TxHash().Equal(txids[i]) {
+			test.Fatalf("Wrong tx %d", i)
+		}
+	}
+	if server.batches != 1 || server.requests != 1 {
+		test.Fatalf("Txs not requested in one batch : %d batches, %d requests", server.batches,
+			server.requests)
+	}
+
+	// Unknown txs are distinguished from failures.
+	if _, err := node.GetTX(ctx, testTx(3).TxHash()); errors.Cause(err) != ErrTxNotFound {
+		test.Fatalf("Wrong error for missing tx : %v", err)
+	}
+	if _, err := node.GetTXs(ctx, []*bitcoin.Hash32{testTx(1).TxHash(),
+		testTx(3).TxHash()}); errors.Cause(err) != ErrTxNotFound {
+		test.Fatalf("Wrong error for missing batch tx : %v", err)
+	}
+
+	txid, err := node.SendTX(ctx, testTx(4))
+	if err != nil {
+		test.Fatalf("Failed to send tx : %s", err)
+	}
+	if !txid.Equal(testTx(4).TxHash()) {
+		test.Fatalf("Wrong txid returned : %s", txid.String())
+	}
+}
+
+func TestRetryAndBreaker(test *testing.T) {
+	ctx := context.Background()
+
+	server := newStubNode(test, testTx(1))
+	defer server.Close()
+
+	config := server.config(test)
+	config.BreakerThreshold = 3
+	config.BreakerCooldown = 200 * time.Millisecond
+	node, err := NewNode(config)
+	if err != nil {
+		test.Fatalf("Failed to create node : %s", err)
+	}
+
+	// Transient failures are retried.
+	server.failures = 2
+	if _, err := node.GetTX(ctx, testTx(1).TxHash()); err != nil {
+		test.Fatalf("Failed to get tx after retry : %s", err)
+	}
+	if server.requests != 3 {
+		test.Fatalf("Wrong request count : %d", server.requests)
+	}
+
+	// Not found is not retried.
+	server.requests = 0
+	if _, err := node.GetTX(ctx, testTx(2).TxHash()); errors.Cause(err) != ErrTxNotFound {
+		test.Fatalf("Wrong error for missing tx : %v", err)
+	}
+	if server.requests != 1 {
+		test.Fatalf("Not found retried : %d requests", server.requests)
+	}
+
+	// Consecutive failures open the circuit.
+	server.requests = 0
+	server.failures = 100
+	if _, err := node.GetTX(ctx, testTx(1).TxHash()); err == nil {
+		test.Fatalf("Failing node returned tx")
+	}
+	if server.requests != 3 {
+		test.Fatalf("Wrong request count : %d", server.requests)
+	}
+	if _, err := node.GetTX(ctx, testTx(1).TxHash()); errors.Cause(err) != ErrCircuitOpen {
+		test.Fatalf("Circuit not opened : %v", err)
+	}
+	if server.requests != 3 {
+		test.Fatalf("Request made with circuit open : %d", server.requests)
+	}
+
+	// Requests are allowed again after the cool down.
+	server.failures = 0
+	time.Sleep(config.BreakerCooldown)
+	if _, err := node.GetTX(ctx, testTx(1).TxHash()); err != nil {
+		test.Fatalf("Failed to get tx after cool down : %s", err)
+	}
+
+	// Sent txs are not retried once the node received them.
+	server.requests = 0
+	server.failures = 1
+	if _, err := node.SendTX(ctx, testTx(4)); err == nil {
+		test.Fatalf("Failed send returned success")
+	}
+	if server.requests != 1 {
+		test.Fatalf("Send retried : %d requests", server.requests)
+	}
+}
+
+func TestBreakerProbe(test *testing.T) {
+	b := newBreaker(2, 50*time.Millisecond)
+	b.failure()
+	b.failure()
+	if err := b.allow(); err != ErrCircuitOpen {
+		test.Fatalf("Circuit not opened : %v", err)
+	}
+
+	// Only one request is allowed through after the cool down.
+	time.Sleep(50 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		test.Fatalf("Probe not allowed : %s", err)
+	}
+	if err := b.allow(); err != ErrCircuitOpen {
+		test.Fatalf("Second request allowed during probe : %v", err)
+	}
+
+	// A failed probe opens the circuit again.
+	b.failure()
+	time.Sleep(10 * time.Millisecond)
+	if err := b.allow(); err != ErrCircuitOpen {
+		test.Fatalf("Circuit not opened after failed probe : %v", err)
+	}
+
+	// A successful probe closes the circuit.
+	time.Sleep(50 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		test.Fatalf("Probe not allowed : %s", err)
+	}
+	b.success()
+	if err := b.allow(); err != nil {
+		test.Fatalf("Circuit not closed after probe : %s", err)
+	}
+	if err := b.allow(); err != nil {
+		test.Fatalf("Circuit not closed after probe : %s", err)
+	}
+}
+
+func testTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{1}, index), []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	return tx
+}
+
+// stubNode is a bitcoind JSON-RPC server over TLS that requires a client certificate.
+type stubNode struct {
+	*httptest.Server
+	dir      string
+	txs      map[bitcoin.Hash32]string
+	failures int // Number of requests to fail with 503
+	requests int
+	batches  int
+	lock     sync.Mutex
+}
+
+func newStubNode(test *testing.T, txs ...*wire.MsgTx) *stubNode {
+	dir, err := ioutil.TempDir("", "rpcnode")
+	if err != nil {
+		test.Fatalf("Failed to create temp dir : %s", err)
+	}
+	test.Cleanup(func() { os.RemoveAll(dir) })
+
+	s := &stubNode{dir: dir, txs: make(map[bitcoin.Hash32]string)}
+	for _, tx := range txs {
+		var buf bytes.Buffer
+		tx.Serialize(&buf)
+		s.txs[*tx.TxHash()] = hex.EncodeToString(buf.Bytes())
+	}
+
+	clientCert := writeCert(test, dir, "client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.handle))
+	s.Server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	s.Server.StartTLS()
+
+	// Server certificate as CA for the client.
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	if err := ioutil.WriteFile(filepath.Join(dir, "ca.pem"), ca, 0600); err != nil {
+		test.Fatalf("Failed to write CA : %s", err)
+	}
+
+	return s
+}
+
+func (s *stubNode) config(test *testing.T) *Config {
+	return &Config{
+		Host:     strings.TrimPrefix(s.URL, "https://"),
+		Username: "user",
+		Password: "pass",
+		TLS:      true,
+		CAFile:   filepath.Join(s.dir, "ca.pem"),
+		CertFile: filepath.Join(s.dir, "client.pem"),
+		KeyFile:  filepath.Join(s.dir, "client.key"),
+		Timeout:  time.Second,
+		Retry:    retry.Policy{MaxAttempts: 3, Delay: 10 * time.Millisecond},
+	}
+}
+
+func (s *stubNode) handle(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests++
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	if len(body) > 0 && body[0] == '[' {
+		s.batches++
+		var requests []request
+		json.Unmarshal(body, &requests)
+		responses := make([]*response, len(requests))
+		for i, req := range requests {
+			responses[i] = s.respond(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req request
+	json.Unmarshal(body, &req)
+	resp := s.respond(req)
+	if resp.Error != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *stubNode) respond(req request) *response {
+	resp := &response{ID: req.ID}
+	switch req.Method {
+	case "getrawtransaction":
+		txid, _ := bitcoin.NewHash32FromStr(req.Params[0].(string))
+		raw, exists := s.txs[*txid]
+		if !exists {
+			resp.Error = &Error{Code: errCodeInvalidAddressOrKey,
+				Message: "No such mempool or blockchain transaction. Use gettransaction for wallet transactions."}
+			return resp
+		}
+		resp.Result, _ = json.Marshal(raw)
+	case "sendrawtransaction":
+		b, _ := hex.DecodeString(req.Params[0].(string))
+		tx := &wire.MsgTx{}
+		tx.Deserialize(bytes.NewReader(b))
+		resp.Result, _ = json.Marshal(tx.TxHash().String())
+	default:
+		resp.Error = &Error{Code: -32601, Message: "Method not found"}
+	}
+	return resp
+}
+
+// writeCert writes a self signed certificate and key to dir as name.pem and name.key.
+func writeCert(test *testing.T, dir, name string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		test.Fatalf("Failed to generate key : %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		test.Fatalf("Failed to create certificate : %s", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		test.Fatalf("Failed to marshal key : %s", err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".pem"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		test.Fatalf("Failed to write certificate : %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		test.Fatalf("Failed to write key : %s", err)
+	}
+
+	return cert
+}
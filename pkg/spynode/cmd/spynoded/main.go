@@ -10,10 +10,12 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/logger"
 	"github.com/tokenized/smart-contract/pkg/spynode"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
 	"github.com/tokenized/smart-contract/pkg/storage"
@@ -45,12 +47,20 @@ func main() {
 	var cfg struct {
 		Network string `default:"mainnet" envconfig:"BITCOIN_NETWORK"`
 		Node    struct {
-			Address        string `envconfig:"NODE_ADDRESS"`
-			UserAgent      string `default:"/Tokenized:0.1.0/" envconfig:"NODE_USER_AGENT"`
-			StartHash      string `envconfig:"START_HASH"`
-			UntrustedNodes int    `default:"25" envconfig:"UNTRUSTED_NODES"`
-			SafeTxDelay    int    `default:"2000" envconfig:"SAFE_TX_DELAY"`
-			ShotgunCount   int    `default:"100" envconfig:"SHOTGUN_COUNT"`
+			Address        string   `envconfig:"NODE_ADDRESS"`
+			UserAgent      string   `default:"/Tokenized:0.1.0/" envconfig:"NODE_USER_AGENT"`
+			StartHash      string   `envconfig:"START_HASH"`
+			UntrustedNodes int      `default:"25" envconfig:"UNTRUSTED_NODES"`
+			SafeTxDelay    int      `default:"2000" envconfig:"SAFE_TX_DELAY"`
+			ShotgunCount   int      `default:"100" envconfig:"SHOTGUN_COUNT"`
+			StaticPeers    []string `envconfig:"NODE_STATIC_PEERS"`
+
+			ProxyAddress  string `envconfig:"NODE_PROXY_ADDRESS"`
+			ProxyUsername string `envconfig:"NODE_PROXY_USERNAME"`
+			ProxyPassword string `envconfig:"NODE_PROXY_PASSWORD" json:"-"`
+
+			TrustedTimeout   time.Duration `default:"30s" envconfig:"NODE_TRUSTED_TIMEOUT"`
+			UntrustedTimeout time.Duration `default:"15s" envconfig:"NODE_UNTRUSTED_TIMEOUT"`
 		}
 		NodeStorage struct {
 			Region    string `default:"ap-southeast-2" envconfig:"NODE_STORAGE_REGION"`
@@ -98,6 +108,11 @@ func main() {
 		logger.Error(ctx, "Failed to create node config : %s\n", err)
 		return
 	}
+	nodeConfig.StaticPeers = cfg.Node.StaticPeers
+	nodeConfig.Dialer = dialer.New(cfg.Node.ProxyAddress, cfg.Node.ProxyUsername,
+		cfg.Node.ProxyPassword)
+	nodeConfig.TrustedTimeout = cfg.Node.TrustedTimeout
+	nodeConfig.UntrustedTimeout = cfg.Node.UntrustedTimeout
 
 	// -------------------------------------------------------------------------
 	// Node
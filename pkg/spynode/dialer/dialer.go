@@ -0,0 +1,33 @@
+package dialer
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// Dialer creates connections to peers.
+type Dialer interface {
+	// Dial connects to the address. The connection attempt fails if it takes longer than timeout.
+	Dial(ctx context.Context, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// New returns a dialer that connects through the SOCKS5 proxy at proxyAddress, or connects
+//   directly if proxyAddress is empty.
+func New(proxyAddress, username, password string) Dialer {
+	if len(proxyAddress) == 0 {
+		return &DirectDialer{}
+	}
+
+	return NewSOCKS5Dialer(proxyAddress, username, password)
+}
+
+// DirectDialer connects directly to peers over TCP.
+type DirectDialer struct{}
+
+// Dial implements the Dialer interface.
+func (d *DirectDialer) Dial(ctx context.Context, address string,
+	timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	return dialer.DialContext(ctx, "tcp", address)
+}
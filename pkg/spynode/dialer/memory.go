@@ -0,0 +1,69 @@
+package dialer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// MemoryPeer serves one in-memory connection. It is run in a goroutine for each connection and
+//   should return when the connection is closed.
+type MemoryPeer func(conn net.Conn)
+
+// MemoryDialer connects to in-memory peers so tests can run deterministically without a network.
+type MemoryDialer struct {
+	peers    map[string]MemoryPeer
+	attempts map[string]int
+	lock     sync.Mutex
+}
+
+// NewMemoryDialer returns a dialer with no peers.
+func NewMemoryDialer() *MemoryDialer {
+	return &MemoryDialer{
+		peers:    make(map[string]MemoryPeer),
+		attempts: make(map[string]int),
+	}
+}
+
+// Register adds a peer at the address.
+func (d *MemoryDialer) Register(address string, peer MemoryPeer) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.peers[address] = peer
+}
+
+// Remove removes the peer at the address. Existing connections are not closed.
+func (d *MemoryDialer) Remove(address string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.peers, address)
+}
+
+// Attempts returns the number of times a connection to the address was attempted.
+func (d *MemoryDialer) Attempts(address string) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.attempts[address]
+}
+
+// Dial implements the Dialer interface. Connections to unregistered addresses are refused.
+func (d *MemoryDialer) Dial(ctx context.Context, address string,
+	timeout time.Duration) (net.Conn, error) {
+	d.lock.Lock()
+	d.attempts[address]++
+	peer, exists := d.peers[address]
+	d.lock.Unlock()
+
+	if !exists {
+		return nil, fmt.Errorf("Connection refused : %s", address)
+	}
+
+	local, remote := net.Pipe()
+	go peer(remote)
+	return local, nil
+}
@@ -0,0 +1,213 @@
+package dialer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SOCKS5 protocol values. See RFC 1928 and RFC 1929.
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthPassword     = 0x02
+	socks5AuthNoAcceptable = 0xff
+
+	socks5PasswordVersion = 0x01
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+)
+
+var socks5Replies = map[byte]string{
+	0x01: "general failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+// SOCKS5Dialer connects to peers through a SOCKS5 proxy.
+type SOCKS5Dialer struct {
+	ProxyAddress string
+	Username     string
+	Password     string
+	Forward      Dialer // Used to connect to the proxy
+}
+
+// NewSOCKS5Dialer returns a dialer that connects through the SOCKS5 proxy at proxyAddress.
+// Username/password authentication is used when username is not empty.
+func NewSOCKS5Dialer(proxyAddress, username, password string) *SOCKS5Dialer {
+	return &SOCKS5Dialer{
+		ProxyAddress: proxyAddress,
+		Username:     username,
+		Password:     password,
+		Forward:      &DirectDialer{},
+	}
+}
+
+// Dial implements the Dialer interface. The timeout covers both connecting to the proxy and the
+//   proxy connecting to the peer.
+func (d *SOCKS5Dialer) Dial(ctx context.Context, address string,
+	timeout time.Duration) (net.Conn, error) {
+	start := time.Now()
+	conn, err := d.Forward.Dial(ctx, d.ProxyAddress, timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to proxy")
+	}
+
+	if timeout > 0 {
+		conn.SetDeadline(start.Add(timeout))
+	}
+
+	if err := d.handshake(conn, address); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "socks5")
+	}
+
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+func (d *SOCKS5Dialer) handshake(conn net.Conn, address string) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "split address")
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil || port < 1 || port > 0xffff {
+		return fmt.Errorf("Invalid port : %s", portString)
+	}
+
+	// Negotiate authentication method
+	methods := []byte{socks5AuthNone}
+	if len(d.Username) > 0 {
+		methods = append(methods, socks5AuthPassword)
+	}
+	request := append([]byte{socks5Version, byte(len(methods))}, methods...)
+	if _, err := conn.Write(request); err != nil {
+		return errors.Wrap(err, "write methods")
+	}
+
+	response := make([]byte, 2)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return errors.Wrap(err, "read method")
+	}
+	if response[0] != socks5Version {
+		return fmt.Errorf("Unsupported proxy version : %d", response[0])
+	}
+
+	switch response[1] {
+	case socks5AuthNone:
+	case socks5AuthPassword:
+		if len(d.Username) == 0 {
+			return errors.New("Proxy requested password authentication")
+		}
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	case socks5AuthNoAcceptable:
+		return errors.New("No acceptable authentication methods")
+	default:
+		return fmt.Errorf("Unsupported authentication method : %d", response[1])
+	}
+
+	// Connect
+	request = []byte{socks5Version, socks5CmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			request = append(request, socks5AddrIPv4)
+			request = append(request, ip4...)
+		} else {
+			request = append(request, socks5AddrIPv6)
+			request = append(request, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return errors.New("Host name too long")
+		}
+		request = append(request, socks5AddrDomain, byte(len(host)))
+		request = append(request, host...)
+	}
+	portBytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(portBytes, uint16(port))
+	request = append(request, portBytes...)
+
+	if _, err := conn.Write(request); err != nil {
+		return errors.Wrap(err, "write connect")
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return errors.Wrap(err, "read connect reply")
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("Unsupported proxy version : %d", header[0])
+	}
+	if header[1] != 0x00 {
+		if reason, exists := socks5Replies[header[1]]; exists {
+			return fmt.Errorf("Connect failed : %s", reason)
+		}
+		return fmt.Errorf("Connect failed : code %d", header[1])
+	}
+
+	// Discard the bound address
+	var size int
+	switch header[3] {
+	case socks5AddrIPv4:
+		size = net.IPv4len
+	case socks5AddrIPv6:
+		size = net.IPv6len
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return errors.Wrap(err, "read bound address")
+		}
+		size = int(length[0])
+	default:
+		return fmt.Errorf("Unsupported bound address type : %d", header[3])
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, size+2)); err != nil {
+		return errors.Wrap(err, "read bound address")
+	}
+
+	return nil
+}
+
+func (d *SOCKS5Dialer) authenticate(conn net.Conn) error {
+	if len(d.Username) > 255 || len(d.Password) > 255 {
+		return errors.New("Proxy credentials too long")
+	}
+
+	request := []byte{socks5PasswordVersion, byte(len(d.Username))}
+	request = append(request, d.Username...)
+	request = append(request, byte(len(d.Password)))
+	request = append(request, d.Password...)
+	if _, err := conn.Write(request); err != nil {
+		return errors.Wrap(err, "write credentials")
+	}
+
+	response := make([]byte, 2)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return errors.Wrap(err, "read authentication")
+	}
+	if response[1] != 0x00 {
+		return errors.New("Proxy authentication failed")
+	}
+
+	return nil
+}
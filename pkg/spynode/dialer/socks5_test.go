@@ -0,0 +1,148 @@
+package dialer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSOCKS5(t *testing.T) {
+	ctx := context.Background()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen : %s", err)
+	}
+	defer listener.Close()
+
+	requests := make(chan string, 1)
+	go serveSOCKS5(listener, "user", "pass", requests)
+
+	d := NewSOCKS5Dialer(listener.Addr().String(), "user", "pass")
+	conn, err := d.Dial(ctx, "10.1.2.3:8333", time.Second)
+	if err != nil {
+		t.Fatalf("Failed to dial : %s", err)
+	}
+	defer conn.Close()
+
+	if requested := <-requests; requested != "10.1.2.3:8333" {
+		t.Fatalf("Wrong address requested : got %s, want 10.1.2.3:8333", requested)
+	}
+
+	// The proxy echoes data after connecting.
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("Failed to write : %s", err)
+	}
+	response := make([]byte, 4)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		t.Fatalf("Failed to read : %s", err)
+	}
+	if !bytes.Equal(response, []byte("ping")) {
+		t.Fatalf("Wrong response : %s", response)
+	}
+
+	// Wrong password
+	d = NewSOCKS5Dialer(listener.Addr().String(), "user", "wrong")
+	if _, err := d.Dial(ctx, "10.1.2.3:8333", time.Second); err == nil {
+		t.Fatalf("Dial with wrong password succeeded")
+	}
+}
+
+func TestSOCKS5Timeout(t *testing.T) {
+	ctx := context.Background()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen : %s", err)
+	}
+	defer listener.Close()
+
+	// Accept connections but never respond.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	d := NewSOCKS5Dialer(listener.Addr().String(), "", "")
+	start := time.Now()
+	if _, err := d.Dial(ctx, "10.1.2.3:8333", 100*time.Millisecond); err == nil {
+		t.Fatalf("Dial to unresponsive proxy succeeded")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("Dial didn't time out")
+	}
+}
+
+// serveSOCKS5 is a minimal SOCKS5 proxy that requires password authentication, reports the
+//   requested address, and then echoes data.
+func serveSOCKS5(listener net.Listener, username, password string, requests chan string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func(conn net.Conn) {
+			defer conn.Close()
+
+			header := make([]byte, 2)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			methods := make([]byte, header[1])
+			if _, err := io.ReadFull(conn, methods); err != nil {
+				return
+			}
+			if !bytes.Contains(methods, []byte{socks5AuthPassword}) {
+				conn.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+				return
+			}
+			conn.Write([]byte{socks5Version, socks5AuthPassword})
+
+			// Credentials
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			user := make([]byte, header[1])
+			io.ReadFull(conn, user)
+			size := make([]byte, 1)
+			io.ReadFull(conn, size)
+			pass := make([]byte, size[0])
+			io.ReadFull(conn, pass)
+			if string(user) != username || string(pass) != password {
+				conn.Write([]byte{socks5PasswordVersion, 0x01})
+				return
+			}
+			conn.Write([]byte{socks5PasswordVersion, 0x00})
+
+			// Connect request
+			request := make([]byte, 4)
+			if _, err := io.ReadFull(conn, request); err != nil {
+				return
+			}
+			if request[3] != socks5AddrIPv4 {
+				conn.Write([]byte{socks5Version, 0x08, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+				return
+			}
+			ip := make([]byte, 4)
+			io.ReadFull(conn, ip)
+			port := make([]byte, 2)
+			io.ReadFull(conn, port)
+			requests <- (&net.TCPAddr{IP: net.IP(ip),
+				Port: int(binary.BigEndian.Uint16(port))}).String()
+
+			conn.Write([]byte{socks5Version, 0x00, 0x00, socks5AddrIPv4, 127, 0, 0, 1, 0x20, 0x8d})
+
+			io.Copy(conn, conn)
+		}(conn)
+	}
+}
@@ -133,19 +133,24 @@ func (handler *BlockHandler) Handle(ctx context.Context, m wire.Message) ([]wire
 		for i, txHash := range hashes {
 			// Remove from unconfirmed. Only matching are in unconfirmed.
 			removed, unconfirmed = removeHash(txHash, unconfirmed)
-			handler.txChannel.Add(&TxData{Msg: block.Transactions[i], ConfirmedHeight: height, Relevant: removed})
 
-			if handler.state.IsReady() && !handler.memPool.RemoveTransaction(txHash) {
-				// Transaction wasn't in the mempool.
+			var conflicting []*bitcoin.Hash32
+			if handler.state.IsReady() {
 				// Check for transactions in the mempool with conflicting inputs (double spends).
-				if conflicting := handler.memPool.Conflicting(block.Transactions[i]); len(conflicting) > 0 {
-					for _, confHash := range conflicting {
-						if containsHash(confHash, unconfirmed) { // Only send for txs that previously matched filters.
-							for _, listener := range handler.listeners {
-								if err = listener.HandleTxState(ctx, ListenerMsgTxStateCancel, *confHash); err != nil {
-									continue
-								}
-							}
+				// The transaction can be in the mempool itself when a double spend was relayed
+				//   before being mined.
+				handler.memPool.RemoveTransaction(txHash)
+				conflicting = handler.memPool.Conflicting(block.Transactions[i])
+			}
+
+			handler.txChannel.Add(&TxData{Msg: block.Transactions[i], ConfirmedHeight: height,
+				Relevant: removed, Conflicts: conflicting})
+
+			for _, confHash := range conflicting {
+				if containsHash(confHash, unconfirmed) { // Only send for txs that previously matched filters.
+					for _, listener := range handler.listeners {
+						if err = listener.HandleTxState(ctx, ListenerMsgTxStateCancel, *confHash); err != nil {
+							continue
 						}
 					}
 				}
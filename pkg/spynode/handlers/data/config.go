@@ -1,35 +1,50 @@
 package data
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
+)
+
+const (
+	DefaultTrustedTimeout   = 30 * time.Second
+	DefaultUntrustedTimeout = 15 * time.Second
 )
 
 // Config holds all configuration for the running service.
 type Config struct {
-	Net            bitcoin.Network
-	NodeAddress    string         // IP address of trusted external full node
-	UserAgent      string         // User agent to send to external node
-	StartHash      bitcoin.Hash32 // Hash of first block to start processing on initial run
-	UntrustedCount int            // The number of untrusted nodes to run for double spend monitoring
-	SafeTxDelay    int            // Number of milliseconds without conflict before a tx is "safe"
-	ShotgunCount   int            // The number of nodes to attempt to send to when broadcasting
-	Lock           sync.Mutex     // Lock for config data
+	Net              bitcoin.Network
+	NodeAddress      string         // IP address of trusted external full node
+	UserAgent        string         // User agent to send to external node
+	StartHash        bitcoin.Hash32 // Hash of first block to start processing on initial run
+	UntrustedCount   int            // The number of untrusted nodes to run for double spend monitoring
+	SafeTxDelay      int            // Number of milliseconds without conflict before a tx is "safe"
+	ShotgunCount     int            // The number of nodes to attempt to send to when broadcasting
+	StaticPeers      []string       // Peer addresses always kept in the peer database
+	Dialer           dialer.Dialer  // Creates peer connections. Direct connections are used if nil.
+	TrustedTimeout   time.Duration  // Connection timeout for the trusted node
+	UntrustedTimeout time.Duration  // Connection timeout for each untrusted peer
+	Lock             sync.Mutex     // Lock for config data
 }
 
 // NewConfig returns a new Config populated from environment variables.
 func NewConfig(net bitcoin.Network, host, useragent, starthash string, untrustedNodes, safeDelay,
 	shotgunCount int) (Config, error) {
 	result := Config{
-		Net:            net,
-		NodeAddress:    host,
-		UserAgent:      useragent,
-		UntrustedCount: untrustedNodes,
-		SafeTxDelay:    safeDelay,
-		ShotgunCount:   shotgunCount,
+		Net:              net,
+		NodeAddress:      host,
+		UserAgent:        useragent,
+		UntrustedCount:   untrustedNodes,
+		SafeTxDelay:      safeDelay,
+		ShotgunCount:     shotgunCount,
+		TrustedTimeout:   DefaultTrustedTimeout,
+		UntrustedTimeout: DefaultUntrustedTimeout,
 	}
 
 	hash, err := bitcoin.NewHash32FromStr(starthash)
@@ -49,6 +64,7 @@ func (c Config) String() string {
 		"UserAgent":   c.UserAgent,
 		"StartHash":   c.StartHash.String(),
 		"SafeTxDelay": fmt.Sprintf("%d ms", c.SafeTxDelay),
+		"StaticPeers": strings.Join(c.StaticPeers, ","),
 	}
 
 	parts := []string{}
@@ -60,6 +76,22 @@ func (c Config) String() string {
 	return fmt.Sprintf("{%v}", strings.Join(parts, " "))
 }
 
+// Dial connects to a peer using the configured dialer. The trusted timeout is used for the trusted
+//   node and the untrusted timeout for all other peers.
+func (c *Config) Dial(ctx context.Context, address string) (net.Conn, error) {
+	d := c.Dialer
+	if d == nil {
+		d = &dialer.DirectDialer{}
+	}
+
+	timeout := c.UntrustedTimeout
+	if address == c.NodeAddress {
+		timeout = c.TrustedTimeout
+	}
+
+	return d.Dial(ctx, address, timeout)
+}
+
 func (c Config) Copy() Config {
 	c.Lock.Lock()
 	defer c.Lock.Unlock()
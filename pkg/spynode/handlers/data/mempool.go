@@ -1,28 +1,48 @@
 package data
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/storage"
 	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	memPoolPath    = "spynode/mempool"
+	memPoolVersion = uint8(0)
+
+	// Conflict sets older than this are dropped when the mempool is saved.
+	conflictRetention = 24 * time.Hour
 )
 
 // MemPool is used for managing announced transactions that haven't confirmed yet.
-// The mempool is non-persistent and is mainly used to prevent duplicate tx requests.
+// It is mainly used to prevent duplicate tx requests and to detect conflicting inputs. The tx index
+//   and conflict history are saved to storage so they survive a restart. Active requests are not.
 type MemPool struct {
-	txs      map[bitcoin.Hash32]*memPoolTx        // Lookup of block height by hash.
-	inputs   map[bitcoin.Hash32][]*bitcoin.Hash32 // Lookup by hash of outpoint. Used to find conflicting inputs.
-	requests map[bitcoin.Hash32]time.Time         // Transactions that have been requested
-	mutex    sync.Mutex
+	txs       map[bitcoin.Hash32]*memPoolTx        // Lookup of block height by hash.
+	inputs    map[bitcoin.Hash32][]*bitcoin.Hash32 // Lookup by hash of outpoint. Used to find conflicting inputs.
+	requests  map[bitcoin.Hash32]time.Time         // Transactions that have been requested
+	conflicts map[bitcoin.Hash32]*conflictSet      // Txs that have been seen conflicting with each tx
+	mutex     sync.Mutex
 }
 
 // NewMemPool returns a new MemPool.
 func NewMemPool() *MemPool {
 	result := MemPool{
-		txs:      make(map[bitcoin.Hash32]*memPoolTx),
-		inputs:   make(map[bitcoin.Hash32][]*bitcoin.Hash32),
-		requests: make(map[bitcoin.Hash32]time.Time),
+		txs:       make(map[bitcoin.Hash32]*memPoolTx),
+		inputs:    make(map[bitcoin.Hash32][]*bitcoin.Hash32),
+		requests:  make(map[bitcoin.Hash32]time.Time),
+		conflicts: make(map[bitcoin.Hash32]*conflictSet),
 	}
 	return &result
 }
@@ -91,8 +111,8 @@ func (memPool *MemPool) AddTransaction(tx *wire.MsgTx) ([]*bitcoin.Hash32, bool)
 			// Append conflicting
 			// It is possible tx conflict on more than one input and we don't want duplicates in
 			//   the result list.
-			appendIfNotContained(result, list)
-			list = append(list, hash)
+			result = appendIfNotContained(result, list)
+			memPool.inputs[*outpointHash] = append(list, hash)
 		} else {
 			// Create new list with only this tx hash
 			list := make([]*bitcoin.Hash32, 1)
@@ -101,11 +121,12 @@ func (memPool *MemPool) AddTransaction(tx *wire.MsgTx) ([]*bitcoin.Hash32, bool)
 		}
 	}
 
+	memPool.addConflicts(hash, result)
 	return result, true
 }
 
 // Appends the items in add to list if they are not already in list
-func appendIfNotContained(list []*bitcoin.Hash32, add []*bitcoin.Hash32) {
+func appendIfNotContained(list []*bitcoin.Hash32, add []*bitcoin.Hash32) []*bitcoin.Hash32 {
 	for _, addHash := range add {
 		found := false
 		for _, hash := range list {
@@ -119,6 +140,8 @@ func appendIfNotContained(list []*bitcoin.Hash32, add []*bitcoin.Hash32) {
 			list = append(list, addHash)
 		}
 	}
+
+	return list
 }
 
 // Removes a tx hash from the mempool
@@ -141,10 +164,11 @@ func (memPool *MemPool) removeTransaction(hash *bitcoin.Hash32) bool {
 			otherHashes, exists := memPool.inputs[*outpointHash]
 			if exists { // It should always exist
 				if len(otherHashes) > 1 {
-					// Remove this outpoint hash from the list
+					// Remove this tx hash from the list
 					for i, otherHash := range otherHashes {
-						if otherHash.Equal(outpointHash) {
-							otherHashes = append(otherHashes[:i], otherHashes[i+1:]...)
+						if otherHash.Equal(hash) {
+							memPool.inputs[*outpointHash] = append(otherHashes[:i],
+								otherHashes[i+1:]...)
 							break
 						}
 					}
@@ -184,15 +208,178 @@ func (memPool *MemPool) Conflicting(tx *wire.MsgTx) []*bitcoin.Hash32 {
 	// Check for conflicting inputs
 	for _, input := range tx.TxIn {
 		if list, exists := memPool.inputs[*input.PreviousOutPoint.OutpointHash()]; exists {
-			for _, hash := range list {
-				result = append(result, hash)
-				memPool.removeTransaction(hash)
-			}
+			result = appendIfNotContained(result, list)
 		}
 	}
+
+	for _, hash := range result {
+		memPool.removeTransaction(hash)
+	}
+
+	memPool.addConflicts(tx.TxHash(), result)
 	return result
 }
 
+// Conflicts returns the txids of all txs that have been seen conflicting with the specified tx.
+// Conflict history is retained after the txs leave the mempool.
+func (memPool *MemPool) Conflicts(txid *bitcoin.Hash32) []bitcoin.Hash32 {
+	memPool.mutex.Lock()
+	defer memPool.mutex.Unlock()
+
+	set, exists := memPool.conflicts[*txid]
+	if !exists {
+		return nil
+	}
+
+	result := make([]bitcoin.Hash32, len(set.txids))
+	copy(result, set.txids)
+	return result
+}
+
+// addConflicts records that the txs in list conflict with txid, in both directions.
+func (memPool *MemPool) addConflicts(txid *bitcoin.Hash32, list []*bitcoin.Hash32) {
+	if len(list) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, other := range list {
+		memPool.addConflict(now, txid, other)
+		memPool.addConflict(now, other, txid)
+	}
+}
+
+func (memPool *MemPool) addConflict(now time.Time, txid, other *bitcoin.Hash32) {
+	set, exists := memPool.conflicts[*txid]
+	if !exists {
+		set = &conflictSet{}
+		memPool.conflicts[*txid] = set
+	}
+
+	set.time = now
+	for _, hash := range set.txids {
+		if hash.Equal(other) {
+			return
+		}
+	}
+	set.txids = append(set.txids, *other)
+}
+
+// Save writes the mempool tx index and conflict history to storage.
+func (memPool *MemPool) Save(ctx context.Context, store storage.Storage) error {
+	memPool.mutex.Lock()
+	defer memPool.mutex.Unlock()
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, memPoolVersion); err != nil {
+		return err
+	}
+
+	// Only txs that have been received are saved. Outstanding requests are re-sent after restart.
+	count := uint32(0)
+	for _, tx := range memPool.txs {
+		if len(tx.outPoints) > 0 {
+			count++
+		}
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, count); err != nil {
+		return err
+	}
+	for hash, tx := range memPool.txs {
+		if len(tx.outPoints) == 0 {
+			continue
+		}
+		if err := tx.write(&buf, &hash); err != nil {
+			return errors.Wrap(err, "write mempool tx")
+		}
+	}
+
+	// Drop old conflict sets
+	cutoff := time.Now().Add(-conflictRetention)
+	for hash, set := range memPool.conflicts {
+		if set.time.Before(cutoff) {
+			delete(memPool.conflicts, hash)
+		}
+	}
+
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(memPool.conflicts))); err != nil {
+		return err
+	}
+	for hash, set := range memPool.conflicts {
+		if err := set.write(&buf, &hash); err != nil {
+			return errors.Wrap(err, "write conflict set")
+		}
+	}
+
+	logger.Verbose(ctx, "Saving mempool with %d txs and %d conflict sets", count,
+		len(memPool.conflicts))
+	return store.Write(ctx, memPoolPath, buf.Bytes(), nil)
+}
+
+// Load reads the mempool tx index and conflict history from storage.
+func (memPool *MemPool) Load(ctx context.Context, store storage.Storage) error {
+	memPool.mutex.Lock()
+	defer memPool.mutex.Unlock()
+
+	memPool.txs = make(map[bitcoin.Hash32]*memPoolTx)
+	memPool.inputs = make(map[bitcoin.Hash32][]*bitcoin.Hash32)
+	memPool.requests = make(map[bitcoin.Hash32]time.Time)
+	memPool.conflicts = make(map[bitcoin.Hash32]*conflictSet)
+
+	data, err := store.Read(ctx, memPoolPath)
+	if err == storage.ErrNotFound {
+		logger.Verbose(ctx, "No mempool to load")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewReader(data)
+
+	var version uint8
+	if err := binary.Read(buf, binary.LittleEndian, &version); err != nil {
+		return errors.Wrap(err, "read mempool version")
+	}
+	if version != memPoolVersion {
+		return fmt.Errorf("Unknown mempool version : %d", version)
+	}
+
+	var count uint32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return errors.Wrap(err, "read mempool tx count")
+	}
+	for i := uint32(0); i < count; i++ {
+		hash, tx, err := readMemPoolTx(buf)
+		if err != nil {
+			return errors.Wrap(err, "read mempool tx")
+		}
+		memPool.txs[hash] = tx
+
+		// Rebuild input index
+		txid := hash
+		for _, outpoint := range tx.outPoints {
+			outpointHash := outpoint.OutpointHash()
+			memPool.inputs[*outpointHash] = append(memPool.inputs[*outpointHash], &txid)
+		}
+	}
+
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return errors.Wrap(err, "read conflict set count")
+	}
+	for i := uint32(0); i < count; i++ {
+		hash, set, err := readConflictSet(buf)
+		if err != nil {
+			return errors.Wrap(err, "read conflict set")
+		}
+		memPool.conflicts[hash] = set
+	}
+
+	logger.Verbose(ctx, "Loaded mempool with %d txs and %d conflict sets", len(memPool.txs),
+		len(memPool.conflicts))
+	return nil
+}
+
 type memPoolTx struct {
 	time      time.Time
 	outPoints []wire.OutPoint
@@ -215,3 +402,110 @@ func (tx *memPoolTx) populateMemPoolTx(txMsg *wire.MsgTx) {
 		tx.outPoints = append(tx.outPoints, input.PreviousOutPoint)
 	}
 }
+
+func (tx *memPoolTx) write(w io.Writer, txid *bitcoin.Hash32) error {
+	if _, err := w.Write(txid[:]); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, tx.time.UnixNano()/1e6); err != nil { // Milliseconds
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(tx.outPoints))); err != nil {
+		return err
+	}
+	for _, outpoint := range tx.outPoints {
+		if _, err := w.Write(outpoint.Hash[:]); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, outpoint.Index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readMemPoolTx(r io.Reader) (bitcoin.Hash32, *memPoolTx, error) {
+	var txid bitcoin.Hash32
+	if _, err := io.ReadFull(r, txid[:]); err != nil {
+		return txid, nil, err
+	}
+
+	var milliseconds int64
+	if err := binary.Read(r, binary.LittleEndian, &milliseconds); err != nil {
+		return txid, nil, err
+	}
+	tx := newMemPoolTx(time.Unix(0, milliseconds*1e6))
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return txid, nil, err
+	}
+	tx.outPoints = make([]wire.OutPoint, count)
+	for i := range tx.outPoints {
+		if _, err := io.ReadFull(r, tx.outPoints[i].Hash[:]); err != nil {
+			return txid, nil, err
+		}
+		if err := binary.Read(r, binary.LittleEndian, &tx.outPoints[i].Index); err != nil {
+			return txid, nil, err
+		}
+	}
+
+	return txid, tx, nil
+}
+
+// conflictSet is the list of txs that were seen conflicting with a tx.
+type conflictSet struct {
+	time  time.Time // Last time a conflict was added
+	txids []bitcoin.Hash32
+}
+
+func (set *conflictSet) write(w io.Writer, txid *bitcoin.Hash32) error {
+	if _, err := w.Write(txid[:]); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, set.time.UnixNano()/1e6); err != nil { // Milliseconds
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(set.txids))); err != nil {
+		return err
+	}
+	for _, hash := range set.txids {
+		if _, err := w.Write(hash[:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readConflictSet(r io.Reader) (bitcoin.Hash32, *conflictSet, error) {
+	var txid bitcoin.Hash32
+	if _, err := io.ReadFull(r, txid[:]); err != nil {
+		return txid, nil, err
+	}
+
+	set := &conflictSet{}
+	var milliseconds int64
+	if err := binary.Read(r, binary.LittleEndian, &milliseconds); err != nil {
+		return txid, nil, err
+	}
+	set.time = time.Unix(0, milliseconds*1e6)
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return txid, nil, err
+	}
+	set.txids = make([]bitcoin.Hash32, count)
+	for i := range set.txids {
+		if _, err := io.ReadFull(r, set.txids[i][:]); err != nil {
+			return txid, nil, err
+		}
+	}
+
+	return txid, set, nil
+}
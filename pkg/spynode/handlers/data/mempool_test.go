@@ -0,0 +1,80 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+)
+
+func TestMemPoolPersistence(t *testing.T) {
+	ctx := context.Background()
+	storageConfig := storage.NewConfig("standalone", t.TempDir())
+	store := storage.NewFilesystemStorage(storageConfig)
+
+	parent := wire.NewMsgTx(2)
+	parent.TxOut = append(parent.TxOut, wire.NewTxOut(1000, []byte{0x51}))
+
+	first := wire.NewMsgTx(2)
+	first.TxIn = append(first.TxIn, wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	first.TxOut = append(first.TxOut, wire.NewTxOut(900, []byte{0x51}))
+
+	second := wire.NewMsgTx(2)
+	second.TxIn = append(second.TxIn, wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	second.TxOut = append(second.TxOut, wire.NewTxOut(800, []byte{0x52}))
+
+	memPool := NewMemPool()
+	if conflicts, added := memPool.AddTransaction(first); !added || len(conflicts) != 0 {
+		t.Fatalf("First tx should be added without conflicts : %t %d", added, len(conflicts))
+	}
+	conflicts, added := memPool.AddTransaction(second)
+	if !added {
+		t.Fatalf("Second tx not added")
+	}
+	if len(conflicts) != 1 || !conflicts[0].Equal(first.TxHash()) {
+		t.Fatalf("Second tx should conflict with first : %d", len(conflicts))
+	}
+
+	if err := memPool.Save(ctx, store); err != nil {
+		t.Fatalf("Failed to save mempool : %s", err)
+	}
+
+	loaded := NewMemPool()
+	if err := loaded.Load(ctx, store); err != nil {
+		t.Fatalf("Failed to load mempool : %s", err)
+	}
+
+	if !loaded.TransactionExists(first.TxHash()) || !loaded.TransactionExists(second.TxHash()) {
+		t.Fatalf("Loaded mempool missing txs")
+	}
+
+	list := loaded.Conflicts(first.TxHash())
+	if len(list) != 1 || !list[0].Equal(second.TxHash()) {
+		t.Fatalf("Conflict set not restored : %v", list)
+	}
+
+	// Input index must be rebuilt so a confirmed spend of the same outpoint is still detected.
+	spend := wire.NewMsgTx(2)
+	spend.TxIn = append(spend.TxIn, wire.NewTxIn(wire.NewOutPoint(parent.TxHash(), 0), nil))
+	spend.TxOut = append(spend.TxOut, wire.NewTxOut(700, []byte{0x53}))
+
+	removed := loaded.Conflicting(spend)
+	if len(removed) != 2 {
+		t.Fatalf("Conflicting should return both mempool txs : %d", len(removed))
+	}
+	if loaded.TransactionExists(first.TxHash()) || loaded.TransactionExists(second.TxHash()) {
+		t.Fatalf("Conflicting txs should be removed from mempool")
+	}
+
+	list = loaded.Conflicts(spend.TxHash())
+	if len(list) != 2 {
+		t.Fatalf("Conflict set for confirmed spend should contain both txs : %d", len(list))
+	}
+
+	var empty bitcoin.Hash32
+	if loaded.Conflicts(&empty) != nil {
+		t.Fatalf("Unknown tx should have no conflicts")
+	}
+}
@@ -84,7 +84,7 @@ func (state *State) AddNewBlock(hash *bitcoin.Hash32, block *wire.MsgBlock) bool
 	state.lock.Lock()
 	defer state.lock.Unlock()
 
-	if len(state.blocksRequested) > 0 || len(state.blocksRequested) > 0 {
+	if len(state.blocksRequested) > 0 || len(state.blocksToRequest) > 0 {
 		return false
 	}
 
@@ -63,6 +63,22 @@ type Listener interface {
 	HandleInSync(ctx context.Context) error
 }
 
+// DoubleSpendListener is an optional interface for listeners that want the evidence recorded when
+//   conflicting txs are seen. It is called when a double spend is recorded and again when one of
+//   its txs confirms.
+type DoubleSpendListener interface {
+	HandleDoubleSpend(ctx context.Context, ds *storage.DoubleSpend) error
+}
+
+// TxStore is an optional store for raw txs. Every tx seen is passed to AddSeen so it is available
+//   as a parent when a relevant tx spending it is passed to AddRelevant.
+type TxStore interface {
+	Load(ctx context.Context) error
+	Save(ctx context.Context) error
+	AddSeen(ctx context.Context, tx *wire.MsgTx)
+	AddRelevant(ctx context.Context, tx *wire.MsgTx) error
+}
+
 // CommandHandler defines an interface for handing commands/messages received from
 // peers over the Bitcoin P2P network.
 type CommandHandler interface {
@@ -91,7 +107,8 @@ func NewTrustedCommandHandlers(ctx context.Context, config data.Config, state *d
 		wire.CmdVersion: NewVersionHandler(state, config.NodeAddress),
 		wire.CmdAddr:    NewAddressHandler(peers),
 		wire.CmdInv:     NewInvHandler(state, txRepo, tracker, memPool),
-		wire.CmdTx:      NewTXHandler(state, unconfTxChannel, memPool, txRepo, listeners, txFilters),
+		wire.CmdTx: NewTXHandler(state, unconfTxChannel, memPool, txRepo, listeners, txFilters,
+			config.NodeAddress),
 		wire.CmdBlock: NewBlockHandler(state, confTxChannel, memPool, blockRepo, txRepo, listeners,
 			txFilters, blockProcessor),
 		wire.CmdHeaders: NewHeadersHandler(config, state, blockRepo, txRepo, reorgRepo, listeners),
@@ -109,7 +126,8 @@ func NewUntrustedCommandHandlers(ctx context.Context, state *data.UntrustedState
 		wire.CmdVersion: NewUntrustedVersionHandler(state, address),
 		wire.CmdAddr:    NewAddressHandler(peers),
 		wire.CmdInv:     NewUntrustedInvHandler(state, tracker, memPool),
-		wire.CmdTx:      NewUntrustedTXHandler(state, txChannel, memPool, txRepo, listeners, txFilters),
+		wire.CmdTx: NewUntrustedTXHandler(state, txChannel, memPool, txRepo, listeners, txFilters,
+			address),
 		wire.CmdHeaders: NewUntrustedHeadersHandler(state, peers, address, blockRepo),
 		wire.CmdReject:  NewRejectHandler(),
 	}
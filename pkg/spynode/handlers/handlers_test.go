@@ -3,10 +3,14 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"testing"
 	"time"
 
 	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
 	handlerStorage "github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
 	"github.com/tokenized/smart-contract/pkg/storage"
@@ -260,6 +264,92 @@ func TestHandlers(test *testing.T) {
 	verify(ctx, test, blocks, blockRepo, testBlockCount+1)
 }
 
+// TestMemoryConnection verifies the version handshake over a connection from the in-memory dialer.
+func TestMemoryConnection(test *testing.T) {
+	ctx := context.Background()
+
+	config, err := data.NewConfig(bitcoin.MainNet, "test", "Tokenized Test",
+		"0000000000000000000000000000000000000000000000000000000000000000", 8, 2000, 10)
+	if err != nil {
+		test.Fatalf("Failed to create config : %v", err)
+	}
+
+	memory := dialer.NewMemoryDialer()
+	config.Dialer = memory
+
+	if _, err := config.Dial(ctx, "test"); err == nil {
+		test.Fatalf("Dial to unregistered peer succeeded")
+	}
+
+	// The peer sends a version and waits for the acknowledge. The connection is closed by the test.
+	verAcked := make(chan error, 1)
+	memory.Register("test", func(conn net.Conn) {
+		local := wire.NewNetAddressIPPort(net.IPv4(127, 0, 0, 1), 8333, 0)
+		remote := wire.NewNetAddressIPPort(net.IPv4(127, 0, 0, 1), 9333, 0)
+		version := wire.NewMsgVersion(remote, local, 1, 100)
+		if err := wire.WriteMessage(conn, version, wire.ProtocolVersion,
+			wire.BitcoinNet(config.Net)); err != nil {
+			verAcked <- err
+			return
+		}
+
+		msg, _, err := wire.ReadMessage(conn, wire.ProtocolVersion, wire.BitcoinNet(config.Net))
+		if err != nil {
+			verAcked <- err
+			return
+		}
+		if _, ok := msg.(*wire.MsgVerAck); !ok {
+			verAcked <- fmt.Errorf("Received %s instead of verack", msg.Command())
+			return
+		}
+		verAcked <- nil
+
+		// Pipe writes block until read, so drain anything else until the connection closes.
+		io.Copy(ioutil.Discard, conn)
+	})
+
+	conn, err := config.Dial(ctx, "test")
+	if err != nil {
+		test.Fatalf("Failed to dial : %v", err)
+	}
+	defer conn.Close()
+
+	state := data.NewState()
+	handler := NewVersionHandler(state, "test")
+
+	msg, _, err := wire.ReadMessage(conn, wire.ProtocolVersion, wire.BitcoinNet(config.Net))
+	if err != nil {
+		test.Fatalf("Failed to read message : %v", err)
+	}
+
+	responses, err := handler.Handle(ctx, msg)
+	if err != nil {
+		test.Fatalf("Failed to handle version : %v", err)
+	}
+	for _, response := range responses {
+		if err := wire.WriteMessage(conn, response, wire.ProtocolVersion,
+			wire.BitcoinNet(config.Net)); err != nil {
+			test.Fatalf("Failed to write response : %v", err)
+		}
+	}
+
+	select {
+	case err := <-verAcked:
+		if err != nil {
+			test.Fatalf("Peer failed : %v", err)
+		}
+	case <-time.After(time.Second):
+		test.Fatalf("Peer didn't receive verack")
+	}
+
+	if !state.VersionReceived() {
+		test.Fatalf("Version not marked as received")
+	}
+	if memory.Attempts("test") != 2 {
+		test.Fatalf("Wrong attempt count : %d", memory.Attempts("test"))
+	}
+}
+
 func handleMessage(ctx context.Context, handlers map[string]CommandHandler, msg wire.Message) error {
 	h, ok := handlers[msg.Command()]
 	if !ok {
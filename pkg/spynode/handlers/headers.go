@@ -176,7 +176,7 @@ func (handler *HeadersHandler) Handle(ctx context.Context, m wire.Message) ([]wi
 			}
 
 			// Assert this header is now next
-			lastHash := handler.state.LastHash()
+			lastHash = handler.state.LastHash()
 			if lastHash == nil {
 				lastHash = handler.blocks.LastHash()
 			}
@@ -201,6 +201,9 @@ func (handler *HeadersHandler) Handle(ctx context.Context, m wire.Message) ([]wi
 					}
 				}
 			}
+
+			// Following headers in this message extend the new chain.
+			lastHash = hash
 			continue
 		}
 
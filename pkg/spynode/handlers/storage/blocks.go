@@ -111,56 +111,45 @@ func (repo *BlockRepository) Load(ctx context.Context) error {
 	if filesLoaded == 0 {
 		// Add genesis
 		logger.Verbose(ctx, "Adding %s genesis block", bitcoin.NetworkName(repo.config.Net))
-		if repo.config.Net == bitcoin.MainNet {
-			// Hash "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
-			prevhash, err := bitcoin.NewHash32FromStr("0000000000000000000000000000000000000000000000000000000000000000")
-			if err != nil {
-				return errors.Wrap(err, "Failed to create genesis prev hash")
-			}
-			merklehash, err := bitcoin.NewHash32FromStr("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
-			if err != nil {
-				return errors.Wrap(err, "Failed to create genesis merkle hash")
-			}
-			genesisHeader := wire.BlockHeader{
-				Version:    1,
-				PrevBlock:  *prevhash,
-				MerkleRoot: *merklehash,
-				Timestamp:  time.Unix(1231006505, 0),
-				Bits:       0x1d00ffff,
-				Nonce:      2083236893,
-			}
-			repo.lastHeaders = append(repo.lastHeaders, genesisHeader)
-			repo.height = 0
-			repo.heights[*genesisHeader.BlockHash()] = repo.height
-			logger.Verbose(ctx, "Added genesis block : %s", genesisHeader.BlockHash().String())
-		} else { // testnet
-			// Hash "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
-			prevhash, err := bitcoin.NewHash32FromStr("0000000000000000000000000000000000000000000000000000000000000000")
-			if err != nil {
-				return errors.Wrap(err, "Failed to create genesis prev hash")
-			}
-			merklehash, err := bitcoin.NewHash32FromStr("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b")
-			if err != nil {
-				return errors.Wrap(err, "Failed to create genesis merkle hash")
-			}
-			genesisHeader := wire.BlockHeader{
-				Version:    1,
-				PrevBlock:  *prevhash,
-				MerkleRoot: *merklehash,
-				Timestamp:  time.Unix(1296688602, 0),
-				Bits:       0x1d00ffff,
-				Nonce:      414098458,
-			}
-			repo.lastHeaders = append(repo.lastHeaders, genesisHeader)
-			repo.height = 0
-			repo.heights[*genesisHeader.BlockHash()] = repo.height
-			logger.Verbose(ctx, "Added testnet genesis block : %s", genesisHeader.BlockHash().String())
-		}
+		genesisHeader := GenesisHeader(repo.config.Net)
+		repo.lastHeaders = append(repo.lastHeaders, *genesisHeader)
+		repo.height = 0
+		repo.heights[*genesisHeader.BlockHash()] = repo.height
+		logger.Verbose(ctx, "Added genesis block : %s", genesisHeader.BlockHash().String())
 	}
 
 	return nil
 }
 
+// GenesisHeader returns the header of the first block of the network.
+func GenesisHeader(net bitcoin.Network) *wire.BlockHeader {
+	// Merkle root "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	merkleRoot := bitcoin.Hash32{0x3b, 0xa3, 0xed, 0xfd, 0x7a, 0x7b, 0x12, 0xb2, 0x7a, 0xc7, 0x2c,
+		0x3e, 0x67, 0x76, 0x8f, 0x61, 0x7f, 0xc8, 0x1b, 0xc3, 0x88, 0x8a, 0x51, 0x32, 0x3a, 0x9f,
+		0xb8, 0xaa, 0x4b, 0x1e, 0x5e, 0x4a}
+
+	if net == bitcoin.MainNet {
+		// Hash "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+		return &wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: merkleRoot,
+			Timestamp:  time.Unix(1231006505, 0),
+			Bits:       0x1d00ffff,
+			Nonce:      2083236893,
+		}
+	}
+
+	// Testnet
+	// Hash "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943"
+	return &wire.BlockHeader{
+		Version:    1,
+		MerkleRoot: merkleRoot,
+		Timestamp:  time.Unix(1296688602, 0),
+		Bits:       0x1d00ffff,
+		Nonce:      414098458,
+	}
+}
+
 // Adds a block header
 func (repo *BlockRepository) Add(ctx context.Context, header *wire.BlockHeader) error {
 	repo.mutex.Lock()
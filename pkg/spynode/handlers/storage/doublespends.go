@@ -0,0 +1,696 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	doubleSpendsPath    = "spynode/doublespends"
+	doubleSpendsVersion = uint8(0)
+
+	trackedPath    = "spynode/trackedtxs"
+	trackedVersion = uint8(0)
+
+	// trackedRetention is how long raw txs are kept for relevant txs that haven't confirmed.
+	trackedRetention = 72 * time.Hour
+)
+
+var (
+	DoubleSpendNotFound = errors.New("Double spend not found")
+)
+
+// Announcement records a peer sending us a tx.
+type Announcement struct {
+	Peer string
+	Time uint32
+}
+
+// DoubleSpendTx is one of the conflicting txs in a double spend.
+type DoubleSpendTx struct {
+	TxID          bitcoin.Hash32
+	Tx            *wire.MsgTx // Nil if the raw tx wasn't retained
+	Announcements []Announcement
+}
+
+// DoubleSpend is the evidence recorded when conflicting txs are seen.
+type DoubleSpend struct {
+	ID              bitcoin.Hash32 // Txid of the first tx in the double spend
+	Time            uint32         // Time the conflict was detected
+	Txs             []*DoubleSpendTx
+	Confirmed       *bitcoin.Hash32 // Txid of the tx that confirmed. Nil until one does.
+	ConfirmedHeight int
+}
+
+// DoubleSpendRepository records evidence of double spends.
+// Raw txs and announcements are tracked for relevant txs until they confirm so they are available
+//   if a conflicting tx is seen. Tracked txs are saved with SaveTracked so they survive a restart.
+type DoubleSpendRepository struct {
+	store   storage.Storage
+	tracked map[bitcoin.Hash32]*trackedTx
+	index   map[bitcoin.Hash32]bitcoin.Hash32 // Txid to double spend id
+	mutex   sync.Mutex
+}
+
+type trackedTx struct {
+	tx   DoubleSpendTx
+	time time.Time
+}
+
+// NewDoubleSpendRepository returns a new DoubleSpendRepository.
+func NewDoubleSpendRepository(store storage.Storage) *DoubleSpendRepository {
+	result := DoubleSpendRepository{
+		store:   store,
+		tracked: make(map[bitcoin.Hash32]*trackedTx),
+		index:   make(map[bitcoin.Hash32]bitcoin.Hash32),
+	}
+	return &result
+}
+
+// Load builds the index of recorded double spends.
+func (repo *DoubleSpendRepository) Load(ctx context.Context) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	list, err := repo.list(ctx)
+	if err != nil {
+		return err
+	}
+
+	repo.index = make(map[bitcoin.Hash32]bitcoin.Hash32)
+	for _, ds := range list {
+		for _, dsTx := range ds.Txs {
+			repo.index[dsTx.TxID] = ds.ID
+		}
+	}
+
+	if err := repo.loadTracked(ctx); err != nil {
+		return errors.Wrap(err, "load tracked txs")
+	}
+
+	logger.Verbose(ctx, "Loaded %d double spends and %d tracked txs", len(list),
+		len(repo.tracked))
+	return nil
+}
+
+// SaveTracked writes the tracked txs to storage.
+func (repo *DoubleSpendRepository) SaveTracked(ctx context.Context) error {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, trackedVersion); err != nil {
+		return err
+	}
+
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(repo.tracked))); err != nil {
+		return err
+	}
+	for _, tracked := range repo.tracked {
+		if err := binary.Write(&buf, binary.LittleEndian, tracked.time.UnixNano()); err != nil {
+			return err
+		}
+		if err := tracked.tx.Write(&buf); err != nil {
+			return errors.Wrap(err, "write tracked tx")
+		}
+	}
+
+	return repo.store.Write(ctx, trackedPath, buf.Bytes(), nil)
+}
+
+// loadTracked reads the tracked txs from storage, dropping those that are too old. The mutex must
+//   be held.
+func (repo *DoubleSpendRepository) loadTracked(ctx context.Context) error {
+	repo.tracked = make(map[bitcoin.Hash32]*trackedTx)
+
+	data, err := repo.store.Read(ctx, trackedPath)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewReader(data)
+
+	var version uint8
+	if err := binary.Read(buf, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version != trackedVersion {
+		return fmt.Errorf("Unknown version : %d", version)
+	}
+
+	var count uint32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-trackedRetention)
+	for i := uint32(0); i < count; i++ {
+		var nanoseconds int64
+		if err := binary.Read(buf, binary.LittleEndian, &nanoseconds); err != nil {
+			return err
+		}
+
+		tracked := &trackedTx{time: time.Unix(0, nanoseconds)}
+		if err := tracked.tx.Read(buf); err != nil {
+			return errors.Wrap(err, "read tracked tx")
+		}
+
+		if tracked.time.Before(cutoff) {
+			continue
+		}
+		repo.tracked[tracked.tx.TxID] = tracked
+	}
+
+	return nil
+}
+
+// Track retains the raw tx and the peer that sent it for a relevant tx.
+func (repo *DoubleSpendRepository) Track(ctx context.Context, tx *wire.MsgTx, peer string) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	txid := tx.TxHash()
+	if _, exists := repo.tracked[*txid]; exists {
+		return
+	}
+
+	now := time.Now()
+	tracked := &trackedTx{
+		tx:   DoubleSpendTx{TxID: *txid, Tx: tx},
+		time: now,
+	}
+	if len(peer) > 0 {
+		tracked.tx.Announcements = []Announcement{{Peer: peer, Time: uint32(now.Unix())}}
+	}
+	repo.tracked[*txid] = tracked
+}
+
+// IsTracked returns true if the tx is being tracked.
+func (repo *DoubleSpendRepository) IsTracked(txid *bitcoin.Hash32) bool {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	_, exists := repo.tracked[*txid]
+	return exists
+}
+
+// Announce records another peer sending a tx. It is only recorded if the tx is tracked or is part
+//   of a recorded double spend.
+func (repo *DoubleSpendRepository) Announce(ctx context.Context, txid *bitcoin.Hash32,
+	peer string) error {
+	if len(peer) == 0 {
+		return nil
+	}
+
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	announcement := Announcement{Peer: peer, Time: uint32(time.Now().Unix())}
+	if tracked, exists := repo.tracked[*txid]; exists {
+		tracked.tx.Announcements = appendAnnouncement(tracked.tx.Announcements, announcement)
+	}
+
+	id, exists := repo.index[*txid]
+	if !exists {
+		return nil
+	}
+
+	ds, err := repo.read(ctx, &id)
+	if err != nil {
+		return errors.Wrap(err, "read double spend")
+	}
+
+	for _, dsTx := range ds.Txs {
+		if dsTx.TxID.Equal(txid) {
+			dsTx.Announcements = appendAnnouncement(dsTx.Announcements, announcement)
+		}
+	}
+
+	return repo.write(ctx, ds)
+}
+
+// Record records evidence that tx conflicts with the specified txs. If any of the txs are already
+//   part of recorded double spends then those records are merged and extended.
+// peer is the peer that sent tx and is empty if tx was received in a block.
+func (repo *DoubleSpendRepository) Record(ctx context.Context, tx *wire.MsgTx, peer string,
+	conflicts []*bitcoin.Hash32) (*DoubleSpend, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	txid := tx.TxHash()
+	now := time.Now()
+
+	// Find existing records. A tx can conflict with txs from separate records, so they are merged
+	//   into the one detected first.
+	var ds *DoubleSpend
+	var merged []*DoubleSpend
+	found := make(map[bitcoin.Hash32]bool)
+	for _, hash := range append([]*bitcoin.Hash32{txid}, conflicts...) {
+		id, exists := repo.index[*hash]
+		if !exists || found[id] {
+			continue
+		}
+		found[id] = true
+
+		existing, err := repo.read(ctx, &id)
+		if err != nil {
+			return nil, errors.Wrap(err, "read double spend")
+		}
+
+		if ds == nil {
+			ds = existing
+		} else if existing.Time < ds.Time {
+			merged = append(merged, ds)
+			ds = existing
+		} else {
+			merged = append(merged, existing)
+		}
+	}
+
+	if ds == nil {
+		ds = &DoubleSpend{Time: uint32(now.Unix())}
+		if len(conflicts) > 0 {
+			ds.ID = *conflicts[0] // The conflicting tx was seen first
+		} else {
+			ds.ID = *txid
+		}
+	}
+
+	for _, other := range merged {
+		ds.merge(other)
+	}
+
+	for _, hash := range conflicts {
+		if existing := ds.find(hash); existing != nil {
+			if tracked, exists := repo.tracked[*hash]; exists && existing.Tx == nil {
+				existing.Tx = tracked.tx.Tx
+			}
+			continue
+		}
+
+		dsTx := &DoubleSpendTx{TxID: *hash}
+		if tracked, exists := repo.tracked[*hash]; exists {
+			dsTx.Tx = tracked.tx.Tx
+			dsTx.Announcements = tracked.tx.Announcements
+		}
+		ds.Txs = append(ds.Txs, dsTx)
+	}
+
+	if existing := ds.find(txid); existing != nil {
+		if existing.Tx == nil {
+			existing.Tx = tx
+		}
+	} else {
+		dsTx := &DoubleSpendTx{TxID: *txid, Tx: tx}
+		if tracked, exists := repo.tracked[*txid]; exists {
+			dsTx.Announcements = tracked.tx.Announcements
+		} else if len(peer) > 0 {
+			dsTx.Announcements = []Announcement{{Peer: peer, Time: uint32(now.Unix())}}
+		}
+		ds.Txs = append(ds.Txs, dsTx)
+	}
+
+	if err := repo.write(ctx, ds); err != nil {
+		return nil, err
+	}
+
+	for _, dsTx := range ds.Txs {
+		repo.index[dsTx.TxID] = ds.ID
+	}
+
+	for _, other := range merged {
+		if err := repo.store.Remove(ctx, buildDoubleSpendPath(&other.ID)); err != nil &&
+			err != storage.ErrNotFound {
+			return nil, errors.Wrap(err, "remove merged double spend")
+		}
+		logger.Verbose(ctx, "Merged double spend %s into %s", other.ID.String(), ds.ID.String())
+	}
+
+	logger.Warn(ctx, "Recorded double spend %s with %d txs", ds.ID.String(), len(ds.Txs))
+	return ds, nil
+}
+
+// Confirm stops tracking a tx and records it as confirmed if it is part of a double spend.
+// Returns the updated double spend, or nil if the tx isn't part of one.
+func (repo *DoubleSpendRepository) Confirm(ctx context.Context, txid *bitcoin.Hash32,
+	height int) (*DoubleSpend, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	delete(repo.tracked, *txid)
+
+	id, exists := repo.index[*txid]
+	if !exists {
+		return nil, nil
+	}
+
+	ds, err := repo.read(ctx, &id)
+	if err != nil {
+		return nil, errors.Wrap(err, "read double spend")
+	}
+
+	if ds.Confirmed != nil && ds.Confirmed.Equal(txid) && ds.ConfirmedHeight == height {
+		return ds, nil // Already recorded
+	}
+
+	confirmed := *txid
+	ds.Confirmed = &confirmed
+	ds.ConfirmedHeight = height
+
+	// The other txs can never confirm now.
+	for _, dsTx := range ds.Txs {
+		delete(repo.tracked, dsTx.TxID)
+	}
+
+	if err := repo.write(ctx, ds); err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// Untrack stops tracking a tx.
+func (repo *DoubleSpendRepository) Untrack(txid *bitcoin.Hash32) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	delete(repo.tracked, *txid)
+}
+
+// PruneTracked stops tracking txs that have been unconfirmed for too long.
+func (repo *DoubleSpendRepository) PruneTracked(ctx context.Context) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	cutoff := time.Now().Add(-trackedRetention)
+	for txid, tracked := range repo.tracked {
+		if tracked.time.Before(cutoff) {
+			delete(repo.tracked, txid)
+		}
+	}
+}
+
+// Get returns the double spend containing the specified tx.
+func (repo *DoubleSpendRepository) Get(ctx context.Context,
+	txid *bitcoin.Hash32) (*DoubleSpend, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	id, exists := repo.index[*txid]
+	if !exists {
+		return nil, DoubleSpendNotFound
+	}
+
+	return repo.read(ctx, &id)
+}
+
+// List returns all recorded double spends in the order they were detected.
+func (repo *DoubleSpendRepository) List(ctx context.Context) ([]*DoubleSpend, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	return repo.list(ctx)
+}
+
+func (repo *DoubleSpendRepository) list(ctx context.Context) ([]*DoubleSpend, error) {
+	data, err := repo.store.Search(ctx, map[string]string{"path": doubleSpendsPath})
+	if err != nil {
+		return nil, errors.Wrap(err, "search double spends")
+	}
+
+	result := make([]*DoubleSpend, 0, len(data))
+	for _, b := range data {
+		ds := &DoubleSpend{}
+		if err := ds.Read(bytes.NewReader(b)); err != nil {
+			return nil, errors.Wrap(err, "read double spend")
+		}
+		result = append(result, ds)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Time < result[j].Time })
+	return result, nil
+}
+
+func (repo *DoubleSpendRepository) read(ctx context.Context,
+	id *bitcoin.Hash32) (*DoubleSpend, error) {
+	data, err := repo.store.Read(ctx, buildDoubleSpendPath(id))
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, DoubleSpendNotFound
+		}
+		return nil, err
+	}
+
+	result := &DoubleSpend{}
+	if err := result.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (repo *DoubleSpendRepository) write(ctx context.Context, ds *DoubleSpend) error {
+	var buf bytes.Buffer
+	if err := ds.Write(&buf); err != nil {
+		return errors.Wrap(err, "serialize double spend")
+	}
+
+	if err := repo.store.Write(ctx, buildDoubleSpendPath(&ds.ID), buf.Bytes(), nil); err != nil {
+		return errors.Wrap(err, "write double spend")
+	}
+	return nil
+}
+
+func buildDoubleSpendPath(id *bitcoin.Hash32) string {
+	return fmt.Sprintf("%s/%s", doubleSpendsPath, id.String())
+}
+
+func (ds *DoubleSpend) find(txid *bitcoin.Hash32) *DoubleSpendTx {
+	for _, dsTx := range ds.Txs {
+		if dsTx.TxID.Equal(txid) {
+			return dsTx
+		}
+	}
+	return nil
+}
+
+// merge adds the txs and announcements of another double spend.
+func (ds *DoubleSpend) merge(other *DoubleSpend) {
+	for _, otherTx := range other.Txs {
+		existing := ds.find(&otherTx.TxID)
+		if existing == nil {
+			ds.Txs = append(ds.Txs, otherTx)
+			continue
+		}
+
+		if existing.Tx == nil {
+			existing.Tx = otherTx.Tx
+		}
+		for _, announcement := range otherTx.Announcements {
+			existing.Announcements = appendAnnouncement(existing.Announcements, announcement)
+		}
+	}
+
+	if ds.Confirmed == nil && other.Confirmed != nil {
+		ds.Confirmed = other.Confirmed
+		ds.ConfirmedHeight = other.ConfirmedHeight
+	}
+}
+
+// appendAnnouncement adds an announcement unless the peer already announced the tx.
+func appendAnnouncement(list []Announcement, announcement Announcement) []Announcement {
+	for _, existing := range list {
+		if existing.Peer == announcement.Peer {
+			return list
+		}
+	}
+	return append(list, announcement)
+}
+
+func (ds *DoubleSpend) Write(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, doubleSpendsVersion); err != nil {
+		return err
+	}
+
+	if err := ds.ID.Serialize(w); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, ds.Time); err != nil {
+		return err
+	}
+
+	if ds.Confirmed == nil {
+		if err := binary.Write(w, binary.LittleEndian, false); err != nil {
+			return err
+		}
+	} else {
+		if err := binary.Write(w, binary.LittleEndian, true); err != nil {
+			return err
+		}
+		if err := ds.Confirmed.Serialize(w); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, int32(ds.ConfirmedHeight)); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(ds.Txs))); err != nil {
+		return err
+	}
+	for _, dsTx := range ds.Txs {
+		if err := dsTx.Write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ds *DoubleSpend) Read(r io.Reader) error {
+	var version uint8
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version != doubleSpendsVersion {
+		return fmt.Errorf("Unknown version : %d", version)
+	}
+
+	id, err := bitcoin.DeserializeHash32(r)
+	if err != nil {
+		return err
+	}
+	ds.ID = *id
+
+	if err := binary.Read(r, binary.LittleEndian, &ds.Time); err != nil {
+		return err
+	}
+
+	var confirmed bool
+	if err := binary.Read(r, binary.LittleEndian, &confirmed); err != nil {
+		return err
+	}
+	if confirmed {
+		hash, err := bitcoin.DeserializeHash32(r)
+		if err != nil {
+			return err
+		}
+		ds.Confirmed = hash
+
+		var height int32
+		if err := binary.Read(r, binary.LittleEndian, &height); err != nil {
+			return err
+		}
+		ds.ConfirmedHeight = int(height)
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+	ds.Txs = make([]*DoubleSpendTx, 0, count)
+	for i := uint32(0); i < count; i++ {
+		dsTx := &DoubleSpendTx{}
+		if err := dsTx.Read(r); err != nil {
+			return err
+		}
+		ds.Txs = append(ds.Txs, dsTx)
+	}
+
+	return nil
+}
+
+func (dsTx *DoubleSpendTx) Write(w io.Writer) error {
+	if err := dsTx.TxID.Serialize(w); err != nil {
+		return err
+	}
+
+	if dsTx.Tx == nil {
+		if err := binary.Write(w, binary.LittleEndian, false); err != nil {
+			return err
+		}
+	} else {
+		if err := binary.Write(w, binary.LittleEndian, true); err != nil {
+			return err
+		}
+		if err := dsTx.Tx.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(dsTx.Announcements))); err != nil {
+		return err
+	}
+	for _, announcement := range dsTx.Announcements {
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(announcement.Peer))); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(announcement.Peer)); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, announcement.Time); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (dsTx *DoubleSpendTx) Read(r io.Reader) error {
+	txid, err := bitcoin.DeserializeHash32(r)
+	if err != nil {
+		return err
+	}
+	dsTx.TxID = *txid
+
+	var hasTx bool
+	if err := binary.Read(r, binary.LittleEndian, &hasTx); err != nil {
+		return err
+	}
+	if hasTx {
+		dsTx.Tx = &wire.MsgTx{}
+		if err := dsTx.Tx.Deserialize(r); err != nil {
+			return err
+		}
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+	dsTx.Announcements = make([]Announcement, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var size uint32
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			return err
+		}
+		peer := make([]byte, size)
+		if _, err := io.ReadFull(r, peer); err != nil {
+			return err
+		}
+
+		announcement := Announcement{Peer: string(peer)}
+		if err := binary.Read(r, binary.LittleEndian, &announcement.Time); err != nil {
+			return err
+		}
+		dsTx.Announcements = append(dsTx.Announcements, announcement)
+	}
+
+	return nil
+}
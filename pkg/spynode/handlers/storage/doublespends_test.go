@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+)
+
+func TestDoubleSpends(test *testing.T) {
+	ctx := context.Background()
+	storageConfig := storage.NewConfig("standalone", "./tmp/test")
+	store := storage.NewFilesystemStorage(storageConfig)
+	store.Clear(ctx, map[string]string{"path": doubleSpendsPath})
+
+	repo := NewDoubleSpendRepository(store)
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to load : %v", err)
+	}
+
+	var spent bitcoin.Hash32
+	spent[0] = 1
+
+	original := wire.NewMsgTx(1)
+	original.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 0), nil))
+	original.AddTxOut(wire.NewTxOut(1000, []byte{1}))
+
+	conflict := wire.NewMsgTx(1)
+	conflict.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 0), nil))
+	conflict.AddTxOut(wire.NewTxOut(1000, []byte{2}))
+
+	repo.Track(ctx, original, "peer 1")
+	if err := repo.Announce(ctx, original.TxHash(), "peer 2"); err != nil {
+		test.Fatalf("Failed to announce : %v", err)
+	}
+
+	ds, err := repo.Record(ctx, conflict, "peer 3", []*bitcoin.Hash32{original.TxHash()})
+	if err != nil {
+		test.Fatalf("Failed to record : %v", err)
+	}
+	if !ds.ID.Equal(original.TxHash()) {
+		test.Fatalf("Wrong id : %s", ds.ID.String())
+	}
+
+	// Later announcements are added to the evidence.
+	if err := repo.Announce(ctx, conflict.TxHash(), "peer 4"); err != nil {
+		test.Fatalf("Failed to announce : %v", err)
+	}
+
+	if _, err := repo.Confirm(ctx, conflict.TxHash(), 100); err != nil {
+		test.Fatalf("Failed to confirm : %v", err)
+	}
+
+	// Reload from storage
+	repo = NewDoubleSpendRepository(store)
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to reload : %v", err)
+	}
+
+	ds, err = repo.Get(ctx, original.TxHash())
+	if err != nil {
+		test.Fatalf("Failed to get : %v", err)
+	}
+
+	if len(ds.Txs) != 2 {
+		test.Fatalf("Wrong tx count : got %d, want 2", len(ds.Txs))
+	}
+	if ds.Confirmed == nil || !ds.Confirmed.Equal(conflict.TxHash()) || ds.ConfirmedHeight != 100 {
+		test.Fatalf("Wrong confirmed tx")
+	}
+
+	for _, dsTx := range ds.Txs {
+		if dsTx.Tx == nil || !dsTx.Tx.TxHash().Equal(&dsTx.TxID) {
+			test.Fatalf("Missing raw tx : %s", dsTx.TxID.String())
+		}
+		if len(dsTx.Announcements) != 2 {
+			test.Fatalf("Wrong announcement count for %s : got %d, want 2", dsTx.TxID.String(),
+				len(dsTx.Announcements))
+		}
+	}
+
+	list, err := repo.List(ctx)
+	if err != nil {
+		test.Fatalf("Failed to list : %v", err)
+	}
+	if len(list) != 1 {
+		test.Fatalf("Wrong double spend count : got %d, want 1", len(list))
+	}
+}
+
+func TestDoubleSpendsRestart(test *testing.T) {
+	ctx := context.Background()
+	storageConfig := storage.NewConfig("standalone", "./tmp/test")
+	store := storage.NewFilesystemStorage(storageConfig)
+	store.Clear(ctx, map[string]string{"path": doubleSpendsPath})
+	store.Remove(ctx, trackedPath)
+
+	repo := NewDoubleSpendRepository(store)
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to load : %v", err)
+	}
+
+	var spent bitcoin.Hash32
+	spent[0] = 2
+
+	original := wire.NewMsgTx(1)
+	original.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 0), nil))
+	original.AddTxOut(wire.NewTxOut(1000, []byte{1}))
+
+	conflict := wire.NewMsgTx(1)
+	conflict.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 0), nil))
+	conflict.AddTxOut(wire.NewTxOut(1000, []byte{2}))
+
+	repo.Track(ctx, original, "peer 1")
+	if err := repo.SaveTracked(ctx); err != nil {
+		test.Fatalf("Failed to save tracked : %v", err)
+	}
+
+	// The original was seen before a restart.
+	repo = NewDoubleSpendRepository(store)
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to reload : %v", err)
+	}
+	if !repo.IsTracked(original.TxHash()) {
+		test.Fatalf("Tracked tx not reloaded")
+	}
+
+	ds, err := repo.Record(ctx, conflict, "peer 2", []*bitcoin.Hash32{original.TxHash()})
+	if err != nil {
+		test.Fatalf("Failed to record : %v", err)
+	}
+
+	if len(ds.Txs) != 2 {
+		test.Fatalf("Wrong tx count : got %d, want 2", len(ds.Txs))
+	}
+	for _, dsTx := range ds.Txs {
+		if dsTx.Tx == nil || !dsTx.Tx.TxHash().Equal(&dsTx.TxID) {
+			test.Fatalf("Missing raw tx : %s", dsTx.TxID.String())
+		}
+		if len(dsTx.Announcements) != 1 {
+			test.Fatalf("Wrong announcement count for %s : got %d, want 1", dsTx.TxID.String(),
+				len(dsTx.Announcements))
+		}
+	}
+}
+
+func TestDoubleSpendsMerge(test *testing.T) {
+	ctx := context.Background()
+	storageConfig := storage.NewConfig("standalone", "./tmp/test")
+	store := storage.NewFilesystemStorage(storageConfig)
+	store.Clear(ctx, map[string]string{"path": doubleSpendsPath})
+	store.Remove(ctx, trackedPath)
+
+	repo := NewDoubleSpendRepository(store)
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to load : %v", err)
+	}
+
+	var spent bitcoin.Hash32
+	spent[0] = 3
+
+	// Two separate double spends of different outputs.
+	var txs []*wire.MsgTx
+	for i := 0; i < 4; i++ {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, uint32(i/2)), nil))
+		tx.AddTxOut(wire.NewTxOut(1000, []byte{byte(i)}))
+		txs = append(txs, tx)
+		repo.Track(ctx, tx, "peer 1")
+	}
+
+	if _, err := repo.Record(ctx, txs[1], "peer 1",
+		[]*bitcoin.Hash32{txs[0].TxHash()}); err != nil {
+		test.Fatalf("Failed to record : %v", err)
+	}
+	if _, err := repo.Record(ctx, txs[3], "peer 1",
+		[]*bitcoin.Hash32{txs[2].TxHash()}); err != nil {
+		test.Fatalf("Failed to record : %v", err)
+	}
+
+	// A tx spending both outputs conflicts with both records.
+	both := wire.NewMsgTx(1)
+	both.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 0), nil))
+	both.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&spent, 1), nil))
+	both.AddTxOut(wire.NewTxOut(1000, []byte{4}))
+
+	ds, err := repo.Record(ctx, both, "peer 2", []*bitcoin.Hash32{txs[0].TxHash(),
+		txs[1].TxHash(), txs[2].TxHash(), txs[3].TxHash()})
+	if err != nil {
+		test.Fatalf("Failed to record : %v", err)
+	}
+
+	if len(ds.Txs) != 5 {
+		test.Fatalf("Wrong tx count : got %d, want 5", len(ds.Txs))
+	}
+
+	for _, tx := range append(txs, both) {
+		found, err := repo.Get(ctx, tx.TxHash())
+		if err != nil {
+			test.Fatalf("Failed to get %s : %v", tx.TxHash().String(), err)
+		}
+		if !found.ID.Equal(&ds.ID) {
+			test.Fatalf("Wrong double spend for %s : %s", tx.TxHash().String(), found.ID.String())
+		}
+	}
+
+	list, err := repo.List(ctx)
+	if err != nil {
+		test.Fatalf("Failed to list : %v", err)
+	}
+	if len(list) != 1 {
+		test.Fatalf("Wrong double spend count : got %d, want 1", len(list))
+	}
+}
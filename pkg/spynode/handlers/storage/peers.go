@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,14 +19,38 @@ import (
 
 const (
 	peersPath    = "spynode/peers"
-	peersVersion = 2
+	peersVersion = 3
+
+	// MaxNewPeers is the maximum number of peers kept in the new bucket. The peers that were
+	//   seen least recently are evicted first.
+	MaxNewPeers = 5000
+
+	// EvictScore is the score at or below which a peer is removed from the database.
+	EvictScore = -10
+
+	// BanDuration is how long a peer is banned for misbehaving.
+	BanDuration = 24 * time.Hour
+
+	peerFlagTried  = 0x01
+	peerFlagStatic = 0x02
 )
 
 // Peer address database. Used to find Tx Peers.
+// Peers start in the "new" bucket and are moved to the "tried" bucket after a connection to them
+//   is successfully verified.
 type Peer struct {
-	Address  string
-	Score    int32
-	LastTime uint32
+	Address   string
+	Score     int32
+	LastTime  uint32
+	Tried     bool   // A connection to the peer was verified at least once
+	Static    bool   // Seeded from config and never evicted
+	BanUntil  uint32 // Unix time when the ban on the peer expires
+	BanReason string
+}
+
+// IsBanned returns true if the peer is banned at the specified time.
+func (peer *Peer) IsBanned(now time.Time) bool {
+	return peer.BanUntil > uint32(now.Unix())
 }
 
 // TxRepository is used for managing Block data
@@ -122,14 +149,82 @@ func (repo *PeerRepository) Add(ctx context.Context, address string) (bool, erro
 	return true, nil
 }
 
-// Get returns all peers at or above the specified score
+// AddStatic adds a peer from config. Static peers are never evicted.
+// Returns true if it was added
+func (repo *PeerRepository) AddStatic(ctx context.Context, address string) (bool, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	peer, exists := repo.lookup[address]
+	if exists {
+		peer.Static = true
+		return false, nil
+	}
+
+	// Add peer
+	peer = &Peer{Address: address, Score: 0, Static: true}
+	repo.list = append(repo.list, peer)
+	repo.lookup[peer.Address] = peer
+	return true, nil
+}
+
+// Remove removes a peer from the database.
+// Returns true if it was found and removed
+func (repo *PeerRepository) Remove(ctx context.Context, address string) bool {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	if _, exists := repo.lookup[address]; !exists {
+		return false
+	}
+
+	delete(repo.lookup, address)
+	for i, peer := range repo.list {
+		if peer.Address == address {
+			repo.list = append(repo.list[:i], repo.list[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// List returns a copy of all peers, including banned peers.
+func (repo *PeerRepository) List(ctx context.Context) []Peer {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	result := make([]Peer, 0, len(repo.list))
+	for _, peer := range repo.list {
+		result = append(result, *peer)
+	}
+	return result
+}
+
+// Get returns all peers at or above the specified score that are not banned
 func (repo *PeerRepository) Get(ctx context.Context, minScore int32) ([]*Peer, error) {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
 	result := make([]*Peer, 0, 500)
+	now := time.Now()
 	for _, peer := range repo.list {
-		if peer.Score >= minScore {
+		if peer.Score >= minScore && !peer.IsBanned(now) {
+			result = append(result, peer)
+		}
+	}
+
+	return result, nil
+}
+
+// GetTried returns peers in the tried bucket at or above the specified score that are not banned
+func (repo *PeerRepository) GetTried(ctx context.Context, minScore int32) ([]*Peer, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	result := make([]*Peer, 0, 500)
+	now := time.Now()
+	for _, peer := range repo.list {
+		if peer.Tried && peer.Score >= minScore && !peer.IsBanned(now) {
 			result = append(result, peer)
 		}
 	}
@@ -146,7 +241,7 @@ func (repo *PeerRepository) GetUnchecked(ctx context.Context) ([]*Peer, error) {
 	now := time.Now()
 	cutoff := uint32(now.Unix()) - 86400 // 24 hours
 	for _, peer := range repo.list {
-		if peer.Score == 0 && peer.LastTime < cutoff {
+		if peer.Score == 0 && peer.LastTime < cutoff && !peer.IsBanned(now) {
 			result = append(result, peer)
 		}
 	}
@@ -187,11 +282,133 @@ func (repo *PeerRepository) UpdateTime(ctx context.Context, address string) bool
 	return false
 }
 
+// MarkTried moves a peer to the tried bucket after a connection to it is verified.
+// Returns true if found and updated
+func (repo *PeerRepository) MarkTried(ctx context.Context, address string) bool {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	peer, exists := repo.lookup[address]
+	if exists {
+		peer.Tried = true
+		return true
+	}
+
+	return false
+}
+
+// Ban temporarily bans a peer. The peer is added if it isn't already in the database so that
+//   the ban is remembered.
+func (repo *PeerRepository) Ban(ctx context.Context, address string, duration time.Duration,
+	reason string) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	peer, exists := repo.lookup[address]
+	if !exists {
+		peer = &Peer{Address: address}
+		repo.list = append(repo.list, peer)
+		repo.lookup[peer.Address] = peer
+	}
+
+	peer.BanUntil = uint32(time.Now().Add(duration).Unix())
+	peer.BanReason = reason
+	logger.Info(ctx, "Banned peer %s until %s : %s", address,
+		time.Unix(int64(peer.BanUntil), 0).String(), reason)
+}
+
+// Unban removes the ban from a peer.
+// Returns true if the peer was banned
+func (repo *PeerRepository) Unban(ctx context.Context, address string) bool {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	peer, exists := repo.lookup[address]
+	if !exists || !peer.IsBanned(time.Now()) {
+		return false
+	}
+
+	peer.BanUntil = 0
+	peer.BanReason = ""
+	return true
+}
+
+// IsBanned returns true if the peer is currently banned.
+func (repo *PeerRepository) IsBanned(ctx context.Context, address string) bool {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	peer, exists := repo.lookup[address]
+	return exists && peer.IsBanned(time.Now())
+}
+
+// evict removes chronically bad peers and trims the new bucket to MaxNewPeers.
+// Static and banned peers are kept. Banned peers are kept so they aren't re-added from address
+//   messages before the ban expires.
+// Returns the number of peers removed.
+func (repo *PeerRepository) evict(ctx context.Context) int {
+	now := time.Now()
+	kept := make([]*Peer, 0, len(repo.list))
+	newPeers := make([]*Peer, 0, len(repo.list))
+	for _, peer := range repo.list {
+		if peer.Static || peer.IsBanned(now) {
+			kept = append(kept, peer)
+			continue
+		}
+
+		if peer.Score <= EvictScore {
+			continue
+		}
+
+		kept = append(kept, peer)
+		if !peer.Tried {
+			newPeers = append(newPeers, peer)
+		}
+	}
+
+	if len(newPeers) > MaxNewPeers {
+		// Remove the peers that were seen least recently.
+		sort.Slice(newPeers, func(i, j int) bool {
+			return newPeers[i].LastTime < newPeers[j].LastTime
+		})
+
+		remove := make(map[*Peer]bool)
+		for _, peer := range newPeers[:len(newPeers)-MaxNewPeers] {
+			remove[peer] = true
+		}
+
+		trimmed := make([]*Peer, 0, len(kept)-len(remove))
+		for _, peer := range kept {
+			if !remove[peer] {
+				trimmed = append(trimmed, peer)
+			}
+		}
+		kept = trimmed
+	}
+
+	removed := len(repo.list) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+
+	repo.list = kept
+	repo.lookup = make(map[string]*Peer)
+	for _, peer := range repo.list {
+		repo.lookup[peer.Address] = peer
+	}
+
+	logger.Verbose(ctx, "Evicted %d peers", removed)
+	return removed
+}
+
 // Saves the peers to storage
+// Chronically bad peers are evicted before saving.
 func (repo *PeerRepository) Save(ctx context.Context) error {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
+	repo.evict(ctx)
+
 	var buffer bytes.Buffer
 
 	// Write version
@@ -251,12 +468,39 @@ func readPeer(input io.Reader, version int32) (Peer, error) {
 	}
 
 	if version > 1 {
-		// Read score
+		// Read time
 		if err := binary.Read(input, binary.LittleEndian, &result.LastTime); err != nil {
 			return result, err
 		}
 	}
 
+	if version > 2 {
+		var flags uint8
+		if err := binary.Read(input, binary.LittleEndian, &flags); err != nil {
+			return result, err
+		}
+		result.Tried = flags&peerFlagTried != 0
+		result.Static = flags&peerFlagStatic != 0
+
+		if err := binary.Read(input, binary.LittleEndian, &result.BanUntil); err != nil {
+			return result, err
+		}
+
+		var reasonSize int32
+		if err := binary.Read(input, binary.LittleEndian, &reasonSize); err != nil {
+			return result, err
+		}
+
+		reasonData := make([]byte, reasonSize)
+		if _, err := io.ReadFull(input, reasonData); err != nil {
+			return result, err
+		}
+		result.BanReason = string(reasonData)
+	} else {
+		// Peers with a positive score were verified before buckets existed.
+		result.Tried = result.Score > 0
+	}
+
 	return result, nil
 }
 
@@ -283,5 +527,52 @@ func (peer *Peer) write(output io.Writer) error {
 		return err
 	}
 
+	// Write flags
+	flags := uint8(0)
+	if peer.Tried {
+		flags |= peerFlagTried
+	}
+	if peer.Static {
+		flags |= peerFlagStatic
+	}
+	err = binary.Write(output, binary.LittleEndian, flags)
+	if err != nil {
+		return err
+	}
+
+	// Write ban
+	err = binary.Write(output, binary.LittleEndian, peer.BanUntil)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(output, binary.LittleEndian, int32(len(peer.BanReason)))
+	if err != nil {
+		return err
+	}
+	_, err = output.Write([]byte(peer.BanReason))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// SubnetGroup returns the network group of a peer address, used to keep connections diverse.
+// IPv4 addresses are grouped by /16 and IPv6 addresses by /32.
+func SubnetGroup(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d", ip4[0], ip4[1])
+	}
+
+	return fmt.Sprintf("%x", []byte(ip.To16()[:4]))
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/tokenized/smart-contract/pkg/storage"
 )
@@ -123,3 +124,96 @@ func TestPeers(test *testing.T) {
 		test.Errorf("Pulled high score peers")
 	}
 }
+
+func TestPeerBans(test *testing.T) {
+	ctx := context.Background()
+	storageConfig := storage.NewConfig("standalone", "./tmp/test")
+	store := storage.NewFilesystemStorage(storageConfig)
+	repo := NewPeerRepository(store)
+
+	repo.Clear(ctx)
+
+	repo.Add(ctx, "10.0.0.1:8333")
+	repo.Add(ctx, "10.0.0.2:8333")
+	repo.AddStatic(ctx, "10.0.0.3:8333")
+
+	repo.UpdateScore(ctx, "10.0.0.1:8333", 5)
+	repo.MarkTried(ctx, "10.0.0.1:8333")
+
+	repo.Ban(ctx, "10.0.0.2:8333", time.Hour, "invalid headers")
+	if !repo.IsBanned(ctx, "10.0.0.2:8333") {
+		test.Fatalf("Peer not banned")
+	}
+
+	peers, err := repo.Get(ctx, 0)
+	if err != nil {
+		test.Fatalf("Failed to get peers : %v", err)
+	}
+	for _, peer := range peers {
+		if peer.Address == "10.0.0.2:8333" {
+			test.Fatalf("Get returned banned peer")
+		}
+	}
+
+	tried, err := repo.GetTried(ctx, 0)
+	if err != nil {
+		test.Fatalf("Failed to get tried peers : %v", err)
+	}
+	if len(tried) != 1 || tried[0].Address != "10.0.0.1:8333" {
+		test.Fatalf("Wrong tried peers : %d", len(tried))
+	}
+
+	// Chronically bad peers are evicted on save, static peers are kept.
+	repo.UpdateScore(ctx, "10.0.0.1:8333", EvictScore-5)
+	repo.UpdateScore(ctx, "10.0.0.3:8333", EvictScore)
+
+	if err := repo.Save(ctx); err != nil {
+		test.Fatalf("Failed to save peers : %v", err)
+	}
+	if err := repo.Load(ctx); err != nil {
+		test.Fatalf("Failed to load peers : %v", err)
+	}
+
+	list := repo.List(ctx)
+	if len(list) != 2 {
+		test.Fatalf("Wrong peer count after eviction : got %d, want 2", len(list))
+	}
+
+	if !repo.IsBanned(ctx, "10.0.0.2:8333") {
+		test.Fatalf("Ban not restored")
+	}
+	for _, peer := range list {
+		if peer.Address == "10.0.0.2:8333" && peer.BanReason != "invalid headers" {
+			test.Fatalf("Wrong ban reason : %s", peer.BanReason)
+		}
+		if peer.Address == "10.0.0.3:8333" && !peer.Static {
+			test.Fatalf("Static flag not restored")
+		}
+	}
+
+	if !repo.Unban(ctx, "10.0.0.2:8333") {
+		test.Fatalf("Failed to unban peer")
+	}
+	if repo.IsBanned(ctx, "10.0.0.2:8333") {
+		test.Fatalf("Peer still banned")
+	}
+}
+
+func TestSubnetGroup(test *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"10.1.2.3:8333", "10.1.200.4:8333", true},
+		{"10.1.2.3:8333", "10.2.2.3:8333", false},
+		{"[2001:db8:1::1]:8333", "[2001:db8:2::1]:8333", true},
+		{"[2001:db8:1::1]:8333", "[2001:db9:1::1]:8333", false},
+	}
+
+	for _, tt := range tests {
+		if same := SubnetGroup(tt.a) == SubnetGroup(tt.b); same != tt.same {
+			test.Errorf("SubnetGroup(%s) vs SubnetGroup(%s) : got same %t, want %t", tt.a, tt.b,
+				same, tt.same)
+		}
+	}
+}
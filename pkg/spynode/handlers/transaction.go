@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
 	"github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
 	"github.com/tokenized/smart-contract/pkg/wire"
@@ -19,6 +20,7 @@ type TXHandler struct {
 	txs       *storage.TxRepository
 	listeners []Listener
 	txFilters []TxFilter
+	address   string
 }
 
 type TxData struct {
@@ -27,10 +29,12 @@ type TxData struct {
 	Safe            bool
 	ConfirmedHeight int
 	Relevant        bool
+	Peer            string            // Address of the peer that sent the tx. Empty for blocks.
+	Conflicts       []*bitcoin.Hash32 // Mempool txs that conflict with a confirmed tx
 }
 
 // NewTXHandler returns a new TXHandler with the given Config.
-func NewTXHandler(ready StateReady, txChannel *TxChannel, memPool *data.MemPool, txs *storage.TxRepository, listeners []Listener, txFilters []TxFilter) *TXHandler {
+func NewTXHandler(ready StateReady, txChannel *TxChannel, memPool *data.MemPool, txs *storage.TxRepository, listeners []Listener, txFilters []TxFilter, address string) *TXHandler {
 	result := TXHandler{
 		ready:     ready,
 		txChannel: txChannel,
@@ -38,6 +42,7 @@ func NewTXHandler(ready StateReady, txChannel *TxChannel, memPool *data.MemPool,
 		txs:       txs,
 		listeners: listeners,
 		txFilters: txFilters,
+		address:   address,
 	}
 	return &result
 }
@@ -54,7 +59,8 @@ func (handler *TXHandler) Handle(ctx context.Context, m wire.Message) ([]wire.Me
 		return nil, nil
 	}
 
-	handler.txChannel.Add(&TxData{Msg: msg, Trusted: true, ConfirmedHeight: -1})
+	handler.txChannel.Add(&TxData{Msg: msg, Trusted: true, ConfirmedHeight: -1,
+		Peer: handler.address})
 	return nil, nil
 }
 
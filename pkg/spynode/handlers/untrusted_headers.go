@@ -65,15 +65,31 @@ func (handler *UntrustedHeadersHandler) Handle(ctx context.Context, m wire.Messa
 		return nil, errors.New(fmt.Sprintf("Returned header at low height : %d", height))
 	}
 
-	// Verify headers are linked
+	// Verify headers are linked and don't conflict with our chain
 	// Note: POW check might be nice here
 	previousHash := hash
-	for _, header := range message.Headers[1:] {
+	lastHeight := handler.blocks.LastHeight()
+	for i, header := range message.Headers[1:] {
 		if !header.PrevBlock.Equal(previousHash) {
+			handler.peers.Ban(ctx, handler.address, storage.BanDuration, "invalid headers")
 			return nil, errors.New("Returned unlinked headers")
 		}
 
 		previousHash = header.BlockHash()
+
+		headerHeight := height + i + 1
+		if headerHeight > lastHeight {
+			continue // Peer is ahead of us, but the rest must still be linked
+		}
+
+		ourHash, err := handler.blocks.Hash(ctx, headerHeight)
+		if err != nil {
+			return nil, errors.Wrap(err, "get block hash")
+		}
+		if !ourHash.Equal(previousHash) {
+			handler.peers.Ban(ctx, handler.address, storage.BanDuration, "conflicting chain tip")
+			return nil, errors.New(fmt.Sprintf("Returned conflicting header at height %d", headerHeight))
+		}
 	}
 
 	handler.state.ClearHeadersRequested()
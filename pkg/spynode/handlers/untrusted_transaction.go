@@ -18,10 +18,11 @@ type UntrustedTXHandler struct {
 	txs       *storage.TxRepository
 	listeners []Listener
 	txFilters []TxFilter
+	address   string
 }
 
 // NewTXHandler returns a new TXHandler with the given Config.
-func NewUntrustedTXHandler(ready StateReady, txChannel *TxChannel, memPool *data.MemPool, txs *storage.TxRepository, listeners []Listener, txFilters []TxFilter) *UntrustedTXHandler {
+func NewUntrustedTXHandler(ready StateReady, txChannel *TxChannel, memPool *data.MemPool, txs *storage.TxRepository, listeners []Listener, txFilters []TxFilter, address string) *UntrustedTXHandler {
 	result := UntrustedTXHandler{
 		ready:     ready,
 		txChannel: txChannel,
@@ -29,6 +30,7 @@ func NewUntrustedTXHandler(ready StateReady, txChannel *TxChannel, memPool *data
 		txs:       txs,
 		listeners: listeners,
 		txFilters: txFilters,
+		address:   address,
 	}
 	return &result
 }
@@ -45,6 +47,7 @@ func (handler *UntrustedTXHandler) Handle(ctx context.Context, m wire.Message) (
 		return nil, nil
 	}
 
-	handler.txChannel.Add(&TxData{Msg: msg, Trusted: false, ConfirmedHeight: -1})
+	handler.txChannel.Add(&TxData{Msg: msg, Trusted: false, ConfirmedHeight: -1,
+		Peer: handler.address})
 	return nil, nil
 }
@@ -29,24 +29,26 @@ type TxCount struct {
 
 // Node is the main object for spynode.
 type Node struct {
-	config          data.Config                        // Configuration
-	state           *data.State                        // Non-persistent data
-	store           storage.Storage                    // Persistent data
-	peers           *handlerstorage.PeerRepository     // Peer data
-	blocks          *handlerstorage.BlockRepository    // Block data
-	txs             *handlerstorage.TxRepository       // Tx data
-	reorgs          *handlerstorage.ReorgRepository    // Reorg data
-	txTracker       *data.TxTracker                    // Tracks tx requests to ensure all txs are received
-	memPool         *data.MemPool                      // Tracks which txs have been received and checked
-	handlers        map[string]handlers.CommandHandler // Handlers for messages from trusted node
-	connection      net.Conn                           // Connection to trusted node
-	outgoing        chan wire.Message                  // Channel for messages to send to trusted node
-	listeners       []handlers.Listener                // Receive data and notifications about transactions
-	txFilters       []handlers.TxFilter                // Determines if a tx should be seen by listeners
-	untrustedNodes  []*UntrustedNode                   // Randomized peer connections to monitor for double spends
-	addresses       map[string]time.Time               // Recently used peer addresses
-	confTxChannel   handlers.TxChannel                 // Channel for directly handled txs so they don't lock the calling thread
-	unconfTxChannel handlers.TxChannel                 // Channel for directly handled txs so they don't lock the calling thread
+	config          data.Config                           // Configuration
+	state           *data.State                           // Non-persistent data
+	store           storage.Storage                       // Persistent data
+	peers           *handlerstorage.PeerRepository        // Peer data
+	blocks          *handlerstorage.BlockRepository       // Block data
+	txs             *handlerstorage.TxRepository          // Tx data
+	reorgs          *handlerstorage.ReorgRepository       // Reorg data
+	doubleSpends    *handlerstorage.DoubleSpendRepository // Double spend evidence
+	txTracker       *data.TxTracker                       // Tracks tx requests to ensure all txs are received
+	memPool         *data.MemPool                         // Tracks which txs have been received and checked
+	handlers        map[string]handlers.CommandHandler    // Handlers for messages from trusted node
+	connection      net.Conn                              // Connection to trusted node
+	outgoing        chan wire.Message                     // Channel for messages to send to trusted node
+	listeners       []handlers.Listener                   // Receive data and notifications about transactions
+	txFilters       []handlers.TxFilter                   // Determines if a tx should be seen by listeners
+	txStore         handlers.TxStore                      // Stores relevant txs and their parents
+	untrustedNodes  []*UntrustedNode                      // Randomized peer connections to monitor for double spends
+	addresses       map[string]time.Time                  // Recently used peer addresses
+	confTxChannel   handlers.TxChannel                    // Channel for directly handled txs so they don't lock the calling thread
+	unconfTxChannel handlers.TxChannel                    // Channel for directly handled txs so they don't lock the calling thread
 	broadcastLock   sync.Mutex
 	broadcastTxs    []TxCount // Txs to transmit to nodes upon connection
 	needsRestart    bool
@@ -69,6 +71,7 @@ func NewNode(config data.Config, store storage.Storage) *Node {
 		blocks:          handlerstorage.NewBlockRepository(&config, store),
 		txs:             handlerstorage.NewTxRepository(store),
 		reorgs:          handlerstorage.NewReorgRepository(store),
+		doubleSpends:    handlerstorage.NewDoubleSpendRepository(store),
 		txTracker:       data.NewTxTracker(),
 		memPool:         data.NewMemPool(),
 		outgoing:        nil,
@@ -98,6 +101,11 @@ func (node *Node) AddTxFilter(filter handlers.TxFilter) {
 	node.txFilters = append(node.txFilters, filter)
 }
 
+// SetTxStore sets a store that is populated with relevant txs and their parents.
+func (node *Node) SetTxStore(store handlers.TxStore) {
+	node.txStore = store
+}
+
 // load loads the data for the node.
 // Must be called after adding filter(s), but before Run()
 func (node *Node) load(ctx context.Context) error {
@@ -106,6 +114,12 @@ func (node *Node) load(ctx context.Context) error {
 		return err
 	}
 
+	for _, address := range node.config.StaticPeers {
+		if _, err := node.peers.AddStatic(ctx, address); err != nil {
+			return err
+		}
+	}
+
 	if err := node.blocks.Load(ctx); err != nil {
 		return err
 	}
@@ -122,6 +136,20 @@ func (node *Node) load(ctx context.Context) error {
 		return err
 	}
 
+	if err := node.memPool.Load(ctx, node.store); err != nil {
+		return err
+	}
+
+	if err := node.doubleSpends.Load(ctx); err != nil {
+		return err
+	}
+
+	if node.txStore != nil {
+		if err := node.txStore.Load(ctx); err != nil {
+			return err
+		}
+	}
+
 	node.handlers = handlers.NewTrustedCommandHandlers(ctx, node.config, node.state, node.peers,
 		node.blocks, node.txs, node.reorgs, node.txTracker, node.memPool, &node.confTxChannel,
 		&node.unconfTxChannel, node.listeners, node.txFilters, node)
@@ -225,7 +253,12 @@ func (node *Node) Run(ctx context.Context) error {
 		logger.Verbose(ctx, "Saving")
 		node.blocks.Save(ctx)
 		node.txs.Save(ctx)
+		node.memPool.Save(ctx, node.store)
+		node.doubleSpends.SaveTracked(ctx)
 		node.peers.Save(ctx)
+		if node.txStore != nil {
+			node.txStore.Save(ctx)
+		}
 
 		if !node.needsRestart || node.hardStop {
 			break
@@ -413,7 +446,7 @@ func (node *Node) ShotgunTransmitTx(ctx context.Context, tx *wire.MsgTx, sendCou
 
 	// Try for peers with a good score
 	for !node.isStopping() && count < sendCount {
-		if node.addUntrustedNode(ctx, &wg, 5, []*wire.MsgTx{tx}) {
+		if node.addUntrustedNode(ctx, &wg, true, 5, []*wire.MsgTx{tx}) {
 			count++
 		} else {
 			break
@@ -422,7 +455,7 @@ func (node *Node) ShotgunTransmitTx(ctx context.Context, tx *wire.MsgTx, sendCou
 
 	// Try for peers with a non negative score
 	for !node.isStopping() && count < sendCount {
-		if node.addUntrustedNode(ctx, &wg, 0, []*wire.MsgTx{tx}) {
+		if node.addUntrustedNode(ctx, &wg, false, 0, []*wire.MsgTx{tx}) {
 			count++
 		} else {
 			break
@@ -461,6 +494,10 @@ func (node *Node) processConfirmedTx(ctx context.Context, tx *handlers.TxData) e
 
 	// Send full tx to listener if we aren't in sync yet and don't have a populated mempool.
 	// Or if it isn't in the mempool (not sent to listener yet).
+	if node.txStore != nil {
+		node.txStore.AddSeen(ctx, tx.Msg)
+	}
+
 	var err error
 	marked := false
 	if !tx.Relevant { // Full tx hasn't been sent to listener yet
@@ -475,7 +512,29 @@ func (node *Node) processConfirmedTx(ctx context.Context, tx *handlers.TxData) e
 		}
 	}
 
+	if len(tx.Conflicts) > 0 && (marked || tx.Relevant || node.anyTracked(tx.Conflicts)) {
+		// A relevant tx was double spent by a tx that skipped the mempool.
+		if err := node.recordDoubleSpend(ctx, tx.Msg, "", tx.Conflicts); err != nil {
+			return err
+		}
+	}
+
+	ds, err := node.doubleSpends.Confirm(ctx, hash, tx.ConfirmedHeight)
+	if err != nil {
+		return errors.Wrap(err, "confirm double spend")
+	}
+	if ds != nil {
+		logger.Warn(ctx, "Double spend %s confirmed : %s", ds.ID.String(), hash.String())
+		node.notifyDoubleSpend(ctx, ds)
+	}
+
 	if marked || tx.Relevant {
+		if node.txStore != nil {
+			if err := node.txStore.AddRelevant(ctx, tx.Msg); err != nil {
+				return errors.Wrap(err, "store tx")
+			}
+		}
+
 		// Notify of confirm
 		for _, listener := range node.listeners {
 			listener.HandleTxState(ctx, handlers.ListenerMsgTxStateConfirm, *hash)
@@ -501,23 +560,41 @@ func (node *Node) processUnconfirmedTx(ctx context.Context, tx *handlers.TxData)
 	//   for attempted double spends.
 	conflicts, added := node.memPool.AddTransaction(tx.Msg)
 	if !added {
-		return nil // Already saw this tx
+		// Already saw this tx. Remember which peers sent it in case it is double spent.
+		if err := node.doubleSpends.Announce(ctx, hash, tx.Peer); err != nil {
+			return errors.Wrap(err, "announce double spend tx")
+		}
+		return nil
 	}
 
+	if node.txStore != nil {
+		node.txStore.AddSeen(ctx, tx.Msg)
+	}
+
+	recorded := false
 	if len(conflicts) > 0 {
 		logger.Warn(ctx, "Found %d conflicts with %s", len(conflicts), hash)
 		// Notify of attempted double spend
+		relevant := false
 		for _, conflict := range conflicts {
 			marked, err := node.txs.MarkUnsafe(ctx, *conflict)
 			if err != nil {
 				return errors.Wrap(err, "Failed to check tx repo")
 			}
 			if marked { // Only send for txs that previously matched filters.
+				relevant = true
 				for _, listener := range node.listeners {
 					listener.HandleTxState(ctx, handlers.ListenerMsgTxStateUnsafe, *conflict)
 				}
 			}
 		}
+
+		if relevant || node.anyTracked(conflicts) {
+			if err := node.recordDoubleSpend(ctx, tx.Msg, tx.Peer, conflicts); err != nil {
+				return err
+			}
+			recorded = true
+		}
 	}
 
 	// We have to succesfully add to tx repo because it is protected by a lock and will prevent
@@ -551,8 +628,22 @@ func (node *Node) processUnconfirmedTx(ctx context.Context, tx *handlers.TxData)
 	}
 
 	if marked {
+		node.doubleSpends.Track(ctx, tx.Msg, tx.Peer)
+
+		if node.txStore != nil {
+			if err := node.txStore.AddRelevant(ctx, tx.Msg); err != nil {
+				return errors.Wrap(err, "store tx")
+			}
+		}
+
 		// Notify of conflicting txs
 		if len(conflicts) > 0 {
+			if !recorded {
+				if err := node.recordDoubleSpend(ctx, tx.Msg, tx.Peer, conflicts); err != nil {
+					return err
+				}
+			}
+
 			node.txs.MarkUnsafe(ctx, *hash)
 			for _, listener := range node.listeners {
 				listener.HandleTxState(ctx, handlers.ListenerMsgTxStateUnsafe, *hash)
@@ -572,6 +663,50 @@ func (node *Node) processUnconfirmedTx(ctx context.Context, tx *handlers.TxData)
 	return nil
 }
 
+// anyTracked returns true if any of the txs are relevant txs being tracked for double spends.
+func (node *Node) anyTracked(txids []*bitcoin.Hash32) bool {
+	for _, txid := range txids {
+		if node.doubleSpends.IsTracked(txid) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordDoubleSpend saves evidence of a double spend and notifies listeners.
+func (node *Node) recordDoubleSpend(ctx context.Context, tx *wire.MsgTx, peer string,
+	conflicts []*bitcoin.Hash32) error {
+	ds, err := node.doubleSpends.Record(ctx, tx, peer, conflicts)
+	if err != nil {
+		return errors.Wrap(err, "record double spend")
+	}
+
+	node.notifyDoubleSpend(ctx, ds)
+	return nil
+}
+
+// notifyDoubleSpend sends double spend evidence to listeners that want it.
+func (node *Node) notifyDoubleSpend(ctx context.Context, ds *handlerstorage.DoubleSpend) {
+	for _, listener := range node.listeners {
+		if dsListener, ok := listener.(handlers.DoubleSpendListener); ok {
+			if err := dsListener.HandleDoubleSpend(ctx, ds); err != nil {
+				logger.Warn(ctx, "Failed to handle double spend : %s", err)
+			}
+		}
+	}
+}
+
+// DoubleSpend returns the double spend evidence containing the specified tx.
+func (node *Node) DoubleSpend(ctx context.Context,
+	txid *bitcoin.Hash32) (*handlerstorage.DoubleSpend, error) {
+	return node.doubleSpends.Get(ctx, txid)
+}
+
+// DoubleSpends returns all recorded double spend evidence.
+func (node *Node) DoubleSpends(ctx context.Context) ([]*handlerstorage.DoubleSpend, error) {
+	return node.doubleSpends.List(ctx)
+}
+
 // processUnconfirmedTxs pulls txs from the unconfirmed tx channel and processes them.
 func (node *Node) processUnconfirmedTxs(ctx context.Context) {
 	for tx := range node.unconfTxChannel.Channel {
@@ -671,7 +806,7 @@ func (node *Node) ProcessBlock(ctx context.Context, block *wire.MsgBlock) error
 }
 
 func (node *Node) connect(ctx context.Context) error {
-	conn, err := net.Dial("tcp", node.config.NodeAddress)
+	conn, err := node.config.Dial(ctx, node.config.NodeAddress)
 	if err != nil {
 		return err
 	}
@@ -838,10 +973,12 @@ func (node *Node) check(ctx context.Context) error {
 }
 
 // monitorRequestTimeouts monitors for request timeouts.
+// It also periodically saves unconfirmed tx state so it isn't lost if the process is killed.
 //
 // This is a blocking function that will run forever, so it should be run
 // in a goroutine.
 func (node *Node) monitorRequestTimeouts(ctx context.Context) {
+	count := 0
 	for !node.isStopping() {
 		node.sleepUntilStop(10) // Only check every 10 seconds
 
@@ -850,6 +987,26 @@ func (node *Node) monitorRequestTimeouts(ctx context.Context) {
 			node.restart(ctx)
 			break
 		}
+
+		count++
+		if count >= 6 { // Every minute
+			count = 0
+			if err := node.txs.Save(ctx); err != nil {
+				logger.Warn(ctx, "Failed to save unconfirmed txs : %s", err)
+			}
+			if err := node.memPool.Save(ctx, node.store); err != nil {
+				logger.Warn(ctx, "Failed to save mempool : %s", err)
+			}
+			if node.txStore != nil {
+				if err := node.txStore.Save(ctx); err != nil {
+					logger.Warn(ctx, "Failed to save tx store : %s", err)
+				}
+			}
+			node.doubleSpends.PruneTracked(ctx)
+			if err := node.doubleSpends.SaveTracked(ctx); err != nil {
+				logger.Warn(ctx, "Failed to save tracked txs : %s", err)
+			}
+		}
 	}
 }
 
@@ -1016,9 +1173,9 @@ func (node *Node) monitorUntrustedNodes(ctx context.Context) {
 		}
 
 		if count < desiredCount/2 {
-			// Try for peers with a good score
+			// Try for peers in the tried bucket with a good score
 			for !node.isStopping() && count < desiredCount/2 {
-				if node.addUntrustedNode(ctx, &wg, 5, txs) {
+				if node.addUntrustedNode(ctx, &wg, true, 5, txs) {
 					count++
 					sentCount++
 				} else {
@@ -1029,7 +1186,7 @@ func (node *Node) monitorUntrustedNodes(ctx context.Context) {
 
 		// Try for peers with a score above zero
 		for !node.isStopping() && count < desiredCount {
-			if node.addUntrustedNode(ctx, &wg, 1, txs) {
+			if node.addUntrustedNode(ctx, &wg, false, 1, txs) {
 				count++
 				sentCount++
 			} else {
@@ -1074,17 +1231,42 @@ func (node *Node) monitorUntrustedNodes(ctx context.Context) {
 }
 
 // addUntrustedNode adds a new untrusted node.
+// When tried is true only peers from the tried bucket are used.
+// Peers in the same subnet as an existing untrusted connection are skipped so one network can't
+//   provide all of our untrusted connections.
 // Returns true if a new node connection was attempted
-func (node *Node) addUntrustedNode(ctx context.Context, wg *sync.WaitGroup, minScore int32,
-	txs []*wire.MsgTx) bool {
+func (node *Node) addUntrustedNode(ctx context.Context, wg *sync.WaitGroup, tried bool,
+	minScore int32, txs []*wire.MsgTx) bool {
 
 	// Get new address
 	// Check we aren't already connected and haven't used it recently
-	peers, err := node.peers.Get(ctx, minScore)
+	var peers []*handlerstorage.Peer
+	var err error
+	if tried {
+		peers, err = node.peers.GetTried(ctx, minScore)
+	} else {
+		peers, err = node.peers.Get(ctx, minScore)
+	}
 	if err != nil {
 		return false
 	}
-	logger.Debug(ctx, "Found %d peers with score %d", len(peers), minScore)
+
+	groups := make(map[string]bool)
+	node.untrustedLock.Lock()
+	for _, untrusted := range node.untrustedNodes {
+		groups[handlerstorage.SubnetGroup(untrusted.address)] = true
+	}
+	node.untrustedLock.Unlock()
+
+	diverse := make([]*handlerstorage.Peer, 0, len(peers))
+	for _, peer := range peers {
+		if !groups[handlerstorage.SubnetGroup(peer.Address)] {
+			diverse = append(diverse, peer)
+		}
+	}
+	logger.Debug(ctx, "Found %d peers with score %d (%d in unused subnets)", len(peers), minScore,
+		len(diverse))
+	peers = diverse
 
 	seed := rand.New(rand.NewSource(time.Now().UnixNano()))
 	var address string
@@ -0,0 +1,237 @@
+package spynode
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/spynode/dialer"
+	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
+	"github.com/tokenized/smart-contract/pkg/spynode/handlers/data"
+	"github.com/tokenized/smart-contract/pkg/spynode/simnet"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/txstore"
+	"github.com/tokenized/smart-contract/pkg/wire"
+)
+
+// TestSimulatedNetwork runs spynode against a simulated trusted node through sync, confirm,
+//   reorg and double spend sequences.
+func TestSimulatedNetwork(test *testing.T) {
+	ctx := context.Background()
+
+	store := storage.NewFilesystemStorage(storage.NewConfig("standalone", "./tmp/test"))
+	for _, path := range []string{"spynode", "txstore"} {
+		if err := store.Clear(ctx, map[string]string{"path": path}); err != nil {
+			test.Fatalf("Failed to clear storage : %v", err)
+		}
+	}
+
+	network := simnet.NewNetwork(bitcoin.MainNet)
+	network.MineBlocks(3)
+	startBlock, err := network.Block(2)
+	if err != nil {
+		test.Fatalf("Failed to get start block : %v", err)
+	}
+
+	memory := dialer.NewMemoryDialer()
+	memory.Register("simnet", network.Connect)
+
+	config, err := data.NewConfig(bitcoin.MainNet, "simnet", "/Tokenized:Test/",
+		startBlock.BlockHash().String(), 0, 100, 0)
+	if err != nil {
+		test.Fatalf("Failed to create config : %v", err)
+	}
+	config.Dialer = memory
+
+	node := NewNode(config.Copy(), store)
+	txStore := txstore.NewStore(store, txstore.DefaultMaxSize, 100)
+	node.SetTxStore(txStore)
+	listener := &simListener{states: make(map[bitcoin.Hash32][]int)}
+	node.RegisterListener(listener)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := node.Run(ctx); err != nil {
+			test.Errorf("Node failed : %v", err)
+		}
+	}()
+	defer func() {
+		node.Stop(ctx)
+		wg.Wait()
+	}()
+
+	waitFor(test, "in sync", func() bool { return listener.isInSync() })
+	if node.blocks.LastHeight() != network.Height() {
+		test.Fatalf("Wrong height : got %d, want %d", node.blocks.LastHeight(), network.Height())
+	}
+	test.Logf("In sync at height %d", network.Height())
+
+	// Unconfirmed tx becomes safe, then confirms.
+	tx := simTx(1)
+	if err := network.AddTx(tx); err != nil {
+		test.Fatalf("Failed to add tx : %v", err)
+	}
+	waitFor(test, "tx safe", func() bool {
+		return listener.hasState(tx.TxHash(), handlers.ListenerMsgTxStateSafe)
+	})
+
+	network.MineBlock()
+	waitFor(test, "tx confirm", func() bool {
+		return listener.hasState(tx.TxHash(), handlers.ListenerMsgTxStateConfirm)
+	})
+	test.Logf("Tx confirmed : %s", tx.TxHash().String())
+
+	if !txStore.Contains(tx.TxHash()) {
+		test.Fatalf("Relevant tx not in tx store")
+	}
+
+	// Reorg the block containing the tx out of the chain.
+	if _, err := network.Reorg(1, 2); err != nil {
+		test.Fatalf("Failed to reorg : %v", err)
+	}
+	waitFor(test, "tx revert", func() bool {
+		return listener.hasState(tx.TxHash(), handlers.ListenerMsgTxStateRevert)
+	})
+	waitFor(test, "reorg height", func() bool { return node.blocks.LastHeight() == network.Height() })
+	if len(network.MemPool()) != 1 {
+		test.Fatalf("Reverted tx not returned to mempool")
+	}
+	test.Logf("Tx reverted : %s", tx.TxHash().String())
+
+	// Double spend is seen and the conflicting tx is mined.
+	original := simTx(2)
+	doubleSpend := simTx(2)
+	doubleSpend.TxOut[0].Value++
+	if err := network.AddTx(original); err != nil {
+		test.Fatalf("Failed to add tx : %v", err)
+	}
+	if err := network.AddTx(doubleSpend); err != simnet.ErrConflict {
+		test.Fatalf("Conflicting tx not rejected : %v", err)
+	}
+	waitFor(test, "original seen", func() bool { return listener.hasTx(original.TxHash()) })
+
+	network.InjectTx(doubleSpend)
+	waitFor(test, "original unsafe", func() bool {
+		return listener.hasState(original.TxHash(), handlers.ListenerMsgTxStateUnsafe)
+	})
+
+	if _, err := network.MineBlockWith(doubleSpend); err != nil {
+		test.Fatalf("Failed to mine double spend : %v", err)
+	}
+	waitFor(test, "original cancel", func() bool {
+		return listener.hasState(original.TxHash(), handlers.ListenerMsgTxStateCancel)
+	})
+	test.Logf("Double spent tx canceled : %s", original.TxHash().String())
+
+	ds, err := node.DoubleSpend(ctx, original.TxHash())
+	if err != nil {
+		test.Fatalf("Failed to get double spend : %v", err)
+	}
+	if ds.Confirmed == nil || !ds.Confirmed.Equal(doubleSpend.TxHash()) {
+		test.Fatalf("Double spend confirm not recorded")
+	}
+
+	// Broadcast txs reach the network.
+	broadcast := simTx(3)
+	if err := node.BroadcastTx(ctx, broadcast); err != nil {
+		test.Fatalf("Failed to broadcast tx : %v", err)
+	}
+	waitFor(test, "broadcast received", func() bool {
+		for _, received := range network.Received() {
+			if received.TxHash().Equal(broadcast.TxHash()) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// simTx returns a tx spending a fake output identified by index.
+func simTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{1}, index), []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	return tx
+}
+
+func waitFor(test *testing.T, name string, check func() bool) {
+	for i := 0; i < 100; i++ {
+		if check() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	test.Fatalf("Timed out waiting for %s", name)
+}
+
+// simListener records notifications from spynode. All txs are marked as relevant.
+type simListener struct {
+	inSync bool
+	txs    []*wire.MsgTx
+	states map[bitcoin.Hash32][]int
+	lock   sync.Mutex
+}
+
+func (l *simListener) HandleBlock(ctx context.Context, msgType int,
+	block *handlers.BlockMessage) error {
+	return nil
+}
+
+func (l *simListener) HandleTx(ctx context.Context, tx *wire.MsgTx) (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.txs = append(l.txs, tx)
+	return true, nil
+}
+
+func (l *simListener) HandleTxState(ctx context.Context, msgType int, txid bitcoin.Hash32) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.states[txid] = append(l.states[txid], msgType)
+	return nil
+}
+
+func (l *simListener) HandleInSync(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inSync = true
+	return nil
+}
+
+func (l *simListener) isInSync() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.inSync
+}
+
+func (l *simListener) hasTx(txid *bitcoin.Hash32) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, tx := range l.txs {
+		if tx.TxHash().Equal(txid) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *simListener) hasState(txid *bitcoin.Hash32, msgType int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for _, state := range l.states[*txid] {
+		if state == msgType {
+			return true
+		}
+	}
+	return false
+}
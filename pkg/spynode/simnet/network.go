@@ -0,0 +1,545 @@
+// Package simnet implements the trusted peer side of the Bitcoin P2P protocol in process.
+//
+// A Network holds a simulated block chain and mempool. Connections are served with Connect, which
+//   matches dialer.MemoryPeer, so spynode can be pointed at a Network through a
+//   dialer.MemoryDialer. Blocks are only mined when requested and reorgs and conflicting txs can
+//   be created at any time, so daemon processing can be driven through confirm, revert and cancel
+//   sequences without a network.
+package simnet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/spynode/handlers"
+	"github.com/tokenized/smart-contract/pkg/spynode/handlers/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UserAgent = "/Tokenized:SimNet/"
+
+	// CoinbaseValue is the value of the single output of each mined coinbase tx.
+	CoinbaseValue = 5000000000
+)
+
+var (
+	ErrConflict = errors.New("Conflicts with mempool tx")
+	ErrNotFound = errors.New("Not found")
+)
+
+// Network is a simulated Bitcoin network with a single trusted peer.
+type Network struct {
+	net        bitcoin.Network
+	chain      []*wire.MsgBlock                  // Active chain. Index is block height.
+	blocks     map[bitcoin.Hash32]*wire.MsgBlock // All mined blocks, including reorged blocks
+	memPool    []*wire.MsgTx                     // Unconfirmed txs in the order they were seen
+	txs        map[bitcoin.Hash32]*wire.MsgTx    // All txs that can be requested with getdata
+	received   []*wire.MsgTx                     // Txs sent to the network by connected peers
+	peers      []*peer
+	extraNonce uint64 // Makes coinbase txs unique across forks
+	lastTime   time.Time
+	lock       sync.Mutex
+}
+
+// peer is a connection being served by the network.
+type peer struct {
+	conn        net.Conn
+	outgoing    chan wire.Message
+	ready       bool // Version was received
+	sendHeaders bool // Announce blocks with headers instead of inventory
+}
+
+// NewNetwork returns a network containing only the genesis block of the specified network.
+func NewNetwork(net bitcoin.Network) *Network {
+	genesis := wire.NewMsgBlock(storage.GenesisHeader(net))
+
+	result := Network{
+		net:      net,
+		chain:    []*wire.MsgBlock{genesis},
+		blocks:   make(map[bitcoin.Hash32]*wire.MsgBlock),
+		txs:      make(map[bitcoin.Hash32]*wire.MsgTx),
+		lastTime: genesis.Header.Timestamp,
+	}
+	result.blocks[*genesis.BlockHash()] = genesis
+
+	return &result
+}
+
+// Connect serves the trusted peer side of the protocol on the connection until it is closed.
+// It implements dialer.MemoryPeer.
+func (n *Network) Connect(conn net.Conn) {
+	ctx := logger.ContextWithLogSubSystem(context.Background(), "SimNet")
+
+	p := &peer{
+		conn:     conn,
+		outgoing: make(chan wire.Message, 1000),
+	}
+
+	n.lock.Lock()
+	n.peers = append(n.peers, p)
+	n.lock.Unlock()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range p.outgoing {
+			if err := wire.WriteMessage(conn, msg, wire.ProtocolVersion,
+				wire.BitcoinNet(n.net)); err != nil {
+				logger.Verbose(ctx, "Failed to send %s message : %s", msg.Command(), err)
+				conn.Close()
+				break
+			}
+		}
+
+		for range p.outgoing { // Drain so senders don't block
+		}
+	}()
+
+	for {
+		msg, _, err := wire.ReadMessage(conn, wire.ProtocolVersion, wire.BitcoinNet(n.net))
+		if err != nil {
+			if wireError, ok := err.(*wire.MessageError); ok &&
+				wireError.Type == wire.MessageErrorUnknownCommand {
+				continue
+			}
+			break
+		}
+
+		if err := n.handleMessage(ctx, p, msg); err != nil {
+			logger.Warn(ctx, "Failed to handle [%s] message : %s", msg.Command(), err)
+			break
+		}
+	}
+
+	n.lock.Lock()
+	for i, other := range n.peers {
+		if other == p {
+			n.peers = append(n.peers[:i], n.peers[i+1:]...)
+			break
+		}
+	}
+	close(p.outgoing)
+	n.lock.Unlock()
+
+	conn.Close()
+	wg.Wait()
+}
+
+// PeerCount returns the number of connections being served.
+func (n *Network) PeerCount() int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return len(n.peers)
+}
+
+// Height returns the height of the active chain.
+func (n *Network) Height() int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return len(n.chain) - 1
+}
+
+// Block returns the block at the specified height in the active chain.
+func (n *Network) Block(height int) (*wire.MsgBlock, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if height < 0 || height >= len(n.chain) {
+		return nil, ErrNotFound
+	}
+	return n.chain[height], nil
+}
+
+// MemPool returns the unconfirmed txs in the order they were seen.
+func (n *Network) MemPool() []*wire.MsgTx {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append([]*wire.MsgTx{}, n.memPool...)
+}
+
+// Received returns the txs sent to the network by connected peers, including rejected txs.
+func (n *Network) Received() []*wire.MsgTx {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	return append([]*wire.MsgTx{}, n.received...)
+}
+
+// AddTx adds a tx to the mempool and announces it to peers. ErrConflict is returned if it spends
+//   the same output as a tx already in the mempool.
+func (n *Network) AddTx(tx *wire.MsgTx) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if len(n.memPoolConflicts(tx)) > 0 {
+		return ErrConflict
+	}
+
+	n.addTx(tx)
+	return nil
+}
+
+// InjectTx adds a tx to the mempool and announces it to peers even if it conflicts with other
+//   mempool txs. It simulates a double spend being relayed.
+func (n *Network) InjectTx(tx *wire.MsgTx) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.addTx(tx)
+}
+
+// MineBlock mines a block containing all mempool txs that don't conflict with earlier mempool
+//   txs and announces it to peers.
+func (n *Network) MineBlock() *wire.MsgBlock {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var txs []*wire.MsgTx
+	for _, tx := range n.memPool {
+		if !conflictsWithAny(tx, txs) {
+			txs = append(txs, tx)
+		}
+	}
+
+	block := n.mine(txs)
+	n.announceBlocks([]*wire.MsgBlock{block})
+	return block
+}
+
+// MineBlockWith mines a block containing only the specified txs, whether or not they are in the
+//   mempool, and announces it to peers. Mempool txs that conflict with them are dropped. This
+//   simulates a miner confirming a double spend.
+func (n *Network) MineBlockWith(txs ...*wire.MsgTx) (*wire.MsgBlock, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for i, tx := range txs {
+		if conflictsWithAny(tx, txs[:i]) {
+			return nil, errors.Wrap(ErrConflict, tx.TxHash().String())
+		}
+	}
+
+	block := n.mine(txs)
+	n.announceBlocks([]*wire.MsgBlock{block})
+	return block, nil
+}
+
+// MineBlocks mines the specified number of blocks. The first contains the mempool txs and the
+//   rest are empty.
+func (n *Network) MineBlocks(count int) []*wire.MsgBlock {
+	var result []*wire.MsgBlock
+	for i := 0; i < count; i++ {
+		result = append(result, n.MineBlock())
+	}
+	return result
+}
+
+// Reorg replaces the top depth blocks of the active chain with count new empty blocks and
+//   announces the new chain to peers. Non-coinbase txs from the replaced blocks are returned to
+//   the mempool, so they can be confirmed again with MineBlock. The new blocks are returned.
+func (n *Network) Reorg(depth, count int) ([]*wire.MsgBlock, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if depth < 1 || depth >= len(n.chain) {
+		return nil, fmt.Errorf("Invalid reorg depth %d at height %d", depth, len(n.chain)-1)
+	}
+	if count <= depth {
+		return nil, fmt.Errorf("Reorg must add more than %d blocks", depth)
+	}
+
+	forkHeight := len(n.chain) - 1 - depth
+	reverted := n.chain[forkHeight+1:]
+	n.chain = n.chain[:forkHeight+1]
+
+	// Return reverted txs to the front of the mempool, since they were seen first.
+	var returned []*wire.MsgTx
+	for _, block := range reverted {
+		returned = append(returned, block.Transactions[1:]...)
+	}
+	n.memPool = append(returned, n.memPool...)
+
+	var result []*wire.MsgBlock
+	for i := 0; i < count; i++ {
+		result = append(result, n.mine(nil))
+	}
+
+	n.announceBlocks(result)
+	return result, nil
+}
+
+// addTx adds a tx to the mempool and announces it. The lock must be held.
+func (n *Network) addTx(tx *wire.MsgTx) {
+	hash := tx.TxHash()
+	if _, exists := n.txs[*hash]; exists {
+		return
+	}
+
+	n.txs[*hash] = tx
+	n.memPool = append(n.memPool, tx)
+
+	inv := wire.NewMsgInv()
+	inv.AddInvVect(wire.NewInvVect(wire.InvTypeTx, hash))
+	for _, p := range n.peers {
+		if p.ready {
+			p.outgoing <- inv
+		}
+	}
+}
+
+// memPoolConflicts returns the mempool txs that spend the same outputs as the tx. The lock must be
+//   held.
+func (n *Network) memPoolConflicts(tx *wire.MsgTx) []*wire.MsgTx {
+	var result []*wire.MsgTx
+	for _, memTx := range n.memPool {
+		if conflicts(tx, memTx) {
+			result = append(result, memTx)
+		}
+	}
+	return result
+}
+
+// mine adds a block containing the txs to the top of the active chain and removes them, and any
+//   txs that conflict with them, from the mempool. The lock must be held.
+func (n *Network) mine(txs []*wire.MsgTx) *wire.MsgBlock {
+	height := len(n.chain)
+	n.extraNonce++
+
+	coinbase := wire.NewMsgTx(1)
+	script := make([]byte, 0, 14)
+	script = append(script, 4) // push 4 byte height
+	script = append(script, byte(height), byte(height>>8), byte(height>>16), byte(height>>24))
+	script = append(script, 8) // push 8 byte extra nonce
+	extraNonce := make([]byte, 8)
+	binary.LittleEndian.PutUint64(extraNonce, n.extraNonce)
+	script = append(script, extraNonce...)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&bitcoin.Hash32{}, 0xffffffff), script))
+	coinbase.AddTxOut(wire.NewTxOut(CoinbaseValue, []byte{0x51})) // OP_TRUE
+
+	blockTxs := append([]*wire.MsgTx{coinbase}, txs...)
+	merkleRoot, _ := handlers.CalculateMerkleHash(context.Background(), blockTxs)
+
+	// Timestamps must increase for each block.
+	timestamp := time.Unix(time.Now().Unix(), 0)
+	if !timestamp.After(n.lastTime) {
+		timestamp = n.lastTime.Add(time.Second)
+	}
+	n.lastTime = timestamp
+
+	header := wire.NewBlockHeader(1, n.chain[height-1].BlockHash(), merkleRoot, 0x207fffff,
+		nonce())
+	header.Timestamp = timestamp
+
+	block := wire.NewMsgBlock(header)
+	for _, tx := range blockTxs {
+		block.AddTransaction(tx)
+		n.txs[*tx.TxHash()] = tx
+	}
+
+	n.chain = append(n.chain, block)
+	n.blocks[*block.BlockHash()] = block
+
+	// Remove mined and conflicting txs from mempool
+	var memPool []*wire.MsgTx
+	for _, memTx := range n.memPool {
+		mined := false
+		memHash := memTx.TxHash()
+		for _, tx := range txs {
+			if memHash.Equal(tx.TxHash()) || conflicts(memTx, tx) {
+				mined = true
+				break
+			}
+		}
+		if !mined {
+			memPool = append(memPool, memTx)
+		}
+	}
+	n.memPool = memPool
+
+	return block
+}
+
+// announceBlocks sends new blocks to peers. The lock must be held.
+func (n *Network) announceBlocks(blocks []*wire.MsgBlock) {
+	headers := wire.NewMsgHeaders()
+	inv := wire.NewMsgInv()
+	for _, block := range blocks {
+		headers.AddBlockHeader(&block.Header)
+		inv.AddInvVect(wire.NewInvVect(wire.InvTypeBlock, block.BlockHash()))
+	}
+
+	for _, p := range n.peers {
+		if !p.ready {
+			continue
+		}
+		if p.sendHeaders {
+			p.outgoing <- headers
+		} else {
+			p.outgoing <- inv
+		}
+	}
+}
+
+// handleMessage responds to a message from a peer.
+func (n *Network) handleMessage(ctx context.Context, p *peer, m wire.Message) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	switch msg := m.(type) {
+	case *wire.MsgVersion:
+		logger.Verbose(ctx, "Version : %s protocol %d, blocks %d", msg.UserAgent,
+			msg.ProtocolVersion, msg.LastBlock)
+		local := wire.NewNetAddressIPPort(net.IPv4(127, 0, 0, 1), 8333, 0)
+		remote := wire.NewNetAddressIPPort(net.IPv4(127, 0, 0, 1), 9333, 0)
+		version := wire.NewMsgVersion(local, remote, uint64(nonce()), int32(len(n.chain)-1))
+		version.UserAgent = UserAgent
+		version.Services = wire.SFNodeNetwork
+		p.outgoing <- version
+		p.outgoing <- wire.NewMsgVerAck()
+		p.ready = true
+
+	case *wire.MsgPing:
+		p.outgoing <- wire.NewMsgPong(msg.Nonce)
+
+	case *wire.MsgSendHeaders:
+		p.sendHeaders = true
+
+	case *wire.MsgGetAddr:
+		p.outgoing <- wire.NewMsgAddr() // There are no other peers
+
+	case *wire.MsgGetHeaders:
+		p.outgoing <- n.headersAfter(msg.BlockLocatorHashes, &msg.HashStop)
+
+	case *wire.MsgGetData:
+		notFound := wire.NewMsgNotFound()
+		for _, item := range msg.InvList {
+			switch item.Type {
+			case wire.InvTypeBlock:
+				if block, exists := n.blocks[item.Hash]; exists {
+					p.outgoing <- block
+					continue
+				}
+			case wire.InvTypeTx:
+				if tx, exists := n.txs[item.Hash]; exists {
+					p.outgoing <- tx
+					continue
+				}
+			}
+			notFound.AddInvVect(item)
+		}
+		if len(notFound.InvList) > 0 {
+			p.outgoing <- notFound
+		}
+
+	case *wire.MsgMemPool:
+		inv := wire.NewMsgInv()
+		for _, tx := range n.memPool {
+			if len(inv.InvList) == wire.MaxInvPerMsg {
+				p.outgoing <- inv
+				inv = wire.NewMsgInv()
+			}
+			inv.AddInvVect(wire.NewInvVect(wire.InvTypeTx, tx.TxHash()))
+		}
+		if len(inv.InvList) > 0 {
+			p.outgoing <- inv
+		}
+
+	case *wire.MsgTx:
+		n.received = append(n.received, msg)
+		if len(n.memPoolConflicts(msg)) > 0 {
+			reject := wire.NewMsgReject(wire.CmdTx, wire.RejectDuplicate, "txn-mempool-conflict")
+			reject.Hash = *msg.TxHash()
+			p.outgoing <- reject
+			return nil
+		}
+		n.addTx(msg)
+
+	case *wire.MsgReject:
+		logger.Warn(ctx, "Reject %s : %s", msg.Cmd, msg.Reason)
+	}
+
+	return nil
+}
+
+// headersAfter returns the headers in the active chain after the first locator hash found in it.
+// The lock must be held.
+func (n *Network) headersAfter(locators []*bitcoin.Hash32,
+	stop *bitcoin.Hash32) *wire.MsgHeaders {
+
+	start := 1 // Genesis is always known
+	for _, locator := range locators {
+		if height, exists := n.height(locator); exists {
+			start = height + 1
+			break
+		}
+	}
+
+	result := wire.NewMsgHeaders()
+	for height := start; height < len(n.chain); height++ {
+		if len(result.Headers) == wire.MaxBlockHeadersPerMsg {
+			break
+		}
+		result.AddBlockHeader(&n.chain[height].Header)
+		if n.chain[height].BlockHash().Equal(stop) {
+			break
+		}
+	}
+	return result
+}
+
+// height returns the height of a block in the active chain. The lock must be held.
+func (n *Network) height(hash *bitcoin.Hash32) (int, bool) {
+	for height := len(n.chain) - 1; height >= 0; height-- {
+		if n.chain[height].BlockHash().Equal(hash) {
+			return height, true
+		}
+	}
+	return -1, false
+}
+
+// conflicts returns true if the txs are different and spend any of the same outputs.
+func conflicts(tx, other *wire.MsgTx) bool {
+	if tx.TxHash().Equal(other.TxHash()) {
+		return false
+	}
+
+	for _, input := range tx.TxIn {
+		for _, otherInput := range other.TxIn {
+			if input.PreviousOutPoint.Hash.Equal(&otherInput.PreviousOutPoint.Hash) &&
+				input.PreviousOutPoint.Index == otherInput.PreviousOutPoint.Index {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflictsWithAny returns true if the tx conflicts with any of the others.
+func conflictsWithAny(tx *wire.MsgTx, others []*wire.MsgTx) bool {
+	for _, other := range others {
+		if conflicts(tx, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func nonce() uint32 {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return binary.LittleEndian.Uint32(buf)
+}
@@ -79,7 +79,7 @@ func (node *UntrustedNode) Run(ctx context.Context) error {
 	node.handlers = handlers.NewUntrustedCommandHandlers(ctx, node.state, node.peers, node.blocks, node.txs,
 		node.txTracker, node.memPool, node.txChannel, node.listeners, node.txFilters, node.address)
 
-	if err := node.connect(); err != nil {
+	if err := node.connect(ctx); err != nil {
 		node.lock.Unlock()
 		node.peers.UpdateScore(ctx, node.address, -1)
 		logger.Debug(ctx, "(%s) Connection failed : %s", node.address, err.Error())
@@ -192,8 +192,8 @@ func (node *UntrustedNode) ProcessBlock(ctx context.Context, txids []*bitcoin.Ha
 	return nil
 }
 
-func (node *UntrustedNode) connect() error {
-	conn, err := net.DialTimeout("tcp", node.address, 15*time.Second)
+func (node *UntrustedNode) connect(ctx context.Context) error {
+	conn, err := node.config.Dial(ctx, node.address)
 	if err != nil {
 		return err
 	}
@@ -261,6 +261,10 @@ func (node *UntrustedNode) check(ctx context.Context) error {
 		return nil // Still performing handshake
 	}
 
+	if node.peers.IsBanned(ctx, node.address) {
+		return errors.New("Peer banned")
+	}
+
 	if !node.state.HandshakeComplete() {
 		// Send header request to verify chain
 		headerRequest, err := buildHeaderRequest(ctx, node.state.ProtocolVersion(), node.blocks, handlers.UntrustedHeaderDelta, 10)
@@ -285,6 +289,7 @@ func (node *UntrustedNode) check(ctx context.Context) error {
 
 	if !node.state.ScoreUpdated() {
 		node.peers.UpdateScore(ctx, node.address, 5)
+		node.peers.MarkTried(ctx, node.address)
 		node.state.SetScoreUpdated()
 	}
 
@@ -10,6 +10,7 @@ const (
 	ErrorCodeBelowDustValue      = 5
 	ErrorCodeDuplicateInput      = 6
 	ErrorCodeMissingInputData    = 7
+	ErrorCodeScriptFailed        = 8
 )
 
 func IsErrorCode(err error, code int) bool {
@@ -52,6 +53,8 @@ func errorCodeString(code int) string {
 		return "Wrong Script Template"
 	case ErrorCodeDuplicateInput:
 		return "Duplicate Input"
+	case ErrorCodeScriptFailed:
+		return "Script Failed"
 	default:
 		return "Unknown Error Code"
 	}
@@ -0,0 +1,598 @@
+package txbuilder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/wire"
+)
+
+// maxScriptNumLength is the maximum bytes in a number used by arithmetic op codes.
+const maxScriptNumLength = 4
+
+// VerifyInput executes the unlocking script of the input at index followed by the locking script
+//   it spends, and returns an error if it doesn't leave true on the stack. Signatures are checked
+//   with the fork id signature hash.
+// It supports the op codes used by P2PKH, P2PK, P2SH, multi-PKH, RPH, and bare multi-sig scripts.
+func VerifyInput(tx *wire.MsgTx, index int, lockingScript []byte, value uint64,
+	hashCache *SigHashCache) error {
+
+	if index >= len(tx.TxIn) {
+		return newError(ErrorCodeScriptFailed, fmt.Sprintf("Input index out of range : %d", index))
+	}
+	if hashCache == nil {
+		hashCache = &SigHashCache{}
+	}
+
+	unlockingScript := tx.TxIn[index].SignatureScript
+	if !isPushOnly(unlockingScript) {
+		return newError(ErrorCodeScriptFailed, "Unlocking script is not push only")
+	}
+
+	in := &interpreter{tx: tx, index: index, value: value, hashCache: hashCache}
+
+	if err := in.execute(unlockingScript); err != nil {
+		return newError(ErrorCodeScriptFailed, fmt.Sprintf("unlocking script : %s", err))
+	}
+	unlockStack := in.copyStack()
+
+	if err := in.execute(lockingScript); err != nil {
+		return newError(ErrorCodeScriptFailed, fmt.Sprintf("locking script : %s", err))
+	}
+	if !in.success() {
+		return newError(ErrorCodeScriptFailed, "locking script result false")
+	}
+
+	// P2SH executes the redeem script from the unlocking script.
+	if ra, err := bitcoin.RawAddressFromLockingScript(lockingScript); err == nil &&
+		ra.Type() == bitcoin.ScriptTypeSH {
+
+		if len(unlockStack) == 0 {
+			return newError(ErrorCodeScriptFailed, "Missing redeem script")
+		}
+		redeemScript := unlockStack[len(unlockStack)-1]
+		in.stack = unlockStack[:len(unlockStack)-1]
+		in.altStack = nil
+
+		if err := in.execute(redeemScript); err != nil {
+			return newError(ErrorCodeScriptFailed, fmt.Sprintf("redeem script : %s", err))
+		}
+		if !in.success() {
+			return newError(ErrorCodeScriptFailed, "redeem script result false")
+		}
+	}
+
+	return nil
+}
+
+// VerifyTx verifies all inputs of a tx. inputs contains the output spent by each input.
+func VerifyTx(tx *wire.MsgTx, inputs []*wire.TxOut) error {
+	if len(inputs) != len(tx.TxIn) {
+		return newError(ErrorCodeMissingInputData, fmt.Sprintf("%d/%d inputs", len(inputs),
+			len(tx.TxIn)))
+	}
+
+	hashCache := &SigHashCache{}
+	for i, input := range inputs {
+		if err := VerifyInput(tx, i, input.PkScript, input.Value, hashCache); err != nil {
+			return newError(ErrorCodeScriptFailed, fmt.Sprintf("input %d : %s", i, err))
+		}
+	}
+
+	return nil
+}
+
+type interpreter struct {
+	tx        *wire.MsgTx
+	index     int
+	value     uint64
+	hashCache *SigHashCache
+
+	stack    [][]byte
+	altStack [][]byte
+}
+
+func (in *interpreter) execute(script []byte) error {
+	items, err := bitcoin.ParseScript(script)
+	if err != nil {
+		return err
+	}
+
+	// Each entry is whether that level of if statements is executing.
+	var conditions []bool
+	executing := func() bool {
+		for _, c := range conditions {
+			if !c {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, item := range items {
+		// Conditionals are tracked even when not executing.
+		switch item.OpCode {
+		case bitcoin.OP_IF, bitcoin.OP_NOTIF:
+			value := false
+			if executing() {
+				b, err := in.pop()
+				if err != nil {
+					return err
+				}
+				value = isTrue(b)
+				if item.OpCode == bitcoin.OP_NOTIF {
+					value = !value
+				}
+			}
+			conditions = append(conditions, value)
+			continue
+		case bitcoin.OP_ELSE:
+			if len(conditions) == 0 {
+				return fmt.Errorf("OP_ELSE without OP_IF")
+			}
+			conditions[len(conditions)-1] = !conditions[len(conditions)-1]
+			continue
+		case bitcoin.OP_ENDIF:
+			if len(conditions) == 0 {
+				return fmt.Errorf("OP_ENDIF without OP_IF")
+			}
+			conditions = conditions[:len(conditions)-1]
+			continue
+		}
+
+		if !executing() {
+			continue
+		}
+
+		if err := in.step(script, item); err != nil {
+			return fmt.Errorf("%s : %s", item.String(), err)
+		}
+	}
+
+	if len(conditions) != 0 {
+		return fmt.Errorf("Unbalanced conditional")
+	}
+
+	return nil
+}
+
+func (in *interpreter) step(script []byte, item bitcoin.ScriptItem) error {
+	if item.IsPush() {
+		if item.OpCode >= bitcoin.OP_1 && item.OpCode <= bitcoin.OP_16 {
+			in.push(encodeScriptNum(int64(item.OpCode-bitcoin.OP_1) + 1))
+		} else if item.OpCode == bitcoin.OP_1NEGATE {
+			in.push(encodeScriptNum(-1))
+		} else {
+			in.push(item.Data)
+		}
+		return nil
+	}
+
+	switch item.OpCode {
+	case bitcoin.OP_NOP:
+
+	case bitcoin.OP_RETURN:
+		return fmt.Errorf("Script returned")
+
+	case bitcoin.OP_VERIFY:
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		if !isTrue(b) {
+			return fmt.Errorf("Verify failed")
+		}
+
+	case bitcoin.OP_TOALTSTACK:
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		in.altStack = append(in.altStack, b)
+
+	case bitcoin.OP_FROMALTSTACK:
+		if len(in.altStack) == 0 {
+			return fmt.Errorf("Alt stack empty")
+		}
+		in.push(in.altStack[len(in.altStack)-1])
+		in.altStack = in.altStack[:len(in.altStack)-1]
+
+	case bitcoin.OP_DROP:
+		if _, err := in.pop(); err != nil {
+			return err
+		}
+
+	case bitcoin.OP_2DROP:
+		for i := 0; i < 2; i++ {
+			if _, err := in.pop(); err != nil {
+				return err
+			}
+		}
+
+	case bitcoin.OP_DUP:
+		b, err := in.peek(0)
+		if err != nil {
+			return err
+		}
+		in.push(b)
+
+	case bitcoin.OP_2DUP:
+		a, err := in.peek(1)
+		if err != nil {
+			return err
+		}
+		b, _ := in.peek(0)
+		in.push(a)
+		in.push(b)
+
+	case bitcoin.OP_OVER:
+		b, err := in.peek(1)
+		if err != nil {
+			return err
+		}
+		in.push(b)
+
+	case bitcoin.OP_NIP:
+		if len(in.stack) < 2 {
+			return fmt.Errorf("Stack too small")
+		}
+		in.stack = append(in.stack[:len(in.stack)-2], in.stack[len(in.stack)-1])
+
+	case bitcoin.OP_SWAP:
+		if len(in.stack) < 2 {
+			return fmt.Errorf("Stack too small")
+		}
+		l := len(in.stack)
+		in.stack[l-1], in.stack[l-2] = in.stack[l-2], in.stack[l-1]
+
+	case bitcoin.OP_ROT:
+		if len(in.stack) < 3 {
+			return fmt.Errorf("Stack too small")
+		}
+		l := len(in.stack)
+		third := in.stack[l-3]
+		in.stack = append(in.stack[:l-3], in.stack[l-2], in.stack[l-1], third)
+
+	case bitcoin.OP_SPLIT:
+		n, err := in.popNumber()
+		if err != nil {
+			return err
+		}
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		if n < 0 || n > int64(len(b)) {
+			return fmt.Errorf("Split position out of range : %d/%d", n, len(b))
+		}
+		in.push(append([]byte{}, b[:n]...))
+		in.push(append([]byte{}, b[n:]...))
+
+	case bitcoin.OP_SIZE:
+		b, err := in.peek(0)
+		if err != nil {
+			return err
+		}
+		in.push(encodeScriptNum(int64(len(b))))
+
+	case bitcoin.OP_EQUAL, bitcoin.OP_EQUALVERIFY:
+		a, err := in.pop()
+		if err != nil {
+			return err
+		}
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		equal := bytes.Equal(a, b)
+		if item.OpCode == bitcoin.OP_EQUALVERIFY {
+			if !equal {
+				return fmt.Errorf("Not equal")
+			}
+		} else {
+			in.push(encodeBool(equal))
+		}
+
+	case bitcoin.OP_1ADD:
+		n, err := in.popNumber()
+		if err != nil {
+			return err
+		}
+		in.push(encodeScriptNum(n + 1))
+
+	case bitcoin.OP_NOT:
+		n, err := in.popNumber()
+		if err != nil {
+			return err
+		}
+		in.push(encodeBool(n == 0))
+
+	case bitcoin.OP_GREATERTHANOREQUAL:
+		b, err := in.popNumber()
+		if err != nil {
+			return err
+		}
+		a, err := in.popNumber()
+		if err != nil {
+			return err
+		}
+		in.push(encodeBool(a >= b))
+
+	case bitcoin.OP_HASH160:
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		in.push(bitcoin.Hash160(b))
+
+	case bitcoin.OP_SHA256:
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(b)
+		in.push(hash[:])
+
+	case bitcoin.OP_HASH256:
+		b, err := in.pop()
+		if err != nil {
+			return err
+		}
+		in.push(bitcoin.DoubleSha256(b))
+
+	case bitcoin.OP_CHECKSIG, bitcoin.OP_CHECKSIGVERIFY:
+		pubKey, err := in.pop()
+		if err != nil {
+			return err
+		}
+		sig, err := in.pop()
+		if err != nil {
+			return err
+		}
+
+		valid, err := in.checkSig(script, sig, pubKey)
+		if err != nil {
+			return err
+		}
+		if item.OpCode == bitcoin.OP_CHECKSIGVERIFY {
+			if !valid {
+				return fmt.Errorf("Invalid signature")
+			}
+		} else {
+			in.push(encodeBool(valid))
+		}
+
+	case bitcoin.OP_CHECKMULTISIG, bitcoin.OP_CHECKMULTISIGVERIFY:
+		valid, err := in.checkMultiSig(script)
+		if err != nil {
+			return err
+		}
+		if item.OpCode == bitcoin.OP_CHECKMULTISIGVERIFY {
+			if !valid {
+				return fmt.Errorf("Invalid signatures")
+			}
+		} else {
+			in.push(encodeBool(valid))
+		}
+
+	default:
+		return fmt.Errorf("Unsupported op code")
+	}
+
+	return nil
+}
+
+// checkSig returns true if the signature is valid for the public key and the input. An empty
+//   signature returns false so it can be used to not sign. A malformed signature or public key
+//   returns an error.
+func (in *interpreter) checkSig(script, sig, pubKey []byte) (bool, error) {
+	if len(sig) == 0 {
+		return false, nil
+	}
+
+	hashType := SigHashType(sig[len(sig)-1])
+	if hashType&SigHashForkID == 0 {
+		return false, fmt.Errorf("Signature hash type missing fork id : 0x%02x", hashType)
+	}
+
+	signature, err := bitcoin.SignatureFromBytes(sig[:len(sig)-1])
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := bitcoin.PublicKeyFromBytes(pubKey)
+	if err != nil {
+		return false, err
+	}
+
+	hash, err := signatureHash(in.tx, in.index, script, in.value, hashType, in.hashCache)
+	if err != nil {
+		return false, err
+	}
+
+	return signature.Verify(hash, publicKey), nil
+}
+
+// checkMultiSig pops <dummy> <sig>... <m> <pubkey>... <n> and returns true if m signatures match
+//   the public keys in order.
+func (in *interpreter) checkMultiSig(script []byte) (bool, error) {
+	n, err := in.popNumber()
+	if err != nil {
+		return false, err
+	}
+	if n < 0 || n > 20 {
+		return false, fmt.Errorf("Invalid public key count : %d", n)
+	}
+	pubKeys := make([][]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		if pubKeys[i], err = in.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	m, err := in.popNumber()
+	if err != nil {
+		return false, err
+	}
+	if m < 0 || m > n {
+		return false, fmt.Errorf("Invalid signature count : %d", m)
+	}
+	sigs := make([][]byte, m)
+	for i := m - 1; i >= 0; i-- {
+		if sigs[i], err = in.pop(); err != nil {
+			return false, err
+		}
+	}
+
+	// Extra item popped because of the original off by one bug.
+	if _, err := in.pop(); err != nil {
+		return false, err
+	}
+
+	key := 0
+	for _, sig := range sigs {
+		matched := false
+		for key < len(pubKeys) {
+			valid, err := in.checkSig(script, sig, pubKeys[key])
+			key++
+			if err != nil {
+				return false, err
+			}
+			if valid {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (in *interpreter) success() bool {
+	if len(in.stack) == 0 {
+		return false
+	}
+	return isTrue(in.stack[len(in.stack)-1])
+}
+
+func (in *interpreter) copyStack() [][]byte {
+	result := make([][]byte, len(in.stack))
+	copy(result, in.stack)
+	return result
+}
+
+func (in *interpreter) push(b []byte) {
+	in.stack = append(in.stack, b)
+}
+
+func (in *interpreter) pop() ([]byte, error) {
+	if len(in.stack) == 0 {
+		return nil, fmt.Errorf("Stack empty")
+	}
+	b := in.stack[len(in.stack)-1]
+	in.stack = in.stack[:len(in.stack)-1]
+	return b, nil
+}
+
+// peek returns the item depth items from the top of the stack.
+func (in *interpreter) peek(depth int) ([]byte, error) {
+	if depth >= len(in.stack) {
+		return nil, fmt.Errorf("Stack too small")
+	}
+	return in.stack[len(in.stack)-1-depth], nil
+}
+
+func (in *interpreter) popNumber() (int64, error) {
+	b, err := in.pop()
+	if err != nil {
+		return 0, err
+	}
+	return decodeScriptNum(b)
+}
+
+func isPushOnly(script []byte) bool {
+	items, err := bitcoin.ParseScript(script)
+	if err != nil {
+		return false
+	}
+	for _, item := range items {
+		if !item.IsPush() {
+			return false
+		}
+	}
+	return true
+}
+
+// isTrue returns true unless the value is zero or negative zero.
+func isTrue(b []byte) bool {
+	for i, v := range b {
+		if v != 0 {
+			// Negative zero is false.
+			return !(i == len(b)-1 && v == 0x80)
+		}
+	}
+	return false
+}
+
+func encodeBool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+	return nil
+}
+
+// encodeScriptNum encodes a number as a minimal little endian sign magnitude value.
+func encodeScriptNum(n int64) []byte {
+	if n == 0 {
+		return nil
+	}
+
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	var result []byte
+	for n > 0 {
+		result = append(result, byte(n&0xff))
+		n >>= 8
+	}
+
+	if result[len(result)-1]&0x80 != 0 {
+		if negative {
+			result = append(result, 0x80)
+		} else {
+			result = append(result, 0x00)
+		}
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+
+	return result
+}
+
+func decodeScriptNum(b []byte) (int64, error) {
+	if len(b) > maxScriptNumLength {
+		return 0, fmt.Errorf("Number too long : %d bytes", len(b))
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	var result int64
+	for i, v := range b {
+		result |= int64(v) << uint8(8*i)
+	}
+
+	if b[len(b)-1]&0x80 != 0 {
+		result &= ^(int64(0x80) << uint8(8*(len(b)-1)))
+		return -result, nil
+	}
+
+	return result, nil
+}
@@ -0,0 +1,190 @@
+package txbuilder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/wire"
+)
+
+func TestVerifyP2PKH(t *testing.T) {
+	key, err := bitcoin.GenerateKey(bitcoin.TestNet)
+	if err != nil {
+		t.Fatalf("Failed to create private key : %s", err)
+	}
+
+	address, err := key.RawAddress()
+	if err != nil {
+		t.Fatalf("Failed to create address : %s", err)
+	}
+	lockingScript, err := address.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	tx := NewTxBuilder(500, 1.0)
+	tx.SetChangeAddress(address, "")
+	if err := tx.AddInput(wire.OutPoint{Index: 0}, lockingScript, 10000); err != nil {
+		t.Fatalf("Failed to add input : %s", err)
+	}
+	if err := tx.AddPaymentOutput(address, 5000, false); err != nil {
+		t.Fatalf("Failed to add output : %s", err)
+	}
+	if err := tx.Sign([]bitcoin.Key{key}); err != nil {
+		t.Fatalf("Failed to sign tx : %s", err)
+	}
+
+	if err := VerifyTx(tx.MsgTx, []*wire.TxOut{wire.NewTxOut(10000, lockingScript)}); err != nil {
+		t.Fatalf("Failed to verify tx : %s", err)
+	}
+
+	// Signature commits to the value spent.
+	err = VerifyInput(tx.MsgTx, 0, lockingScript, 10001, nil)
+	if !IsErrorCode(err, ErrorCodeScriptFailed) {
+		t.Fatalf("Verified with wrong value : %v", err)
+	}
+
+	// Different key
+	otherKey, err := bitcoin.GenerateKey(bitcoin.TestNet)
+	if err != nil {
+		t.Fatalf("Failed to create private key : %s", err)
+	}
+	otherAddress, _ := otherKey.RawAddress()
+	otherScript, _ := otherAddress.LockingScript()
+	if err := VerifyInput(tx.MsgTx, 0, otherScript, 10000, nil); err == nil {
+		t.Fatalf("Verified with wrong locking script")
+	}
+
+	// Modified output
+	tx.MsgTx.TxOut[0].Value--
+	if err := VerifyInput(tx.MsgTx, 0, lockingScript, 10000, nil); err == nil {
+		t.Fatalf("Verified modified tx")
+	}
+}
+
+func TestVerifyMultiPKH(t *testing.T) {
+	var keys []bitcoin.Key
+	var pubKeys, pkhs [][]byte
+	for i := 0; i < 3; i++ {
+		key, err := bitcoin.GenerateKey(bitcoin.TestNet)
+		if err != nil {
+			t.Fatalf("Failed to create private key : %s", err)
+		}
+		keys = append(keys, key)
+		pubKeys = append(pubKeys, key.PublicKey().Bytes())
+		pkhs = append(pkhs, bitcoin.Hash160(key.PublicKey().Bytes()))
+	}
+
+	address, err := bitcoin.NewAddressMultiPKH(2, pkhs, bitcoin.TestNet)
+	if err != nil {
+		t.Fatalf("Failed to create address : %s", err)
+	}
+	lockingScript, err := bitcoin.NewRawAddressFromAddress(address).LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil))
+	tx.AddTxOut(wire.NewTxOut(9000, lockingScript))
+
+	hashCache := &SigHashCache{}
+	sigs := make([][]byte, 3)
+	for _, i := range []int{0, 2} {
+		sigs[i], err = InputSignature(keys[i], tx, 0, lockingScript, 10000,
+			SigHashAll+SigHashForkID, hashCache)
+		if err != nil {
+			t.Fatalf("Failed to sign : %s", err)
+		}
+	}
+
+	tx.TxIn[0].SignatureScript, err = P2MultiPKHUnlockingScript(2, pubKeys, sigs)
+	if err != nil {
+		t.Fatalf("Failed to create unlocking script : %s", err)
+	}
+
+	if err := VerifyInput(tx, 0, lockingScript, 10000, nil); err != nil {
+		t.Fatalf("Failed to verify input : %s", err)
+	}
+
+	// Not enough signatures
+	tx.TxIn[0].SignatureScript, _ = P2MultiPKHUnlockingScript(2, pubKeys,
+		[][]byte{sigs[0], nil, nil})
+	if err := VerifyInput(tx, 0, lockingScript, 10000, nil); err == nil {
+		t.Fatalf("Verified with only one signature")
+	}
+
+	// Signature in the wrong position
+	tx.TxIn[0].SignatureScript, _ = P2MultiPKHUnlockingScript(2, pubKeys,
+		[][]byte{sigs[0], sigs[2], nil})
+	if err := VerifyInput(tx, 0, lockingScript, 10000, nil); err == nil {
+		t.Fatalf("Verified with signature for wrong key")
+	}
+}
+
+func TestVerifyP2SHMultiSig(t *testing.T) {
+	var keys []bitcoin.Key
+	redeemScript := &bytes.Buffer{}
+	redeemScript.WriteByte(bitcoin.OP_1 + 1)
+	for i := 0; i < 2; i++ {
+		key, err := bitcoin.GenerateKey(bitcoin.TestNet)
+		if err != nil {
+			t.Fatalf("Failed to create private key : %s", err)
+		}
+		keys = append(keys, key)
+		bitcoin.WritePushDataScript(redeemScript, key.PublicKey().Bytes())
+	}
+	redeemScript.WriteByte(bitcoin.OP_1 + 1)
+	redeemScript.WriteByte(bitcoin.OP_CHECKMULTISIG)
+
+	address, err := bitcoin.NewRawAddressSH(bitcoin.Hash160(redeemScript.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create address : %s", err)
+	}
+	lockingScript, err := address.LockingScript()
+	if err != nil {
+		t.Fatalf("Failed to create locking script : %s", err)
+	}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 0}, nil))
+	tx.AddTxOut(wire.NewTxOut(9000, lockingScript))
+
+	unlockingScript := &bytes.Buffer{}
+	unlockingScript.WriteByte(bitcoin.OP_FALSE)
+	for _, key := range keys {
+		// P2SH signatures sign the redeem script.
+		sig, err := InputSignature(key, tx, 0, redeemScript.Bytes(), 10000,
+			SigHashAll+SigHashForkID, &SigHashCache{})
+		if err != nil {
+			t.Fatalf("Failed to sign : %s", err)
+		}
+		bitcoin.WritePushDataScript(unlockingScript, sig)
+	}
+	bitcoin.WritePushDataScript(unlockingScript, redeemScript.Bytes())
+	tx.TxIn[0].SignatureScript = unlockingScript.Bytes()
+
+	if err := VerifyInput(tx, 0, lockingScript, 10000, nil); err != nil {
+		t.Fatalf("Failed to verify input : %s", err)
+	}
+
+	// Unlocking script must be push only.
+	tx.TxIn[0].SignatureScript = append([]byte{bitcoin.OP_DUP}, unlockingScript.Bytes()...)
+	if err := VerifyInput(tx, 0, lockingScript, 10000, nil); err == nil {
+		t.Fatalf("Verified non push unlocking script")
+	}
+}
+
+func TestScriptNum(t *testing.T) {
+	for _, n := range []int64{0, 1, -1, 16, 127, 128, -128, 255, 256, 32767, -32768, 1 << 30} {
+		b := encodeScriptNum(n)
+		result, err := decodeScriptNum(b)
+		if err != nil {
+			t.Fatalf("Failed to decode %d : %s", n, err)
+		}
+		if result != n {
+			t.Fatalf("Wrong number : got %d, want %d (%x)", result, n, b)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+package txstore
+
+/**
+ * Tx Store Kit
+ *
+ * What is my purpose?
+ * - You keep the raw txs that will be needed to parse tx inputs
+ * - You stay within a size limit by dropping the least recently used txs
+ * - You answer tx requests so an RPC node isn't needed
+ */
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/inspector"
+	"github.com/tokenized/smart-contract/pkg/logger"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// SubSystem is used by the logger package
+	SubSystem = "TxStore"
+
+	storagePath  = "txstore"
+	indexPath    = "txstore/index"
+	indexVersion = uint8(1)
+
+	DefaultMaxSize     = 100000000 // 100 MB
+	DefaultRecentCount = 50000
+)
+
+var (
+	// ErrNotFound is returned when a tx isn't in the store and there is no fallback.
+	ErrNotFound = inspector.ErrTxNotFound
+)
+
+// Metrics are counters describing the use of the store.
+type Metrics struct {
+	Count        int    // Number of stored txs
+	Size         uint64 // Total size of stored txs
+	RecentCount  int    // Number of recently seen txs held in memory
+	Hits         uint64 // Requests answered from stored txs
+	RecentHits   uint64 // Requests answered from recently seen txs
+	FallbackHits uint64 // Requests answered by the fallback
+	Misses       uint64 // Requests that couldn't be answered
+	Adds         uint64 // Txs stored
+	Evictions    uint64 // Txs removed to stay under the max size
+	Spills       uint64 // Seen txs stored when dropped from the recent list
+}
+
+func (m Metrics) String() string {
+	return fmt.Sprintf("count %d, size %d, recent %d, hits %d, recent hits %d, fallback hits %d, misses %d, adds %d, evictions %d, spills %d",
+		m.Count, m.Size, m.RecentCount, m.Hits, m.RecentHits, m.FallbackHits, m.Misses, m.Adds,
+		m.Evictions, m.Spills)
+}
+
+// Store is a persistent, size bounded store of raw txs that implements inspector.NodeInterface.
+//
+// Relevant txs, and the parents of relevant txs, are written to storage. Every other tx seen is
+//   held in a bounded in memory list so it is available if a relevant tx spending it is seen
+//   later. When there is no fallback, txs dropped from that list are written to storage too, so
+//   the parents of requests funded long ago can still be found. When the stored txs exceed the
+//   max size the least recently used are removed, seen txs before relevant txs.
+type Store struct {
+	store       storage.Storage
+	maxSize     uint64
+	recentCount int
+
+	// Fallback is used to request txs that aren't in the store. Txs it returns are stored.
+	Fallback inspector.NodeInterface
+
+	entries map[bitcoin.Hash32]*list.Element // Values are *entry
+	lru     *list.List                       // Relevant txs. Most recently used at front
+	seenLRU *list.List                       // Seen txs. Most recently used at front
+	size    uint64
+
+	recent     map[bitcoin.Hash32]*list.Element // Values are *wire.MsgTx
+	recentList *list.List                       // Most recently seen at front
+
+	metrics  Metrics
+	modified bool
+	lock     sync.Mutex
+}
+
+type entry struct {
+	txid     bitcoin.Hash32
+	size     uint64
+	relevant bool
+}
+
+// NewStore returns a new store. maxSize is the maximum total size of stored txs and recentCount
+//   is the number of recently seen txs held in memory.
+func NewStore(store storage.Storage, maxSize uint64, recentCount int) *Store {
+	return &Store{
+		store:       store,
+		maxSize:     maxSize,
+		recentCount: recentCount,
+		entries:     make(map[bitcoin.Hash32]*list.Element),
+		lru:         list.New(),
+		seenLRU:     list.New(),
+		recent:      make(map[bitcoin.Hash32]*list.Element),
+		recentList:  list.New(),
+	}
+}
+
+// Load reads the index of stored txs.
+func (s *Store) Load(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries = make(map[bitcoin.Hash32]*list.Element)
+	s.lru = list.New()
+	s.seenLRU = list.New()
+	s.size = 0
+
+	b, err := s.store.Read(ctx, indexPath)
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "read index")
+	}
+
+	buf := bytes.NewReader(b)
+
+	var version uint8
+	if err := binary.Read(buf, binary.LittleEndian, &version); err != nil {
+		return errors.Wrap(err, "read version")
+	}
+	if version > indexVersion {
+		return fmt.Errorf("Unknown tx store index version : %d", version)
+	}
+
+	var count uint32
+	if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+		return errors.Wrap(err, "read count")
+	}
+
+	// Entries are saved from most to least recently used.
+	for i := uint32(0); i < count; i++ {
+		txid, err := bitcoin.DeserializeHash32(buf)
+		if err != nil {
+			return errors.Wrap(err, "read txid")
+		}
+
+		var size uint32
+		if err := binary.Read(buf, binary.LittleEndian, &size); err != nil {
+			return errors.Wrap(err, "read size")
+		}
+
+		// Version 0 only stored relevant txs.
+		e := &entry{txid: *txid, size: uint64(size), relevant: true}
+		if version > 0 {
+			if err := binary.Read(buf, binary.LittleEndian, &e.relevant); err != nil {
+				return errors.Wrap(err, "read relevant")
+			}
+		}
+
+		if e.relevant {
+			s.entries[*txid] = s.lru.PushBack(e)
+		} else {
+			s.entries[*txid] = s.seenLRU.PushBack(e)
+		}
+		s.size += e.size
+	}
+
+	s.metrics.Count = len(s.entries)
+	s.metrics.Size = s.size
+	s.modified = false
+	return nil
+}
+
+// Save writes the index of stored txs if it has changed.
+func (s *Store) Save(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+	logger.Verbose(ctx, "Metrics : %s", s.metrics.String())
+
+	if !s.modified {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, indexVersion); err != nil {
+		return errors.Wrap(err, "write version")
+	}
+	if err := binary.Write(&buf, binary.LittleEndian, uint32(len(s.entries))); err != nil {
+		return errors.Wrap(err, "write count")
+	}
+
+	for _, l := range []*list.List{s.lru, s.seenLRU} {
+		for item := l.Front(); item != nil; item = item.Next() {
+			e := item.Value.(*entry)
+			if err := e.txid.Serialize(&buf); err != nil {
+				return errors.Wrap(err, "write txid")
+			}
+			if err := binary.Write(&buf, binary.LittleEndian, uint32(e.size)); err != nil {
+				return errors.Wrap(err, "write size")
+			}
+			if err := binary.Write(&buf, binary.LittleEndian, e.relevant); err != nil {
+				return errors.Wrap(err, "write relevant")
+			}
+		}
+	}
+
+	if err := s.store.Write(ctx, indexPath, buf.Bytes(), nil); err != nil {
+		return errors.Wrap(err, "write index")
+	}
+
+	s.modified = false
+	return nil
+}
+
+// Metrics returns the current metrics.
+func (s *Store) Metrics() Metrics {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.metrics
+}
+
+// Contains returns true if the tx is stored.
+func (s *Store) Contains(txid *bitcoin.Hash32) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, exists := s.entries[*txid]
+	return exists
+}
+
+// AddSeen holds a tx in memory in case a relevant tx spending it is seen later.
+func (s *Store) AddSeen(ctx context.Context, tx *wire.MsgTx) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.addRecent(logger.ContextWithLogSubSystem(ctx, SubSystem), tx)
+}
+
+// AddRelevant stores a tx and any of its parents that have been seen.
+func (s *Store) AddRelevant(ctx context.Context, tx *wire.MsgTx) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+
+	// Parents are kept first so they aren't evicted to make room for the tx.
+	for _, input := range tx.TxIn {
+		parentID := input.PreviousOutPoint.Hash
+		if item, exists := s.entries[parentID]; exists {
+			s.markRelevant(item)
+			continue
+		}
+
+		item, exists := s.recent[parentID]
+		if !exists {
+			continue
+		}
+
+		if err := s.add(ctx, item.Value.(*wire.MsgTx), true); err != nil {
+			return errors.Wrap(err, "add parent tx")
+		}
+	}
+
+	if err := s.add(ctx, tx, true); err != nil {
+		return errors.Wrap(err, "add tx")
+	}
+
+	return nil
+}
+
+// SaveTX stores a tx.
+// It implements inspector.NodeInterface.
+func (s *Store) SaveTX(ctx context.Context, tx *wire.MsgTx) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.add(logger.ContextWithLogSubSystem(ctx, SubSystem), tx, true)
+}
+
+// GetTX returns a tx from the store, the recently seen txs, or the fallback.
+// It implements inspector.NodeInterface.
+func (s *Store) GetTX(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	ctx = logger.ContextWithLogSubSystem(ctx, SubSystem)
+
+	s.lock.Lock()
+	tx, err := s.get(ctx, txid)
+	fallback := s.Fallback
+	s.lock.Unlock()
+
+	if err == nil {
+		return tx, nil
+	}
+	if errors.Cause(err) != ErrNotFound {
+		return nil, err
+	}
+
+	if fallback == nil {
+		s.lock.Lock()
+		s.metrics.Misses++
+		s.lock.Unlock()
+		return nil, errors.Wrap(ErrNotFound, txid.String())
+	}
+
+	tx, err = fallback.GetTX(ctx, txid)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err != nil {
+		s.metrics.Misses++
+		return nil, err
+	}
+
+	s.metrics.FallbackHits++
+	if err := s.add(ctx, tx, true); err != nil {
+		return nil, errors.Wrap(err, "add tx")
+	}
+	return tx, nil
+}
+
+// GetTXs returns txs in the same order as the txids. If any tx is not found then the txs found
+//   before it are returned with the error.
+// It implements inspector.NodeInterface.
+func (s *Store) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	results := make([]*wire.MsgTx, len(txids))
+	for i, txid := range txids {
+		tx, err := s.GetTX(ctx, txid)
+		if err != nil {
+			return results, err
+		}
+		results[i] = tx
+	}
+
+	return results, nil
+}
+
+// get returns a stored or recently seen tx. The lock must be held.
+func (s *Store) get(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	if item, exists := s.entries[*txid]; exists {
+		b, err := s.store.Read(ctx, txPath(txid))
+		if err == storage.ErrNotFound {
+			// The index was saved before the tx was removed.
+			s.remove(item)
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "read tx")
+		}
+
+		tx := &wire.MsgTx{}
+		if err := tx.Deserialize(bytes.NewReader(b)); err != nil {
+			return nil, errors.Wrap(err, "deserialize tx")
+		}
+
+		// It is needed so keep it with the relevant txs.
+		s.markRelevant(item)
+		s.metrics.Hits++
+		return tx, nil
+	}
+
+	if item, exists := s.recent[*txid]; exists {
+		tx := item.Value.(*wire.MsgTx)
+
+		// Store it since it is needed.
+		if err := s.add(ctx, tx, true); err != nil {
+			return nil, errors.Wrap(err, "add tx")
+		}
+
+		s.metrics.RecentHits++
+		return tx, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+// add writes a tx to storage and evicts the least recently used txs if the max size is
+//   exceeded. Seen txs that aren't relevant are evicted first. The lock must be held.
+func (s *Store) add(ctx context.Context, tx *wire.MsgTx, relevant bool) error {
+	txid := tx.TxHash()
+	if item, exists := s.entries[*txid]; exists {
+		if relevant {
+			s.markRelevant(item)
+		} else {
+			s.listOf(item).MoveToFront(item)
+			s.modified = true
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return errors.Wrap(err, "serialize tx")
+	}
+
+	if err := s.store.Write(ctx, txPath(txid), buf.Bytes(), nil); err != nil {
+		return errors.Wrap(err, "write tx")
+	}
+
+	e := &entry{txid: *txid, size: uint64(buf.Len()), relevant: relevant}
+	var added *list.Element
+	if relevant {
+		added = s.lru.PushFront(e)
+	} else {
+		added = s.seenLRU.PushFront(e)
+	}
+	s.entries[*txid] = added
+	s.size += e.size
+	s.modified = true
+	s.metrics.Adds++
+
+	for s.size > s.maxSize {
+		oldest := s.seenLRU.Back()
+		if oldest == nil || oldest == added {
+			oldest = s.lru.Back()
+		}
+		if oldest == nil || oldest == added {
+			break // Never evict the tx just added.
+		}
+
+		logger.Verbose(ctx, "Evicting tx : %s", oldest.Value.(*entry).txid.String())
+		if err := s.store.Remove(ctx, txPath(&oldest.Value.(*entry).txid)); err != nil &&
+			err != storage.ErrNotFound {
+			return errors.Wrap(err, "remove tx")
+		}
+		s.remove(oldest)
+		s.metrics.Evictions++
+	}
+
+	s.metrics.Count = len(s.entries)
+	s.metrics.Size = s.size
+	return nil
+}
+
+// markRelevant moves an entry to the front of the relevant txs so it is evicted after seen
+//   txs. The lock must be held.
+func (s *Store) markRelevant(item *list.Element) {
+	e := item.Value.(*entry)
+	if e.relevant {
+		s.lru.MoveToFront(item)
+	} else {
+		s.seenLRU.Remove(item)
+		e.relevant = true
+		s.entries[e.txid] = s.lru.PushFront(e)
+	}
+	s.modified = true
+}
+
+// listOf returns the list containing an entry.
+func (s *Store) listOf(item *list.Element) *list.List {
+	if item.Value.(*entry).relevant {
+		return s.lru
+	}
+	return s.seenLRU
+}
+
+// remove removes an entry from the index. The lock must be held.
+func (s *Store) remove(item *list.Element) {
+	e := item.Value.(*entry)
+	s.listOf(item).Remove(item)
+	delete(s.entries, e.txid)
+	s.size -= e.size
+	s.modified = true
+
+	s.metrics.Count = len(s.entries)
+	s.metrics.Size = s.size
+}
+
+// addRecent holds a tx in memory, dropping the oldest if there are too many. Dropped txs are
+//   stored when there is no fallback to request them from. The lock must be held.
+func (s *Store) addRecent(ctx context.Context, tx *wire.MsgTx) {
+	txid := tx.TxHash()
+	if item, exists := s.recent[*txid]; exists {
+		s.recentList.MoveToFront(item)
+		return
+	}
+
+	s.recent[*txid] = s.recentList.PushFront(tx)
+	for s.recentList.Len() > s.recentCount {
+		oldest := s.recentList.Back()
+		oldestTx := oldest.Value.(*wire.MsgTx)
+		delete(s.recent, *oldestTx.TxHash())
+		s.recentList.Remove(oldest)
+
+		if _, exists := s.entries[*oldestTx.TxHash()]; exists || s.Fallback != nil {
+			continue
+		}
+		if err := s.add(ctx, oldestTx, false); err != nil {
+			logger.Warn(ctx, "Failed to store seen tx %s : %s", oldestTx.TxHash().String(), err)
+			continue
+		}
+		s.metrics.Spills++
+	}
+
+	s.metrics.RecentCount = s.recentList.Len()
+}
+
+func txPath(txid *bitcoin.Hash32) string {
+	return fmt.Sprintf("%s/%s", storagePath, txid.String())
+}
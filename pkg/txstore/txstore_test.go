@@ -0,0 +1,317 @@
+package txstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/tokenized/smart-contract/pkg/bitcoin"
+	"github.com/tokenized/smart-contract/pkg/storage"
+	"github.com/tokenized/smart-contract/pkg/wire"
+
+	"github.com/pkg/errors"
+)
+
+func TestEviction(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	txs := make([]*wire.MsgTx, 5)
+	for i := range txs {
+		txs[i] = testTx(&bitcoin.Hash32{byte(i)}, 0)
+	}
+	txSize := uint64(txs[0].SerializeSize())
+
+	// Room for 3 txs
+	s := NewStore(store, 3*txSize, 10)
+	for _, tx := range txs[:3] {
+		if err := s.SaveTX(ctx, tx); err != nil {
+			t.Fatalf("Failed to save tx : %s", err)
+		}
+	}
+
+	// Use the first tx so the second is least recently used.
+	if _, err := s.GetTX(ctx, txs[0].TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+
+	if err := s.SaveTX(ctx, txs[3]); err != nil {
+		t.Fatalf("Failed to save tx : %s", err)
+	}
+
+	if s.Contains(txs[1].TxHash()) {
+		t.Fatalf("Least recently used tx not evicted")
+	}
+	for _, i := range []int{0, 2, 3} {
+		if !s.Contains(txs[i].TxHash()) {
+			t.Fatalf("Tx %d evicted", i)
+		}
+	}
+
+	if _, err := s.GetTX(ctx, txs[1].TxHash()); errors.Cause(err) != ErrNotFound {
+		t.Fatalf("Evicted tx returned : %v", err)
+	}
+	if _, err := store.Read(ctx, txPath(txs[1].TxHash())); err != storage.ErrNotFound {
+		t.Fatalf("Evicted tx not removed from storage : %v", err)
+	}
+
+	metrics := s.Metrics()
+	t.Logf("Metrics : %s", metrics.String())
+	if metrics.Count != 3 || metrics.Size != 3*txSize {
+		t.Fatalf("Wrong size metrics : count %d, size %d", metrics.Count, metrics.Size)
+	}
+	if metrics.Evictions != 1 || metrics.Hits != 1 || metrics.Misses != 1 || metrics.Adds != 4 {
+		t.Fatalf("Wrong counter metrics : %s", metrics.String())
+	}
+
+	// Index is restored in the same order.
+	if err := s.Save(ctx); err != nil {
+		t.Fatalf("Failed to save : %s", err)
+	}
+
+	loaded := NewStore(store, 3*txSize, 10)
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatalf("Failed to load : %s", err)
+	}
+
+	if err := loaded.SaveTX(ctx, txs[4]); err != nil {
+		t.Fatalf("Failed to save tx : %s", err)
+	}
+
+	// Tx 2 was least recently used before the save.
+	if loaded.Contains(txs[2].TxHash()) {
+		t.Fatalf("Least recently used tx not evicted after load")
+	}
+
+	tx, err := loaded.GetTX(ctx, txs[0].TxHash())
+	if err != nil {
+		t.Fatalf("Failed to get tx after load : %s", err)
+	}
+	if !bytes.Equal(serialize(t, tx), serialize(t, txs[0])) {
+		t.Fatalf("Wrong tx after load")
+	}
+}
+
+func TestRelevantParents(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	s := NewStore(store, DefaultMaxSize, 2)
+
+	parent := testTx(&bitcoin.Hash32{1}, 0)
+	other := testTx(&bitcoin.Hash32{2}, 0)
+	child := testTx(parent.TxHash(), 0)
+
+	s.AddSeen(ctx, parent)
+	s.AddSeen(ctx, other)
+
+	if s.Contains(parent.TxHash()) {
+		t.Fatalf("Seen tx stored before it was needed")
+	}
+
+	if err := s.AddRelevant(ctx, child); err != nil {
+		t.Fatalf("Failed to add relevant tx : %s", err)
+	}
+
+	if !s.Contains(child.TxHash()) {
+		t.Fatalf("Relevant tx not stored")
+	}
+	if !s.Contains(parent.TxHash()) {
+		t.Fatalf("Parent tx not stored")
+	}
+	if s.Contains(other.TxHash()) {
+		t.Fatalf("Unrelated tx stored")
+	}
+
+	// Recently seen txs are returned and stored when requested.
+	if _, err := s.GetTX(ctx, other.TxHash()); err != nil {
+		t.Fatalf("Failed to get recently seen tx : %s", err)
+	}
+	if !s.Contains(other.TxHash()) {
+		t.Fatalf("Requested tx not stored")
+	}
+	if s.Metrics().RecentHits != 1 {
+		t.Fatalf("Wrong recent hits : %d", s.Metrics().RecentHits)
+	}
+
+	// Only the most recent seen txs are held.
+	for i := 0; i < 3; i++ {
+		s.AddSeen(ctx, testTx(&bitcoin.Hash32{3}, uint32(i)))
+	}
+	if s.Metrics().RecentCount != 2 {
+		t.Fatalf("Wrong recent count : %d", s.Metrics().RecentCount)
+	}
+
+	// Without a fallback dropped txs are stored instead.
+	if !s.Contains(testTx(&bitcoin.Hash32{3}, 0).TxHash()) {
+		t.Fatalf("Dropped recent tx not stored")
+	}
+	if s.Metrics().Spills != 1 {
+		t.Fatalf("Wrong spills : %d", s.Metrics().Spills)
+	}
+}
+
+func TestEvictedRecentParent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	parent := testTx(&bitcoin.Hash32{1}, 0)
+	relevant := testTx(&bitcoin.Hash32{2}, 0)
+	txSize := uint64(parent.SerializeSize())
+
+	// Room for 3 txs with only 1 recent tx held in memory.
+	s := NewStore(store, 3*txSize, 1)
+
+	if err := s.AddRelevant(ctx, relevant); err != nil {
+		t.Fatalf("Failed to add relevant tx : %s", err)
+	}
+
+	s.AddSeen(ctx, parent)
+	for i := 0; i < 2; i++ {
+		s.AddSeen(ctx, testTx(&bitcoin.Hash32{3}, uint32(i)))
+	}
+
+	// The parent was dropped from the recent list long before the tx spending it is seen.
+	child := testTx(parent.TxHash(), 0)
+	if err := s.AddRelevant(ctx, child); err != nil {
+		t.Fatalf("Failed to add relevant tx : %s", err)
+	}
+
+	tx, err := s.GetTX(ctx, parent.TxHash())
+	if err != nil {
+		t.Fatalf("Failed to get evicted parent : %s", err)
+	}
+	if !bytes.Equal(serialize(t, tx), serialize(t, parent)) {
+		t.Fatalf("Wrong parent tx")
+	}
+
+	// Seen txs are evicted before relevant txs.
+	if s.Contains(testTx(&bitcoin.Hash32{3}, 0).TxHash()) {
+		t.Fatalf("Seen tx not evicted")
+	}
+	for _, tx := range []*wire.MsgTx{relevant, parent, child} {
+		if !s.Contains(tx.TxHash()) {
+			t.Fatalf("Relevant tx evicted : %s", tx.TxHash().String())
+		}
+	}
+
+	// Relevance is restored after a restart.
+	if err := s.Save(ctx); err != nil {
+		t.Fatalf("Failed to save : %s", err)
+	}
+
+	loaded := NewStore(store, 3*txSize, 1)
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatalf("Failed to load : %s", err)
+	}
+
+	loaded.AddSeen(ctx, testTx(&bitcoin.Hash32{4}, 0))
+	loaded.AddSeen(ctx, testTx(&bitcoin.Hash32{4}, 1))
+	if !loaded.Contains(parent.TxHash()) {
+		t.Fatalf("Relevant parent evicted after load")
+	}
+
+	// Dropped txs aren't stored when they can be requested from the fallback.
+	withFallback := NewStore(newTestStorage(t), DefaultMaxSize, 1)
+	withFallback.Fallback = &testNode{txs: make(map[bitcoin.Hash32]*wire.MsgTx)}
+	withFallback.AddSeen(ctx, parent)
+	withFallback.AddSeen(ctx, relevant)
+	if withFallback.Contains(parent.TxHash()) {
+		t.Fatalf("Dropped tx stored with a fallback")
+	}
+}
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStorage(t)
+
+	tx := testTx(&bitcoin.Hash32{1}, 0)
+	fallback := &testNode{txs: map[bitcoin.Hash32]*wire.MsgTx{*tx.TxHash(): tx}}
+
+	s := NewStore(store, DefaultMaxSize, 0)
+	s.Fallback = fallback
+
+	txs, err := s.GetTXs(ctx, []*bitcoin.Hash32{tx.TxHash()})
+	if err != nil {
+		t.Fatalf("Failed to get txs : %s", err)
+	}
+	if !txs[0].TxHash().Equal(tx.TxHash()) {
+		t.Fatalf("Wrong tx")
+	}
+	if !s.Contains(tx.TxHash()) {
+		t.Fatalf("Fallback tx not stored")
+	}
+	if s.Metrics().FallbackHits != 1 {
+		t.Fatalf("Wrong fallback hits : %d", s.Metrics().FallbackHits)
+	}
+
+	// Stored txs don't use the fallback.
+	if _, err := s.GetTX(ctx, tx.TxHash()); err != nil {
+		t.Fatalf("Failed to get tx : %s", err)
+	}
+	if fallback.requests != 1 {
+		t.Fatalf("Wrong fallback requests : %d", fallback.requests)
+	}
+
+	if _, err := s.GetTX(ctx, &bitcoin.Hash32{2}); err == nil {
+		t.Fatalf("Unknown tx returned")
+	}
+	if s.Metrics().Misses != 1 {
+		t.Fatalf("Wrong misses : %d", s.Metrics().Misses)
+	}
+}
+
+// testNode is a fallback node containing a fixed set of txs.
+type testNode struct {
+	txs      map[bitcoin.Hash32]*wire.MsgTx
+	requests int
+}
+
+func (n *testNode) SaveTX(ctx context.Context, tx *wire.MsgTx) error {
+	n.txs[*tx.TxHash()] = tx
+	return nil
+}
+
+func (n *testNode) GetTX(ctx context.Context, txid *bitcoin.Hash32) (*wire.MsgTx, error) {
+	n.requests++
+	tx, exists := n.txs[*txid]
+	if !exists {
+		return nil, errors.New("Tx not found")
+	}
+	return tx, nil
+}
+
+func (n *testNode) GetTXs(ctx context.Context, txids []*bitcoin.Hash32) ([]*wire.MsgTx, error) {
+	results := make([]*wire.MsgTx, len(txids))
+	for i, txid := range txids {
+		tx, err := n.GetTX(ctx, txid)
+		if err != nil {
+			return results, err
+		}
+		results[i] = tx
+	}
+	return results, nil
+}
+
+func newTestStorage(t *testing.T) storage.Storage {
+	store := storage.NewFilesystemStorage(storage.NewConfig("standalone", "./tmp/test"))
+	if err := store.Clear(context.Background(), map[string]string{"path": storagePath}); err != nil {
+		t.Fatalf("Failed to clear storage : %s", err)
+	}
+	return store
+}
+
+func testTx(parent *bitcoin.Hash32, index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(parent, index), []byte{0x51}))
+	tx.AddTxOut(wire.NewTxOut(1000, []byte{0x51}))
+	return tx
+}
+
+func serialize(t *testing.T, tx *wire.MsgTx) []byte {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("Failed to serialize tx : %s", err)
+	}
+	return buf.Bytes()
+}
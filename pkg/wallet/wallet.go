@@ -16,6 +16,7 @@ import (
 )
 
 type WalletInterface interface {
+	Add(*Key) error
 	Get(bitcoin.RawAddress) (*Key, error)
 	List([]bitcoin.RawAddress) ([]*Key, error)
 	ListAll() []*Key